@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// APIKeyHeader 機器客戶端傳送 API key 的 header
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix API key 的固定開頭，方便在程式碼或日誌中辨識外洩的 key
+const apiKeyPrefix = "mk_"
+
+var (
+	ErrInvalidAPIKey        = errors.New("無效的 API key")
+	ErrAmbiguousCredentials = errors.New("請勿同時提供 Authorization 與 X-API-Key")
+)
+
+// APIKeyAuthenticator 驗證 API key 並返回其代表的呼叫者；權限為 key 的範圍與會員目前權限的交集。
+// 與 SessionValidator 相同，由服務層在啟動時註冊實作。
+type APIKeyAuthenticator func(ctx context.Context, rawKey, clientIP string) (*Principal, error)
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator 註冊 API key 驗證，傳入 nil 表示不接受 API key
+func SetAPIKeyAuthenticator(a APIKeyAuthenticator) {
+	apiKeyAuthenticator = a
+}
+
+// GenerateAPIKey 產生新的 API key，格式為 mk_<8 碼識別碼>_<密鑰>；
+// 識別碼可公開顯示以辨認是哪一把 key，完整 key 只會返回一次
+func GenerateAPIKey() (string, string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	prefix := hex.EncodeToString(buf)
+
+	secret, err := GenerateOpaqueToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return apiKeyPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// ParseAPIKeyPrefix 取出 API key 的識別碼，格式不符時返回 false
+func ParseAPIKeyPrefix(rawKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(rawKey, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 8 || secret == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(prefix); err != nil {
+		return "", false
+	}
+	return prefix, true
+}
+
+// AuthenticateRequest 依請求帶的憑證驗證呼叫者：X-API-Key 或 Bearer token 擇一，REST 與 GraphQL 共用
+func AuthenticateRequest(ctx context.Context, authHeader, apiKey, clientIP string) (*Principal, error) {
+	if apiKey == "" {
+		claims, err := Authenticate(ctx, authHeader)
+		if err != nil {
+			return nil, err
+		}
+		return NewPrincipal(claims), nil
+	}
+
+	if authHeader != "" {
+		return nil, ErrAmbiguousCredentials
+	}
+	if _, ok := ParseAPIKeyPrefix(apiKey); !ok || apiKeyAuthenticator == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	return apiKeyAuthenticator(ctx, apiKey, clientIP)
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	raw, prefix, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, "mk_"+prefix+"_"))
+	assert.Len(t, prefix, 8)
+
+	parsed, ok := ParseAPIKeyPrefix(raw)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+
+	other, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, raw, other)
+}
+
+func TestParseAPIKeyPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		ok   bool
+	}{
+		{"格式正確", "mk_0a1b2c3d_secret", true},
+		{"缺少開頭", "0a1b2c3d_secret", false},
+		{"識別碼長度錯誤", "mk_0a1b_secret", false},
+		{"識別碼不是十六進位", "mk_zzzzzzzz_secret", false},
+		{"缺少密鑰", "mk_0a1b2c3d_", false},
+		{"Bearer token", "eyJhbGciOiJFZERTQSJ9.e30.sig", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := ParseAPIKeyPrefix(tt.raw)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer SetAPIKeyAuthenticator(nil)
+
+	validKey, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	SetAPIKeyAuthenticator(func(_ context.Context, rawKey, clientIP string) (*Principal, error) {
+		if rawKey != validKey {
+			return nil, ErrInvalidAPIKey
+		}
+		assert.NotEmpty(t, clientIP)
+		return &Principal{UserID: 7, Email: "bot@example.com", APIKeyID: 3, Permissions: []string{PermProductRead}}, nil
+	})
+
+	serve := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(AuthMiddleware())
+		router.GET("/", RequirePermission(PermProductRead), func(c *gin.Context) {
+			principal := PrincipalFromContext(c.Request.Context())
+			assert.Equal(t, uint(3), principal.APIKeyID)
+			assert.Equal(t, int64(7), c.MustGet("user_id"))
+			c.Status(http.StatusOK)
+		})
+		router.GET("/admin", RequirePermission(PermMemberDelete), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/", map[string]string{APIKeyHeader: validKey}).Code)
+
+	// key 的範圍不包含的權限
+	assert.Equal(t, http.StatusForbidden, serve("/admin", map[string]string{APIKeyHeader: validKey}).Code)
+
+	w := serve("/", map[string]string{APIKeyHeader: "mk_00000000_wrong"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), ErrInvalidAPIKey.Error())
+
+	w = serve("/", map[string]string{APIKeyHeader: validKey, "Authorization": "Bearer x"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), ErrAmbiguousCredentials.Error())
+
+	// 未註冊驗證實作時一律拒絕
+	SetAPIKeyAuthenticator(nil)
+	assert.Equal(t, http.StatusUnauthorized, serve("/", map[string]string{APIKeyHeader: validKey}).Code)
+}
@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTooManyAttempts = errors.New("登入嘗試過於頻繁，請稍後再試")
+	ErrAccountLocked   = errors.New("帳號因多次登入失敗已暫時鎖定，請稍後再試")
+)
+
+// LockoutPolicy 登入失敗的退避與鎖定規則
+type LockoutPolicy struct {
+	// FreeAttempts 不受延遲限制的失敗次數
+	FreeAttempts int
+	// BaseDelay 超過免費次數後第一次失敗的等待時間，之後每次加倍
+	BaseDelay time.Duration
+	// MaxDelay 退避等待時間上限
+	MaxDelay time.Duration
+	// LockoutThreshold 連續失敗達到此次數即鎖定，0 表示不鎖定
+	LockoutThreshold int
+	// LockoutDuration 鎖定時間
+	LockoutDuration time.Duration
+	// ResetAfter 距上次失敗超過此時間後重新計數
+	ResetAfter time.Duration
+}
+
+// DefaultAccountLockoutPolicy 以 email 為單位的預設規則
+func DefaultAccountLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Minute,
+		LockoutThreshold: 10,
+		LockoutDuration:  15 * time.Minute,
+		ResetAfter:       time.Hour,
+	}
+}
+
+// DefaultIPLockoutPolicy 以來源 IP 為單位的預設規則，門檻較高以免誤傷共用 IP 的使用者
+func DefaultIPLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		FreeAttempts:     20,
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		LockoutThreshold: 0,
+		ResetAfter:       time.Hour,
+	}
+}
+
+// AttemptState 某個 key（email 或 IP）的登入失敗狀態
+type AttemptState struct {
+	Failures      int
+	LastFailureAt time.Time
+	// BlockedUntil 在此時間前拒絕登入
+	BlockedUntil time.Time
+	// Locked 表示 BlockedUntil 來自鎖定而非退避
+	Locked bool
+}
+
+// RetryAfter 返回距可再次嘗試的剩餘時間，0 表示可立即嘗試
+func (s AttemptState) RetryAfter(now time.Time) time.Duration {
+	if now.Before(s.BlockedUntil) {
+		return s.BlockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// Fail 將一次失敗套用到狀態上並返回新狀態
+func (p LockoutPolicy) Fail(state AttemptState, now time.Time) AttemptState {
+	if p.ResetAfter > 0 && !state.LastFailureAt.IsZero() && now.Sub(state.LastFailureAt) > p.ResetAfter {
+		state = AttemptState{}
+	}
+
+	state.Failures++
+	state.LastFailureAt = now
+	state.Locked = false
+
+	if p.LockoutThreshold > 0 && state.Failures >= p.LockoutThreshold {
+		state.BlockedUntil = now.Add(p.LockoutDuration)
+		state.Locked = true
+		return state
+	}
+
+	if over := state.Failures - p.FreeAttempts; over > 0 {
+		delay := p.BaseDelay
+		for i := 1; i < over && delay < p.MaxDelay; i++ {
+			delay *= 2
+		}
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+		state.BlockedUntil = now.Add(delay)
+	}
+
+	return state
+}
+
+// Expired 判斷狀態是否已無作用，可以清除
+func (p LockoutPolicy) Expired(state AttemptState, now time.Time) bool {
+	if now.Before(state.BlockedUntil) {
+		return false
+	}
+	return p.ResetAfter > 0 && now.Sub(state.LastFailureAt) > p.ResetAfter
+}
+
+// LoginAttemptTracker 保存登入失敗狀態，實作必須保證 RecordFailure 的讀寫是原子的
+type LoginAttemptTracker interface {
+	// Get 返回 key 目前的狀態，不存在時返回零值
+	Get(ctx context.Context, key string) (AttemptState, error)
+	// RecordFailure 依 policy 記錄一次失敗並返回新狀態
+	RecordFailure(ctx context.Context, key string, policy LockoutPolicy) (AttemptState, error)
+	// Reset 清除 key 的狀態
+	Reset(ctx context.Context, key string) error
+}
+
+// MemoryAttemptTracker 以記憶體保存狀態，適用於單一實例部署與測試
+type MemoryAttemptTracker struct {
+	mu      sync.Mutex
+	entries map[string]AttemptState
+	now     func() time.Time
+}
+
+// memoryTrackerPruneSize 記錄數超過此值時清除已過期的狀態
+const memoryTrackerPruneSize = 10000
+
+func NewMemoryAttemptTracker() *MemoryAttemptTracker {
+	return &MemoryAttemptTracker{entries: make(map[string]AttemptState), now: time.Now}
+}
+
+func (t *MemoryAttemptTracker) Get(_ context.Context, key string) (AttemptState, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.entries[key], nil
+}
+
+func (t *MemoryAttemptTracker) RecordFailure(_ context.Context, key string, policy LockoutPolicy) (AttemptState, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if len(t.entries) >= memoryTrackerPruneSize {
+		for k, state := range t.entries {
+			if policy.Expired(state, now) {
+				delete(t.entries, k)
+			}
+		}
+	}
+
+	state := policy.Fail(t.entries[key], now)
+	t.entries[key] = state
+	return state, nil
+}
+
+func (t *MemoryAttemptTracker) Reset(_ context.Context, key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, key)
+	return nil
+}
+
+// LoginGuard 同時以 email 與來源 IP 追蹤登入失敗
+type LoginGuard struct {
+	Tracker       LoginAttemptTracker
+	AccountPolicy LockoutPolicy
+	IPPolicy      LockoutPolicy
+}
+
+// NewLoginGuard 以預設規則建立 LoginGuard
+func NewLoginGuard(tracker LoginAttemptTracker) *LoginGuard {
+	return &LoginGuard{
+		Tracker:       tracker,
+		AccountPolicy: DefaultAccountLockoutPolicy(),
+		IPPolicy:      DefaultIPLockoutPolicy(),
+	}
+}
+
+var loginGuard = NewLoginGuard(NewMemoryAttemptTracker())
+
+// SetLoginGuard 替換全域的 LoginGuard，例如改用資料庫實作以支援多實例部署
+func SetLoginGuard(g *LoginGuard) {
+	loginGuard = g
+}
+
+// CurrentLoginGuard 返回目前使用的 LoginGuard
+func CurrentLoginGuard() *LoginGuard {
+	return loginGuard
+}
+
+// AccountAttemptKey 返回 email 對應的追蹤 key，不分大小寫
+func AccountAttemptKey(email string) string {
+	return "email:" + NormalizeEmail(email)
+}
+
+// IPAttemptKey 返回來源 IP 對應的追蹤 key
+func IPAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check 在驗證密碼前呼叫；被限制時返回 ErrAccountLocked 或 ErrTooManyAttempts 與剩餘等待時間
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	account, err := g.Tracker.Get(ctx, AccountAttemptKey(email))
+	if err != nil {
+		return 0, err
+	}
+	if wait := account.RetryAfter(now); wait > 0 {
+		if account.Locked {
+			return wait, ErrAccountLocked
+		}
+		return wait, ErrTooManyAttempts
+	}
+
+	if ip != "" {
+		source, err := g.Tracker.Get(ctx, IPAttemptKey(ip))
+		if err != nil {
+			return 0, err
+		}
+		if wait := source.RetryAfter(now); wait > 0 {
+			return wait, ErrTooManyAttempts
+		}
+	}
+
+	return 0, nil
+}
+
+// Fail 記錄一次登入失敗，返回 email 的新狀態
+func (g *LoginGuard) Fail(ctx context.Context, email, ip string) (AttemptState, error) {
+	if ip != "" {
+		if _, err := g.Tracker.RecordFailure(ctx, IPAttemptKey(ip), g.IPPolicy); err != nil {
+			return AttemptState{}, err
+		}
+	}
+	return g.Tracker.RecordFailure(ctx, AccountAttemptKey(email), g.AccountPolicy)
+}
+
+// Succeed 登入成功後清除 email 的失敗紀錄；IP 紀錄保留，避免攻擊者以自己的帳號重置計數
+func (g *LoginGuard) Succeed(ctx context.Context, email string) error {
+	return g.Tracker.Reset(ctx, AccountAttemptKey(email))
+}
+
+// Unlock 解除 email 的鎖定與退避
+func (g *LoginGuard) Unlock(ctx context.Context, email string) error {
+	return g.Tracker.Reset(ctx, AccountAttemptKey(email))
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockoutPolicyFail(t *testing.T) {
+	policy := LockoutPolicy{
+		FreeAttempts:     2,
+		BaseDelay:        time.Second,
+		MaxDelay:         4 * time.Second,
+		LockoutThreshold: 6,
+		LockoutDuration:  time.Minute,
+		ResetAfter:       time.Hour,
+	}
+	now := time.Unix(1700000000, 0)
+
+	var state AttemptState
+	wantDelays := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second}
+	for i, want := range wantDelays {
+		state = policy.Fail(state, now)
+		assert.Equal(t, i+1, state.Failures)
+		assert.Equal(t, want, state.RetryAfter(now), "failure %d", i+1)
+		assert.False(t, state.Locked)
+	}
+
+	// 達到門檻即鎖定
+	state = policy.Fail(state, now)
+	assert.True(t, state.Locked)
+	assert.Equal(t, time.Minute, state.RetryAfter(now))
+
+	// 超過 ResetAfter 後重新計數
+	later := now.Add(2 * time.Hour)
+	assert.True(t, policy.Expired(state, later))
+	state = policy.Fail(state, later)
+	assert.Equal(t, 1, state.Failures)
+	assert.False(t, state.Locked)
+	assert.Zero(t, state.RetryAfter(later))
+}
+
+func TestLoginGuard(t *testing.T) {
+	ctx := context.Background()
+	guard := NewLoginGuard(NewMemoryAttemptTracker())
+	guard.AccountPolicy = LockoutPolicy{LockoutThreshold: 3, LockoutDuration: time.Minute, ResetAfter: time.Hour}
+	guard.IPPolicy = LockoutPolicy{FreeAttempts: 100, BaseDelay: time.Second, MaxDelay: time.Second, ResetAfter: time.Hour}
+
+	for i := 0; i < 2; i++ {
+		_, err := guard.Fail(ctx, "User@Example.com", "203.0.113.7")
+		require.NoError(t, err)
+	}
+	_, err := guard.Check(ctx, "user@example.com", "203.0.113.7")
+	assert.NoError(t, err)
+
+	state, err := guard.Fail(ctx, "user@example.com", "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, state.Locked)
+
+	wait, err := guard.Check(ctx, "USER@example.com", "198.51.100.1")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	assert.Greater(t, wait, time.Duration(0))
+
+	// 其他帳號不受影響
+	_, err = guard.Check(ctx, "other@example.com", "203.0.113.7")
+	assert.NoError(t, err)
+
+	require.NoError(t, guard.Unlock(ctx, "user@example.com"))
+	_, err = guard.Check(ctx, "user@example.com", "203.0.113.7")
+	assert.NoError(t, err)
+}
+
+func TestLoginGuardIPBackoff(t *testing.T) {
+	ctx := context.Background()
+	guard := NewLoginGuard(NewMemoryAttemptTracker())
+	guard.IPPolicy = LockoutPolicy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Minute, ResetAfter: time.Hour}
+
+	_, err := guard.Fail(ctx, "a@example.com", "203.0.113.7")
+	require.NoError(t, err)
+	_, err = guard.Fail(ctx, "b@example.com", "203.0.113.7")
+	require.NoError(t, err)
+
+	// 同一 IP 嘗試其他帳號也會被退避
+	_, err = guard.Check(ctx, "c@example.com", "203.0.113.7")
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+
+	// 登入成功不會清除 IP 的紀錄
+	require.NoError(t, guard.Succeed(ctx, "c@example.com"))
+	_, err = guard.Check(ctx, "c@example.com", "203.0.113.7")
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+}
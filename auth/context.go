@@ -0,0 +1,82 @@
+package auth
+
+import "context"
+
+// Principal 已認證的呼叫者，由 token claims 或 API key 轉換而來並放入 request context
+type Principal struct {
+	UserID    uint
+	Email     string
+	SessionID string
+	TokenID   string
+	// APIKeyID 以 API key 認證時為該 key 的 ID，以 token 認證時為 0
+	APIKeyID uint
+	// ClientID 以 OAuth access token 認證時為簽發對象的用戶端，Scopes 為其授權範圍
+	ClientID string
+	Scopes   []string
+	// ActorID 與 ActorEmail 為代理登入時實際操作的管理員，一般請求為 0 與空字串
+	ActorID       uint
+	ActorEmail    string
+	Roles         []string
+	Permissions   []string
+	Groups        []string
+	EmailVerified bool
+}
+
+// NewPrincipal 由已驗證的 claims 建立 Principal
+func NewPrincipal(claims *Claims) *Principal {
+	var userID uint
+	if claims.UserID > 0 {
+		userID = uint(claims.UserID)
+	}
+	var actorEmail string
+	if claims.Act != nil {
+		actorEmail = claims.Act.Email
+	}
+	return &Principal{
+		UserID:        userID,
+		Email:         claims.Email,
+		SessionID:     claims.SessionID,
+		TokenID:       claims.ID,
+		ClientID:      claims.ClientID,
+		Scopes:        ParseScope(claims.Scope),
+		ActorID:       claims.ActorID(),
+		ActorEmail:    actorEmail,
+		Roles:         claims.Roles,
+		Permissions:   claims.Permissions,
+		Groups:        claims.Groups,
+		EmailVerified: claims.EmailVerified,
+	}
+}
+
+// HasPermission 判斷呼叫者是否擁有指定權限
+func (p *Principal) HasPermission(permission string) bool {
+	return contains(p.Permissions, permission)
+}
+
+// IsFirstParty 判斷呼叫者是否為會員本人登入取得的 token，而非 API key、第三方應用程式或管理員代理登入
+func (p *Principal) IsFirstParty() bool {
+	return p.APIKeyID == 0 && p.ClientID == "" && p.ActorID == 0
+}
+
+// HasRole 判斷呼叫者是否擁有指定角色
+func (p *Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+// InGroup 判斷呼叫者是否屬於指定群組
+func (p *Principal) InGroup(group string) bool {
+	return contains(p.Groups, group)
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal 將 Principal 放入 context，供 GraphQL resolver 與 service 使用
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext 從 context 取出 Principal，未認證時返回 nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
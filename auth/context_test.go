@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPrincipal(t *testing.T) {
+	claims := NewClaims(9, "p@example.com")
+	claims.SessionID = "family"
+	claims.Roles = []string{RoleAdmin}
+	claims.Permissions = []string{PermMemberDelete}
+	claims.Groups = []string{"vip"}
+
+	principal := NewPrincipal(claims)
+
+	assert.Equal(t, uint(9), principal.UserID)
+	assert.Equal(t, "p@example.com", principal.Email)
+	assert.Equal(t, "family", principal.SessionID)
+	assert.True(t, principal.HasRole(RoleAdmin))
+	assert.True(t, principal.HasPermission(PermMemberDelete))
+	assert.False(t, principal.HasPermission(PermRoleManage))
+	assert.True(t, principal.InGroup("vip"))
+	assert.False(t, principal.InGroup("staff"))
+}
+
+func TestNewPrincipalNegativeUserID(t *testing.T) {
+	principal := NewPrincipal(NewClaims(-1, "neg@example.com"))
+	assert.Equal(t, uint(0), principal.UserID)
+}
+
+func TestPrincipalContext(t *testing.T) {
+	assert.Nil(t, PrincipalFromContext(context.Background()))
+
+	principal := &Principal{UserID: 3}
+	ctx := ContextWithPrincipal(context.Background(), principal)
+	assert.Same(t, principal, PrincipalFromContext(ctx))
+}
+
+func TestAuthenticate(t *testing.T) {
+	token, err := GenerateToken(5, "a@example.com")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr error
+	}{
+		{name: "缺少 header", header: "", wantErr: ErrMissingAuthHeader},
+		{name: "缺少 Bearer 前綴", header: token, wantErr: ErrInvalidAuthHeader},
+		{name: "無效 token", header: "Bearer invalid", wantErr: ErrInvalidToken},
+		{name: "有效 token", header: "Bearer " + token},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := Authenticate(context.Background(), tt.header)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, claims)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(5), claims.UserID)
+		})
+	}
+}
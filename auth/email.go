@@ -0,0 +1,10 @@
+package auth
+
+import "strings"
+
+// NormalizeEmail 返回 email 的標準形式：去除前後空白並轉為小寫。
+// 會員 email 以此形式儲存與查詢，Foo@Example.com 與 foo@example.com 視為同一個帳號；
+// 不去除 + 別名，別名是不同的收件地址，重複帳號由管理員另行合併
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
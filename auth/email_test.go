@@ -0,0 +1,25 @@
+package auth
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "user@example.com", want: "user@example.com"},
+		{email: "Foo@Example.COM", want: "foo@example.com"},
+		{email: "  spaced@example.com\t", want: "spaced@example.com"},
+		{email: "User+Shop@Example.com", want: "user+shop@example.com"},
+		{email: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeEmail(tt.email); got != tt.want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+	if AccountAttemptKey("Foo@Example.com ") != AccountAttemptKey("foo@example.com") {
+		t.Error("AccountAttemptKey() differs for emails with the same normalized form")
+	}
+}
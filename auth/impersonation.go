@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var ErrImpersonating = errors.New("代理登入期間不可執行此操作")
+
+// impersonationTTL 代理登入 token 的有效期，預設 15 分鐘，不簽發 refresh token
+var impersonationTTL = 15 * time.Minute
+
+// Actor 代理登入時實際操作的管理員，對應 RFC 8693 的 act claim
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+}
+
+// ImpersonatedRequest 代理登入期間的一個請求
+type ImpersonatedRequest struct {
+	Method    string
+	Path      string
+	Status    int
+	IP        string
+	UserAgent string
+}
+
+// ImpersonationRecorder 記錄代理登入期間的每個請求；與 SessionValidator 相同，由服務層在啟動時註冊實作
+type ImpersonationRecorder func(ctx context.Context, principal *Principal, req ImpersonatedRequest)
+
+var impersonationRecorder ImpersonationRecorder
+
+// SetImpersonationRecorder 註冊代理登入請求的記錄，傳入 nil 表示不記錄
+func SetImpersonationRecorder(r ImpersonationRecorder) {
+	impersonationRecorder = r
+}
+
+// SetImpersonationTTL 設定代理登入 token 的有效期，非正值會被忽略
+func SetImpersonationTTL(ttl time.Duration) {
+	if ttl > 0 {
+		impersonationTTL = ttl
+	}
+}
+
+// ImpersonationTTL 返回代理登入 token 的有效期
+func ImpersonationTTL() time.Duration {
+	return impersonationTTL
+}
+
+// SignImpersonationToken 以目標會員的 claims 簽發代理登入 token，act claim 記錄實際操作的管理員；
+// claims 的 SessionID 為代理登入紀錄的 ID
+func SignImpersonationToken(claims *Claims, actorID uint, actorEmail string) (string, error) {
+	claims.Act = &Actor{Subject: strconv.FormatUint(uint64(actorID), 10), Email: actorEmail}
+	return signClaims(claims, impersonationTTL)
+}
+
+// ActorID 返回代理登入的管理員 ID，不是代理登入 token 時為 0
+func (c *Claims) ActorID() uint {
+	if c.Act == nil {
+		return 0
+	}
+	id, err := strconv.ParseUint(c.Act.Subject, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// IsImpersonated 判斷請求是否由管理員代理會員發出
+func (p *Principal) IsImpersonated() bool {
+	return p.ActorID != 0
+}
+
+// RecordImpersonatedRequest 若請求為代理登入，交給已註冊的記錄器；REST 與 GraphQL 在請求處理完後呼叫
+func RecordImpersonatedRequest(ctx context.Context, r *http.Request, status int, clientIP string) {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil || !principal.IsImpersonated() || impersonationRecorder == nil {
+		return
+	}
+	impersonationRecorder(ctx, principal, ImpersonatedRequest{
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Status:    status,
+		IP:        clientIP,
+		UserAgent: r.UserAgent(),
+	})
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignImpersonationToken(t *testing.T) {
+	defer SetSessionValidator(nil)
+
+	claims := NewClaims(7, "member@example.com")
+	claims.SessionID = "imp-1"
+	claims.Permissions = []string{PermProductRead}
+	token, err := SignImpersonationToken(claims, 1, "admin@example.com")
+	require.NoError(t, err)
+
+	var validated *Claims
+	SetSessionValidator(func(_ context.Context, c *Claims) error {
+		validated = c
+		return nil
+	})
+	parsed, err := Authenticate(context.Background(), "Bearer "+token)
+	require.NoError(t, err)
+	require.NotNil(t, validated)
+	assert.Equal(t, int64(7), parsed.UserID)
+	assert.Equal(t, uint(1), parsed.ActorID())
+	assert.WithinDuration(t, parsed.IssuedAt.Add(ImpersonationTTL()), parsed.ExpiresAt.Time, 0)
+
+	principal := NewPrincipal(parsed)
+	assert.True(t, principal.IsImpersonated())
+	assert.Equal(t, uint(1), principal.ActorID)
+	assert.Equal(t, "admin@example.com", principal.ActorEmail)
+	assert.False(t, principal.IsFirstParty())
+	assert.True(t, principal.HasPermission(PermProductRead))
+
+	// 一般 token 沒有 act claim
+	plain, err := ValidateToken(mustGenerateToken(t, 7, "member@example.com"))
+	require.NoError(t, err)
+	assert.Nil(t, plain.Act)
+	assert.Zero(t, plain.ActorID())
+	assert.False(t, NewPrincipal(plain).IsImpersonated())
+}
+
+func TestRequireFirstPartyImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/profile/password", nil)
+	c.Request = c.Request.WithContext(ContextWithPrincipal(c.Request.Context(), &Principal{UserID: 7, ActorID: 1}))
+
+	RequireFirstParty()(c)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), ErrImpersonating.Error())
+}
+
+func TestAuthMiddlewareRecordsImpersonatedRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer SetSessionValidator(nil)
+	defer SetImpersonationRecorder(nil)
+	SetSessionValidator(func(context.Context, *Claims) error { return nil })
+
+	var recorded []ImpersonatedRequest
+	var actors []uint
+	SetImpersonationRecorder(func(_ context.Context, p *Principal, req ImpersonatedRequest) {
+		actors = append(actors, p.ActorID)
+		recorded = append(recorded, req)
+	})
+
+	router := gin.New()
+	router.Use(AuthMiddleware())
+	router.GET("/products", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	claims := NewClaims(7, "member@example.com")
+	claims.SessionID = "imp-1"
+	impersonated, err := SignImpersonationToken(claims, 1, "admin@example.com")
+	require.NoError(t, err)
+
+	for _, token := range []string{impersonated, mustGenerateToken(t, 7, "member@example.com")} {
+		req := httptest.NewRequest("GET", "/products", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", "support-console")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	}
+
+	// 只記錄代理登入的請求
+	require.Len(t, recorded, 1)
+	assert.Equal(t, []uint{1}, actors)
+	assert.Equal(t, "GET", recorded[0].Method)
+	assert.Equal(t, "/products", recorded[0].Path)
+	assert.Equal(t, http.StatusNoContent, recorded[0].Status)
+	assert.Equal(t, "support-console", recorded[0].UserAgent)
+}
+
+func mustGenerateToken(t *testing.T, userID int64, email string) string {
+	t.Helper()
+	token, err := GenerateToken(userID, email)
+	require.NoError(t, err)
+	return token
+}
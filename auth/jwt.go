@@ -1,45 +1,121 @@
 package auth
 
 import (
+	"errors"
+	"log"
 	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// jwtSecret 舊版 HS256 密鑰，只在設定 JWT_SECRET 時用於驗證遷移期間尚未過期的舊 token，不再用於簽發
 var jwtSecret []byte
 
+// accessTokenTTL access token 的有效期，預設 15 分鐘，可由 SetTokenTTL 覆蓋
+var accessTokenTTL = 15 * time.Minute
+
+// refreshTokenTTL refresh token 的有效期，預設 30 天
+var refreshTokenTTL = 30 * 24 * time.Hour
+
 func init() {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key-change-in-production"
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		jwtSecret = []byte(secret)
+	}
+
+	// 預設使用臨時產生的 Ed25519 金鑰，正式環境應由 JWT_KEYS_DIR 載入固定金鑰
+	key, err := GenerateEd25519Key("ephemeral")
+	if err != nil {
+		log.Fatalf("failed to generate signing key: %v", err)
 	}
-	jwtSecret = []byte(secret)
+	ks := NewKeySet()
+	ks.Add(key)
+	SetKeySet(ks)
+}
+
+// SetTokenTTL 設定 access token 與 refresh token 的有效期，非正值會被忽略
+func SetTokenTTL(access, refresh time.Duration) {
+	if access > 0 {
+		accessTokenTTL = access
+	}
+	if refresh > 0 {
+		refreshTokenTTL = refresh
+	}
+}
+
+// AccessTokenTTL 返回 access token 的有效期
+func AccessTokenTTL() time.Duration {
+	return accessTokenTTL
+}
+
+// RefreshTokenTTL 返回 refresh token 的有效期
+func RefreshTokenTTL() time.Duration {
+	return refreshTokenTTL
 }
 
 // Claims 定義 JWT 的 claims
 type Claims struct {
 	UserID int64  `json:"user_id"`
 	Email  string `json:"email"`
+	// SessionID 對應 refresh token 的 family，用於登出時識別登入鏈
+	SessionID   string   `json:"sid,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"perms,omitempty"`
+	// Groups 會員所屬的群組名稱，供下游服務依會員分群（如 VIP 價格）判斷；群組異動在 token 刷新後生效
+	Groups        []string `json:"groups,omitempty"`
+	EmailVerified bool     `json:"email_verified"`
+	// Purpose 非空時表示特殊用途 token（如 MFA challenge、OAuth access token），不能作為一般 access token
+	Purpose string `json:"purpose,omitempty"`
+	// ClientID 與 Scope 只出現在簽發給 OAuth 用戶端的 token
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	// Act 只出現在代理登入 token，記錄實際操作的管理員
+	Act *Actor `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成 JWT token
-func GenerateToken(userID int64, email string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour) // 24小時過期
-
-	claims := &Claims{
+// NewClaims 建立帶有基本用戶信息的 claims，時間欄位由 SignClaims 填入
+func NewClaims(userID int64, email string) *Claims {
+	return &Claims{
 		UserID: userID,
 		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "member-api",
-		},
 	}
+}
+
+// SignClaims 填入簽發時間與過期時間後，以目前的簽章金鑰簽署 access token
+func SignClaims(claims *Claims) (string, error) {
+	return signClaims(claims, accessTokenTTL)
+}
+
+// signClaims 以目前的簽章金鑰簽署 claims，有效期為 ttl
+func signClaims(claims *Claims, ttl time.Duration) (string, error) {
+	// jti 讓每個 token 可被個別識別，用於審計與撤銷
+	if claims.ID == "" {
+		jti, err := GenerateOpaqueToken(16)
+		if err != nil {
+			return "", err
+		}
+		claims.ID = jti
+	}
+
+	now := time.Now()
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.Issuer = "member-api"
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	key := CurrentKeySet().Active()
+	if key == nil {
+		return "", errors.New("沒有可用的簽章金鑰")
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+// GenerateToken 生成 JWT access token
+func GenerateToken(userID int64, email string) (string, error) {
+	return SignClaims(NewClaims(userID, email))
 }
 
 // ValidateToken 驗證 JWT token
@@ -47,17 +123,26 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		// 驗證簽名算法，防止算法替換攻擊
-		// 第一步：確保使用 HMAC 算法（拒絕 RSA, ECDSA, none 等）
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
+		// 舊版 HMAC token：只接受 HS256，且必須明確設定了 JWT_SECRET
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			if token.Method.Alg() != jwt.SigningMethodHS256.Alg() || len(jwtSecret) == 0 {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return jwtSecret, nil
 		}
-		// 第二步：只接受 HS256，拒絕 HS384 和 HS512
-		// 這確保了與我們的密鑰生成和期望算法的一致性
-		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+
+		// 非對稱 token：以 kid 找到金鑰，且算法必須與該金鑰一致
+		kid, _ := token.Header["kid"].(string)
+		key := CurrentKeySet().Lookup(kid)
+		if key == nil || token.Method.Alg() != key.Method.Alg() {
 			return nil, jwt.ErrSignatureInvalid
 		}
-		return jwtSecret, nil
-	})
+		return key.public, nil
+	}, jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+		jwt.SigningMethodHS256.Alg(),
+	}))
 
 	if err != nil {
 		return nil, err
@@ -9,7 +9,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// setupTest 統一的測試環境設置
+// setupTest 統一的測試環境設置，同時啟用舊版 HS256 密鑰以覆蓋遷移期間的驗證路徑
 func setupTest(t *testing.T) {
 	t.Helper()
 	if err := os.Setenv("JWT_SECRET", "test-secret-key"); err != nil {
@@ -135,8 +135,8 @@ func TestGenerateTokenExpiration(t *testing.T) {
 		t.Fatalf("ValidateToken() failed: %v", err)
 	}
 
-	// 驗證過期時間應該是 24 小時後
-	expectedExpiry := time.Now().Add(24 * time.Hour)
+	// 驗證過期時間應該等於 access token 有效期
+	expectedExpiry := time.Now().Add(AccessTokenTTL())
 	actualExpiry := claims.ExpiresAt.Time
 
 	// 允許 5 秒的誤差
@@ -582,13 +582,13 @@ func TestValidateTokenAlgorithmSubstitution(t *testing.T) {
 			description: "使用 RS256 算法的 token 應該被拒絕（防止非對稱算法攻擊）",
 		},
 		{
-			name: "正常的 HS256 token 應該通過",
+			name: "正常簽發的 token 應該通過",
 			setupToken: func() string {
 				token, _ := GenerateToken(1, "valid@example.com")
 				return token
 			},
 			wantErr:     false,
-			description: "正確的 HS256 token 應該通過驗證",
+			description: "以目前簽章金鑰簽發的 token 應該通過驗證",
 		},
 	}
 
@@ -611,3 +611,47 @@ func TestValidateTokenAlgorithmSubstitution(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTokenLegacyHS256(t *testing.T) {
+	claims := createTestClaims(1, "legacy@example.com", time.Now().Add(time.Hour), time.Now())
+
+	t.Run("設定 JWT_SECRET 時接受舊版 HS256 token", func(t *testing.T) {
+		setupTest(t)
+		tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+
+		parsed, err := ValidateToken(tokenString)
+		if err != nil {
+			t.Fatalf("ValidateToken() failed: %v", err)
+		}
+		if parsed.Email != "legacy@example.com" {
+			t.Errorf("Email = %v, want legacy@example.com", parsed.Email)
+		}
+	})
+
+	t.Run("未設定 JWT_SECRET 時拒絕 HS256 token", func(t *testing.T) {
+		setupTest(t)
+		tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+
+		jwtSecret = nil
+		defer func() { jwtSecret = []byte("test-secret-key") }()
+
+		if _, err := ValidateToken(tokenString); err == nil {
+			t.Error("未設定 JWT_SECRET 時 HS256 token 應該被拒絕")
+		}
+	})
+
+	t.Run("新簽發的 token 不使用 HS256", func(t *testing.T) {
+		setupTest(t)
+		tokenString, _ := GenerateToken(1, "new@example.com")
+		token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+		if err != nil {
+			t.Fatalf("ParseUnverified() failed: %v", err)
+		}
+		if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+			t.Error("新 token 不應使用 HS256 簽發")
+		}
+		if _, ok := token.Header["kid"].(string); !ok {
+			t.Error("新 token 應帶有 kid header")
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key 一把以 kid 識別的簽章金鑰；只有公鑰的 Key 僅用於驗證（已退役的金鑰）
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// CanSign 判斷金鑰是否可用於簽發 token
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// NewKey 由私鑰或公鑰建立 Key，支援 RSA（RS256）與 Ed25519（EdDSA）
+func NewKey(kid string, key interface{}) (*Key, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, public: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, private: k, public: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, public: k}, nil
+	default:
+		return nil, fmt.Errorf("不支援的金鑰類型 %T", key)
+	}
+}
+
+// GenerateEd25519Key 產生新的 Ed25519 簽章金鑰
+func GenerateEd25519Key(kid string) (*Key, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(kid, private)
+}
+
+// KeySet 一組同時有效的金鑰，其中一把為目前的簽章金鑰
+type KeySet struct {
+	mu       sync.RWMutex
+	keys     map[string]*Key
+	activeID string
+}
+
+// NewKeySet 建立空的 KeySet
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]*Key)}
+}
+
+// Add 加入金鑰；第一把可簽章的金鑰自動成為簽章金鑰
+func (ks *KeySet) Add(key *Key) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[key.ID] = key
+	if ks.activeID == "" && key.CanSign() {
+		ks.activeID = key.ID
+	}
+}
+
+// SetActive 指定用於簽發 token 的金鑰
+func (ks *KeySet) SetActive(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	key, ok := ks.keys[kid]
+	if !ok {
+		return fmt.Errorf("找不到金鑰 %q", kid)
+	}
+	if !key.CanSign() {
+		return fmt.Errorf("金鑰 %q 只有公鑰，無法用於簽章", kid)
+	}
+	ks.activeID = kid
+	return nil
+}
+
+// Active 返回目前的簽章金鑰，沒有時返回 nil
+func (ks *KeySet) Active() *Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[ks.activeID]
+}
+
+// Lookup 依 kid 取得驗證用的金鑰
+func (ks *KeySet) Lookup(kid string) *Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys[kid]
+}
+
+// JWK JSON Web Key（RFC 7517），只包含公鑰資訊
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// PublicKey 將 JWK 轉換為公鑰，支援 RSA、EC（P-256/P-384/P-521）與 OKP（Ed25519）
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exp.IsInt64() || exp.Int64() <= 1 || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("無效的 RSA JWK")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+
+	case "EC":
+		var (
+			curve elliptic.Curve
+			check ecdh.Curve
+		)
+		switch k.Crv {
+		case "P-256":
+			curve, check = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, check = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, check = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("不支援的曲線 %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("無效的 EC JWK")
+		}
+		// 以未壓縮格式交給 ecdh 驗證點是否在曲線上
+		point := append([]byte{4}, append(x, y...)...)
+		if _, err := check.NewPublicKey(point); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("不支援的曲線 %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("無效的 Ed25519 JWK")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("不支援的金鑰類型 %q", k.Kty)
+	}
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回所有金鑰的公鑰，供其他服務驗證 token
+func (ks *KeySet) JWKS() JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	out := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		key := ks.keys[id]
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		out.Keys = append(out.Keys, jwk)
+	}
+	return out
+}
+
+// LoadKeySet 從目錄載入所有 *.pem 金鑰，檔名（不含副檔名）即為 kid。
+// 私鑰可用於簽章與驗證；只有公鑰的檔案代表已退役的金鑰，僅用於驗證尚未過期的舊 token。
+// activeKID 為空時，以檔名排序最後的私鑰作為簽章金鑰（建議以日期命名，例如 2026-01.pem）。
+func LoadKeySet(dir, activeKID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	ks := NewKeySet()
+	var lastSigning string
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, err := loadKeyFile(kid, path)
+		if err != nil {
+			return nil, fmt.Errorf("載入金鑰 %s 失敗: %w", path, err)
+		}
+		ks.Add(key)
+		if key.CanSign() {
+			lastSigning = kid
+		}
+	}
+
+	if lastSigning == "" {
+		return nil, fmt.Errorf("目錄 %s 中沒有可用於簽章的私鑰", dir)
+	}
+	if activeKID == "" {
+		activeKID = lastSigning
+	}
+	if err := ks.SetActive(activeKID); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// loadKeyFile 解析 PEM 檔案（PKCS#8 / PKCS#1 私鑰或 PKIX 公鑰）
+func loadKeyFile(kid, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("不是有效的 PEM 檔案")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("不支援的 PEM 類型 %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(kid, parsed)
+}
+
+var (
+	keySetMu sync.RWMutex
+	keySet   *KeySet
+)
+
+// SetKeySet 替換目前使用的金鑰組
+func SetKeySet(ks *KeySet) {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	keySet = ks
+}
+
+// CurrentKeySet 返回目前使用的金鑰組
+func CurrentKeySet() *KeySet {
+	keySetMu.RLock()
+	defer keySetMu.RUnlock()
+	return keySet
+}
+
+// WatchKeys 定期重新載入金鑰目錄，讓新增或退役的金鑰不需重啟即可生效；載入失敗時保留原金鑰組
+func WatchKeys(ctx context.Context, dir, activeKID string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ks, err := LoadKeySet(dir, activeKID)
+			if err != nil {
+				log.Printf("[Auth] Warning: reloading signing keys failed: %v\n", err)
+				continue
+			}
+			SetKeySet(ks)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM 將金鑰以 PEM 格式寫入目錄中的 <kid>.pem
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600))
+}
+
+func writeEd25519Private(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	writePEM(t, dir, kid, "PRIVATE KEY", der)
+	return private
+}
+
+func writeRSAPrivate(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePEM(t, dir, kid, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(private))
+	return private
+}
+
+// useKeySet 在測試期間替換金鑰組，結束後還原
+func useKeySet(t *testing.T, ks *KeySet) {
+	t.Helper()
+	original := CurrentKeySet()
+	SetKeySet(ks)
+	t.Cleanup(func() { SetKeySet(original) })
+}
+
+func TestLoadKeySet(t *testing.T) {
+	t.Run("以排序最後的私鑰作為簽章金鑰", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAPrivate(t, dir, "2026-01")
+		writeEd25519Private(t, dir, "2026-02")
+
+		ks, err := LoadKeySet(dir, "")
+		require.NoError(t, err)
+		assert.Equal(t, "2026-02", ks.Active().ID)
+		assert.Equal(t, jwt.SigningMethodEdDSA, ks.Active().Method)
+		assert.Equal(t, jwt.SigningMethodRS256, ks.Lookup("2026-01").Method)
+	})
+
+	t.Run("指定簽章金鑰", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAPrivate(t, dir, "2026-01")
+		writeEd25519Private(t, dir, "2026-02")
+
+		ks, err := LoadKeySet(dir, "2026-01")
+		require.NoError(t, err)
+		assert.Equal(t, "2026-01", ks.Active().ID)
+	})
+
+	t.Run("公鑰檔案只用於驗證", func(t *testing.T) {
+		dir := t.TempDir()
+		writeEd25519Private(t, dir, "2026-02")
+		private := writeEd25519Private(t, t.TempDir(), "unused")
+		der, err := x509.MarshalPKIXPublicKey(private.Public())
+		require.NoError(t, err)
+		writePEM(t, dir, "2025-12", "PUBLIC KEY", der)
+
+		ks, err := LoadKeySet(dir, "")
+		require.NoError(t, err)
+		assert.False(t, ks.Lookup("2025-12").CanSign())
+		assert.Error(t, ks.SetActive("2025-12"))
+	})
+
+	t.Run("沒有私鑰時返回錯誤", func(t *testing.T) {
+		_, err := LoadKeySet(t.TempDir(), "")
+		assert.Error(t, err)
+	})
+
+	t.Run("無效的 PEM 返回錯誤", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.pem"), []byte("not pem"), 0o600))
+		_, err := LoadKeySet(dir, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, err := GenerateEd25519Key("old")
+	require.NoError(t, err)
+	newKey, err := GenerateEd25519Key("new")
+	require.NoError(t, err)
+
+	ks := NewKeySet()
+	ks.Add(oldKey)
+	useKeySet(t, ks)
+
+	oldToken, err := GenerateToken(1, "rotate@example.com")
+	require.NoError(t, err)
+
+	// 加入新金鑰並切換為簽章金鑰，舊 token 仍應可驗證
+	ks.Add(newKey)
+	require.NoError(t, ks.SetActive("new"))
+
+	newToken, err := GenerateToken(2, "rotate@example.com")
+	require.NoError(t, err)
+
+	_, err = ValidateToken(oldToken)
+	assert.NoError(t, err, "輪換後舊金鑰簽發的 token 應繼續有效")
+	_, err = ValidateToken(newToken)
+	assert.NoError(t, err)
+
+	// 移除舊金鑰後，舊 token 失效
+	ks2 := NewKeySet()
+	ks2.Add(newKey)
+	SetKeySet(ks2)
+	_, err = ValidateToken(oldToken)
+	assert.Error(t, err, "舊金鑰移除後其簽發的 token 應失效")
+	_, err = ValidateToken(newToken)
+	assert.NoError(t, err)
+}
+
+func TestValidateTokenKidMismatch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := NewKey("rsa", rsaKey)
+	require.NoError(t, err)
+	other, err := GenerateEd25519Key("other")
+	require.NoError(t, err)
+
+	ks := NewKeySet()
+	ks.Add(key)
+	ks.Add(other)
+	useKeySet(t, ks)
+
+	claims := NewClaims(1, "kid@example.com")
+	token, err := SignClaims(claims)
+	require.NoError(t, err)
+
+	t.Run("未知的 kid 被拒絕", func(t *testing.T) {
+		parsed := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		parsed.Header["kid"] = "unknown"
+		tokenString, _ := parsed.SignedString(rsaKey)
+		_, err := ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("kid 與算法不符被拒絕", func(t *testing.T) {
+		parsed := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		parsed.Header["kid"] = "other"
+		tokenString, _ := parsed.SignedString(rsaKey)
+		_, err := ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("RS256 token 正常驗證", func(t *testing.T) {
+		_, err := ValidateToken(token)
+		assert.NoError(t, err)
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := writeRSAPrivate(t, dir, "a-rsa")
+	writeEd25519Private(t, dir, "b-ed")
+
+	ks, err := LoadKeySet(dir, "")
+	require.NoError(t, err)
+
+	jwks := ks.JWKS()
+	require.Len(t, jwks.Keys, 2)
+
+	rsaJWK := jwks.Keys[0]
+	assert.Equal(t, "a-rsa", rsaJWK.Kid)
+	assert.Equal(t, "RSA", rsaJWK.Kty)
+	assert.Equal(t, "RS256", rsaJWK.Alg)
+	assert.Equal(t, "sig", rsaJWK.Use)
+	assert.Equal(t, "AQAB", rsaJWK.E)
+	assert.NotEmpty(t, rsaJWK.N)
+	assert.NotEqual(t, rsaKey.D.String(), rsaJWK.N, "JWKS 不應包含私鑰")
+
+	edJWK := jwks.Keys[1]
+	assert.Equal(t, "b-ed", edJWK.Kid)
+	assert.Equal(t, "OKP", edJWK.Kty)
+	assert.Equal(t, "Ed25519", edJWK.Crv)
+	assert.Equal(t, "EdDSA", edJWK.Alg)
+	assert.Len(t, edJWK.X, 43)
+}
+
+func TestJWKPublicKey(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := writeRSAPrivate(t, dir, "a-rsa")
+	edKey := writeEd25519Private(t, dir, "b-ed")
+
+	ks, err := LoadKeySet(dir, "")
+	require.NoError(t, err)
+	jwks := ks.JWKS()
+	require.Len(t, jwks.Keys, 2)
+
+	pub, err := jwks.Keys[0].PublicKey()
+	require.NoError(t, err)
+	assert.True(t, rsaKey.PublicKey.Equal(pub))
+
+	pub, err = jwks.Keys[1].PublicKey()
+	require.NoError(t, err)
+	assert.True(t, edKey.Public().(ed25519.PublicKey).Equal(pub))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecJWK := JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+	}
+	pub, err = ecJWK.PublicKey()
+	require.NoError(t, err)
+	assert.True(t, ecKey.PublicKey.Equal(pub))
+
+	// 不在曲線上的點必須被拒絕
+	ecJWK.Y = ecJWK.X
+	_, err = ecJWK.PublicKey()
+	assert.Error(t, err)
+
+	_, err = JWK{Kty: "oct"}.PublicKey()
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// PurposeMFAChallenge 標記只能用於兩步驟登入第二步的 token
+const PurposeMFAChallenge = "mfa_challenge"
+
+// mfaChallengeTTL MFA challenge token 的有效期
+var mfaChallengeTTL = 5 * time.Minute
+
+var ErrInvalidMFAChallenge = errors.New("無效或已過期的 MFA challenge")
+
+// MFAChallengeTTL 返回 MFA challenge token 的有效期
+func MFAChallengeTTL() time.Duration {
+	return mfaChallengeTTL
+}
+
+// GenerateMFAChallenge 在密碼驗證通過後簽發短效 challenge token，不能作為 access token 使用
+func GenerateMFAChallenge(userID int64, email string) (string, error) {
+	claims := NewClaims(userID, email)
+	claims.Purpose = PurposeMFAChallenge
+	return signClaims(claims, mfaChallengeTTL)
+}
+
+// ValidateMFAChallenge 驗證 challenge token 並返回其 claims
+func ValidateMFAChallenge(tokenString string) (*Claims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil || claims.Purpose != PurposeMFAChallenge {
+		return nil, ErrInvalidMFAChallenge
+	}
+	return claims, nil
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAChallenge(t *testing.T) {
+	challenge, err := GenerateMFAChallenge(7, "mfa@example.com")
+	require.NoError(t, err)
+
+	claims, err := ValidateMFAChallenge(challenge)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), claims.UserID)
+	assert.Equal(t, PurposeMFAChallenge, claims.Purpose)
+
+	// challenge 不能當作 access token 使用
+	_, err = Authenticate(context.Background(), "Bearer "+challenge)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// access token 也不能當作 challenge 使用
+	access, err := GenerateToken(7, "mfa@example.com")
+	require.NoError(t, err)
+	_, err = ValidateMFAChallenge(access)
+	assert.ErrorIs(t, err, ErrInvalidMFAChallenge)
+}
@@ -1,42 +1,130 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware JWT 認證中間件
+var (
+	ErrMissingAuthHeader = errors.New("缺少 Authorization header")
+	ErrInvalidAuthHeader = errors.New("Authorization header 格式錯誤，應為 'Bearer {token}'")
+	ErrInvalidToken      = errors.New("無效的 token")
+)
+
+// Authenticate 解析 Authorization header 並驗證 Bearer token，REST 與 GraphQL 共用；
+// 已註冊 SessionValidator 時一併確認 token 所屬的登入未被撤銷，OAuth access token 則確認授權未被撤銷
+func Authenticate(ctx context.Context, authHeader string) (*Claims, error) {
+	if authHeader == "" {
+		return nil, ErrMissingAuthHeader
+	}
+
+	// 檢查 Bearer 前綴
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, ErrInvalidAuthHeader
+	}
+
+	claims, err := ValidateToken(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	switch claims.Purpose {
+	case "":
+		if sessionValidator != nil {
+			if err := sessionValidator(ctx, claims); err != nil {
+				return nil, err
+			}
+		}
+	case PurposeOAuthAccess:
+		// OAuth token 一律需確認授權未被撤銷，未註冊檢查時不接受
+		if oauthGrantValidator == nil || claims.ClientID == "" {
+			return nil, ErrInvalidToken
+		}
+		if err := oauthGrantValidator(ctx, claims); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// AuthMiddleware 認證中間件，接受 Bearer JWT 或 X-API-Key
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "缺少 Authorization header"})
+		principal, err := AuthenticateRequest(c.Request.Context(), c.GetHeader("Authorization"), c.GetHeader(APIKeyHeader), c.ClientIP())
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
 
-		// 檢查 Bearer 前綴
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header 格式錯誤，應為 'Bearer {token}'"})
+		// 將用戶信息存儲到 context
+		c.Set("user_id", int64(principal.UserID))
+		c.Set("user_email", principal.Email)
+		c.Request = c.Request.WithContext(ContextWithPrincipal(c.Request.Context(), principal))
+
+		c.Next()
+
+		RecordImpersonatedRequest(c.Request.Context(), c.Request, c.Writer.Status(), c.ClientIP())
+	}
+}
+
+// RequirePermission 權限檢查中間件，必須放在 AuthMiddleware 之後；需同時擁有所有列出的權限
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFromContext(c.Request.Context())
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證"})
 			c.Abort()
 			return
 		}
 
-		tokenString := parts[1]
-		claims, err := ValidateToken(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "無效的 token"})
+		for _, permission := range permissions {
+			if !principal.HasPermission(permission) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "權限不足"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelfOrPermission 允許會員存取路徑參數 param 指定的自己；存取其他會員需已驗證電子郵件（政策啟用時）並擁有權限。
+// 必須放在 AuthMiddleware 之後
+func RequireSelfOrPermission(param, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFromContext(c.Request.Context())
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證"})
 			c.Abort()
 			return
 		}
 
-		// 將用戶信息存儲到 context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
+		if id, err := strconv.ParseUint(c.Param(param), 10, 64); err == nil && principal.UserID != 0 && uint64(principal.UserID) == id {
+			c.Next()
+			return
+		}
+
+		if EmailVerificationRequired() && !principal.EmailVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "請先完成電子郵件驗證"})
+			c.Abort()
+			return
+		}
+		if !principal.HasPermission(permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "權限不足"})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
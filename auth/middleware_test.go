@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -108,3 +109,164 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.True(t, nextCalled)
 	})
 }
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	signed := func(permissions ...string) string {
+		claims := NewClaims(1, "perm@example.com")
+		claims.Permissions = permissions
+		token, err := SignClaims(claims)
+		assert.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		required   []string
+		wantStatus int
+	}{
+		{
+			name:       "擁有權限",
+			token:      signed(PermMemberDelete),
+			required:   []string{PermMemberDelete},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "缺少權限",
+			token:      signed(PermMemberRead),
+			required:   []string{PermMemberDelete},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "需要同時擁有多個權限",
+			token:      signed(PermProductRead),
+			required:   []string{PermProductRead, PermProductWrite},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(AuthMiddleware(), RequirePermission(tt.required...))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	t.Run("未經過 AuthMiddleware 返回 401", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		RequirePermission(PermMemberRead)(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.True(t, c.IsAborted())
+	})
+}
+
+func TestAuthMiddlewareSessionValidator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer SetSessionValidator(nil)
+
+	claims := NewClaims(1, "session@example.com")
+	claims.SessionID = "family-1"
+	token, err := SignClaims(claims)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, claims.ID, "SignClaims should assign a jti")
+
+	revoked := map[string]bool{}
+	SetSessionValidator(func(_ context.Context, c *Claims) error {
+		if c.ID == "" || revoked[c.SessionID] {
+			return ErrSessionRevoked
+		}
+		return nil
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(AuthMiddleware())
+		router.GET("/", func(c *gin.Context) {
+			assert.Equal(t, "family-1", PrincipalFromContext(c.Request.Context()).SessionID)
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve().Code)
+
+	revoked["family-1"] = true
+	w := serve()
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), ErrSessionRevoked.Error())
+}
+
+func TestRequireSelfOrPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	signed := func(userID int64, verified bool, permissions ...string) string {
+		claims := NewClaims(userID, "self@example.com")
+		claims.EmailVerified = verified
+		claims.Permissions = permissions
+		token, err := SignClaims(claims)
+		assert.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		path       string
+		wantStatus int
+	}{
+		{name: "查詢自己", token: signed(7, true), path: "/user/7", wantStatus: http.StatusOK},
+		{name: "查詢其他會員", token: signed(7, true), path: "/user/8", wantStatus: http.StatusForbidden},
+		{name: "擁有權限", token: signed(7, true, PermMemberRead), path: "/user/8", wantStatus: http.StatusOK},
+		{name: "無效的 ID 需要權限", token: signed(7, true), path: "/user/abc", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(AuthMiddleware())
+			router.GET("/user/:id", RequireSelfOrPermission("id", PermMemberRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	t.Run("查詢其他會員需要驗證電子郵件", func(t *testing.T) {
+		assert.NoError(t, SetEmailVerificationPolicy(EmailVerificationRestrict))
+		defer SetEmailVerificationPolicy(EmailVerificationOff)
+
+		router := gin.New()
+		router.Use(AuthMiddleware())
+		router.GET("/user/:id", RequireSelfOrPermission("id", PermMemberRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/user/8", nil)
+		req.Header.Set("Authorization", "Bearer "+signed(7, false, PermMemberRead))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
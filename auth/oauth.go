@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// PurposeOAuthAccess 標記簽發給 OAuth 用戶端（第三方應用程式）的 access token
+const PurposeOAuthAccess = "oauth_access"
+
+// OAuth scope：除以下身分資料 scope 外，權限名稱（如 product:read）也可作為 scope 申請
+const (
+	ScopeProfile       = "profile"
+	ScopeEmail         = "email"
+	ScopeOfflineAccess = "offline_access"
+)
+
+var (
+	ErrGrantRevoked     = errors.New("應用程式的授權已被撤銷")
+	ErrFirstPartyOnly   = errors.New("此操作不接受 API key 或第三方應用程式的 token")
+	errNoGrantValidator = errors.New("未啟用 OAuth 授權驗證")
+)
+
+// oauthAccessTokenTTL 與 oauthRefreshTokenTTL 為簽發給 OAuth 用戶端的 token 有效期
+var (
+	oauthAccessTokenTTL  = time.Hour
+	oauthRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// OAuthGrantValidator 檢查 OAuth access token 所屬的授權是否仍有效；
+// 與 SessionValidator 相同，由服務層在啟動時註冊實作。
+type OAuthGrantValidator func(ctx context.Context, claims *Claims) error
+
+var oauthGrantValidator OAuthGrantValidator
+
+// SetOAuthGrantValidator 註冊 OAuth 授權檢查，傳入 nil 表示不接受 OAuth access token
+func SetOAuthGrantValidator(v OAuthGrantValidator) {
+	oauthGrantValidator = v
+}
+
+// SetOAuthTokenTTL 設定 OAuth access token 與 refresh token 的有效期，非正值會被忽略
+func SetOAuthTokenTTL(access, refresh time.Duration) {
+	if access > 0 {
+		oauthAccessTokenTTL = access
+	}
+	if refresh > 0 {
+		oauthRefreshTokenTTL = refresh
+	}
+}
+
+// OAuthAccessTokenTTL 返回 OAuth access token 的有效期
+func OAuthAccessTokenTTL() time.Duration {
+	return oauthAccessTokenTTL
+}
+
+// OAuthRefreshTokenTTL 返回 OAuth refresh token 的有效期
+func OAuthRefreshTokenTTL() time.Duration {
+	return oauthRefreshTokenTTL
+}
+
+// SignOAuthAccessToken 簽發 OAuth access token；claims 的 SessionID 為授權 ID，ClientID 為用戶端。
+// aud 為用戶端，sub 為會員 ID，client_credentials 沒有會員時為用戶端本身
+func SignOAuthAccessToken(claims *Claims) (string, error) {
+	claims.Purpose = PurposeOAuthAccess
+	claims.Audience = jwt.ClaimStrings{claims.ClientID}
+	if claims.UserID > 0 {
+		claims.Subject = strconv.FormatInt(claims.UserID, 10)
+	} else {
+		claims.Subject = claims.ClientID
+	}
+	return signClaims(claims, oauthAccessTokenTTL)
+}
+
+// ValidateOAuthAccessToken 驗證 OAuth access token 的簽章與用途，不檢查授權是否已撤銷
+func ValidateOAuthAccessToken(tokenString string) (*Claims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil || claims.Purpose != PurposeOAuthAccess || claims.ClientID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// IsValidScope 判斷是否為可申請的 OAuth scope
+func IsValidScope(scope string) bool {
+	switch scope {
+	case ScopeProfile, ScopeEmail, ScopeOfflineAccess:
+		return true
+	}
+	return contains(AllPermissions(), scope)
+}
+
+// ParseScope 將以空白分隔的 scope 字串拆成不重複的清單，保留原本順序
+func ParseScope(scope string) []string {
+	fields := strings.Fields(scope)
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if !contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// RequireFirstParty 拒絕以 API key、OAuth access token 或代理登入 token 認證的請求，
+// 用於密碼、登入裝置、兩步驟驗證等帳號管理操作；必須放在 AuthMiddleware 之後
+func RequireFirstParty() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFromContext(c.Request.Context())
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證"})
+			c.Abort()
+			return
+		}
+
+		if !principal.IsFirstParty() {
+			err := ErrFirstPartyOnly
+			if principal.IsImpersonated() {
+				err = ErrImpersonating
+			}
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasScope 判斷呼叫者是否擁有指定 scope；只有 OAuth access token 受 scope 限制，其他憑證一律視為擁有
+func (p *Principal) HasScope(scope string) bool {
+	return p.ClientID == "" || contains(p.Scopes, scope)
+}
+
+// RequireScope 要求 OAuth access token 擁有指定 scope，會員本人的 token 與 API key 不受影響；必須放在 AuthMiddleware 之後
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFromContext(c.Request.Context())
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證"})
+			c.Abort()
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "應用程式未取得 " + scope + " 授權"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOAuthClaims() *Claims {
+	claims := NewClaims(7, "")
+	claims.SessionID = "grant-1"
+	claims.ClientID = "client-1"
+	claims.Scope = "profile product:read"
+	claims.Permissions = []string{PermProductRead}
+	return claims
+}
+
+func TestSignOAuthAccessToken(t *testing.T) {
+	token, err := SignOAuthAccessToken(newOAuthClaims())
+	require.NoError(t, err)
+
+	claims, err := ValidateOAuthAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, PurposeOAuthAccess, claims.Purpose)
+	assert.Equal(t, "7", claims.Subject)
+	assert.Equal(t, []string{"client-1"}, []string(claims.Audience))
+	assert.WithinDuration(t, claims.IssuedAt.Add(OAuthAccessTokenTTL()), claims.ExpiresAt.Time, 0)
+
+	// 一般 access token 不能當作 OAuth token
+	plain, err := GenerateToken(7, "a@example.com")
+	require.NoError(t, err)
+	_, err = ValidateOAuthAccessToken(plain)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	cc := newOAuthClaims()
+	cc.UserID = 0
+	token, err = SignOAuthAccessToken(cc)
+	require.NoError(t, err)
+	claims, err = ValidateOAuthAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "client-1", claims.Subject)
+}
+
+func TestAuthenticateOAuthToken(t *testing.T) {
+	defer SetOAuthGrantValidator(nil)
+	defer SetSessionValidator(nil)
+
+	token, err := SignOAuthAccessToken(newOAuthClaims())
+	require.NoError(t, err)
+	header := "Bearer " + token
+
+	// 未註冊授權檢查時不接受 OAuth token
+	SetOAuthGrantValidator(nil)
+	_, err = Authenticate(context.Background(), header)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// OAuth token 不經過 SessionValidator
+	SetSessionValidator(func(context.Context, *Claims) error { return ErrSessionRevoked })
+	revoked := false
+	SetOAuthGrantValidator(func(_ context.Context, c *Claims) error {
+		if revoked || c.SessionID != "grant-1" {
+			return ErrGrantRevoked
+		}
+		return nil
+	})
+
+	claims, err := Authenticate(context.Background(), header)
+	require.NoError(t, err)
+	principal := NewPrincipal(claims)
+	assert.Equal(t, "client-1", principal.ClientID)
+	assert.Equal(t, []string{ScopeProfile, PermProductRead}, principal.Scopes)
+	assert.True(t, principal.HasScope(ScopeProfile))
+	assert.False(t, principal.HasScope(ScopeEmail))
+	assert.False(t, principal.IsFirstParty())
+
+	revoked = true
+	_, err = Authenticate(context.Background(), header)
+	assert.ErrorIs(t, err, ErrGrantRevoked)
+
+	// 其他用途的 token（如 MFA challenge）一律拒絕
+	challenge, err := GenerateMFAChallenge(7, "a@example.com")
+	require.NoError(t, err)
+	_, err = Authenticate(context.Background(), "Bearer "+challenge)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestIsValidScope(t *testing.T) {
+	assert.True(t, IsValidScope(ScopeProfile))
+	assert.True(t, IsValidScope(ScopeOfflineAccess))
+	assert.True(t, IsValidScope(PermProductRead))
+	assert.False(t, IsValidScope("openid-ish"))
+	assert.False(t, IsValidScope(""))
+}
+
+func TestParseScope(t *testing.T) {
+	assert.Equal(t, []string{"profile", "email"}, ParseScope("  profile email profile "))
+	assert.Empty(t, ParseScope(""))
+}
+
+func TestRequireFirstPartyAndScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		principal  *Principal
+		firstParty int
+		profile    int
+	}{
+		{name: "未認證", principal: nil, firstParty: http.StatusUnauthorized, profile: http.StatusUnauthorized},
+		{name: "會員本人", principal: &Principal{UserID: 1}, firstParty: http.StatusOK, profile: http.StatusOK},
+		{name: "API key", principal: &Principal{UserID: 1, APIKeyID: 3}, firstParty: http.StatusForbidden, profile: http.StatusOK},
+		{name: "OAuth 有 profile", principal: &Principal{UserID: 1, ClientID: "c", Scopes: []string{ScopeProfile}}, firstParty: http.StatusForbidden, profile: http.StatusOK},
+		{name: "OAuth 沒有 profile", principal: &Principal{UserID: 1, ClientID: "c", Scopes: []string{ScopeEmail}}, firstParty: http.StatusForbidden, profile: http.StatusForbidden},
+	}
+
+	serve := func(middleware gin.HandlerFunc, principal *Principal) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		if principal != nil {
+			c.Request = c.Request.WithContext(ContextWithPrincipal(c.Request.Context(), principal))
+		}
+		middleware(c)
+		if !c.IsAborted() {
+			c.Status(http.StatusOK)
+		}
+		return w.Code
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.firstParty, serve(RequireFirstParty(), tt.principal))
+			assert.Equal(t, tt.profile, serve(RequireScope(ScopeProfile), tt.principal))
+		})
+	}
+}
@@ -1,15 +1,184 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
-// HashPassword 加密密碼
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 支援的密碼雜湊演算法
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+// Argon2Params argon2id 的成本參數
+type Argon2Params struct {
+	// Memory 記憶體用量（KiB）
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// PasswordHashOptions 新密碼使用的演算法與成本；舊雜湊在登入時會依此升級
+type PasswordHashOptions struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+// DefaultPasswordHashOptions 預設使用 argon2id（RFC 9106 建議的第二組參數）
+func DefaultPasswordHashOptions() PasswordHashOptions {
+	return PasswordHashOptions{
+		Algorithm:  HashArgon2id,
+		BcryptCost: 12,
+		Argon2: Argon2Params{
+			Memory:      64 * 1024,
+			Iterations:  3,
+			Parallelism: 4,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+	}
+}
+
+var passwordHashOptions = DefaultPasswordHashOptions()
+
+// SetPasswordHashOptions 設定新密碼使用的演算法與成本
+func SetPasswordHashOptions(opts PasswordHashOptions) error {
+	switch opts.Algorithm {
+	case HashArgon2id:
+		p := opts.Argon2
+		if p.Memory == 0 || p.Iterations == 0 || p.Parallelism == 0 || p.SaltLength == 0 || p.KeyLength == 0 {
+			return errors.New("argon2id 參數必須為正數")
+		}
+	case HashBcrypt:
+		if opts.BcryptCost < bcrypt.MinCost || opts.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost 必須介於 %d 到 %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("不支援的密碼雜湊演算法 %q", opts.Algorithm)
+	}
+	passwordHashOptions = opts
+	return nil
+}
+
+// HashPassword 以目前設定的演算法加密密碼
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	opts := passwordHashOptions
+	if opts.Algorithm == HashBcrypt {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), opts.BcryptCost)
+		return string(bytes), err
+	}
+	return hashArgon2id(password, opts.Argon2)
 }
 
-// CheckPassword 驗證密碼
+// CheckPassword 驗證密碼，依雜湊前綴自動判斷演算法
 func CheckPassword(password, hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// NeedsRehash 判斷雜湊是否使用舊的演算法或低於目前設定的成本，應在密碼驗證成功後重新雜湊
+func NeedsRehash(hash string) bool {
+	opts := passwordHashOptions
+
+	if strings.HasPrefix(hash, "$argon2id$") {
+		if opts.Algorithm != HashArgon2id {
+			return true
+		}
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return true
+		}
+		return params.Memory != opts.Argon2.Memory ||
+			params.Iterations != opts.Argon2.Iterations ||
+			params.Parallelism != opts.Argon2.Parallelism ||
+			uint32(len(salt)) != opts.Argon2.SaltLength ||
+			uint32(len(key)) != opts.Argon2.KeyLength
+	}
+
+	if opts.Algorithm != HashBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != opts.BcryptCost
+}
+
+// IsPasswordHash 判斷字串是否為可驗證的 argon2id 或 bcrypt 雜湊，用於匯入其他系統已雜湊的密碼
+func IsPasswordHash(hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		_, _, _, err := decodeArgon2id(hash)
+		return err == nil
+	}
+	if len(hash) != 60 {
+		return false
+	}
+	_, err := bcrypt.Cost([]byte(hash))
+	return err == nil
+}
+
+// hashArgon2id 產生 PHC 格式的 argon2id 雜湊：$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+func hashArgon2id(password string, p Argon2Params) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// decodeArgon2id 解析 PHC 格式的 argon2id 雜湊
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errors.New("無效的 argon2id 雜湊")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errors.New("不支援的 argon2 版本")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, errors.New("無效的 argon2id 參數")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	if len(key) == 0 {
+		return p, nil, nil, errors.New("無效的 argon2id 雜湊")
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
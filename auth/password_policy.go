@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicy 設定密碼時必須符合的規則
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// ForbidPersonalInfo 禁止密碼包含 email 帳號或姓名
+	ForbidPersonalInfo bool
+	// Blocklist 常見或已外洩的密碼（小寫）
+	Blocklist map[string]struct{}
+}
+
+// DefaultPasswordPolicy 預設規則：至少 8 個字元、不可包含個人資料
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:          8,
+		MaxLength:          128,
+		ForbidPersonalInfo: true,
+	}
+}
+
+// PasswordPolicyError 密碼不符合規則，Violations 列出所有未通過的項目
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "密碼不符合規則：" + strings.Join(e.Violations, "；")
+}
+
+var passwordPolicy = DefaultPasswordPolicy()
+
+// SetPasswordPolicy 設定全域密碼規則
+func SetPasswordPolicy(p PasswordPolicy) {
+	passwordPolicy = p
+}
+
+// CurrentPasswordPolicy 返回目前的密碼規則
+func CurrentPasswordPolicy() PasswordPolicy {
+	return passwordPolicy
+}
+
+// ValidatePassword 以目前的規則檢查密碼，personal 為會員的 email、姓名等個人資料
+func ValidatePassword(password string, personal ...string) error {
+	return passwordPolicy.Validate(password, personal...)
+}
+
+// Validate 檢查密碼是否符合規則，不符合時返回 *PasswordPolicyError
+func (p PasswordPolicy) Validate(password string, personal ...string) error {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		violations = append(violations, "長度至少 "+strconv.Itoa(p.MinLength)+" 個字元")
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, "長度不可超過 "+strconv.Itoa(p.MaxLength)+" 個字元")
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, "需包含大寫字母")
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, "需包含小寫字母")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "需包含數字")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "需包含符號")
+	}
+
+	lower := strings.ToLower(password)
+	if _, blocked := p.Blocklist[lower]; blocked {
+		violations = append(violations, "此密碼過於常見或已外洩")
+	}
+
+	if p.ForbidPersonalInfo {
+		for _, token := range personalTokens(personal) {
+			if strings.Contains(lower, token) {
+				violations = append(violations, "不可包含電子郵件或姓名")
+				break
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// personalTokens 將 email 與姓名拆成用於比對的片段，過短的片段會被忽略以免誤判
+func personalTokens(values []string) []string {
+	const minTokenLength = 3
+
+	var tokens []string
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if at := strings.LastIndex(value, "@"); at >= 0 {
+			value = value[:at]
+		}
+		if utf8.RuneCountInString(value) >= minTokenLength {
+			tokens = append(tokens, value)
+		}
+		for _, field := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if field != value && utf8.RuneCountInString(field) >= minTokenLength {
+				tokens = append(tokens, field)
+			}
+		}
+	}
+	return tokens
+}
+
+// LoadPasswordBlocklist 讀取每行一個密碼的清單檔，忽略空行與 # 開頭的註解
+func LoadPasswordBlocklist(path string) (map[string]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	blocklist := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		blocklist[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return blocklist, nil
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:          8,
+		MaxLength:          64,
+		RequireUpper:       true,
+		RequireDigit:       true,
+		ForbidPersonalInfo: true,
+		Blocklist:          map[string]struct{}{"password1a": {}},
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		personal   []string
+		violations int
+	}{
+		{name: "符合規則", password: "Sunny-Meadow-42", personal: []string{"alice@example.com", "Alice Chen"}},
+		{name: "太短", password: "Ab1", violations: 1},
+		{name: "缺少大寫與數字", password: "lowercaseonly", violations: 2},
+		{name: "在封鎖清單中（不分大小寫）", password: "PASSWORD1A", violations: 1},
+		{name: "包含 email 帳號", password: "Alice.Smith99", personal: []string{"alice.smith@example.com"}, violations: 1},
+		{name: "包含姓名片段", password: "IamChen2024", personal: []string{"x@example.com", "Alice Chen"}, violations: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.personal...)
+			if tt.violations == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var policyErr *PasswordPolicyError
+			require.ErrorAs(t, err, &policyErr)
+			assert.Len(t, policyErr.Violations, tt.violations)
+		})
+	}
+}
+
+func TestLoadPasswordBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "common.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# comment\n123456\n\nQwerty123\n"), 0o600))
+
+	blocklist, err := LoadPasswordBlocklist(path)
+	require.NoError(t, err)
+	assert.Len(t, blocklist, 2)
+	assert.Contains(t, blocklist, "qwerty123")
+
+	_, err = LoadPasswordBlocklist(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
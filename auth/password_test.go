@@ -1,7 +1,10 @@
 package auth
 
 import (
+	"strings"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestHashPassword(t *testing.T) {
@@ -119,3 +122,107 @@ func TestHashPasswordConsistency(t *testing.T) {
 		t.Error("CheckPassword() failed to verify second hash")
 	}
 }
+
+func TestPasswordHashAlgorithms(t *testing.T) {
+	defer func() { _ = SetPasswordHashOptions(DefaultPasswordHashOptions()) }()
+
+	bcryptOpts := DefaultPasswordHashOptions()
+	bcryptOpts.Algorithm = HashBcrypt
+	bcryptOpts.BcryptCost = bcrypt.MinCost
+	if err := SetPasswordHashOptions(bcryptOpts); err != nil {
+		t.Fatalf("SetPasswordHashOptions() error = %v", err)
+	}
+	legacy, err := HashPassword("legacy-password")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if !strings.HasPrefix(legacy, "$2a$") {
+		t.Errorf("HashPassword() = %q, want bcrypt hash", legacy)
+	}
+	if NeedsRehash(legacy) {
+		t.Error("NeedsRehash() = true for hash matching current bcrypt settings")
+	}
+
+	// 切換到 argon2id 後，舊的 bcrypt 雜湊仍可驗證但需要升級
+	if err := SetPasswordHashOptions(DefaultPasswordHashOptions()); err != nil {
+		t.Fatalf("SetPasswordHashOptions() error = %v", err)
+	}
+	if !CheckPassword("legacy-password", legacy) {
+		t.Error("CheckPassword() failed to verify bcrypt hash after switching algorithm")
+	}
+	if !NeedsRehash(legacy) {
+		t.Error("NeedsRehash() = false for bcrypt hash while argon2id is configured")
+	}
+
+	upgraded, err := HashPassword("legacy-password")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if !strings.HasPrefix(upgraded, "$argon2id$v=19$m=65536,t=3,p=4$") {
+		t.Errorf("HashPassword() = %q, want argon2id PHC string", upgraded)
+	}
+	if !CheckPassword("legacy-password", upgraded) || CheckPassword("wrong-password", upgraded) {
+		t.Error("CheckPassword() gave wrong result for argon2id hash")
+	}
+	if NeedsRehash(upgraded) {
+		t.Error("NeedsRehash() = true for hash matching current argon2id settings")
+	}
+
+	// 提高成本後需要重新雜湊
+	stronger := DefaultPasswordHashOptions()
+	stronger.Argon2.Iterations = 4
+	if err := SetPasswordHashOptions(stronger); err != nil {
+		t.Fatalf("SetPasswordHashOptions() error = %v", err)
+	}
+	if !NeedsRehash(upgraded) {
+		t.Error("NeedsRehash() = false after raising argon2id iterations")
+	}
+}
+
+func TestSetPasswordHashOptionsInvalid(t *testing.T) {
+	opts := DefaultPasswordHashOptions()
+	opts.Algorithm = "md5"
+	if err := SetPasswordHashOptions(opts); err == nil {
+		t.Error("SetPasswordHashOptions() accepted unsupported algorithm")
+	}
+
+	opts = DefaultPasswordHashOptions()
+	opts.Algorithm = HashBcrypt
+	opts.BcryptCost = 99
+	if err := SetPasswordHashOptions(opts); err == nil {
+		t.Error("SetPasswordHashOptions() accepted out-of-range bcrypt cost")
+	}
+}
+
+func TestIsPasswordHash(t *testing.T) {
+	argon, err := hashArgon2id("imported-password", DefaultPasswordHashOptions().Argon2)
+	if err != nil {
+		t.Fatalf("hashArgon2id() error = %v", err)
+	}
+	legacy, err := bcrypt.GenerateFromPassword([]byte("imported-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{name: "argon2id", hash: argon, want: true},
+		{name: "bcrypt", hash: string(legacy), want: true},
+		{name: "empty", hash: "", want: false},
+		{name: "plain text", hash: "imported-password", want: false},
+		{name: "truncated bcrypt", hash: string(legacy[:40]), want: false},
+		{name: "argon2id without key", hash: "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$", want: false},
+		{name: "unsupported scheme", hash: "$6$rounds=5000$salt$hash", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPasswordHash(tt.hash); got != tt.want {
+				t.Errorf("IsPasswordHash(%q) = %v, want %v", tt.hash, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+// 權限名稱，格式為 "資源:動作"
+const (
+	PermMemberRead   = "member:read"
+	PermMemberWrite  = "member:write"
+	PermMemberDelete = "member:delete"
+	PermProductRead  = "product:read"
+	PermProductWrite = "product:write"
+	PermRoleManage   = "role:manage"
+	PermAuditRead    = "audit:read"
+	PermAPIKeyManage = "apikey:manage"
+	PermOAuthManage  = "oauth:manage"
+	// PermMemberImpersonate 以會員身分代理登入，供客服查看會員看到的畫面
+	PermMemberImpersonate = "member:impersonate"
+	// PermPrivacyManage 代會員匯出個人資料與清除（匿名化）會員個人資料
+	PermPrivacyManage = "privacy:manage"
+	// PermMemberExport 匯出所有會員資料（CSV / NDJSON）
+	PermMemberExport = "member:export"
+	// PermMemberMerge 將重複的會員合併為一位
+	PermMemberMerge = "member:merge"
+	// PermGroupManage 管理會員群組與會員的群組指派
+	PermGroupManage = "group:manage"
+	// PermPointsManage 為會員累積、兌換或調整點數
+	PermPointsManage = "points:manage"
+)
+
+// AllPermissions 返回所有內建權限名稱
+func AllPermissions() []string {
+	return []string{
+		PermMemberRead, PermMemberWrite, PermMemberDelete,
+		PermProductRead, PermProductWrite, PermRoleManage,
+		PermAuditRead, PermAPIKeyManage, PermOAuthManage, PermMemberImpersonate,
+		PermPrivacyManage, PermMemberExport, PermMemberMerge, PermGroupManage, PermPointsManage,
+	}
+}
+
+// 內建角色名稱
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// HasPermission 判斷 claims 是否擁有指定權限
+func (c *Claims) HasPermission(permission string) bool {
+	return contains(c.Permissions, permission)
+}
+
+// HasRole 判斷 claims 是否擁有指定角色
+func (c *Claims) HasRole(role string) bool {
+	return contains(c.Roles, role)
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimsHasPermission(t *testing.T) {
+	claims := &Claims{Permissions: []string{PermMemberRead, PermProductWrite}}
+
+	assert.True(t, claims.HasPermission(PermMemberRead))
+	assert.True(t, claims.HasPermission(PermProductWrite))
+	assert.False(t, claims.HasPermission(PermMemberDelete))
+	assert.False(t, (&Claims{}).HasPermission(PermMemberRead))
+}
+
+func TestClaimsHasRole(t *testing.T) {
+	claims := &Claims{Roles: []string{RoleAdmin}}
+
+	assert.True(t, claims.HasRole(RoleAdmin))
+	assert.False(t, claims.HasRole(RoleMember))
+}
+
+func TestRolesAndPermissionsRoundTrip(t *testing.T) {
+	claims := NewClaims(42, "admin@example.com")
+	claims.Roles = []string{RoleAdmin}
+	claims.Permissions = []string{PermMemberDelete, PermRoleManage}
+
+	token, err := SignClaims(claims)
+	assert.NoError(t, err)
+
+	parsed, err := ValidateToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{RoleAdmin}, parsed.Roles)
+	assert.Equal(t, []string{PermMemberDelete, PermRoleManage}, parsed.Permissions)
+}
@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrSessionRevoked = errors.New("登入已失效，請重新登入")
+
+// SessionValidator 檢查 access token 所屬的登入是否仍有效，返回錯誤時請求會被拒絕。
+// auth 套件不直接存取資料庫，由服務層在啟動時註冊實作。
+type SessionValidator func(ctx context.Context, claims *Claims) error
+
+var sessionValidator SessionValidator
+
+// SetSessionValidator 註冊登入狀態檢查，傳入 nil 表示不檢查
+func SetSessionValidator(v SessionValidator) {
+	sessionValidator = v
+}
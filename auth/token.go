@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateOpaqueToken 生成 n 個隨機位元組並以 base64url 編碼
+func GenerateOpaqueToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateRefreshToken 生成 refresh token 原文，只會返回給客戶端一次
+func GenerateRefreshToken() (string, error) {
+	return GenerateOpaqueToken(32)
+}
+
+// HashToken 計算 token 的 SHA-256 摘要，資料庫只保存摘要
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateRecoveryCode 生成 MFA 備用碼，格式為 XXXX-XXXX-XXXX-XXXX（base32 字元，80 位元隨機）
+func GenerateRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	raw := base32.StdEncoding.EncodeToString(buf)
+	return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16], nil
+}
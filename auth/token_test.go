@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateRefreshToken(t *testing.T) {
+	t.Run("長度與編碼正確", func(t *testing.T) {
+		token, err := GenerateRefreshToken()
+		assert.NoError(t, err)
+
+		raw, err := base64.RawURLEncoding.DecodeString(token)
+		assert.NoError(t, err)
+		assert.Len(t, raw, 32)
+	})
+
+	t.Run("每次生成結果不同", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for i := 0; i < 100; i++ {
+			token, err := GenerateRefreshToken()
+			assert.NoError(t, err)
+			assert.False(t, seen[token], "refresh token 不應重複")
+			seen[token] = true
+		}
+	})
+}
+
+func TestHashToken(t *testing.T) {
+	t.Run("相同輸入得到相同摘要", func(t *testing.T) {
+		assert.Equal(t, HashToken("abc"), HashToken("abc"))
+	})
+
+	t.Run("不同輸入得到不同摘要", func(t *testing.T) {
+		assert.NotEqual(t, HashToken("abc"), HashToken("abd"))
+	})
+
+	t.Run("摘要為 64 位十六進位字串", func(t *testing.T) {
+		hash := HashToken("token")
+		assert.Len(t, hash, 64)
+		assert.NotContains(t, hash, "token")
+	})
+}
+
+func TestSetTokenTTL(t *testing.T) {
+	origAccess, origRefresh := AccessTokenTTL(), RefreshTokenTTL()
+	defer SetTokenTTL(origAccess, origRefresh)
+
+	SetTokenTTL(0, -1)
+	assert.Equal(t, origAccess, AccessTokenTTL(), "非正值不應覆蓋")
+	assert.Equal(t, origRefresh, RefreshTokenTTL(), "非正值不應覆蓋")
+
+	SetTokenTTL(5*time.Minute, 0)
+	assert.Equal(t, 5*time.Minute, AccessTokenTTL())
+	assert.Equal(t, origRefresh, RefreshTokenTTL())
+}
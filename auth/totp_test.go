@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret 為 RFC 6238 附錄 B 的 SHA1 測試密鑰 "12345678901234567890" 的 base32 編碼
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 附錄 B 的 8 位數結果取末 6 位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfcSecret, TOTPCounter(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "unix=%d", tt.unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	counter := TOTPCounter(now)
+
+	t.Run("當前時間步長", func(t *testing.T) {
+		got, ok := ValidateTOTP(rfcSecret, "005924", now)
+		assert.True(t, ok)
+		assert.Equal(t, counter, got)
+	})
+
+	t.Run("允許一個步長的誤差", func(t *testing.T) {
+		prev, err := TOTPCode(rfcSecret, counter-1)
+		require.NoError(t, err)
+		got, ok := ValidateTOTP(rfcSecret, prev, now)
+		assert.True(t, ok)
+		assert.Equal(t, counter-1, got)
+	})
+
+	t.Run("超出誤差範圍", func(t *testing.T) {
+		old, err := TOTPCode(rfcSecret, counter-2)
+		require.NoError(t, err)
+		_, ok := ValidateTOTP(rfcSecret, old, now)
+		assert.False(t, ok)
+	})
+
+	t.Run("格式錯誤", func(t *testing.T) {
+		_, ok := ValidateTOTP(rfcSecret, "12345", now)
+		assert.False(t, ok)
+		_, ok = ValidateTOTP("not base32!", "005924", now)
+		assert.False(t, ok)
+	})
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := TOTPCode(secret, TOTPCounter(time.Now()))
+	require.NoError(t, err)
+	_, ok := ValidateTOTP(secret, code, time.Now())
+	assert.True(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Member API", "user@example.com", rfcSecret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/"))
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "/Member API:user@example.com", parsed.Path)
+	assert.Equal(t, rfcSecret, parsed.Query().Get("secret"))
+	assert.Equal(t, "Member API", parsed.Query().Get("issuer"))
+	assert.Equal(t, "6", parsed.Query().Get("digits"))
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 電子郵件驗證政策
+const (
+	// EmailVerificationOff 不強制驗證
+	EmailVerificationOff = "off"
+	// EmailVerificationRestrict 未驗證可登入，但不能執行需要驗證的操作
+	EmailVerificationRestrict = "restrict"
+	// EmailVerificationBlockLogin 未驗證不能登入
+	EmailVerificationBlockLogin = "block_login"
+)
+
+var emailVerificationPolicy = EmailVerificationOff
+
+// SetEmailVerificationPolicy 設定電子郵件驗證政策
+func SetEmailVerificationPolicy(policy string) error {
+	switch policy {
+	case EmailVerificationOff, EmailVerificationRestrict, EmailVerificationBlockLogin:
+		emailVerificationPolicy = policy
+		return nil
+	default:
+		return fmt.Errorf("不支援的電子郵件驗證政策 %q", policy)
+	}
+}
+
+// EmailVerificationPolicy 返回目前的電子郵件驗證政策
+func EmailVerificationPolicy() string {
+	return emailVerificationPolicy
+}
+
+// EmailVerificationRequired 判斷未驗證郵箱的會員是否會被限制操作
+func EmailVerificationRequired() bool {
+	return emailVerificationPolicy != EmailVerificationOff
+}
+
+// RequireVerifiedEmail 在驗證政策啟用時，拒絕尚未驗證電子郵件的會員，必須放在 AuthMiddleware 之後
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFromContext(c.Request.Context())
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證"})
+			c.Abort()
+			return
+		}
+
+		if EmailVerificationRequired() && !principal.EmailVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "請先完成電子郵件驗證"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
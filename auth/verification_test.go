@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetEmailVerificationPolicy(t *testing.T) {
+	defer func() { _ = SetEmailVerificationPolicy(EmailVerificationOff) }()
+
+	assert.NoError(t, SetEmailVerificationPolicy(EmailVerificationRestrict))
+	assert.Equal(t, EmailVerificationRestrict, EmailVerificationPolicy())
+	assert.True(t, EmailVerificationRequired())
+
+	assert.Error(t, SetEmailVerificationPolicy("strict"))
+	assert.Equal(t, EmailVerificationRestrict, EmailVerificationPolicy())
+
+	assert.NoError(t, SetEmailVerificationPolicy(EmailVerificationOff))
+	assert.False(t, EmailVerificationRequired())
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer func() { _ = SetEmailVerificationPolicy(EmailVerificationOff) }()
+
+	signed := func(verified bool) string {
+		claims := NewClaims(1, "verify@example.com")
+		claims.EmailVerified = verified
+		token, err := SignClaims(claims)
+		assert.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		policy     string
+		verified   bool
+		wantStatus int
+	}{
+		{name: "政策關閉", policy: EmailVerificationOff, verified: false, wantStatus: http.StatusOK},
+		{name: "已驗證", policy: EmailVerificationRestrict, verified: true, wantStatus: http.StatusOK},
+		{name: "未驗證", policy: EmailVerificationRestrict, verified: false, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, SetEmailVerificationPolicy(tt.policy))
+
+			router := gin.New()
+			router.Use(AuthMiddleware(), RequireVerifiedEmail())
+			router.POST("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signed(tt.verified))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
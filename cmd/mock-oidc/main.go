@@ -0,0 +1,40 @@
+// mock-oidc 在本機啟動模擬的 OpenID Connect 提供者，用於手動測試外部登入。
+//
+//	go run ./cmd/mock-oidc -addr 127.0.0.1:9999 -client-id member-api -client-secret secret
+//
+// 並設定 OIDC_PROVIDERS=mock、OIDC_MOCK_ISSUER=http://127.0.0.1:9999、
+// OIDC_MOCK_CLIENT_ID 與 OIDC_MOCK_CLIENT_SECRET。授權時不顯示登入頁，直接以參數指定的身分登入。
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"member_API/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9999", "監聽位址")
+	clientID := flag.String("client-id", "member-api", "接受的 client ID")
+	clientSecret := flag.String("client-secret", "secret", "接受的 client secret")
+	subject := flag.String("sub", "mock-user", "登入身分的 subject")
+	email := flag.String("email", "mock-user@example.com", "登入身分的 email")
+	name := flag.String("name", "Mock User", "登入身分的名稱")
+	verified := flag.Bool("email-verified", true, "email 是否已由提供者驗證")
+	flag.Parse()
+
+	server, err := oidctest.Listen(*addr, *clientID, *clientSecret)
+	if err != nil {
+		log.Fatalf("Failed to start mock OIDC provider: %v", err)
+	}
+	defer server.Close()
+	server.SetUser(oidctest.User{Subject: *subject, Email: *email, EmailVerified: *verified, Name: *name})
+
+	log.Printf("Mock OIDC provider listening, issuer: %s\n", server.Issuer())
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"member_API/services"
+)
+
+const commandUsage = `Usage:
+  member_API                         start the API server
+  member_API import [flags] <file>   import members from a CSV or NDJSON file ("-" reads stdin)
+  member_API export [flags]          export members as CSV or NDJSON
+
+Run "member_API <command> -h" for the flags of a command.`
+
+// runCommand 執行命令列子命令；子命令與服務器共用設定與資料庫連線
+func runCommand(args []string) error {
+	switch args[0] {
+	case "import":
+		return importCommand(args[1:])
+	case "export":
+		return exportCommand(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprintln(os.Stderr, commandUsage)
+		return nil
+	default:
+		fmt.Fprintln(os.Stderr, commandUsage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// importCommand 批次匯入會員，結果以 JSON 輸出至 stdout；有資料列錯誤或匯入中止時返回錯誤
+func importCommand(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "file format: csv or ndjson (default: from the file extension)")
+	onDuplicate := flags.String("on-duplicate", services.DuplicateFail, "when the email already exists: skip, update or fail")
+	dryRun := flags.Bool("dry-run", false, "validate and report without writing anything")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("import needs exactly one file argument")
+	}
+	if db == nil {
+		return errors.New("database connection not configured")
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = formatFromPath(path)
+	}
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	result, err := services.NewMemberBulkService(db).Import(input, services.ImportOptions{
+		Format:      *format,
+		OnDuplicate: *onDuplicate,
+		DryRun:      *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+	switch {
+	case result.Aborted:
+		return errors.New("import aborted on a duplicate email; no changes were saved")
+	case result.Failed > 0:
+		return fmt.Errorf("%d of %d rows failed", result.Failed, result.Total)
+	}
+	return nil
+}
+
+// exportCommand 匯出所有未刪除的會員至檔案或 stdout
+func exportCommand(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "", "file format: csv or ndjson (default: from the output extension, or csv)")
+	output := flags.String("o", "-", `output file ("-" writes to stdout)`)
+	includeHash := flags.Bool("include-password-hash", false, "include password hashes, for migrating to another system")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("export takes no arguments")
+	}
+	if db == nil {
+		return errors.New("database connection not configured")
+	}
+	if *format == "" {
+		if *format = formatFromPath(*output); *format == "" {
+			*format = services.BulkFormatCSV
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	var file *os.File
+	if *output != "-" {
+		var err error
+		if file, err = os.Create(*output); err != nil {
+			return err
+		}
+		out = file
+	}
+
+	svc := services.NewMemberBulkService(db)
+	count, err := svc.Export(out, services.ExportOptions{Format: *format, IncludePasswordHash: *includeHash})
+	if file != nil {
+		// 關閉失敗代表檔案可能沒有完整寫入，不記錄為成功的匯出
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	svc.RecordExport(0, *format, count)
+	log.Printf("Exported %d members\n", count)
+	return nil
+}
+
+// formatFromPath 依副檔名判斷格式，無法判斷時返回空字串
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return services.BulkFormatCSV
+	case ".ndjson", ".jsonl":
+		return services.BulkFormatNDJSON
+	default:
+		return ""
+	}
+}
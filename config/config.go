@@ -3,12 +3,20 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
+	Auth     AuthConfig
+	Password PasswordConfig
+	Mail     MailConfig
+	OIDC     OIDCConfig
+	Trash    TrashConfig
+	Privacy  PrivacyConfig
+	Points   PointsConfig
 }
 
 type DatabaseConfig struct {
@@ -20,9 +28,98 @@ type DatabaseConfig struct {
 
 type ServerConfig struct {
 	Port string
+	// PublicURL 前端網站的對外網址，用於組合郵件中的連結
+	PublicURL string
+}
+
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// BootstrapAdminEmail 啟動時自動授予 admin 角色的會員 email，留空則不處理
+	BootstrapAdminEmail string
+	// KeysDir 存放 JWT 簽章金鑰 (*.pem) 的目錄，留空時使用臨時產生的金鑰
+	KeysDir            string
+	ActiveKeyID        string
+	KeysReloadInterval time.Duration
+	PasswordResetTTL   time.Duration
+	// EmailVerificationPolicy 未驗證郵箱會員的限制：off、restrict（限制敏感操作）或 block_login（禁止登入）
+	EmailVerificationPolicy string
+	EmailVerificationTTL    time.Duration
+	// MFAIssuer 顯示在驗證器 App 中的服務名稱
+	MFAIssuer string
+	// LoginAttemptStore 登入失敗紀錄的保存方式：memory（單一實例）或 database（多實例共用）
+	LoginAttemptStore     string
+	LoginLockoutThreshold int
+	LoginLockoutDuration  time.Duration
+	// OAuthAccessTokenTTL 與 OAuthRefreshTokenTTL 為簽發給第三方應用程式的 token 有效期
+	OAuthAccessTokenTTL  time.Duration
+	OAuthRefreshTokenTTL time.Duration
+	// ImpersonationTTL 管理員代理會員登入的 token 有效期
+	ImpersonationTTL time.Duration
+}
+
+// TrashConfig 軟刪除資料的保留期限；超過期限的會員與產品每隔 PurgeInterval 會被永久刪除
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// PrivacyConfig 個人資料匯出設定；匯出檔在 DataExportTTL 後失效
+type PrivacyConfig struct {
+	DataExportTTL time.Duration
+}
+
+// PointsConfig 會員點數設定；點數在入帳 Expiry 後到期，每隔 ExpiryInterval 結算一次到期的點數
+type PointsConfig struct {
+	Expiry         time.Duration
+	ExpiryInterval time.Duration
+}
+
+type PasswordConfig struct {
+	// HashAlgorithm 新密碼使用的雜湊演算法：argon2id 或 bcrypt，舊雜湊會在登入時升級
+	HashAlgorithm     string
+	BcryptCost        int
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	MinLength         int
+	RequireUpper      bool
+	RequireLower      bool
+	RequireDigit      bool
+	RequireSymbol     bool
+	// BlocklistFile 每行一個常見或已外洩密碼的清單檔，留空則不檢查
+	BlocklistFile string
+}
+
+type MailConfig struct {
+	// Driver 郵件寄送方式：log（輸出到日誌）或 file（寫入 FileDir）
+	Driver  string
+	From    string
+	FileDir string
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+}
+
+// OIDCProviderConfig 一個外部登入提供者，由 OIDC_<NAME>_* 環境變數設定
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// wellKnownIssuers 常見提供者的預設 issuer，可用 OIDC_<NAME>_ISSUER 覆寫
+var wellKnownIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+	"line":   "https://access.line.me",
 }
 
 func Load() *Config {
+	publicURL := getEnv("APP_PUBLIC_URL", "http://localhost:8080")
 	return &Config{
 		Database: DatabaseConfig{
 			DSN:             getEnv("POSTGRES_DSN", ""),
@@ -31,9 +128,81 @@ func Load() *Config {
 			ConnMaxLifetime: time.Hour,
 		},
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port:      getEnv("PORT", "8080"),
+			PublicURL: publicURL,
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:          getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:         getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			BootstrapAdminEmail:     getEnv("BOOTSTRAP_ADMIN_EMAIL", ""),
+			KeysDir:                 getEnv("JWT_KEYS_DIR", ""),
+			ActiveKeyID:             getEnv("JWT_ACTIVE_KID", ""),
+			KeysReloadInterval:      getEnvDuration("JWT_KEYS_RELOAD_INTERVAL", 5*time.Minute),
+			PasswordResetTTL:        getEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+			EmailVerificationPolicy: getEnv("EMAIL_VERIFICATION_POLICY", "off"),
+			EmailVerificationTTL:    getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			MFAIssuer:               getEnv("MFA_ISSUER", "Member API"),
+			LoginAttemptStore:       getEnv("LOGIN_ATTEMPT_STORE", "memory"),
+			LoginLockoutThreshold:   getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+			LoginLockoutDuration:    getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			OAuthAccessTokenTTL:     getEnvDuration("OAUTH_ACCESS_TOKEN_TTL", time.Hour),
+			OAuthRefreshTokenTTL:    getEnvDuration("OAUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			ImpersonationTTL:        getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
+		},
+		Password: PasswordConfig{
+			HashAlgorithm:     getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:        getEnvInt("PASSWORD_BCRYPT_COST", 12),
+			Argon2Memory:      getEnvInt("PASSWORD_ARGON2_MEMORY_KB", 64*1024),
+			Argon2Iterations:  getEnvInt("PASSWORD_ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvInt("PASSWORD_ARGON2_PARALLELISM", 4),
+			MinLength:         getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			RequireUpper:      getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+			RequireLower:      getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+			RequireDigit:      getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:     getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			BlocklistFile:     getEnv("PASSWORD_BLOCKLIST_FILE", ""),
+		},
+		Mail: MailConfig{
+			Driver:  getEnv("MAIL_DRIVER", "log"),
+			From:    getEnv("MAIL_FROM", "noreply@member-api.local"),
+			FileDir: getEnv("MAIL_FILE_DIR", "./tmp/mail"),
 		},
+		OIDC: OIDCConfig{
+			Providers: loadOIDCProviders(publicURL),
+		},
+		Trash: TrashConfig{
+			Retention:     getEnvDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", 24*time.Hour),
+		},
+		Privacy: PrivacyConfig{
+			DataExportTTL: getEnvDuration("DATA_EXPORT_TTL", 7*24*time.Hour),
+		},
+		Points: PointsConfig{
+			Expiry:         getEnvDuration("POINTS_EXPIRY", 365*24*time.Hour),
+			ExpiryInterval: getEnvDuration("POINTS_EXPIRY_INTERVAL", time.Hour),
+		},
+	}
+}
+
+// loadOIDCProviders 讀取 OIDC_PROVIDERS（逗號分隔的提供者代號）以及各提供者的 OIDC_<NAME>_* 設定
+func loadOIDCProviders(publicURL string) []OIDCProviderConfig {
+	providers := []OIDCProviderConfig{}
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", wellKnownIssuers[name]),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", publicURL+"/api/v1/auth/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
 	}
+	return providers
 }
 
 func getEnv(key, defaultValue string) string {
@@ -51,3 +220,21 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
@@ -30,6 +30,30 @@ func TestLoad(t *testing.T) {
 				assert.Equal(t, 25, cfg.Database.MaxIdleConns)
 				assert.Equal(t, time.Hour, cfg.Database.ConnMaxLifetime)
 				assert.Equal(t, "8080", cfg.Server.Port)
+				assert.Equal(t, 15*time.Minute, cfg.Auth.AccessTokenTTL)
+				assert.Equal(t, 30*24*time.Hour, cfg.Auth.RefreshTokenTTL)
+				assert.Equal(t, "", cfg.Auth.KeysDir)
+				assert.Equal(t, 5*time.Minute, cfg.Auth.KeysReloadInterval)
+				assert.Equal(t, 30*time.Minute, cfg.Auth.PasswordResetTTL)
+				assert.Equal(t, "off", cfg.Auth.EmailVerificationPolicy)
+				assert.Equal(t, 24*time.Hour, cfg.Auth.EmailVerificationTTL)
+				assert.Equal(t, "Member API", cfg.Auth.MFAIssuer)
+				assert.Equal(t, "memory", cfg.Auth.LoginAttemptStore)
+				assert.Equal(t, 10, cfg.Auth.LoginLockoutThreshold)
+				assert.Equal(t, 15*time.Minute, cfg.Auth.LoginLockoutDuration)
+				assert.Equal(t, time.Hour, cfg.Auth.OAuthAccessTokenTTL)
+				assert.Equal(t, 30*24*time.Hour, cfg.Auth.OAuthRefreshTokenTTL)
+				assert.Equal(t, 15*time.Minute, cfg.Auth.ImpersonationTTL)
+				assert.Equal(t, "argon2id", cfg.Password.HashAlgorithm)
+				assert.Equal(t, 8, cfg.Password.MinLength)
+				assert.False(t, cfg.Password.RequireSymbol)
+				assert.Equal(t, "log", cfg.Mail.Driver)
+				assert.Equal(t, "http://localhost:8080", cfg.Server.PublicURL)
+				assert.Equal(t, 30*24*time.Hour, cfg.Trash.Retention)
+				assert.Equal(t, 24*time.Hour, cfg.Trash.PurgeInterval)
+				assert.Equal(t, 7*24*time.Hour, cfg.Privacy.DataExportTTL)
+				assert.Equal(t, 365*24*time.Hour, cfg.Points.Expiry)
+				assert.Equal(t, time.Hour, cfg.Points.ExpiryInterval)
 			},
 		},
 		{
@@ -234,3 +258,134 @@ func TestConfigStructure(t *testing.T) {
 	assert.Equal(t, 2*time.Hour, cfg.Database.ConnMaxLifetime)
 	assert.Equal(t, "8080", cfg.Server.Port)
 }
+
+func TestGetEnvDuration(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		defaultValue time.Duration
+		envValue     string
+		setEnv       bool
+		expected     time.Duration
+	}{
+		{
+			name:         "環境變數為有效時間長度",
+			key:          "TEST_DURATION",
+			defaultValue: time.Minute,
+			envValue:     "90s",
+			setEnv:       true,
+			expected:     90 * time.Second,
+		},
+		{
+			name:         "環境變數不存在使用預設值",
+			key:          "TEST_DURATION_NOT_SET",
+			defaultValue: time.Hour,
+			setEnv:       false,
+			expected:     time.Hour,
+		},
+		{
+			name:         "環境變數格式錯誤使用預設值",
+			key:          "TEST_DURATION_INVALID",
+			defaultValue: time.Minute,
+			envValue:     "15",
+			setEnv:       true,
+			expected:     time.Minute,
+		},
+		{
+			name:         "環境變數為負數使用預設值",
+			key:          "TEST_DURATION_NEGATIVE",
+			defaultValue: time.Minute,
+			envValue:     "-5m",
+			setEnv:       true,
+			expected:     time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setEnv {
+				_ = os.Setenv(tt.key, tt.envValue)
+				defer func() { _ = os.Unsetenv(tt.key) }()
+			}
+
+			result := getEnvDuration(tt.key, tt.defaultValue)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestGetEnvBool(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		defaultValue bool
+		envValue     string
+		setEnv       bool
+		expected     bool
+	}{
+		{
+			name:         "環境變數為 true",
+			key:          "TEST_BOOL",
+			defaultValue: false,
+			envValue:     "true",
+			setEnv:       true,
+			expected:     true,
+		},
+		{
+			name:         "環境變數不存在使用預設值",
+			key:          "TEST_BOOL_NOT_SET",
+			defaultValue: true,
+			setEnv:       false,
+			expected:     true,
+		},
+		{
+			name:         "環境變數格式錯誤使用預設值",
+			key:          "TEST_BOOL_INVALID",
+			defaultValue: false,
+			envValue:     "yes please",
+			setEnv:       true,
+			expected:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setEnv {
+				_ = os.Setenv(tt.key, tt.envValue)
+				defer func() { _ = os.Unsetenv(tt.key) }()
+			}
+
+			assert.Equal(t, tt.expected, getEnvBool(tt.key, tt.defaultValue))
+		})
+	}
+}
+
+func TestLoadOIDCProviders(t *testing.T) {
+	t.Setenv("OIDC_PROVIDERS", "Google, mock,")
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "google-client")
+	t.Setenv("OIDC_MOCK_ISSUER", "http://localhost:9999")
+	t.Setenv("OIDC_MOCK_CLIENT_ID", "mock-client")
+	t.Setenv("OIDC_MOCK_CLIENT_SECRET", "mock-secret")
+	t.Setenv("OIDC_MOCK_SCOPES", "openid email")
+	t.Setenv("OIDC_MOCK_REDIRECT_URL", "http://localhost:3000/callback")
+
+	providers := loadOIDCProviders("https://member.example.com")
+	assert.Len(t, providers, 2)
+
+	google := providers[0]
+	assert.Equal(t, "google", google.Name)
+	assert.Equal(t, "https://accounts.google.com", google.Issuer)
+	assert.Equal(t, "google-client", google.ClientID)
+	assert.Equal(t, "https://member.example.com/api/v1/auth/oidc/google/callback", google.RedirectURL)
+	assert.Equal(t, []string{"openid", "email", "profile"}, google.Scopes)
+
+	mock := providers[1]
+	assert.Equal(t, "mock", mock.Name)
+	assert.Equal(t, "http://localhost:9999", mock.Issuer)
+	assert.Equal(t, "mock-secret", mock.ClientSecret)
+	assert.Equal(t, "http://localhost:3000/callback", mock.RedirectURL)
+	assert.Equal(t, []string{"openid", "email"}, mock.Scopes)
+
+	t.Setenv("OIDC_PROVIDERS", "")
+	assert.Empty(t, loadOIDCProviders("https://member.example.com"))
+}
@@ -0,0 +1,315 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"member_API/auth"
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKeyRequest 建立 API key 的參數
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100" example:"nightly-sync"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1" example:"product:read"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2027-01-01T00:00:00Z"`
+}
+
+// APIKeyResponse API key 的資訊，不含 key 本身
+type APIKeyResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	Name       string     `json:"name" example:"nightly-sync"`
+	Prefix     string     `json:"prefix" example:"3f9a1c0b"`
+	Scopes     []string   `json:"scopes" example:"product:read"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip" example:"203.0.113.7"`
+}
+
+// CreatedAPIKeyResponse 新建立的 API key；key 只會在此回應中出現一次
+type CreatedAPIKeyResponse struct {
+	Key    string         `json:"key" example:"mk_3f9a1c0b_Xq2..."`
+	APIKey APIKeyResponse `json:"api_key"`
+}
+
+// CreateServiceAccountRequest 建立服務帳號的參數
+type CreateServiceAccountRequest struct {
+	Name  string `json:"name" binding:"required" example:"ERP 同步"`
+	Email string `json:"email" binding:"required,email" example:"erp-sync@service.local"`
+}
+
+// GetAPIKeys 列出當前用戶的 API key
+// @Summary 列出 API key
+// @Description 列出當前用戶尚未撤銷的 API key，不含 key 本身
+// @Tags API key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]APIKeyResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /api-keys [get]
+func GetAPIKeys(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+	listAPIKeys(c, currentUserID(c))
+}
+
+// CreateAPIKey 建立當前用戶的 API key
+// @Summary 建立 API key
+// @Description 為當前用戶建立具名的 API key，權限範圍只能是自己擁有的權限；完整 key 只會顯示一次。
+// @Description 機器客戶端以 X-API-Key header 傳送，API key 本身不能用來建立新的 API key
+// @Tags API key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPIKeyRequest true "名稱、權限範圍與到期時間"
+// @Success 201 {object} CreatedAPIKeyResponse "建立成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤或權限範圍無效"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "不可以 API key 建立 API key"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /api-keys [post]
+func CreateAPIKey(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+	if principal := auth.PrincipalFromContext(c.Request.Context()); principal != nil && principal.APIKeyID != 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "不可以 API key 建立新的 API key"})
+		return
+	}
+	createAPIKey(c, currentUserID(c))
+}
+
+// RevokeAPIKey 撤銷當前用戶的 API key
+// @Summary 撤銷 API key
+// @Description 撤銷當前用戶的某把 API key，使用該 key 的請求立即被拒絕
+// @Tags API key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} map[string]string "已撤銷"
+// @Failure 400 {object} map[string]string "無效的 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "API key 不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /api-keys/{id} [delete]
+func RevokeAPIKey(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+	revokeAPIKey(c, currentUserID(c), c.Param("id"))
+}
+
+// CreateServiceAccount 建立服務帳號（管理員）
+// @Summary 建立服務帳號
+// @Description 建立沒有密碼、無法登入的服務帳號，之後以 /admin/members/{id}/api-keys 為其建立 API key，需要 apikey:manage 權限
+// @Tags API key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateServiceAccountRequest true "服務帳號資料"
+// @Success 201 {object} map[string]User "建立成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 409 {object} map[string]string "該電子郵件已被使用"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/service-accounts [post]
+func CreateServiceAccount(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	var req CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewAPIKeyService(db.WithContext(c.Request.Context()))
+	member, err := svc.CreateServiceAccount(req.Name, req.Email, currentUserID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrEmailInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": "該電子郵件已被使用"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"user": User{ID: int64(member.ID), Name: member.Name, Email: member.Email}})
+}
+
+// GetMemberAPIKeys 列出指定會員的 API key（管理員）
+// @Summary 列出會員 API key
+// @Description 列出指定會員或服務帳號尚未撤銷的 API key，需要 apikey:manage 權限
+// @Tags API key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID"
+// @Success 200 {object} map[string][]APIKeyResponse "獲取成功"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/api-keys [get]
+func GetMemberAPIKeys(c *gin.Context) {
+	memberID, ok := adminMemberParam(c)
+	if !ok {
+		return
+	}
+	listAPIKeys(c, memberID)
+}
+
+// CreateMemberAPIKey 為指定會員建立 API key（管理員）
+// @Summary 為會員建立 API key
+// @Description 為指定會員或服務帳號建立 API key，權限範圍只能是該會員擁有的權限，需要 apikey:manage 權限
+// @Tags API key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID"
+// @Param request body CreateAPIKeyRequest true "名稱、權限範圍與到期時間"
+// @Success 201 {object} CreatedAPIKeyResponse "建立成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤或權限範圍無效"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "用戶不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/api-keys [post]
+func CreateMemberAPIKey(c *gin.Context) {
+	memberID, ok := adminMemberParam(c)
+	if !ok {
+		return
+	}
+	createAPIKey(c, memberID)
+}
+
+// RevokeMemberAPIKey 撤銷指定會員的 API key（管理員）
+// @Summary 撤銷會員 API key
+// @Description 撤銷指定會員或服務帳號的某把 API key，需要 apikey:manage 權限
+// @Tags API key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID"
+// @Param key_id path int true "API key ID"
+// @Success 200 {object} map[string]string "已撤銷"
+// @Failure 400 {object} map[string]string "無效的 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "API key 不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/api-keys/{key_id} [delete]
+func RevokeMemberAPIKey(c *gin.Context) {
+	memberID, ok := adminMemberParam(c)
+	if !ok {
+		return
+	}
+	revokeAPIKey(c, memberID, c.Param("key_id"))
+}
+
+// adminMemberParam 解析管理員路由中的會員 ID，失敗時寫入錯誤回應
+func adminMemberParam(c *gin.Context) (uint, bool) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return 0, false
+	}
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return 0, false
+	}
+	return uint(memberID), true
+}
+
+func listAPIKeys(c *gin.Context, memberID uint) {
+	svc := services.NewAPIKeyService(db.WithContext(c.Request.Context()))
+	keys, err := svc.List(memberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, toAPIKeyResponse(key))
+	}
+	c.JSON(http.StatusOK, gin.H{"api_keys": out})
+}
+
+func createAPIKey(c *gin.Context, memberID uint) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewAPIKeyService(db.WithContext(c.Request.Context()))
+	key, rawKey, err := svc.Create(memberID, services.APIKeyInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}, currentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAPIKeyScopeInvalid), errors.Is(err, services.ErrAPIKeyExpiry):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "用戶不存在"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatedAPIKeyResponse{Key: rawKey, APIKey: toAPIKeyResponse(*key)})
+}
+
+func revokeAPIKey(c *gin.Context, memberID uint, rawKeyID string) {
+	keyID, err := strconv.ParseUint(rawKeyID, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 API key ID"})
+		return
+	}
+
+	svc := services.NewAPIKeyService(db.WithContext(c.Request.Context()))
+	if err := svc.Revoke(memberID, uint(keyID), currentUserID(c)); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key 已撤銷"})
+}
+
+func toAPIKeyResponse(key models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     strings.Fields(key.Scopes),
+		CreatedAt:  key.CreationTime,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogResponse represents a single audit log entry.
+type AuditLogResponse struct {
+	ID        uint      `json:"id" example:"1"`
+	Action    string    `json:"action" example:"login.failed"`
+	MemberID  uint      `json:"member_id" example:"1"`
+	ActorID   uint      `json:"actor_id" example:"0"`
+	IP        string    `json:"ip" example:"203.0.113.7"`
+	UserAgent string    `json:"user_agent" example:"Mozilla/5.0"`
+	Detail    string    `json:"detail" example:"email=user@example.com reason=invalid_password failures=1"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetAuditLogs returns audit log entries, newest first.
+// @Summary 查詢審計紀錄
+// @Description 依事件、會員或操作者查詢審計紀錄，新的在前，需要 audit:read 權限
+// @Tags 審計
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param action query string false "事件名稱" example(login.failed)
+// @Param member_id query int false "相關會員 ID"
+// @Param actor_id query int false "操作者 ID"
+// @Param limit query int false "每頁筆數（預設 50，最多 200）"
+// @Param offset query int false "略過筆數"
+// @Success 200 {object} map[string]interface{} "獲取成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/audit-logs [get]
+func GetAuditLogs(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	filter := services.AuditFilter{Action: c.Query("action")}
+	for param, target := range map[string]*uint{"member_id": &filter.MemberID, "actor_id": &filter.ActorID} {
+		if raw := c.Query(param); raw != "" {
+			id, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+				return
+			}
+			*target = uint(id)
+		}
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit 必須介於 1 到 200"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset 不能為負數"})
+		return
+	}
+
+	svc := services.NewAuditService(db)
+	logs, total, err := svc.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]AuditLogResponse, len(logs))
+	for i, entry := range logs {
+		out[i] = AuditLogResponse{
+			ID:        entry.ID,
+			Action:    entry.Action,
+			MemberID:  entry.MemberID,
+			ActorID:   entry.ActorID,
+			IP:        entry.IP,
+			UserAgent: entry.UserAgent,
+			Detail:    entry.Detail,
+			CreatedAt: entry.CreationTime,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"audit_logs": out,
+		"total":      total,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
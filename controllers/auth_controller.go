@@ -2,7 +2,12 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"member_API/auth"
 	"member_API/models"
@@ -14,29 +19,43 @@ import (
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"user@example.com"`
-	Password string `json:"password" binding:"required,min=6" example:"password123"`
+	Password string `json:"password" binding:"required" example:"password123"`
 }
 
 type RegisterRequest struct {
 	Name     string `json:"name" binding:"required" example:"張三"`
 	Email    string `json:"email" binding:"required,email" example:"user@example.com"`
-	Password string `json:"password" binding:"required,min=6" example:"password123"`
+	Password string `json:"password" binding:"required" example:"Correct-Horse-42"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"3q2-7wX9..."`
+}
+
+// MFAChallengeResponse 會員啟用兩步驟驗證時，密碼驗證通過後返回的 challenge
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required" example:"true"`
+	MFAToken    string `json:"mfa_token" example:"eyJhbGciOiJFZERTQSIsImtpZCI6..."`
+	ExpiresIn   int64  `json:"expires_in" example:"300"`
 }
 
 type AuthResponse struct {
-	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	User  User   `json:"user"`
+	Token        string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"3q2-7wX9..."`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+	User         User   `json:"user"`
 }
 
 // Register 用戶註冊
 // @Summary 用戶註冊
-// @Description 註冊新用戶，返回 JWT token 和用戶信息
+// @Description 註冊新用戶並寄出驗證郵件，返回 access token、refresh token 和用戶信息；若驗證政策為 block_login 則不返回 token
 // @Tags 認證
 // @Accept json
 // @Produce json
 // @Param register body RegisterRequest true "註冊信息"
+// @Param X-Device-Name header string false "裝置名稱，顯示在登入裝置列表"
 // @Success 201 {object} AuthResponse "註冊成功"
-// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 400 {object} map[string]interface{} "請求參數錯誤或密碼不符合規則"
 // @Failure 409 {object} map[string]string "該電子郵件已被註冊"
 // @Failure 500 {object} map[string]string "服務器錯誤"
 // @Router /register [post]
@@ -58,39 +77,45 @@ func Register(input *gin.Context) {
 	// 註冊時使用 creatorId = 0 表示自行註冊
 	member, err := svc.CreateMember(req.Name, req.Email, req.Password, 0)
 	if err != nil {
-		if err.Error() == "email 已被使用" {
+		if errors.Is(err, services.ErrEmailInUse) {
 			input.JSON(http.StatusConflict, gin.H{"error": "該電子郵件已被註冊"})
 			return
 		}
+		var policyErr *auth.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			input.JSON(http.StatusBadRequest, gin.H{"error": policyErr.Error(), "violations": policyErr.Violations})
+			return
+		}
 		input.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	user := User{ID: int64(member.ID), Name: member.Name, Email: member.Email}
-
-	// 生成 token
-	token, err := auth.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		input.JSON(http.StatusInternalServerError, gin.H{"error": "Token 生成失敗"})
+	// 需先驗證郵箱才能登入時，註冊後不簽發 token
+	if auth.EmailVerificationPolicy() == auth.EmailVerificationBlockLogin {
+		input.JSON(http.StatusCreated, gin.H{
+			"user":    User{ID: int64(member.ID), Name: member.Name, Email: member.Email},
+			"message": "註冊成功，請至郵箱完成驗證後再登入",
+		})
 		return
 	}
 
-	input.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	respondWithTokens(input, http.StatusCreated, member)
 }
 
 // Login 用戶登入
 // @Summary 用戶登入
-// @Description 用戶登入，驗證郵件和密碼後返回 JWT token 和用戶信息
+// @Description 用戶登入，驗證郵件和密碼後返回 access token、refresh token 和用戶信息；
+// @Description 若會員已啟用兩步驟驗證，改為返回 MFAChallengeResponse，需以 /login/mfa 提交驗證碼換取 token
 // @Tags 認證
 // @Accept json
 // @Produce json
 // @Param login body LoginRequest true "登入信息"
+// @Param X-Device-Name header string false "裝置名稱，顯示在登入裝置列表"
 // @Success 200 {object} AuthResponse "登入成功"
 // @Failure 400 {object} map[string]string "請求參數錯誤"
 // @Failure 401 {object} map[string]string "電子郵件或密碼錯誤"
+// @Failure 403 {object} map[string]string "尚未完成電子郵件驗證或帳號已停權、封鎖、關閉"
+// @Failure 429 {object} map[string]string "登入失敗次數過多，已暫時限制或鎖定"
 // @Failure 500 {object} map[string]string "服務器錯誤"
 // @Router /login [post]
 func Login(input *gin.Context) {
@@ -105,14 +130,20 @@ func Login(input *gin.Context) {
 		return
 	}
 
+	// 被退避或鎖定時直接拒絕，不驗證密碼
+	if !checkLoginGuard(input, req.Email) {
+		return
+	}
+
 	// 查詢用戶
 	var member models.Member
 	err := db.WithContext(input.Request.Context()).
-		Where("email = ?", req.Email).
+		Scopes(models.NotDeleted, services.ByEmail(req.Email)).
 		First(&member).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			recordLoginFailure(input, req.Email, 0, services.AuditLoginFailed, "unknown_email")
 			input.JSON(http.StatusUnauthorized, gin.H{"error": "電子郵件或密碼錯誤"})
 			return
 		}
@@ -122,33 +153,219 @@ func Login(input *gin.Context) {
 
 	// 驗證密碼
 	if !auth.CheckPassword(req.Password, member.PasswordHash) {
+		recordLoginFailure(input, req.Email, member.ID, services.AuditLoginFailed, "invalid_password")
 		input.JSON(http.StatusUnauthorized, gin.H{"error": "電子郵件或密碼錯誤"})
 		return
 	}
 
-	user := User{ID: int64(member.ID), Name: member.Name, Email: member.Email}
+	// 停權、封鎖等狀態在密碼驗證後才告知，避免洩漏帳號狀態
+	if err := services.CheckMemberStatus(&member); err != nil {
+		input.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	if auth.EmailVerificationPolicy() == auth.EmailVerificationBlockLogin && member.EmailVerifiedAt == nil {
+		input.JSON(http.StatusForbidden, gin.H{"error": "請先完成電子郵件驗證"})
+		return
+	}
+
+	// 已啟用兩步驟驗證：密碼正確只換得短效 challenge，不簽發 access token
+	if member.TOTPEnabledAt != nil {
+		respondWithMFAChallenge(input, &member)
+		return
+	}
+
+	// 舊演算法或較低成本的雜湊趁此時升級
+	services.NewMemberService(db.WithContext(input.Request.Context())).UpgradePasswordHash(&member, req.Password)
+
+	clearLoginFailures(input, member.Email)
+	respondWithTokens(input, http.StatusOK, &member)
+}
+
+// RefreshToken 換發 token
+// @Summary 換發 token
+// @Description 以 refresh token 換發新的 access token 與 refresh token，舊的 refresh token 立即失效；重複使用已輪換的 refresh token 會撤銷整個登入
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param refresh body RefreshTokenRequest true "refresh token"
+// @Success 200 {object} AuthResponse "換發成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "refresh token 無效或已被重複使用"
+// @Failure 403 {object} map[string]string "帳號已停權、封鎖或關閉"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /token/refresh [post]
+func RefreshToken(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// 生成 token
-	token, err := auth.GenerateToken(user.ID, user.Email)
+	svc := services.NewTokenService(db.WithContext(c.Request.Context()))
+	pair, member, err := svc.Refresh(req.RefreshToken, sessionMetadata(c))
 	if err != nil {
-		input.JSON(http.StatusInternalServerError, gin.H{"error": "Token 生成失敗"})
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if services.IsMemberStatusError(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	input.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  user,
+	c.JSON(http.StatusOK, AuthResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		User:         User{ID: int64(member.ID), Name: member.Name, Email: member.Email},
+	})
+}
+
+// Logout 用戶登出
+// @Summary 用戶登出
+// @Description 撤銷 refresh token 所屬的登入，之後該登入的 refresh token 均無法再換發
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param refresh body RefreshTokenRequest true "refresh token"
+// @Success 200 {object} map[string]string "登出成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "refresh token 無效"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /logout [post]
+func Logout(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewTokenService(db.WithContext(c.Request.Context()))
+	if err := svc.Revoke(req.RefreshToken); err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已登出"})
+}
+
+// checkLoginGuard 檢查 email 與來源 IP 是否因登入失敗被限制，被限制時寫入 429 回應並返回 false
+func checkLoginGuard(c *gin.Context, email string) bool {
+	wait, err := auth.CurrentLoginGuard().Check(c.Request.Context(), email, c.ClientIP())
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, auth.ErrAccountLocked) || errors.Is(err, auth.ErrTooManyAttempts) {
+		seconds := int64(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "retry_after": seconds})
+		return false
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	return false
+}
+
+// recordLoginFailure 累計登入失敗並寫入審計紀錄；達到鎖定門檻時另記一筆鎖定事件
+func recordLoginFailure(c *gin.Context, email string, memberID uint, action, reason string) {
+	ctx := c.Request.Context()
+	state, err := auth.CurrentLoginGuard().Fail(ctx, email, c.ClientIP())
+	if err != nil {
+		log.Printf("[Login] failed to record login failure: %v\n", err)
+	}
+
+	audit := services.NewAuditService(db.WithContext(ctx))
+	entry := services.AuditEntry{
+		Action:    action,
+		MemberID:  memberID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Detail:    fmt.Sprintf("email=%s reason=%s failures=%d", email, reason, state.Failures),
+	}
+	audit.TryRecord(entry)
+
+	if state.Locked && state.Failures == auth.CurrentLoginGuard().AccountPolicy.LockoutThreshold {
+		entry.Action = services.AuditLoginLocked
+		entry.Detail = fmt.Sprintf("email=%s until=%s", email, state.BlockedUntil.Format(time.RFC3339))
+		audit.TryRecord(entry)
+	}
+}
+
+// clearLoginFailures 登入成功後清除 email 的失敗紀錄
+func clearLoginFailures(c *gin.Context, email string) {
+	if err := auth.CurrentLoginGuard().Succeed(c.Request.Context(), email); err != nil {
+		log.Printf("[Login] failed to reset login failures: %v\n", err)
+	}
+}
+
+// sessionMetadata 從請求取得登入的裝置資訊，裝置名稱由客戶端以 X-Device-Name header 提供
+func sessionMetadata(c *gin.Context) services.SessionMetadata {
+	return services.SessionMetadata{
+		DeviceName: c.GetHeader("X-Device-Name"),
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}
+}
+
+// respondWithMFAChallenge 第一步驗證通過但會員已啟用兩步驟驗證時，返回短效 challenge
+func respondWithMFAChallenge(c *gin.Context, member *models.Member) {
+	challenge, err := auth.GenerateMFAChallenge(int64(member.ID), member.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token 生成失敗"})
+		return
+	}
+	c.JSON(http.StatusOK, MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    challenge,
+		ExpiresIn:   int64(auth.MFAChallengeTTL().Seconds()),
+	})
+}
+
+// respondWithTokens 為會員開啟新的登入並返回 token 組合
+func respondWithTokens(c *gin.Context, status int, member *models.Member) {
+	svc := services.NewTokenService(db.WithContext(c.Request.Context()))
+	pair, err := svc.IssueTokenPair(member, sessionMetadata(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token 生成失敗"})
+		return
+	}
+
+	c.JSON(status, AuthResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		User:         User{ID: int64(member.ID), Name: member.Name, Email: member.Email},
 	})
 }
 
 // GetProfile 獲取當前用戶信息（需要認證）
 // @Summary 獲取當前用戶信息
-// @Description 獲取當前登入用戶的詳細信息，需要 JWT 認證
+// @Description 獲取當前登入用戶的詳細信息與個人檔案（profile），需要 JWT 認證；代理登入時會附上 impersonation 欄位。
+// @Description 第三方應用程式需要 profile scope，與 userinfo 相同只在取得 email scope 時返回 email，且不返回手機號碼
 // @Tags 用戶
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} map[string]User "獲取成功"
+// @Success 200 {object} map[string]interface{} "獲取成功"
 // @Failure 401 {object} map[string]string "未認證"
 // @Failure 404 {object} map[string]string "用戶不存在"
 // @Failure 500 {object} map[string]string "服務器錯誤"
@@ -173,7 +390,8 @@ func GetProfile(c *gin.Context) {
 
 	var member models.Member
 	if err := db.WithContext(c.Request.Context()).
-		Select("id", "name", "email").
+		Scopes(models.NotDeleted).
+		Select("id", "name", "email", "phone", "birthday", "avatar_url", "locale", "timezone").
 		First(&member, idValue).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "用戶不存在"})
@@ -183,5 +401,128 @@ func GetProfile(c *gin.Context) {
 		return
 	}
 
+	// 第三方應用程式與 userinfo 相同：email 需要 email scope，不提供手機號碼
+	user := User{ID: int64(member.ID), Name: member.Name, Email: member.Email}
+	profile := toMemberProfileResponse(&member)
+	if principal := auth.PrincipalFromContext(c.Request.Context()); principal != nil {
+		if !principal.HasScope(auth.ScopeEmail) {
+			user.Email = ""
+		}
+		if principal.ClientID != "" {
+			profile.Phone = ""
+		}
+	}
+	response := gin.H{
+		"user":    user,
+		"profile": profile,
+	}
+	if impersonation := currentImpersonation(c); impersonation != nil {
+		response["impersonation"] = impersonation
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type UpdateProfileRequest struct {
+	Name  string `json:"name" binding:"required" example:"張三"`
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+	// CurrentPassword 變更 email 時必填
+	CurrentPassword string `json:"current_password" example:"password123"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"password123"`
+	NewPassword     string `json:"new_password" binding:"required" example:"Correct-Horse-42"`
+}
+
+// UpdateProfile 修改當前用戶資料（需要認證）
+// @Summary 修改當前用戶資料
+// @Description 修改當前登入用戶的姓名與電子郵件；變更電子郵件需提供目前密碼，新郵箱需重新驗證
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body UpdateProfileRequest true "用戶資料"
+// @Success 200 {object} map[string]User "修改成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤或目前密碼錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "用戶不存在"
+// @Failure 409 {object} map[string]string "該電子郵件已被使用"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile [put]
+func UpdateProfile(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewMemberService(db.WithContext(c.Request.Context()))
+	member, err := svc.UpdateProfile(currentUserID(c), req.Name, req.Email, req.CurrentPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "目前密碼錯誤"})
+		case errors.Is(err, services.ErrEmailInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": "該電子郵件已被使用"})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "用戶不存在"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"user": User{ID: int64(member.ID), Name: member.Name, Email: member.Email}})
 }
+
+// ChangePassword 修改當前用戶密碼（需要認證）
+// @Summary 修改密碼
+// @Description 驗證目前密碼後設定新密碼，成功後除目前登入外的其他登入都會被登出
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ChangePasswordRequest true "目前密碼與新密碼"
+// @Success 200 {object} map[string]string "修改成功"
+// @Failure 400 {object} map[string]interface{} "請求參數錯誤、目前密碼錯誤或新密碼不符合規則"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "用戶不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/password [post]
+func ChangePassword(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewMemberService(db.WithContext(c.Request.Context()))
+	if err := svc.ChangePassword(currentUserID(c), req.CurrentPassword, req.NewPassword, currentSessionID(c)); err != nil {
+		var policyErr *auth.PasswordPolicyError
+		switch {
+		case errors.Is(err, services.ErrInvalidPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "目前密碼錯誤"})
+		case errors.Is(err, services.ErrSamePassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.As(err, &policyErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": policyErr.Error(), "violations": policyErr.Violations})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "用戶不存在"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "密碼已更新，其他裝置的登入已登出"})
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"q1w2e3..."`
+}
+
+// VerifyEmail 確認電子郵件
+// @Summary 確認電子郵件
+// @Description 以驗證郵件中的 token 完成電子郵件驗證；已登入的客戶端需換發 token 後才會帶有已驗證狀態
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "驗證 token"
+// @Success 200 {object} map[string]User "驗證成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤或 token 無效"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /email/verify [post]
+func VerifyEmail(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewEmailVerificationService(db.WithContext(c.Request.Context()))
+	member, err := svc.Verify(req.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMemberToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": User{ID: int64(member.ID), Name: member.Name, Email: member.Email}})
+}
+
+// ResendVerificationEmail 重新寄送驗證郵件
+// @Summary 重新寄送驗證郵件
+// @Description 為目前登入的會員重新寄送電子郵件驗證連結，先前的驗證連結將失效
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "已寄出"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 409 {object} map[string]string "電子郵件已完成驗證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /email/verify/resend [post]
+func ResendVerificationEmail(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewEmailVerificationService(db.WithContext(c.Request.Context()))
+	if err := svc.ResendVerification(c.Request.Context(), currentUserID(c)); err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "驗證郵件已寄出"})
+}
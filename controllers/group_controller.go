@@ -0,0 +1,349 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GroupResponse represents a member group.
+type GroupResponse struct {
+	ID          uint      `json:"id" example:"1"`
+	Name        string    `json:"name" example:"vip"`
+	Description string    `json:"description" example:"VIP 會員"`
+	MemberCount *int64    `json:"member_count,omitempty" example:"42"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GroupRequest represents the request body for creating or updating a group.
+type GroupRequest struct {
+	Name        string `json:"name" binding:"required" example:"vip"`
+	Description string `json:"description" example:"VIP 會員"`
+}
+
+// AddGroupMembersRequest represents the request body for adding members to a group.
+type AddGroupMembersRequest struct {
+	MemberIDs []uint `json:"member_ids" binding:"required" example:"1,2,3"`
+}
+
+// SetMemberGroupsRequest represents the request body for replacing a member's groups.
+type SetMemberGroupsRequest struct {
+	Groups []string `json:"groups" binding:"required" example:"vip,wholesale"`
+}
+
+func groupResponse(group *models.MemberGroup) GroupResponse {
+	return GroupResponse{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedAt:   group.CreationTime,
+	}
+}
+
+// writeGroupError maps group service errors to HTTP responses.
+func writeGroupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidGroupName),
+		errors.Is(err, services.ErrTooManyMembers),
+		errors.Is(err, services.ErrMembersNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrGroupNotFound), errors.Is(err, services.ErrNotGroupMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrGroupNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err.Error() == "會員不存在":
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetGroups returns all member groups with their member counts (admin).
+// @Summary 獲取會員群組列表
+// @Description 獲取所有會員群組（如 vip、wholesale、staff）與其未刪除的會員人數，依名稱排序，需要 member:read 權限
+// @Tags 群組
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]GroupResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/groups [get]
+func GetGroups(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	svc := services.NewGroupService(db.WithContext(c.Request.Context()))
+	groups, err := svc.ListGroups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]GroupResponse, len(groups))
+	for i := range groups {
+		out[i] = groupResponse(&groups[i].Group)
+		out[i].MemberCount = &groups[i].MemberCount
+	}
+	c.JSON(http.StatusOK, gin.H{"groups": out})
+}
+
+// CreateGroup creates a member group (admin).
+// @Summary 建立會員群組
+// @Description 建立會員群組，名稱為 1 到 50 個小寫英文字母、數字、- 或 _，不分大小寫且不可重複，需要 group:manage 權限
+// @Tags 群組
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param group body GroupRequest true "群組名稱與說明"
+// @Success 201 {object} map[string]GroupResponse "建立成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 409 {object} map[string]string "群組名稱已被使用"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/groups [post]
+func CreateGroup(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewGroupService(db.WithContext(c.Request.Context()))
+	group, err := svc.CreateGroup(req.Name, req.Description, currentUserID(c))
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"group": groupResponse(group)})
+}
+
+// UpdateGroup renames or re-describes a member group (admin).
+// @Summary 修改會員群組
+// @Description 修改群組名稱與說明，需要 group:manage 權限；token 中的群組名稱在會員下次換發 token 時更新
+// @Tags 群組
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "群組 ID" example(1)
+// @Param group body GroupRequest true "群組名稱與說明"
+// @Success 200 {object} map[string]GroupResponse "修改成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "群組不存在"
+// @Failure 409 {object} map[string]string "群組名稱已被使用"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/groups/{id} [put]
+func UpdateGroup(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewGroupService(db.WithContext(c.Request.Context()))
+	group, err := svc.UpdateGroup(uint(groupID), req.Name, req.Description, currentUserID(c))
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"group": groupResponse(group)})
+}
+
+// DeleteGroup deletes a member group and removes all of its assignments (admin).
+// @Summary 刪除會員群組
+// @Description 刪除群組並將所有會員移出該群組，需要 group:manage 權限
+// @Tags 群組
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "群組 ID" example(1)
+// @Success 200 {object} map[string]string "刪除成功"
+// @Failure 400 {object} map[string]string "無效的群組 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "群組不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/groups/{id} [delete]
+func DeleteGroup(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	svc := services.NewGroupService(db.WithContext(c.Request.Context()))
+	if err := svc.DeleteGroup(uint(groupID), currentUserID(c)); err != nil {
+		writeGroupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "群組已刪除"})
+}
+
+// AddGroupMembers adds members to a group (admin).
+// @Summary 將會員加入群組
+// @Description 將多位會員加入群組，已在群組中的會員略過；任一會員不存在時不加入任何會員。一次最多 1000 位，需要 group:manage 權限
+// @Tags 群組
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "群組 ID" example(1)
+// @Param members body AddGroupMembersRequest true "會員 ID"
+// @Success 200 {object} map[string]int64 "加入成功，added 為新加入的人數"
+// @Failure 400 {object} map[string]string "請求參數錯誤或會員不存在"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "群組不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/groups/{id}/members [post]
+func AddGroupMembers(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	var req AddGroupMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewGroupService(db.WithContext(c.Request.Context()))
+	added, err := svc.AddMembers(uint(groupID), req.MemberIDs, currentUserID(c))
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"added": added})
+}
+
+// RemoveGroupMember removes a member from a group (admin).
+// @Summary 將會員移出群組
+// @Description 將會員移出群組，需要 group:manage 權限
+// @Tags 群組
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "群組 ID" example(1)
+// @Param member_id path int true "會員 ID" example(2)
+// @Success 200 {object} map[string]string "移出成功"
+// @Failure 400 {object} map[string]string "無效的 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "群組不存在或會員不在群組中"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/groups/{id}/members/{member_id} [delete]
+func RemoveGroupMember(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+	memberID, err := strconv.ParseUint(c.Param("member_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	svc := services.NewGroupService(db.WithContext(c.Request.Context()))
+	if err := svc.RemoveMember(uint(groupID), uint(memberID), currentUserID(c)); err != nil {
+		writeGroupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "會員已移出群組"})
+}
+
+// SetMemberGroups replaces the groups assigned to a member (admin).
+// @Summary 設定會員群組
+// @Description 以指定群組完全取代會員目前的群組，空陣列會移出所有群組，需要 group:manage 權限；token 中的群組在會員下次換發 token 時更新
+// @Tags 群組
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Param groups body SetMemberGroupsRequest true "群組名稱"
+// @Success 200 {object} map[string]interface{} "設定成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員或群組不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/groups [put]
+func SetMemberGroups(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req SetMemberGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewGroupService(db.WithContext(c.Request.Context()))
+	member, err := svc.SetMemberGroups(uint(memberID), req.Groups, currentUserID(c))
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+
+	groups := make([]string, len(member.Groups))
+	for i, group := range member.Groups {
+		groups[i] = group.Name
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":   User{ID: int64(member.ID), Name: member.Name, Email: member.Email},
+		"groups": groups,
+	})
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/auth"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonateRequest 代理登入的原因，會寫入審計紀錄
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required" example:"協助處理工單 #1234"`
+}
+
+// ImpersonationResponse 代理登入 token；沒有 refresh token，過期後需重新申請
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token" example:"eyJhbGciOiJFZERTQSIs..."`
+	TokenType   string    `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64     `json:"expires_in" example:"900"`
+	SessionID   string    `json:"session_id" example:"Zr7uG1Xq..."`
+	ExpiresAt   time.Time `json:"expires_at"`
+	User        User      `json:"user"`
+}
+
+// ImpersonationInfo 顯示在 /profile 的代理登入資訊
+type ImpersonationInfo struct {
+	ActorID    uint   `json:"actor_id" example:"1"`
+	ActorEmail string `json:"actor_email" example:"admin@example.com"`
+	SessionID  string `json:"session_id" example:"Zr7uG1Xq..."`
+}
+
+// ImpersonateMember 以會員身分代理登入（管理員）
+// @Summary 代理會員登入
+// @Description 簽發短效的代理登入 token，token 的 act claim 記錄實際操作的管理員。代理登入期間不可變更密碼、email、兩步驟驗證等帳號設定，
+// @Description 每個請求都會寫入審計紀錄。不可代理自己、服務帳號或擁有管理員沒有之權限的會員，需要 member:impersonate 權限
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Param request body ImpersonateRequest true "代理登入原因"
+// @Success 201 {object} ImpersonationResponse "代理登入成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足或不可代理此會員"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/impersonate [post]
+func ImpersonateMember(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewImpersonationService(db.WithContext(c.Request.Context()))
+	result, err := svc.Start(currentUserID(c), uint(memberID), req.Reason, sessionMetadata(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImpersonationReason):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrImpersonationNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, ImpersonationResponse{
+		AccessToken: result.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   result.ExpiresIn,
+		SessionID:   result.Session.SessionID,
+		ExpiresAt:   result.Session.ExpiresAt,
+		User:        User{ID: int64(result.Member.ID), Name: result.Member.Name, Email: result.Member.Email},
+	})
+}
+
+// EndImpersonation 結束目前的代理登入
+// @Summary 結束代理登入
+// @Description 以代理登入 token 呼叫，結束該次代理登入並使 token 立即失效
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "已結束代理登入"
+// @Failure 400 {object} map[string]string "目前不是代理登入"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "代理登入不存在或已結束"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /impersonation [delete]
+func EndImpersonation(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	principal := auth.PrincipalFromContext(c.Request.Context())
+	if principal == nil || !principal.IsImpersonated() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "目前不是代理登入"})
+		return
+	}
+
+	svc := services.NewImpersonationService(db.WithContext(c.Request.Context()))
+	if err := svc.End(principal.SessionID, principal.ActorID); err != nil {
+		if errors.Is(err, services.ErrImpersonationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已結束代理登入"})
+}
+
+// currentImpersonation 返回代理登入資訊，不是代理登入時為 nil
+func currentImpersonation(c *gin.Context) *ImpersonationInfo {
+	principal := auth.PrincipalFromContext(c.Request.Context())
+	if principal == nil || !principal.IsImpersonated() {
+		return nil
+	}
+	return &ImpersonationInfo{
+		ActorID:    principal.ActorID,
+		ActorEmail: principal.ActorEmail,
+		SessionID:  principal.SessionID,
+	}
+}
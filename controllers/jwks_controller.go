@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"net/http"
+
+	"member_API/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS publishes the public signing keys so other services can verify member tokens.
+// @Summary JWT 公鑰集合
+// @Description 返回目前所有有效的 JWT 簽章公鑰（RFC 7517 JWKS），token header 的 kid 對應其中一把金鑰
+// @Tags 認證
+// @Produce json
+// @Success 200 {object} auth.JWKS "公鑰集合"
+// @Router /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, auth.CurrentKeySet().JWKS())
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxImportBodyBytes 匯入檔案的大小上限
+	maxImportBodyBytes = 20 << 20
+	// maxImportRows 透過 API 匯入時的資料列上限，更大的檔案請使用命令列匯入
+	maxImportRows = 10000
+)
+
+// ImportMembers imports members from a CSV or NDJSON request body (admin).
+// @Summary 批次匯入會員
+// @Description 從請求內容匯入會員，format 未指定時依 Content-Type 判斷（text/csv 或 application/x-ndjson）。
+// @Description CSV 第一列為標題列，必須包含 name 與 email，可加上 password、password_hash（argon2id 或 bcrypt 雜湊）、email_verified、phone、birthday、avatar_url、locale、timezone；
+// @Description NDJSON 每行一個相同欄位的 JSON 物件。匯出檔的 id、status、created_at 欄位會被忽略，可直接匯入。
+// @Description 格式錯誤的資料列會在 errors 中列出並略過；on_duplicate 決定 email 已存在時略過、以檔案內容更新或中止整個匯入。
+// @Description dry_run=true 時只驗證不寫入。新會員不會收到驗證郵件，最多 10000 筆，需要 member:write 權限
+// @Tags 用戶
+// @Accept plain
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "檔案格式" Enums(csv, ndjson)
+// @Param on_duplicate query string false "email 已存在時的處理方式（預設 fail）" Enums(skip, update, fail)
+// @Param dry_run query bool false "只驗證不寫入"
+// @Param request body string true "CSV 或 NDJSON 內容"
+// @Success 200 {object} map[string]services.ImportResult "匯入完成"
+// @Failure 400 {object} map[string]string "請求參數或標題列錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 409 {object} map[string]services.ImportResult "email 已存在，匯入中止，所有變更已復原"
+// @Failure 413 {object} map[string]string "檔案過大或筆數超過上限"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/import [post]
+func ImportMembers(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = bulkFormatFromContentType(c.ContentType())
+	}
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		if value != "true" && value != "false" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run 必須為 true 或 false"})
+			return
+		}
+		dryRun = value == "true"
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+	svc := services.NewMemberBulkService(db.WithContext(c.Request.Context()))
+	result, err := svc.Import(body, services.ImportOptions{
+		Format:      format,
+		OnDuplicate: c.Query("on_duplicate"),
+		DryRun:      dryRun,
+		MaxRows:     maxImportRows,
+		ActorID:     currentUserID(c),
+	})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("檔案不可超過 %d MB", maxImportBodyBytes>>20)})
+		case errors.Is(err, services.ErrTooManyImportRows):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUnsupportedBulkFormat),
+			errors.Is(err, services.ErrInvalidDuplicateStrategy),
+			errors.Is(err, services.ErrInvalidImportHeader):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if result.Aborted {
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"result": result})
+}
+
+// ExportMembers streams all non-deleted members as CSV or NDJSON (admin).
+// @Summary 批次匯出會員
+// @Description 以串流方式匯出所有未刪除的會員（不含服務帳號），欄位為 id、name、email、email_verified、status、phone、birthday、avatar_url、locale、timezone、created_at，
+// @Description 格式與匯入相同；不包含密碼雜湊。需要 member:export 權限（只限管理員），操作會寫入審計紀錄
+// @Tags 用戶
+// @Produce plain
+// @Security BearerAuth
+// @Param format query string false "檔案格式（預設 csv）" Enums(csv, ndjson)
+// @Success 200 {file} file "匯出檔"
+// @Failure 400 {object} map[string]string "不支援的格式"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/export [get]
+func ExportMembers(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	format := c.DefaultQuery("format", services.BulkFormatCSV)
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case services.BulkFormatCSV:
+	case services.BulkFormatNDJSON:
+		contentType = "application/x-ndjson; charset=utf-8"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrUnsupportedBulkFormat.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("members-%s.%s", time.Now().UTC().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// 回應已開始傳送，中途失敗只能中斷連線並記錄日誌
+	svc := services.NewMemberBulkService(db.WithContext(c.Request.Context()))
+	count, err := svc.Export(c.Writer, services.ExportOptions{Format: format})
+	if err != nil {
+		log.Printf("[Member] exporting members failed after %d rows: %v\n", count, err)
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	svc.RecordExport(currentUserID(c), format, count)
+}
+
+// bulkFormatFromContentType 依 Content-Type 判斷匯入格式，無法判斷時返回空字串
+func bulkFormatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "text/csv":
+		return services.BulkFormatCSV
+	case "application/x-ndjson", "application/jsonl":
+		return services.BulkFormatNDJSON
+	default:
+		return ""
+	}
+}
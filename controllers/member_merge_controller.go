@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MergeMemberRequest 合併的目標會員與原因，原因會寫入合併紀錄與審計紀錄
+type MergeMemberRequest struct {
+	TargetID uint   `json:"target_id" binding:"required" example:"2"`
+	Reason   string `json:"reason" binding:"required" example:"同一位會員以 Gmail 別名重複註冊"`
+}
+
+// MemberMergeResponse 合併紀錄；舊的會員 ID 可依此轉址至合併後的會員
+type MemberMergeResponse struct {
+	SourceMemberID uint      `json:"source_member_id" example:"5"`
+	TargetMemberID uint      `json:"target_member_id" example:"2"`
+	SourceName     string    `json:"source_name" example:"張三"`
+	SourceEmail    string    `json:"source_email" example:"zhang.san+shop@gmail.com"`
+	MergedBy       uint      `json:"merged_by" example:"1"`
+	Reason         string    `json:"reason" example:"同一位會員以 Gmail 別名重複註冊"`
+	Moved          string    `json:"moved" example:"sessions=2 addresses=1"`
+	MergedAt       time.Time `json:"merged_at"`
+}
+
+// GetDuplicateMembers lists groups of members that are likely the same person (admin).
+// @Summary 列出可能重複的會員
+// @Description 比對所有未刪除的會員，列出可能為同一人的會員組：email 正規化後相同（不分大小寫、忽略 + 別名與 Gmail 帳號中的點），
+// @Description 或姓名相近（不分大小寫、標點與姓名順序）且手機號碼或 email @ 之前的部分相同。需要 member:read 權限
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "每頁組數（預設 50，最多 200）"
+// @Param offset query int false "略過組數"
+// @Success 200 {object} map[string]interface{} "獲取成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/duplicates [get]
+func GetDuplicateMembers(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	limit, offset, ok := listPagination(c)
+	if !ok {
+		return
+	}
+
+	svc := services.NewMemberMergeService(db.WithContext(c.Request.Context()))
+	groups, total, err := svc.FindDuplicates(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"groups": groups,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GetMemberDuplicates lists the members that are likely the same person as a member (admin).
+// @Summary 查詢會員可能的重複帳號
+// @Description 返回與指定會員可能為同一人的會員組（包含該會員本身），沒有重複時 group 為 null，比對規則同列出可能重複的會員。需要 member:read 權限
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Success 200 {object} map[string]services.DuplicateGroup "獲取成功"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/duplicates [get]
+func GetMemberDuplicates(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	svc := services.NewMemberMergeService(db.WithContext(c.Request.Context()))
+	group, err := svc.FindDuplicatesOf(uint(memberID))
+	if err != nil {
+		if err.Error() == "會員不存在" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group": group})
+}
+
+// MergeMember merges a duplicate member into another member (admin).
+// @Summary 合併重複的會員
+// @Description 在同一個 transaction 中將路徑中的來源會員合併至 target_id：外部帳號連結、API key、地址、登入與 OAuth 授權紀錄及審計紀錄移轉至目標會員，來源會員建立或最後修改的資料（會員、產品、群組等）改記在目標會員名下，角色與群組取聯集，點數餘額轉入目標會員，
+// @Description 目標會員未填的個人檔案欄位以來源補上。來源會員的登入與授權全部撤銷，帳號關閉並刪除，保留合併紀錄；之後以來源會員 ID 查詢會返回 merged_into。
+// @Description 無法還原，不可合併自己或服務帳號，需要 member:merge 權限
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "來源會員 ID" example(5)
+// @Param request body MergeMemberRequest true "目標會員與原因"
+// @Success 200 {object} map[string]MemberMergeResponse "合併成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足或不可合併此會員"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/merge [post]
+func MergeMember(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	sourceID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req MergeMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewMemberMergeService(db.WithContext(c.Request.Context()))
+	merge, err := svc.Merge(uint(sourceID), req.TargetID, currentUserID(c), req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMergeReasonRequired), errors.Is(err, services.ErrMergeSameMember):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrMergeNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"merge": MemberMergeResponse{
+		SourceMemberID: merge.SourceMemberID,
+		TargetMemberID: merge.TargetMemberID,
+		SourceName:     merge.SourceName,
+		SourceEmail:    merge.SourceEmail,
+		MergedBy:       merge.MergedBy,
+		Reason:         merge.Reason,
+		Moved:          merge.Moved,
+		MergedAt:       merge.CreationTime,
+	}})
+}
@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangeMemberStatusRequest 變更帳號狀態；停權（suspended）需指定 suspended_until，active 以外的狀態需說明原因
+type ChangeMemberStatusRequest struct {
+	Status         string     `json:"status" binding:"required,oneof=pending active suspended banned closed" example:"suspended"`
+	Reason         string     `json:"reason" example:"違反社群規範"`
+	SuspendedUntil *time.Time `json:"suspended_until" example:"2026-12-31T00:00:00Z"`
+}
+
+// MemberStatusResponse 會員目前的帳號狀態；status 為考慮停權期限後的實際狀態
+type MemberStatusResponse struct {
+	MemberID       uint       `json:"member_id" example:"1"`
+	Status         string     `json:"status" example:"suspended"`
+	Reason         string     `json:"reason" example:"違反社群規範"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+}
+
+// MemberStatusChangeResponse 一筆帳號狀態變更紀錄
+type MemberStatusChangeResponse struct {
+	ID             uint       `json:"id" example:"1"`
+	FromStatus     string     `json:"from_status" example:"active"`
+	ToStatus       string     `json:"to_status" example:"suspended"`
+	Reason         string     `json:"reason" example:"違反社群規範"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	ActorID        uint       `json:"actor_id" example:"1"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ChangeMemberStatus changes the lifecycle status of a member.
+// @Summary 變更會員帳號狀態
+// @Description 將會員設為 pending、active、suspended（至指定時間）、banned 或 closed，需要 member:write 權限。
+// @Description 變更為 active 以外的狀態時會撤銷該會員所有登入與 OAuth 授權；API key 在恢復前無法使用。每次變更都會寫入狀態歷史與審計紀錄
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Param request body ChangeMemberStatusRequest true "新狀態"
+// @Success 200 {object} map[string]MemberStatusResponse "變更成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 409 {object} map[string]string "不允許的狀態變更"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/status [put]
+func ChangeMemberStatus(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req ChangeMemberStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewMemberStatusService(db.WithContext(c.Request.Context()))
+	member, err := svc.ChangeStatus(uint(memberID), services.MemberStatusInput{
+		Status:         req.Status,
+		Reason:         req.Reason,
+		SuspendedUntil: req.SuspendedUntil,
+	}, currentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidMemberStatus),
+			errors.Is(err, services.ErrStatusReasonRequired),
+			errors.Is(err, services.ErrInvalidSuspendedUntil):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": toMemberStatusResponse(member)})
+}
+
+// GetMemberStatusHistory lists the status changes of a member.
+// @Summary 會員帳號狀態歷史
+// @Description 列出會員每次帳號狀態變更的時間、操作者與原因，新的在前，需要 member:read 權限
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Success 200 {object} map[string]interface{} "獲取成功"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/status [get]
+func GetMemberStatusHistory(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	member, err := services.NewMemberService(db.WithContext(ctx)).GetMemberByID(uint(memberID))
+	if err != nil {
+		if err.Error() == "會員不存在" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	changes, err := services.NewMemberStatusService(db.WithContext(ctx)).History(member.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	history := make([]MemberStatusChangeResponse, len(changes))
+	for i, change := range changes {
+		history[i] = MemberStatusChangeResponse{
+			ID:             change.ID,
+			FromStatus:     change.FromStatus,
+			ToStatus:       change.ToStatus,
+			Reason:         change.Reason,
+			SuspendedUntil: change.SuspendedUntil,
+			ActorID:        change.ActorID,
+			CreatedAt:      change.CreationTime,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"status": toMemberStatusResponse(member), "history": history})
+}
+
+func toMemberStatusResponse(member *models.Member) MemberStatusResponse {
+	status := services.EffectiveMemberStatus(member, time.Now())
+	resp := MemberStatusResponse{MemberID: member.ID, Status: status, Reason: member.StatusReason}
+	if status == models.MemberStatusSuspended {
+		resp.SuspendedUntil = member.SuspendedUntil
+	}
+	return resp
+}
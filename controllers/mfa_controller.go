@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"member_API/auth"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"eyJhbGciOiJFZERTQSIsImtpZCI6..."`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required" example:"password123"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+type TOTPSetupResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/Member%20API:user@example.com?secret=..."`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"ABCD-EFGH-IJKL-MNOP"`
+}
+
+// LoginMFA 兩步驟登入第二步
+// @Summary 兩步驟登入第二步
+// @Description 以登入時取得的 mfa_token 與 TOTP 驗證碼（或備用碼）換取 access token 與 refresh token
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param request body MFALoginRequest true "challenge 與驗證碼"
+// @Param X-Device-Name header string false "裝置名稱，顯示在登入裝置列表"
+// @Success 200 {object} AuthResponse "登入成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "challenge 無效或驗證碼錯誤"
+// @Failure 403 {object} map[string]string "帳號已停權、封鎖或關閉"
+// @Failure 429 {object} map[string]string "登入失敗次數過多，已暫時限制或鎖定"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /login/mfa [post]
+func LoginMFA(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := auth.ValidateMFAChallenge(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 驗證碼錯誤與密碼錯誤共用同一組失敗計數，避免以 challenge 暴力嘗試驗證碼
+	if !checkLoginGuard(c, claims.Email) {
+		return
+	}
+
+	svc := services.NewMFAService(db.WithContext(c.Request.Context()))
+	member, err := svc.VerifyLogin(uint(claims.UserID), req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) {
+			recordLoginFailure(c, claims.Email, uint(claims.UserID), services.AuditMFAFailed, "invalid_code")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrMFANotEnabled) || err.Error() == "會員不存在" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.CheckMemberStatus(member); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	clearLoginFailures(c, member.Email)
+	respondWithTokens(c, http.StatusOK, member)
+}
+
+// SetupTOTP 產生 TOTP 密鑰
+// @Summary 產生 TOTP 密鑰
+// @Description 為目前登入的會員產生新的 TOTP 密鑰與 otpauth provisioning URI（可轉為 QR code），需再呼叫啟用端點確認
+// @Tags 兩步驟驗證
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} TOTPSetupResponse "已產生"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 409 {object} map[string]string "已啟用兩步驟驗證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /mfa/totp/setup [post]
+func SetupTOTP(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewMFAService(db.WithContext(c.Request.Context()))
+	secret, uri, err := svc.SetupTOTP(currentUserID(c))
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TOTPSetupResponse{Secret: secret, ProvisioningURI: uri})
+}
+
+// EnableTOTP 啟用兩步驟驗證
+// @Summary 啟用兩步驟驗證
+// @Description 以驗證器 App 產生的驗證碼確認密鑰並啟用兩步驟驗證，返回只顯示一次的備用碼
+// @Tags 兩步驟驗證
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MFACodeRequest true "驗證碼"
+// @Success 200 {object} RecoveryCodesResponse "已啟用"
+// @Failure 400 {object} map[string]string "請求參數錯誤或驗證碼錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 409 {object} map[string]string "已啟用兩步驟驗證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /mfa/totp/enable [post]
+func EnableTOTP(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewMFAService(db.WithContext(c.Request.Context()))
+	codes, err := svc.EnableTOTP(currentUserID(c), req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP 停用兩步驟驗證
+// @Summary 停用兩步驟驗證
+// @Description 需提供目前密碼與有效的驗證碼或備用碼，停用後所有備用碼失效
+// @Tags 兩步驟驗證
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body DisableTOTPRequest true "密碼與驗證碼"
+// @Success 200 {object} map[string]string "已停用"
+// @Failure 400 {object} map[string]string "請求參數錯誤、密碼或驗證碼錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 409 {object} map[string]string "尚未啟用兩步驟驗證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /mfa/totp/disable [post]
+func DisableTOTP(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewMFAService(db.WithContext(c.Request.Context()))
+	if err := svc.DisableTOTP(currentUserID(c), req.Password, req.Code); err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已停用兩步驟驗證"})
+}
+
+// RegenerateRecoveryCodes 換發備用碼
+// @Summary 換發備用碼
+// @Description 以有效的驗證碼換發一組新的備用碼，舊的備用碼全部失效
+// @Tags 兩步驟驗證
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MFACodeRequest true "驗證碼"
+// @Success 200 {object} RecoveryCodesResponse "已換發"
+// @Failure 400 {object} map[string]string "請求參數錯誤或驗證碼錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 409 {object} map[string]string "尚未啟用兩步驟驗證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /mfa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewMFAService(db.WithContext(c.Request.Context()))
+	codes, err := svc.RegenerateRecoveryCodes(currentUserID(c), req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// respondMFAError 將兩步驟驗證相關錯誤轉為對應的 HTTP 狀態碼
+func respondMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode),
+		errors.Is(err, services.ErrInvalidPassword),
+		errors.Is(err, services.ErrMFASetupRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMFAAlreadyEnabled),
+		errors.Is(err, services.ErrMFANotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err.Error() == "會員不存在":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
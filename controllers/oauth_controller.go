@@ -0,0 +1,724 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"member_API/auth"
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthAuthorizeRequest 授權請求的參數（RFC 6749 §4.1.1、RFC 7636 §4.3）
+type OAuthAuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type" example:"code"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required" example:"q3ZkP0y9..."`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri" example:"https://app.example.com/callback"`
+	Scope               string `form:"scope" json:"scope" example:"profile email offline_access"`
+	State               string `form:"state" json:"state" example:"xyz"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge" example:"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method" example:"S256"`
+}
+
+// OAuthConsentRequest 會員對授權請求的決定
+type OAuthConsentRequest struct {
+	OAuthAuthorizeRequest
+	Approve *bool `form:"approve" json:"approve" binding:"required" example:"true"`
+}
+
+// OAuthClientSummary 顯示在同意畫面上的應用程式資訊
+type OAuthClientSummary struct {
+	ClientID string `json:"client_id" example:"q3ZkP0y9..."`
+	Name     string `json:"name" example:"Example App"`
+}
+
+// OAuthAuthorizeResponse 授權請求的結果：consent_required 為 true 時需顯示同意畫面並以 POST 送出決定，
+// 否則前端應將瀏覽器導向 redirect_to
+type OAuthAuthorizeResponse struct {
+	RedirectTo      string             `json:"redirect_to,omitempty" example:"https://app.example.com/callback?code=...&state=xyz"`
+	ConsentRequired bool               `json:"consent_required" example:"false"`
+	Client          OAuthClientSummary `json:"client"`
+	Scopes          []string           `json:"scopes" example:"profile,email"`
+}
+
+// OAuthTokenResponse token 端點的成功回應（RFC 6749 §5.1）
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJFZERTQSIs..."`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"3600"`
+	RefreshToken string `json:"refresh_token,omitempty" example:"Zr7uG1Xq..."`
+	Scope        string `json:"scope" example:"profile email"`
+}
+
+// OAuthErrorResponse OAuth 端點的錯誤回應（RFC 6749 §5.2）
+type OAuthErrorResponse struct {
+	Error            string `json:"error" example:"invalid_grant"`
+	ErrorDescription string `json:"error_description,omitempty" example:"授權碼已過期"`
+}
+
+// OAuthIntrospectionResponse token 狀態（RFC 7662 §2.2）
+type OAuthIntrospectionResponse struct {
+	Active    bool     `json:"active" example:"true"`
+	Scope     string   `json:"scope,omitempty" example:"profile email"`
+	ClientID  string   `json:"client_id,omitempty" example:"q3ZkP0y9..."`
+	Username  string   `json:"username,omitempty" example:"user@example.com"`
+	TokenType string   `json:"token_type,omitempty" example:"access_token"`
+	Exp       int64    `json:"exp,omitempty" example:"1767225600"`
+	Iat       int64    `json:"iat,omitempty" example:"1767222000"`
+	Sub       string   `json:"sub,omitempty" example:"42"`
+	Aud       []string `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty" example:"member-api"`
+	Jti       string   `json:"jti,omitempty"`
+}
+
+// OAuthUserInfoResponse 依 scope 返回的會員資料
+type OAuthUserInfoResponse struct {
+	Sub           string `json:"sub" example:"42"`
+	Name          string `json:"name,omitempty" example:"張三"`
+	Email         string `json:"email,omitempty" example:"user@example.com"`
+	EmailVerified *bool  `json:"email_verified,omitempty" example:"true"`
+	Picture       string `json:"picture,omitempty" example:"https://cdn.example.com/avatars/1.png"`
+	Birthdate     string `json:"birthdate,omitempty" example:"1990-05-20"`
+	Locale        string `json:"locale,omitempty" example:"zh-TW"`
+	Zoneinfo      string `json:"zoneinfo,omitempty" example:"Asia/Taipei"`
+}
+
+// OAuthAuthorizedAppResponse 會員已授權的應用程式
+type OAuthAuthorizedAppResponse struct {
+	ClientID  string    `json:"client_id" example:"q3ZkP0y9..."`
+	Name      string    `json:"name" example:"Example App"`
+	Scopes    []string  `json:"scopes" example:"profile,email"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+// CreateOAuthClientRequest 登記 OAuth 用戶端的參數
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100" example:"Example App"`
+	RedirectURIs []string `json:"redirect_uris" example:"https://app.example.com/callback"`
+	GrantTypes   []string `json:"grant_types" example:"authorization_code,refresh_token"`
+	Scopes       []string `json:"scopes" binding:"required,min=1" example:"profile,email,offline_access"`
+	Confidential bool     `json:"confidential" example:"true"`
+	FirstParty   bool     `json:"first_party" example:"false"`
+}
+
+// OAuthClientResponse OAuth 用戶端資訊，不含 client secret
+type OAuthClientResponse struct {
+	ClientID     string    `json:"client_id" example:"q3ZkP0y9..."`
+	Name         string    `json:"name" example:"Example App"`
+	RedirectURIs []string  `json:"redirect_uris" example:"https://app.example.com/callback"`
+	GrantTypes   []string  `json:"grant_types" example:"authorization_code,refresh_token"`
+	Scopes       []string  `json:"scopes" example:"profile,email,offline_access"`
+	Confidential bool      `json:"confidential" example:"true"`
+	FirstParty   bool      `json:"first_party" example:"false"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CreatedOAuthClientResponse 新登記的用戶端；client_secret 只會在此回應中出現一次，公開用戶端沒有 secret
+type CreatedOAuthClientResponse struct {
+	ClientSecret string              `json:"client_secret,omitempty" example:"Jx8c2L..."`
+	Client       OAuthClientResponse `json:"client"`
+}
+
+// GetOAuthAuthorize 處理授權請求（需要認證）
+// @Summary 授權第三方應用程式
+// @Description 前端授權頁以已登入會員的 token 轉送用戶端的授權請求（authorization code，公開用戶端必須使用 PKCE S256）。
+// @Description 第一方應用程式或會員先前已同意所有 scope 時直接返回帶授權碼的 redirect_to；否則返回 consent_required，
+// @Description 前端顯示同意畫面後以 POST 送出決定。client_id 或 redirect_uri 無效時返回 400，不可導回用戶端
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client_id query string true "用戶端 ID"
+// @Param response_type query string true "固定為 code"
+// @Param redirect_uri query string false "登記過的導回網址，只登記一個時可省略"
+// @Param scope query string false "以空白分隔的 scope，省略時為用戶端登記的全部 scope"
+// @Param state query string false "用戶端的 state，原樣附在導回網址"
+// @Param code_challenge query string false "PKCE code challenge"
+// @Param code_challenge_method query string false "固定為 S256"
+// @Success 200 {object} OAuthAuthorizeResponse "導回網址或需要會員同意"
+// @Failure 400 {object} map[string]string "用戶端不存在或 redirect_uri 無效"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "不接受 API key 或第三方應用程式的 token"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /oauth/authorize [get]
+func GetOAuthAuthorize(c *gin.Context) {
+	var req OAuthAuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	authorizeOAuth(c, req, nil)
+}
+
+// PostOAuthAuthorize 送出會員對授權請求的決定（需要認證）
+// @Summary 同意或拒絕授權
+// @Description 會員在同意畫面上同意（approve=true）或拒絕授權；同意的 scope 會被記錄，之後相同的請求不再詢問。
+// @Description 返回帶授權碼或 access_denied 錯誤的 redirect_to
+// @Tags OAuth
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Security BearerAuth
+// @Param request body OAuthConsentRequest true "原授權請求參數與會員的決定"
+// @Success 200 {object} OAuthAuthorizeResponse "導回網址"
+// @Failure 400 {object} map[string]string "用戶端不存在或 redirect_uri 無效"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "不接受 API key 或第三方應用程式的 token"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /oauth/authorize [post]
+func PostOAuthAuthorize(c *gin.Context) {
+	var req OAuthConsentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	authorizeOAuth(c, req.OAuthAuthorizeRequest, req.Approve)
+}
+
+// OAuthToken 簽發 token
+// @Summary 取得 token
+// @Description OAuth 2.0 token 端點，支援 authorization_code（含 PKCE）、refresh_token（每次輪換）與 client_credentials。
+// @Description 機密用戶端以 HTTP Basic 或 client_id/client_secret 表單欄位驗證；公開用戶端只提供 client_id
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code、refresh_token 或 client_credentials"
+// @Param code formData string false "授權碼"
+// @Param redirect_uri formData string false "授權請求帶了 redirect_uri 時必填，且須與其相同"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "refresh token"
+// @Param scope formData string false "以空白分隔的 scope，不可超出原授權"
+// @Param client_id formData string false "用戶端 ID（未使用 HTTP Basic 時）"
+// @Param client_secret formData string false "client secret（未使用 HTTP Basic 時）"
+// @Success 200 {object} OAuthTokenResponse "簽發成功"
+// @Failure 400 {object} OAuthErrorResponse "請求錯誤或授權無效"
+// @Failure 401 {object} OAuthErrorResponse "用戶端驗證失敗"
+// @Failure 500 {object} OAuthErrorResponse "服務器錯誤"
+// @Router /oauth/token [post]
+func OAuthToken(c *gin.Context) {
+	client, basic, ok := authenticateOAuthClient(c)
+	if !ok {
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	tokens, err := svc.Token(c.Request.Context(), client, services.TokenRequest{
+		GrantType:    c.PostForm("grant_type"),
+		Code:         c.PostForm("code"),
+		RedirectURI:  c.PostForm("redirect_uri"),
+		CodeVerifier: c.PostForm("code_verifier"),
+		RefreshToken: c.PostForm("refresh_token"),
+		Scope:        c.PostForm("scope"),
+	})
+	if err != nil {
+		respondOAuthError(c, err, basic)
+		return
+	}
+
+	c.JSON(http.StatusOK, OAuthTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+		Scope:        strings.Join(tokens.Scopes, " "),
+	})
+}
+
+// OAuthIntrospect 查詢 token 狀態
+// @Summary 查詢 token 狀態
+// @Description RFC 7662 token introspection，只接受機密用戶端。第一方用戶端可查詢任何 OAuth token，
+// @Description 其他用戶端只能查詢簽發給自己的 token；無效、過期、已撤銷或無權查詢的 token 一律返回 active=false
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "access token 或 refresh token"
+// @Param token_type_hint formData string false "access_token 或 refresh_token"
+// @Param client_id formData string false "用戶端 ID（未使用 HTTP Basic 時）"
+// @Param client_secret formData string false "client secret（未使用 HTTP Basic 時）"
+// @Success 200 {object} OAuthIntrospectionResponse "token 狀態"
+// @Failure 400 {object} OAuthErrorResponse "缺少 token"
+// @Failure 401 {object} OAuthErrorResponse "用戶端驗證失敗"
+// @Failure 500 {object} OAuthErrorResponse "服務器錯誤"
+// @Router /oauth/introspect [post]
+func OAuthIntrospect(c *gin.Context) {
+	client, basic, ok := authenticateOAuthClient(c)
+	if !ok {
+		return
+	}
+	if !client.Confidential {
+		respondOAuthError(c, &services.OAuthError{Code: services.OAuthErrInvalidClient, Description: "只有機密用戶端可查詢 token"}, basic)
+		return
+	}
+	token := c.PostForm("token")
+	if token == "" {
+		respondOAuthError(c, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "缺少 token"}, basic)
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	result, err := svc.Introspect(c.Request.Context(), client, token, c.PostForm("token_type_hint"))
+	if err != nil {
+		respondOAuthError(c, err, basic)
+		return
+	}
+
+	resp := OAuthIntrospectionResponse{Active: result.Active}
+	if result.Active {
+		resp.Scope = result.Scope
+		resp.ClientID = result.ClientID
+		resp.Username = result.Username
+		resp.TokenType = result.TokenType
+		resp.Exp = result.ExpiresAt.Unix()
+		resp.Iat = result.IssuedAt.Unix()
+		resp.Sub = result.Subject
+		resp.Aud = result.Audience
+		resp.Iss = "member-api"
+		resp.Jti = result.TokenID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// OAuthRevoke 撤銷 token
+// @Summary 撤銷 token
+// @Description RFC 7009 token revocation。撤銷 access token 或 refresh token 時，同一授權下的所有 token 一併失效；
+// @Description token 無效或不屬於呼叫的用戶端時同樣返回 200
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "access token 或 refresh token"
+// @Param token_type_hint formData string false "access_token 或 refresh_token"
+// @Param client_id formData string false "用戶端 ID（未使用 HTTP Basic 時）"
+// @Param client_secret formData string false "client secret（未使用 HTTP Basic 時）"
+// @Success 200 "已處理"
+// @Failure 400 {object} OAuthErrorResponse "缺少 token"
+// @Failure 401 {object} OAuthErrorResponse "用戶端驗證失敗"
+// @Failure 500 {object} OAuthErrorResponse "服務器錯誤"
+// @Router /oauth/revoke [post]
+func OAuthRevoke(c *gin.Context) {
+	client, basic, ok := authenticateOAuthClient(c)
+	if !ok {
+		return
+	}
+	token := c.PostForm("token")
+	if token == "" {
+		respondOAuthError(c, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "缺少 token"}, basic)
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	if err := svc.Revoke(c.Request.Context(), client, token, c.PostForm("token_type_hint")); err != nil {
+		respondOAuthError(c, err, basic)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// OAuthUserInfo 返回 token 所代表會員的資料（需要認證）
+// @Summary 取得授權會員資料
+// @Description 以 OAuth access token 取得會員資料：sub 一律返回，name、picture、birthdate、locale、zoneinfo 需要 profile scope，email 與 email_verified 需要 email scope
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} OAuthUserInfoResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "token 沒有代表的會員"
+// @Failure 404 {object} map[string]string "用戶不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /oauth/userinfo [get]
+func OAuthUserInfo(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+	principal := auth.PrincipalFromContext(c.Request.Context())
+	if principal == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證"})
+		return
+	}
+	if principal.UserID == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "此 token 沒有代表的會員"})
+		return
+	}
+
+	member, err := services.NewMemberService(db.WithContext(c.Request.Context())).GetMemberByID(principal.UserID)
+	if err != nil {
+		if err.Error() == "會員不存在" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "用戶不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := OAuthUserInfoResponse{Sub: strconv.FormatUint(uint64(principal.UserID), 10)}
+	if principal.HasScope(auth.ScopeProfile) {
+		profile := toMemberProfileResponse(member)
+		resp.Name = member.Name
+		resp.Picture = profile.AvatarURL
+		resp.Birthdate = profile.Birthday
+		resp.Locale = profile.Locale
+		resp.Zoneinfo = profile.Timezone
+	}
+	if principal.HasScope(auth.ScopeEmail) {
+		verified := member.EmailVerifiedAt != nil
+		resp.Email = member.Email
+		resp.EmailVerified = &verified
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetOAuthConsents 列出當前用戶已授權的應用程式
+// @Summary 列出已授權的應用程式
+// @Description 列出當前用戶同意過的第三方應用程式與其 scope
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]OAuthAuthorizedAppResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /oauth/consents [get]
+func GetOAuthConsents(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	apps, err := svc.ListConsents(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]OAuthAuthorizedAppResponse, 0, len(apps))
+	for _, app := range apps {
+		out = append(out, OAuthAuthorizedAppResponse{
+			ClientID:  app.ClientID,
+			Name:      app.Name,
+			Scopes:    app.Scopes,
+			GrantedAt: app.GrantedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"applications": out})
+}
+
+// RevokeOAuthConsent 取消對應用程式的授權
+// @Summary 取消應用程式授權
+// @Description 取消當前用戶對應用程式的授權，已簽發給該應用程式的 access token 與 refresh token 立即失效
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client_id path string true "用戶端 ID"
+// @Success 200 {object} map[string]string "已取消授權"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "尚未授權此應用程式"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /oauth/consents/{client_id} [delete]
+func RevokeOAuthConsent(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	if err := svc.RevokeConsent(currentUserID(c), c.Param("client_id")); err != nil {
+		if errors.Is(err, services.ErrOAuthConsentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已取消授權"})
+}
+
+// GetOAuthClients 列出 OAuth 用戶端（管理員）
+// @Summary 列出 OAuth 用戶端
+// @Description 列出所有已登記的 OAuth 用戶端，需要 oauth:manage 權限
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]OAuthClientResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/oauth/clients [get]
+func GetOAuthClients(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	clients, err := svc.ListClients()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]OAuthClientResponse, 0, len(clients))
+	for _, client := range clients {
+		out = append(out, toOAuthClientResponse(client))
+	}
+	c.JSON(http.StatusOK, gin.H{"clients": out})
+}
+
+// CreateOAuthClient 登記 OAuth 用戶端（管理員）
+// @Summary 登記 OAuth 用戶端
+// @Description 登記第一方或第三方應用程式。機密用戶端會取得只顯示一次的 client_secret；公開用戶端（SPA、行動 App）沒有 secret，必須使用 PKCE。
+// @Description grant_types 預設為 authorization_code；scope 可為 profile、email、offline_access 或權限名稱，需要 oauth:manage 權限
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateOAuthClientRequest true "用戶端設定"
+// @Success 201 {object} CreatedOAuthClientResponse "登記成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤或設定無效"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/oauth/clients [post]
+func CreateOAuthClient(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	var req CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	client, secret, err := svc.RegisterClient(services.OAuthClientInput{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		GrantTypes:   req.GrantTypes,
+		Scopes:       req.Scopes,
+		Confidential: req.Confidential,
+		FirstParty:   req.FirstParty,
+	}, currentUserID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrOAuthClientInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatedOAuthClientResponse{ClientSecret: secret, Client: toOAuthClientResponse(*client)})
+}
+
+// GetOAuthClient 取得 OAuth 用戶端（管理員）
+// @Summary 取得 OAuth 用戶端
+// @Description 取得指定 OAuth 用戶端的設定，需要 oauth:manage 權限
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client_id path string true "用戶端 ID"
+// @Success 200 {object} map[string]OAuthClientResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "OAuth 用戶端不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/oauth/clients/{client_id} [get]
+func GetOAuthClient(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	client, err := svc.GetClient(c.Param("client_id"))
+	if err != nil {
+		respondOAuthClientError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"client": toOAuthClientResponse(*client)})
+}
+
+// RotateOAuthClientSecret 重新產生 client secret（管理員）
+// @Summary 重新產生 client secret
+// @Description 為機密用戶端產生新的 client_secret，舊 secret 立即失效；新 secret 只會顯示一次，需要 oauth:manage 權限
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client_id path string true "用戶端 ID"
+// @Success 200 {object} map[string]string "新的 client_secret"
+// @Failure 400 {object} map[string]string "公開用戶端沒有 client secret"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "OAuth 用戶端不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/oauth/clients/{client_id}/secret [post]
+func RotateOAuthClientSecret(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	secret, err := svc.RotateClientSecret(c.Param("client_id"), currentUserID(c))
+	if err != nil {
+		respondOAuthClientError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"client_secret": secret})
+}
+
+// DeleteOAuthClient 刪除 OAuth 用戶端（管理員）
+// @Summary 刪除 OAuth 用戶端
+// @Description 刪除 OAuth 用戶端，並撤銷所有會員對它的授權與已簽發的 token，需要 oauth:manage 權限
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client_id path string true "用戶端 ID"
+// @Success 200 {object} map[string]string "已刪除"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "OAuth 用戶端不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/oauth/clients/{client_id} [delete]
+func DeleteOAuthClient(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	if err := svc.DeleteClient(c.Param("client_id"), currentUserID(c)); err != nil {
+		respondOAuthClientError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "OAuth client deleted"})
+}
+
+// authorizeOAuth 處理授權請求並返回導回網址或同意畫面所需的資訊
+func authorizeOAuth(c *gin.Context, req OAuthAuthorizeRequest, approve *bool) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	result, err := svc.Authorize(currentUserID(c), services.AuthorizeRequest{
+		ResponseType:        req.ResponseType,
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	}, approve)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOAuthClientNotFound), errors.Is(err, services.ErrInvalidRedirectURI):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "用戶不存在"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, OAuthAuthorizeResponse{
+		RedirectTo:      result.RedirectTo,
+		ConsentRequired: result.ConsentRequired,
+		Client:          OAuthClientSummary{ClientID: result.Client.ClientID, Name: result.Client.Name},
+		Scopes:          result.Scopes,
+	})
+}
+
+// authenticateOAuthClient 以 HTTP Basic（client_secret_basic）或表單欄位（client_secret_post）驗證用戶端，
+// 失敗時寫入錯誤回應；basic 表示用戶端使用 HTTP Basic，錯誤時需帶 WWW-Authenticate
+func authenticateOAuthClient(c *gin.Context) (*models.OAuthClient, bool, bool) {
+	// token 端點的回應不可被快取（RFC 6749 §5.1）
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, OAuthErrorResponse{Error: "server_error", ErrorDescription: "數據庫連接未配置"})
+		return nil, false, false
+	}
+
+	clientID, secret, basic := c.Request.BasicAuth()
+	if basic {
+		// HTTP Basic 的帳號密碼需先以 application/x-www-form-urlencoded 編碼（RFC 6749 §2.3.1）
+		var err error
+		if clientID, err = url.QueryUnescape(clientID); err == nil {
+			secret, err = url.QueryUnescape(secret)
+		}
+		if err != nil || c.PostForm("client_secret") != "" {
+			respondOAuthError(c, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "用戶端憑證格式錯誤"}, basic)
+			return nil, basic, false
+		}
+	} else {
+		clientID = c.PostForm("client_id")
+		secret = c.PostForm("client_secret")
+	}
+
+	svc := services.NewOAuthService(db.WithContext(c.Request.Context()))
+	client, err := svc.AuthenticateClient(clientID, secret)
+	if err != nil {
+		respondOAuthError(c, err, basic)
+		return nil, basic, false
+	}
+	return client, basic, true
+}
+
+// respondOAuthError 依 RFC 6749 §5.2 回應 OAuth 錯誤
+func respondOAuthError(c *gin.Context, err error, basic bool) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, OAuthErrorResponse{Error: "server_error", ErrorDescription: err.Error()})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == services.OAuthErrInvalidClient {
+		status = http.StatusUnauthorized
+		if basic {
+			c.Header("WWW-Authenticate", `Basic realm="member-api"`)
+		}
+	}
+	c.JSON(status, OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+}
+
+func respondOAuthClientError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrOAuthClientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "OAuth client not found"})
+	case errors.Is(err, services.ErrOAuthClientInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func toOAuthClientResponse(client models.OAuthClient) OAuthClientResponse {
+	return OAuthClientResponse{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: strings.Fields(client.RedirectURIs),
+		GrantTypes:   strings.Fields(client.GrantTypes),
+		Scopes:       strings.Fields(client.Scopes),
+		Confidential: client.Confidential,
+		FirstParty:   client.FirstParty,
+		CreatedAt:    client.CreationTime,
+	}
+}
@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"member_API/auth"
+	"member_API/models"
+	"member_API/oidc"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcStateCookie 綁定授權請求與發起的瀏覽器，值為 state 的雜湊；callback 時需與 state 相符，
+// 避免他人將自己發起的登入或連結網址交給受害者完成（login / link CSRF）
+const oidcStateCookie = "oidc_state"
+
+// oidcCookiePath 涵蓋開始外部登入、連結與 callback 的路徑
+const oidcCookiePath = "/api/v1/auth/oidc"
+
+// OIDCAuthorizeResponse 導向外部登入提供者的網址；客戶端需保留 state，於 callback 時核對
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://accounts.google.com/o/oauth2/v2/auth?client_id=..."`
+	State            string `json:"state" example:"mF3k9Qz..."`
+}
+
+// ExternalIdentityResponse 會員已連結的外部帳號
+type ExternalIdentityResponse struct {
+	Provider    string    `json:"provider" example:"google"`
+	Email       string    `json:"email" example:"user@gmail.com"`
+	LinkedAt    time.Time `json:"linked_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// GetOIDCProviders 列出可用的外部登入提供者
+// @Summary 列出外部登入提供者
+// @Description 列出已設定、可用於登入或連結帳號的 OpenID Connect 提供者代號
+// @Tags 外部登入
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]string "獲取成功"
+// @Router /auth/oidc/providers [get]
+func GetOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": oidc.CurrentRegistry().Names()})
+}
+
+// OIDCAuthorize 開始外部登入
+// @Summary 開始外部登入
+// @Description 建立授權請求（authorization code + PKCE），返回導向提供者登入頁的網址；帶 redirect=true 時直接以 302 導向。
+// @Description 同時設定 HttpOnly 的 oidc_state cookie，callback 必須由同一個瀏覽器完成
+// @Tags 外部登入
+// @Accept json
+// @Produce json
+// @Param provider path string true "提供者代號，例如 google、line"
+// @Param redirect query bool false "是否直接導向提供者"
+// @Success 200 {object} OIDCAuthorizeResponse "授權網址"
+// @Success 302 "導向提供者登入頁"
+// @Failure 404 {object} map[string]string "不支援的登入提供者"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /auth/oidc/{provider}/authorize [get]
+func OIDCAuthorize(c *gin.Context) {
+	beginOIDCLogin(c, 0)
+}
+
+// LinkOIDCProvider 連結外部帳號（需要認證）
+// @Summary 連結外部帳號
+// @Description 為當前用戶建立連結外部帳號的授權請求，提供者導回 callback 後完成連結；
+// @Description 與開始外部登入相同會設定 oidc_state cookie，callback 必須由同一個瀏覽器完成
+// @Tags 外部登入
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "提供者代號，例如 google、line"
+// @Success 200 {object} OIDCAuthorizeResponse "授權網址"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "不支援的登入提供者"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /auth/oidc/{provider}/link [post]
+func LinkOIDCProvider(c *gin.Context) {
+	memberID := currentUserID(c)
+	if memberID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證"})
+		return
+	}
+	beginOIDCLogin(c, memberID)
+}
+
+// OIDCCallback 外部登入提供者導回
+// @Summary 完成外部登入
+// @Description 驗證 state 與開始登入時設定的 oidc_state cookie 相符，並以授權碼換取 ID token；已連結的外部帳號直接登入，
+// @Description 未連結時若提供者確認過 email 且同 email 的會員已驗證郵箱則連結該會員（未驗證則需先以密碼登入後自行連結），否則建立新會員。
+// @Description 會員已啟用兩步驟驗證時返回 MFAChallengeResponse；連結流程則只返回連結結果，不簽發 token
+// @Tags 外部登入
+// @Accept json
+// @Produce json
+// @Param provider path string true "提供者代號"
+// @Param code query string true "授權碼"
+// @Param state query string true "授權請求的 state"
+// @Param X-Device-Name header string false "裝置名稱，顯示在登入裝置列表"
+// @Success 200 {object} AuthResponse "登入成功"
+// @Failure 400 {object} map[string]string "state 無效或與 cookie 不符、提供者拒絕授權或 ID token 驗證失敗"
+// @Failure 403 {object} map[string]string "尚未完成電子郵件驗證或帳號已停權、封鎖、關閉"
+// @Failure 404 {object} map[string]string "不支援的登入提供者"
+// @Failure 409 {object} map[string]string "電子郵件已被其他會員使用或外部帳號已連結其他會員"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /auth/oidc/{provider}/callback [get]
+func OIDCCallback(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "登入提供者拒絕授權", "provider_error": errCode, "description": c.Query("error_description")})
+		return
+	}
+
+	state := c.Query("state")
+	matched := oidcStateMatchesCookie(c, state)
+	setOIDCStateCookie(c, "", -1)
+	if !matched {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidOIDCState.Error()})
+		return
+	}
+
+	svc := services.NewOIDCService(db.WithContext(c.Request.Context()))
+	result, err := svc.CompleteLogin(c.Request.Context(), c.Param("provider"), state, c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, oidc.ErrUnknownProvider):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidOIDCState),
+			errors.Is(err, services.ErrOIDCEmailRequired),
+			errors.Is(err, oidc.ErrExchangeFailed),
+			errors.Is(err, oidc.ErrInvalidIDToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOIDCEmailInUse),
+			errors.Is(err, services.ErrIdentityLinked),
+			errors.Is(err, services.ErrProviderAlreadyLinked):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if result.Linked {
+		c.JSON(http.StatusOK, gin.H{"message": "已連結外部帳號", "identity": toExternalIdentityResponse(*result.Identity)})
+		return
+	}
+
+	member := result.Member
+	if err := services.CheckMemberStatus(member); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if auth.EmailVerificationPolicy() == auth.EmailVerificationBlockLogin && member.EmailVerifiedAt == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "請先完成電子郵件驗證"})
+		return
+	}
+	if member.TOTPEnabledAt != nil {
+		respondWithMFAChallenge(c, member)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
+	}
+	respondWithTokens(c, status, member)
+}
+
+// GetOIDCIdentities 列出當前用戶已連結的外部帳號
+// @Summary 列出已連結的外部帳號
+// @Description 列出當前用戶已連結的外部登入帳號
+// @Tags 外部登入
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]ExternalIdentityResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /auth/oidc/identities [get]
+func GetOIDCIdentities(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewOIDCService(db.WithContext(c.Request.Context()))
+	identities, err := svc.ListIdentities(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]ExternalIdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		out = append(out, toExternalIdentityResponse(identity))
+	}
+	c.JSON(http.StatusOK, gin.H{"identities": out})
+}
+
+// UnlinkOIDCProvider 解除外部帳號連結（需要認證）
+// @Summary 解除外部帳號連結
+// @Description 移除當前用戶與外部登入提供者的連結；沒有設定密碼且這是唯一的外部帳號時拒絕
+// @Tags 外部登入
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "提供者代號"
+// @Success 200 {object} map[string]string "已解除連結"
+// @Failure 400 {object} map[string]string "這是唯一的登入方式"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "外部帳號連結不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /auth/oidc/identities/{provider} [delete]
+func UnlinkOIDCProvider(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewOIDCService(db.WithContext(c.Request.Context()))
+	if err := svc.Unlink(currentUserID(c), c.Param("provider")); err != nil {
+		switch {
+		case errors.Is(err, services.ErrIdentityNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrLastLoginMethod):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "用戶不存在"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已解除連結"})
+}
+
+// beginOIDCLogin 建立授權請求並返回授權網址，或依 redirect 參數直接導向
+func beginOIDCLogin(c *gin.Context, linkMemberID uint) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewOIDCService(db.WithContext(c.Request.Context()))
+	authURL, state, err := svc.BeginLogin(c.Request.Context(), c.Param("provider"), linkMemberID)
+	if err != nil {
+		if errors.Is(err, oidc.ErrUnknownProvider) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setOIDCStateCookie(c, auth.HashToken(state), int(services.OIDCAuthRequestTTL.Seconds()))
+
+	if linkMemberID == 0 && c.Query("redirect") == "true" {
+		c.Redirect(http.StatusFound, authURL)
+		return
+	}
+	c.JSON(http.StatusOK, OIDCAuthorizeResponse{AuthorizationURL: authURL, State: state})
+}
+
+// setOIDCStateCookie 設定（maxAge 為負數時清除）綁定授權請求的 HttpOnly、SameSite=Lax cookie；
+// 提供者導回屬於跨站的頂層導覽，Lax 仍會帶上 cookie
+func setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, oidcCookiePath, "", secure, true)
+}
+
+// oidcStateMatchesCookie 判斷 callback 的 state 是否由同一個瀏覽器發起
+func oidcStateMatchesCookie(c *gin.Context, state string) bool {
+	cookie, err := c.Cookie(oidcStateCookie)
+	if err != nil || cookie == "" || state == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(auth.HashToken(state))) == 1
+}
+
+func toExternalIdentityResponse(identity models.ExternalIdentity) ExternalIdentityResponse {
+	return ExternalIdentityResponse{
+		Provider:    identity.Provider,
+		Email:       identity.Email,
+		LinkedAt:    identity.CreationTime,
+		LastLoginAt: identity.LastLoginAt,
+	}
+}
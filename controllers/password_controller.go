@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"member_API/auth"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required" example:"q1w2e3..."`
+	NewPassword string `json:"new_password" binding:"required" example:"Correct-Horse-42"`
+}
+
+// ForgotPassword 申請重設密碼
+// @Summary 申請重設密碼
+// @Description 寄送重設密碼連結到會員郵箱；無論郵箱是否已註冊都返回相同結果
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "會員郵箱"
+// @Success 200 {object} map[string]string "已受理"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /password/forgot [post]
+func ForgotPassword(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewPasswordResetService(db.WithContext(c.Request.Context()))
+	if err := svc.RequestReset(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "重設密碼申請失敗，請稍後再試"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "若該電子郵件已註冊，重設密碼連結已寄出"})
+}
+
+// ResetPassword 重設密碼
+// @Summary 重設密碼
+// @Description 以郵件中的一次性 token 設定新密碼，成功後該會員所有登入都會被登出
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "重設 token 與新密碼"
+// @Success 200 {object} map[string]string "重設成功"
+// @Failure 400 {object} map[string]interface{} "請求參數錯誤、token 無效或密碼不符合規則"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /password/reset [post]
+func ResetPassword(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewPasswordResetService(db.WithContext(c.Request.Context()))
+	if err := svc.ResetPassword(req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, services.ErrInvalidMemberToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var policyErr *auth.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": policyErr.Error(), "violations": policyErr.Violations})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "密碼已重設，請重新登入"})
+}
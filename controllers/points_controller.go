@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PointsEntryResponse represents one entry in a member's points ledger.
+type PointsEntryResponse struct {
+	ID        uint       `json:"id" example:"1"`
+	Type      string     `json:"type" example:"earn"`
+	Points    int64      `json:"points" example:"100"`
+	Balance   int64      `json:"balance" example:"1200"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Reason    string     `json:"reason" example:"訂單 #A1001 消費回饋"`
+	Reference string     `json:"reference,omitempty" example:"order-A1001"`
+	ActorID   uint       `json:"actor_id,omitempty" example:"1"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RecordPointsRequest represents the request body for booking points for a member.
+type RecordPointsRequest struct {
+	Type      string `json:"type" binding:"required" example:"adjust"`
+	Points    int64  `json:"points" binding:"required" example:"-50"`
+	Reason    string `json:"reason" example:"客服補償重複扣點"`
+	Reference string `json:"reference" example:"ticket-8812"`
+}
+
+func toPointsEntryResponse(entry models.PointsLedgerEntry) PointsEntryResponse {
+	return PointsEntryResponse{
+		ID:        entry.ID,
+		Type:      entry.Type,
+		Points:    entry.Points,
+		Balance:   entry.Balance,
+		ExpiresAt: entry.ExpiresAt,
+		Reason:    entry.Reason,
+		Reference: entry.Reference,
+		ActorID:   entry.ActorID,
+		CreatedAt: entry.CreationTime,
+	}
+}
+
+// writePoints responds with a member's points summary and one page of history.
+func writePoints(c *gin.Context, memberID uint, notFound string) {
+	limit, offset, ok := listPagination(c)
+	if !ok {
+		return
+	}
+
+	svc := services.NewPointsService(db.WithContext(c.Request.Context()))
+	summary, err := svc.Summary(memberID)
+	if err != nil {
+		if err.Error() == "會員不存在" {
+			c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	entries, total, err := svc.History(memberID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	history := make([]PointsEntryResponse, len(entries))
+	for i, entry := range entries {
+		history[i] = toPointsEntryResponse(entry)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"points":  summary,
+		"entries": history,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// GetMyPoints 獲取當前用戶的點數餘額與異動紀錄
+// @Summary 獲取我的點數
+// @Description 返回點數餘額、最近一批即將到期的點數與到期時間，以及點數異動紀錄（新的在前）。點數在入帳一年後到期（依設定），使用時先扣最早到期的點數
+// @Tags 點數
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "每頁筆數（預設 50，最多 200）"
+// @Param offset query int false "略過筆數"
+// @Success 200 {object} map[string]interface{} "獲取成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "用戶不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /points [get]
+func GetMyPoints(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	writePoints(c, currentUserID(c), "用戶不存在")
+}
+
+// GetMemberPoints returns a member's points balance and ledger (admin).
+// @Summary 獲取會員點數
+// @Description 返回會員的點數餘額、即將到期的點數與點數異動紀錄（新的在前），需要 member:read 權限
+// @Tags 點數
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Param limit query int false "每頁筆數（預設 50，最多 200）"
+// @Param offset query int false "略過筆數"
+// @Success 200 {object} map[string]interface{} "獲取成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/points [get]
+func GetMemberPoints(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	writePoints(c, uint(memberID), "user not found")
+}
+
+// RecordMemberPoints books an earn, redeem or adjust entry for a member (admin).
+// @Summary 會員點數入帳
+// @Description 在會員的點數帳本新增一筆異動：earn 累積、redeem 兌換（points 皆為正數，只限 active 的會員），
+// @Description adjust 由管理員補發（正數）或扣除（負數）並需提供原因，會寫入審計紀錄。扣點不可超過餘額；
+// @Description reference（如訂單編號）在同一會員、同一類型內不可重複，可安全重試。帳本只新增不修改，更正請再新增一筆 adjust。需要 points:manage 權限
+// @Tags 點數
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Param request body RecordPointsRequest true "點數異動"
+// @Success 201 {object} map[string]PointsEntryResponse "入帳成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 409 {object} map[string]string "餘額不足、會員未啟用或參考編號已入帳"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/points [post]
+func RecordMemberPoints(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req RecordPointsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewPointsService(db.WithContext(c.Request.Context()))
+	entry, err := svc.Record(uint(memberID), services.PointsEntryInput{
+		Type:      req.Type,
+		Points:    req.Points,
+		Reason:    req.Reason,
+		Reference: req.Reference,
+		ActorID:   currentUserID(c),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPoints),
+			errors.Is(err, services.ErrInvalidPointsEntryType),
+			errors.Is(err, services.ErrPointsReasonRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInsufficientPoints),
+			errors.Is(err, services.ErrPointsMemberInactive),
+			errors.Is(err, services.ErrDuplicatePointsReference):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"entry": toPointsEntryResponse(*entry)})
+}
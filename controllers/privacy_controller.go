@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataExportResponse 資料匯出工作的狀態；status 為 completed 時可下載，expires_at 後失效
+type DataExportResponse struct {
+	ExportID    string     `json:"export_id" example:"q0Jx4b3Vt1..."`
+	MemberID    uint       `json:"member_id" example:"1"`
+	Status      string     `json:"status" example:"completed"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// EraseMemberRequest 清除個人資料的原因，會寫入審計紀錄
+type EraseMemberRequest struct {
+	Reason string `json:"reason" binding:"required" example:"會員依 GDPR 第 17 條申請刪除"`
+}
+
+// RequestMyDataExport requests an export of the current user's personal data.
+// @Summary 申請匯出個人資料
+// @Description 在背景產生目前使用者的個人資料匯出檔，包含會員資料、角色、登入裝置、API key、外部帳號連結、OAuth 授權、狀態歷史、合併紀錄與審計紀錄；
+// @Description 密碼、token 等機密不會匯出。同時只能有一個進行中的匯出，完成後可在 DATA_EXPORT_TTL 內下載
+// @Tags 個人資料
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} map[string]DataExportResponse "已開始匯出"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 409 {object} map[string]string "已有進行中的匯出"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /data-exports [post]
+func RequestMyDataExport(c *gin.Context) {
+	requestDataExport(c, currentUserID(c))
+}
+
+// GetMyDataExport returns the status of one of the current user's data exports.
+// @Summary 查詢個人資料匯出
+// @Description 查詢目前使用者的資料匯出工作狀態
+// @Tags 個人資料
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "匯出 ID"
+// @Success 200 {object} map[string]DataExportResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "匯出不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /data-exports/{id} [get]
+func GetMyDataExport(c *gin.Context) {
+	export, ok := loadDataExport(c, currentUserID(c))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"export": toDataExportResponse(export)})
+}
+
+// DownloadMyDataExport downloads a completed data export of the current user.
+// @Summary 下載個人資料匯出檔
+// @Description 下載已完成的資料匯出檔；format=json（預設）為單一 JSON 文件，format=zip 為每個資料類別一個 JSON 檔的 ZIP
+// @Tags 個人資料
+// @Produce json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path string true "匯出 ID"
+// @Param format query string false "json 或 zip" Enums(json, zip)
+// @Success 200 {file} file "匯出檔"
+// @Failure 400 {object} map[string]string "不支援的格式"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "匯出不存在"
+// @Failure 409 {object} map[string]string "匯出尚未完成"
+// @Failure 410 {object} map[string]string "匯出已過期"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /data-exports/{id}/download [get]
+func DownloadMyDataExport(c *gin.Context) {
+	export, ok := loadDataExport(c, currentUserID(c))
+	if !ok {
+		return
+	}
+	downloadDataExport(c, export)
+}
+
+// RequestMemberDataExport requests an export of a member's personal data (admin).
+// @Summary 代會員匯出個人資料
+// @Description 在背景產生指定會員的個人資料匯出檔，用於處理會員的資料查閱請求，需要 privacy:manage 權限；操作會寫入審計紀錄
+// @Tags 個人資料
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Success 202 {object} map[string]DataExportResponse "已開始匯出"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 409 {object} map[string]string "已有進行中的匯出或個人資料已清除"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/data-exports [post]
+func RequestMemberDataExport(c *gin.Context) {
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	requestDataExport(c, uint(memberID))
+}
+
+// GetMemberDataExport returns the status of any data export (admin).
+// @Summary 查詢會員資料匯出
+// @Description 查詢任一會員的資料匯出工作狀態，需要 privacy:manage 權限
+// @Tags 個人資料
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "匯出 ID"
+// @Success 200 {object} map[string]DataExportResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "匯出不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/data-exports/{id} [get]
+func GetMemberDataExport(c *gin.Context) {
+	export, ok := loadDataExport(c, 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"export": toDataExportResponse(export)})
+}
+
+// DownloadMemberDataExport downloads any completed data export (admin).
+// @Summary 下載會員資料匯出檔
+// @Description 下載任一會員已完成的資料匯出檔，需要 privacy:manage 權限；format 同個人資料匯出
+// @Tags 個人資料
+// @Produce json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path string true "匯出 ID"
+// @Param format query string false "json 或 zip" Enums(json, zip)
+// @Success 200 {file} file "匯出檔"
+// @Failure 400 {object} map[string]string "不支援的格式"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "匯出不存在"
+// @Failure 409 {object} map[string]string "匯出尚未完成"
+// @Failure 410 {object} map[string]string "匯出已過期"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/data-exports/{id}/download [get]
+func DownloadMemberDataExport(c *gin.Context) {
+	export, ok := loadDataExport(c, 0)
+	if !ok {
+		return
+	}
+	downloadDataExport(c, export)
+}
+
+// EraseMember anonymizes the personal data of a member (admin).
+// @Summary 清除會員個人資料
+// @Description 將會員的姓名與 email 匿名化，清空密碼與兩步驟驗證，帳號關閉並刪除，無法還原。登入、token、API key、外部帳號連結、OAuth 授權與匯出檔一併刪除；
+// @Description 會員列保留，其他資料的 creator_id、last_modifier_id 等參照不受影響，審計紀錄保留但清除 IP 與 User-Agent，合併紀錄中被合併會員的姓名與 email 一併匿名化。不可清除自己，需要 privacy:manage 權限
+// @Tags 個人資料
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Param request body EraseMemberRequest true "清除原因"
+// @Success 200 {object} map[string]User "清除成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足或不可清除此會員"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 409 {object} map[string]string "個人資料已清除"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/erase [post]
+func EraseMember(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req EraseMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewPrivacyService(db.WithContext(c.Request.Context()))
+	member, err := svc.Erase(uint(memberID), currentUserID(c), req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrErasureReasonRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrErasureNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAlreadyErased):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": User{ID: int64(member.ID), Name: member.Name, Email: member.Email}})
+}
+
+// requestDataExport 為會員建立資料匯出工作並回應 202
+func requestDataExport(c *gin.Context, memberID uint) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	svc := services.NewPrivacyService(db.WithContext(c.Request.Context()))
+	export, err := svc.RequestExport(memberID, currentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrExportInProgress), errors.Is(err, services.ErrAlreadyErased):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err.Error() == "會員不存在":
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"export": toDataExportResponse(export)})
+}
+
+// loadDataExport 取得路徑中的匯出工作；memberID 不為 0 時只允許該會員的匯出，錯誤時已回應
+func loadDataExport(c *gin.Context, memberID uint) (*models.DataExport, bool) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return nil, false
+	}
+
+	svc := services.NewPrivacyService(db.WithContext(c.Request.Context()))
+	export, err := svc.GetExport(c.Param("id"))
+	if err == nil && memberID != 0 && export.MemberID != memberID {
+		err = services.ErrExportNotFound
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrExportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return export, true
+}
+
+// downloadDataExport 以 format 參數指定的格式回應匯出檔
+func downloadDataExport(c *gin.Context, export *models.DataExport) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format 必須為 json 或 zip"})
+		return
+	}
+
+	doc, err := services.ExportDocument(export)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrExportNotReady):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrExportExpired):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	if format == "zip" {
+		body, err = services.ExportZip(doc)
+		contentType = "application/zip"
+	} else {
+		body, err = json.MarshalIndent(doc, "", "  ")
+		contentType = "application/json; charset=utf-8"
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("member-%d-data-%s.%s", export.MemberID, export.CreationTime.UTC().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func toDataExportResponse(export *models.DataExport) DataExportResponse {
+	return DataExportResponse{
+		ExportID:    export.ExportID,
+		MemberID:    export.MemberID,
+		Status:      export.Status,
+		Error:       export.Error,
+		CreatedAt:   export.CreationTime,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
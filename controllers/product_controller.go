@@ -161,7 +161,7 @@ func GetProductByID(c *gin.Context) {
 
 // CreateProduct creates a new product in the database.
 // @Summary 創建產品
-// @Description 創建新產品，需要 JWT 認證
+// @Description 創建新產品，需要 product:write 權限
 // @Tags 產品
 // @Accept json
 // @Produce json
@@ -170,6 +170,7 @@ func GetProductByID(c *gin.Context) {
 // @Success 201 {object} map[string]ProductResponse "創建成功"
 // @Failure 400 {object} map[string]string "請求參數錯誤"
 // @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
 // @Failure 500 {object} map[string]string "服務器錯誤"
 // @Router /product [post]
 func CreateProduct(c *gin.Context) {
@@ -185,13 +186,7 @@ func CreateProduct(c *gin.Context) {
 	}
 
 	// 獲取當前用戶 ID（從 JWT token 中）
-	userID, exists := c.Get("user_id")
-	var creatorID uint
-	if exists {
-		if id, ok := userID.(uint); ok {
-			creatorID = id
-		}
-	}
+	creatorID := currentUserID(c)
 
 	// 使用 Service 層
 	svc := services.NewProductService(productDB)
@@ -223,7 +218,7 @@ func CreateProduct(c *gin.Context) {
 
 // UpdateProduct updates an existing product in the database.
 // @Summary 更新產品
-// @Description 根據產品 ID 更新產品信息，需要 JWT 認證
+// @Description 根據產品 ID 更新產品信息，需要 product:write 權限
 // @Tags 產品
 // @Accept json
 // @Produce json
@@ -233,6 +228,7 @@ func CreateProduct(c *gin.Context) {
 // @Success 200 {object} map[string]ProductResponse "更新成功"
 // @Failure 400 {object} map[string]string "請求參數錯誤"
 // @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
 // @Failure 404 {object} map[string]string "產品不存在"
 // @Failure 500 {object} map[string]string "服務器錯誤"
 // @Router /product/{id} [put]
@@ -255,13 +251,7 @@ func UpdateProduct(c *gin.Context) {
 	}
 
 	// 獲取當前用戶 ID
-	userID, exists := c.Get("user_id")
-	var modifierID uint
-	if exists {
-		if id, ok := userID.(uint); ok {
-			modifierID = id
-		}
-	}
+	modifierID := currentUserID(c)
 
 	// 構建更新欄位
 	updates := make(map[string]interface{})
@@ -308,7 +298,7 @@ func UpdateProduct(c *gin.Context) {
 
 // DeleteProduct soft deletes a product by ID from the database.
 // @Summary 刪除產品
-// @Description 根據產品 ID 軟刪除產品，需要 JWT 認證
+// @Description 根據產品 ID 軟刪除產品，需要 product:write 權限
 // @Tags 產品
 // @Accept json
 // @Produce json
@@ -317,6 +307,7 @@ func UpdateProduct(c *gin.Context) {
 // @Success 200 {object} map[string]string "刪除成功"
 // @Failure 400 {object} map[string]string "無效的產品 ID"
 // @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
 // @Failure 404 {object} map[string]string "產品不存在"
 // @Failure 500 {object} map[string]string "服務器錯誤"
 // @Router /product/{id} [delete]
@@ -333,13 +324,7 @@ func DeleteProduct(c *gin.Context) {
 	}
 
 	// 獲取當前用戶 ID
-	userID, exists := c.Get("user_id")
-	var deleterID uint
-	if exists {
-		if id, ok := userID.(uint); ok {
-			deleterID = id
-		}
-	}
+	deleterID := currentUserID(c)
 
 	// 使用 Service 層
 	svc := services.NewProductService(productDB)
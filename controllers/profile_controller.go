@@ -0,0 +1,447 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MemberProfileResponse 會員的個人檔案
+type MemberProfileResponse struct {
+	Phone     string `json:"phone" example:"+886912345678"`
+	Birthday  string `json:"birthday" example:"1990-05-20"`
+	AvatarURL string `json:"avatar_url" example:"https://cdn.example.com/avatars/1.png"`
+	Locale    string `json:"locale" example:"zh-TW"`
+	Timezone  string `json:"timezone" example:"Asia/Taipei"`
+}
+
+// UpdateProfileDetailsRequest 修改個人檔案；省略的欄位不變，傳入空字串會清除該欄位
+type UpdateProfileDetailsRequest struct {
+	Phone     *string `json:"phone" example:"+886912345678"`
+	Birthday  *string `json:"birthday" example:"1990-05-20"`
+	AvatarURL *string `json:"avatar_url" example:"https://cdn.example.com/avatars/1.png"`
+	Locale    *string `json:"locale" example:"zh-TW"`
+	Timezone  *string `json:"timezone" example:"Asia/Taipei"`
+}
+
+// AddressRequest 新增或修改地址；type 為 shipping 或 billing，country 為 ISO 3166-1 二碼
+type AddressRequest struct {
+	Type          string `json:"type" example:"shipping"`
+	Label         string `json:"label" example:"公司"`
+	RecipientName string `json:"recipient_name" example:"張三"`
+	Phone         string `json:"phone" example:"0912345678"`
+	Country       string `json:"country" example:"TW"`
+	PostalCode    string `json:"postal_code" example:"110"`
+	Region        string `json:"region" example:"臺北市"`
+	City          string `json:"city" example:"信義區"`
+	Line1         string `json:"line1" example:"信義路五段 7 號"`
+	Line2         string `json:"line2" example:"89 樓"`
+	IsDefault     bool   `json:"is_default" example:"true"`
+}
+
+// AddressResponse 會員的一個地址
+type AddressResponse struct {
+	ID            uint   `json:"id" example:"1"`
+	Type          string `json:"type" example:"shipping"`
+	Label         string `json:"label" example:"公司"`
+	RecipientName string `json:"recipient_name" example:"張三"`
+	Phone         string `json:"phone" example:"0912345678"`
+	Country       string `json:"country" example:"TW"`
+	PostalCode    string `json:"postal_code" example:"110"`
+	Region        string `json:"region" example:"臺北市"`
+	City          string `json:"city" example:"信義區"`
+	Line1         string `json:"line1" example:"信義路五段 7 號"`
+	Line2         string `json:"line2" example:"89 樓"`
+	IsDefault     bool   `json:"is_default" example:"true"`
+}
+
+// PreferencesRequest 修改溝通偏好；省略的欄位不變
+type PreferencesRequest struct {
+	MarketingEmail   *bool   `json:"marketing_email" example:"true"`
+	MarketingSMS     *bool   `json:"marketing_sms" example:"false"`
+	Newsletter       *bool   `json:"newsletter" example:"true"`
+	PreferredChannel *string `json:"preferred_channel" example:"email"`
+}
+
+// PreferencesResponse 會員的溝通偏好；preferred_channel 為 email、sms 或 none
+type PreferencesResponse struct {
+	MarketingEmail   bool   `json:"marketing_email" example:"true"`
+	MarketingSMS     bool   `json:"marketing_sms" example:"false"`
+	Newsletter       bool   `json:"newsletter" example:"true"`
+	PreferredChannel string `json:"preferred_channel" example:"email"`
+}
+
+// UpdateProfileDetails 修改當前用戶的個人檔案
+// @Summary 修改個人檔案
+// @Description 修改手機號碼、生日（YYYY-MM-DD）、頭像網址、語言（BCP 47）與時區（IANA）；格式錯誤時 fields 列出每個欄位的錯誤
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateProfileDetailsRequest true "個人檔案"
+// @Success 200 {object} map[string]MemberProfileResponse "修改成功"
+// @Failure 400 {object} map[string]interface{} "欄位格式錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "用戶不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/details [put]
+func UpdateProfileDetails(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req UpdateProfileDetailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewProfileService(db.WithContext(c.Request.Context()))
+	member, err := svc.UpdateDetails(currentUserID(c), services.ProfileInput{
+		Phone:     req.Phone,
+		Birthday:  req.Birthday,
+		AvatarURL: req.AvatarURL,
+		Locale:    req.Locale,
+		Timezone:  req.Timezone,
+	})
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profile": toMemberProfileResponse(member)})
+}
+
+// GetAddresses 列出當前用戶的地址
+// @Summary 列出地址
+// @Description 列出當前用戶的收貨與帳單地址，預設地址在前
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]AddressResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/addresses [get]
+func GetAddresses(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewProfileService(db.WithContext(c.Request.Context()))
+	addresses, err := svc.ListAddresses(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]AddressResponse, len(addresses))
+	for i := range addresses {
+		out[i] = toAddressResponse(&addresses[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"addresses": out})
+}
+
+// CreateAddress 新增當前用戶的地址
+// @Summary 新增地址
+// @Description 新增收貨（shipping）或帳單（billing）地址，每位會員最多 20 個；該類型的第一個地址自動設為預設，is_default 為 true 時取代原本的預設地址
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AddressRequest true "地址"
+// @Success 201 {object} map[string]AddressResponse "新增成功"
+// @Failure 400 {object} map[string]interface{} "欄位格式錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 409 {object} map[string]string "地址數量已達上限"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/addresses [post]
+func CreateAddress(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewProfileService(db.WithContext(c.Request.Context()))
+	address, err := svc.CreateAddress(currentUserID(c), toAddressInput(req))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"address": toAddressResponse(address)})
+}
+
+// UpdateAddress 修改當前用戶的地址
+// @Summary 修改地址
+// @Description 以新內容取代地址；預設地址維持預設，改變類型時原類型的預設地址由最早建立的地址遞補
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "地址 ID" example(1)
+// @Param request body AddressRequest true "地址"
+// @Success 200 {object} map[string]AddressResponse "修改成功"
+// @Failure 400 {object} map[string]interface{} "欄位格式錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "地址不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/addresses/{id} [put]
+func UpdateAddress(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewProfileService(db.WithContext(c.Request.Context()))
+	address, err := svc.UpdateAddress(currentUserID(c), addressID, toAddressInput(req))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"address": toAddressResponse(address)})
+}
+
+// SetDefaultAddress 將地址設為預設
+// @Summary 設為預設地址
+// @Description 將地址設為同類型（收貨或帳單）的預設地址
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "地址 ID" example(1)
+// @Success 200 {object} map[string]AddressResponse "設定成功"
+// @Failure 400 {object} map[string]string "無效的地址 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "地址不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/addresses/{id}/default [put]
+func SetDefaultAddress(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	svc := services.NewProfileService(db.WithContext(c.Request.Context()))
+	address, err := svc.SetDefaultAddress(currentUserID(c), addressID)
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"address": toAddressResponse(address)})
+}
+
+// DeleteAddress 刪除當前用戶的地址
+// @Summary 刪除地址
+// @Description 刪除地址；刪除預設地址時由同類型最早建立的地址遞補為預設
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "地址 ID" example(1)
+// @Success 200 {object} map[string]string "刪除成功"
+// @Failure 400 {object} map[string]string "無效的地址 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "地址不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/addresses/{id} [delete]
+func DeleteAddress(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	svc := services.NewProfileService(db.WithContext(c.Request.Context()))
+	if err := svc.DeleteAddress(currentUserID(c), addressID); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "地址已刪除"})
+}
+
+// GetPreferences 獲取當前用戶的溝通偏好
+// @Summary 獲取溝通偏好
+// @Description 獲取行銷訊息訂閱與偏好的聯絡管道；尚未設定時所有行銷訊息皆為不訂閱
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]PreferencesResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/preferences [get]
+func GetPreferences(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewProfileService(db.WithContext(c.Request.Context()))
+	preference, err := svc.GetPreferences(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": toPreferencesResponse(preference)})
+}
+
+// UpdatePreferences 修改當前用戶的溝通偏好
+// @Summary 修改溝通偏好
+// @Description 修改行銷 email、行銷簡訊、電子報訂閱與偏好的聯絡管道（email、sms 或 none）；選擇 sms 前需先設定手機號碼
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PreferencesRequest true "溝通偏好"
+// @Success 200 {object} map[string]PreferencesResponse "修改成功"
+// @Failure 400 {object} map[string]interface{} "欄位格式錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "用戶不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /profile/preferences [put]
+func UpdatePreferences(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var req PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewProfileService(db.WithContext(c.Request.Context()))
+	preference, err := svc.UpdatePreferences(currentUserID(c), services.PreferencesInput{
+		MarketingEmail:   req.MarketingEmail,
+		MarketingSMS:     req.MarketingSMS,
+		Newsletter:       req.Newsletter,
+		PreferredChannel: req.PreferredChannel,
+	})
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": toPreferencesResponse(preference)})
+}
+
+// respondProfileError 將個人檔案相關的錯誤轉換為回應；欄位格式錯誤時附上 fields
+func respondProfileError(c *gin.Context, err error) {
+	var validationErr *services.ProfileValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error(), "fields": validationErr.Fields})
+	case errors.Is(err, services.ErrAddressNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyAddresses):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err.Error() == "會員不存在":
+		c.JSON(http.StatusNotFound, gin.H{"error": "用戶不存在"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// addressIDParam 解析路徑中的地址 ID，錯誤時已回應 400
+func addressIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的地址 ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func toAddressInput(req AddressRequest) services.AddressInput {
+	return services.AddressInput{
+		Type:          req.Type,
+		Label:         req.Label,
+		RecipientName: req.RecipientName,
+		Phone:         req.Phone,
+		Country:       req.Country,
+		PostalCode:    req.PostalCode,
+		Region:        req.Region,
+		City:          req.City,
+		Line1:         req.Line1,
+		Line2:         req.Line2,
+		IsDefault:     req.IsDefault,
+	}
+}
+
+func toMemberProfileResponse(member *models.Member) MemberProfileResponse {
+	resp := MemberProfileResponse{
+		Phone:     member.Phone,
+		AvatarURL: member.AvatarURL,
+		Locale:    member.Locale,
+		Timezone:  member.Timezone,
+	}
+	if member.Birthday != nil {
+		resp.Birthday = member.Birthday.Format("2006-01-02")
+	}
+	return resp
+}
+
+func toAddressResponse(address *models.MemberAddress) AddressResponse {
+	return AddressResponse{
+		ID:            address.ID,
+		Type:          address.Type,
+		Label:         address.Label,
+		RecipientName: address.RecipientName,
+		Phone:         address.Phone,
+		Country:       address.Country,
+		PostalCode:    address.PostalCode,
+		Region:        address.Region,
+		City:          address.City,
+		Line1:         address.Line1,
+		Line2:         address.Line2,
+		IsDefault:     address.IsDefault,
+	}
+}
+
+func toPreferencesResponse(preference *models.MemberPreference) PreferencesResponse {
+	return PreferencesResponse{
+		MarketingEmail:   preference.MarketingEmail,
+		MarketingSMS:     preference.MarketingSMS,
+		Newsletter:       preference.Newsletter,
+		PreferredChannel: preference.PreferredChannel,
+	}
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleResponse represents a role and its permissions.
+type RoleResponse struct {
+	ID          uint     `json:"id" example:"1"`
+	Name        string   `json:"name" example:"admin"`
+	Description string   `json:"description" example:"系統管理員"`
+	Permissions []string `json:"permissions" example:"member:read,member:delete"`
+}
+
+// SetMemberRolesRequest represents the request body for replacing a member's roles.
+type SetMemberRolesRequest struct {
+	Roles []string `json:"roles" binding:"required" example:"admin,member"`
+}
+
+// GetRoles returns all roles with their permissions.
+// @Summary 獲取角色列表
+// @Description 獲取所有角色及其權限，需要 role:manage 權限
+// @Tags 角色
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]RoleResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /roles [get]
+func GetRoles(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	svc := services.NewRoleService(db.WithContext(c.Request.Context()))
+	roles, err := svc.GetRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]RoleResponse, len(roles))
+	for i, role := range roles {
+		permissions := make([]string, len(role.Permissions))
+		for j, p := range role.Permissions {
+			permissions[j] = p.Name
+		}
+		out[i] = RoleResponse{
+			ID:          role.ID,
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: permissions,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": out})
+}
+
+// SetMemberRoles replaces the roles assigned to a member.
+// @Summary 設定會員角色
+// @Description 以指定角色完全取代會員目前的角色，需要 role:manage 權限；變更會在會員下次換發 token 時生效
+// @Tags 角色
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Param roles body SetMemberRolesRequest true "角色名稱"
+// @Success 200 {object} map[string]interface{} "設定成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤或角色不存在"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/roles [put]
+func SetMemberRoles(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req SetMemberRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	svc := services.NewRoleService(db.WithContext(c.Request.Context()))
+	member, err := svc.SetMemberRoles(uint(memberID), req.Roles, currentUserID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "會員不存在" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	roles := make([]string, len(member.Roles))
+	for i, role := range member.Roles {
+		roles[i] = role.Name
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":  User{ID: int64(member.ID), Name: member.Name, Email: member.Email},
+		"roles": roles,
+	})
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/auth"
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionResponse represents a login on one device.
+type SessionResponse struct {
+	ID         string    `json:"id" example:"Vb3o0bX5m2JtT0g3kq7QmA"`
+	DeviceName string    `json:"device_name" example:"iPhone 15"`
+	IP         string    `json:"ip" example:"203.0.113.7"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current" example:"true"`
+}
+
+// GetSessions 列出當前用戶的登入裝置
+// @Summary 列出登入裝置
+// @Description 列出當前用戶所有有效的登入，current 標示發出此請求的登入
+// @Tags 登入管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string][]SessionResponse "獲取成功"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /sessions [get]
+func GetSessions(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewSessionService(db.WithContext(c.Request.Context()))
+	sessions, err := svc.List(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": toSessionResponses(sessions, currentSessionID(c))})
+}
+
+// RevokeSession 登出當前用戶的某個登入
+// @Summary 登出指定裝置
+// @Description 撤銷當前用戶的某個登入，該裝置的 access token 與 refresh token 立即失效
+// @Tags 登入管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "登入 ID"
+// @Success 200 {object} map[string]string "已登出"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 404 {object} map[string]string "登入不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	svc := services.NewSessionService(db.WithContext(c.Request.Context()))
+	if err := svc.Revoke(currentUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已登出該裝置"})
+}
+
+// RevokeAllSessions 登出當前用戶的所有裝置
+// @Summary 登出所有裝置
+// @Description 撤銷當前用戶的所有登入；keep_current=true 時保留發出此請求的登入
+// @Tags 登入管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param keep_current query bool false "是否保留目前的登入"
+// @Success 200 {object} map[string]interface{} "已登出"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /sessions [delete]
+func RevokeAllSessions(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "數據庫連接未配置"})
+		return
+	}
+
+	var keep string
+	if c.Query("keep_current") == "true" {
+		keep = currentSessionID(c)
+	}
+
+	svc := services.NewSessionService(db.WithContext(c.Request.Context()))
+	count, err := svc.RevokeAll(currentUserID(c), keep)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已登出所有裝置", "revoked": count})
+}
+
+// GetMemberSessions 列出指定會員的登入裝置（管理員）
+// @Summary 列出會員登入裝置
+// @Description 列出指定會員所有有效的登入，需要 member:write 權限
+// @Tags 登入管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Success 200 {object} map[string][]SessionResponse "獲取成功"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/sessions [get]
+func GetMemberSessions(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	svc := services.NewSessionService(db.WithContext(c.Request.Context()))
+	sessions, err := svc.List(uint(memberID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": toSessionResponses(sessions, currentSessionID(c))})
+}
+
+// RevokeMemberSessions 登出指定會員的所有裝置（管理員）
+// @Summary 登出會員所有裝置
+// @Description 撤銷指定會員的所有登入，需要 member:write 權限；操作會寫入審計紀錄
+// @Tags 登入管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Success 200 {object} map[string]interface{} "已登出"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/sessions [delete]
+func RevokeMemberSessions(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	svc := services.NewSessionService(db.WithContext(c.Request.Context()))
+	count, err := svc.RevokeAll(uint(memberID), "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	services.NewAuditService(db.WithContext(c.Request.Context())).TryRecord(services.AuditEntry{
+		Action:    services.AuditSessionsRevoked,
+		MemberID:  uint(memberID),
+		ActorID:   currentUserID(c),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "已登出該會員所有裝置", "revoked": count})
+}
+
+// currentSessionID 返回發出請求的登入 ID
+func currentSessionID(c *gin.Context) string {
+	if principal := auth.PrincipalFromContext(c.Request.Context()); principal != nil {
+		return principal.SessionID
+	}
+	return ""
+}
+
+func toSessionResponses(sessions []models.Session, currentID string) []SessionResponse {
+	out := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		out[i] = SessionResponse{
+			ID:         s.SessionID,
+			DeviceName: s.DeviceName,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreationTime,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.SessionID == currentID,
+		}
+	}
+	return out
+}
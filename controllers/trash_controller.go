@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"member_API/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeletedMemberResponse 回收桶中的會員
+type DeletedMemberResponse struct {
+	ID        uint       `json:"id" example:"1"`
+	Name      string     `json:"name" example:"張三"`
+	Email     string     `json:"email" example:"user@example.com"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	DeletedBy uint       `json:"deleted_by" example:"1"`
+}
+
+// DeletedProductResponse 回收桶中的產品
+type DeletedProductResponse struct {
+	ProductResponse
+	DeletedAt *time.Time `json:"deleted_at"`
+	DeletedBy uint       `json:"deleted_by" example:"1"`
+}
+
+// GetDeletedMembers lists soft-deleted members.
+// @Summary 回收桶中的會員
+// @Description 列出已刪除但尚未永久清除的會員，最近刪除的在前；超過保留期限（SOFT_DELETE_RETENTION）後會被永久刪除，需要 member:delete 權限
+// @Tags 回收桶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "每頁筆數（預設 50，最多 200）"
+// @Param offset query int false "略過筆數"
+// @Success 200 {object} map[string]interface{} "獲取成功"
+// @Failure 400 {object} map[string]string "分頁參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/trash/members [get]
+func GetDeletedMembers(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	limit, offset, ok := listPagination(c)
+	if !ok {
+		return
+	}
+
+	svc := services.NewTrashService(db.WithContext(c.Request.Context()))
+	members, total, err := svc.ListMembers(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]DeletedMemberResponse, len(members))
+	for i, member := range members {
+		out[i] = DeletedMemberResponse{
+			ID:        member.ID,
+			Name:      member.Name,
+			Email:     member.Email,
+			DeletedAt: member.DeletedAt,
+			DeletedBy: member.LastModifierId,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"members": out,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// GetDeletedProducts lists soft-deleted products.
+// @Summary 回收桶中的產品
+// @Description 列出已刪除但尚未永久清除的產品，最近刪除的在前，需要 product:write 權限
+// @Tags 回收桶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "每頁筆數（預設 50，最多 200）"
+// @Param offset query int false "略過筆數"
+// @Success 200 {object} map[string]interface{} "獲取成功"
+// @Failure 400 {object} map[string]string "分頁參數錯誤"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/trash/products [get]
+func GetDeletedProducts(c *gin.Context) {
+	if productDB == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	limit, offset, ok := listPagination(c)
+	if !ok {
+		return
+	}
+
+	svc := services.NewTrashService(productDB.WithContext(c.Request.Context()))
+	products, total, err := svc.ListProducts(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	out := make([]DeletedProductResponse, len(products))
+	for i, product := range products {
+		out[i] = DeletedProductResponse{
+			ProductResponse: ProductResponse{
+				ID:                 product.ID,
+				ProductName:        product.ProductName,
+				ProductPrice:       product.ProductPrice,
+				ProductDescription: product.ProductDescription,
+				ProductImage:       product.ProductImage,
+				ProductStock:       product.ProductStock,
+			},
+			DeletedAt: product.DeletedAt,
+			DeletedBy: product.LastModifierId,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"products": out,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// RestoreMember restores a soft-deleted member.
+// @Summary 還原會員
+// @Description 將回收桶中的會員還原；刪除時撤銷的登入不會恢復，會員需重新登入，需要 member:delete 權限；操作會寫入審計紀錄
+// @Tags 回收桶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Success 200 {object} map[string]User "還原成功"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不在回收桶中"
+// @Failure 409 {object} map[string]string "email 已被其他會員使用"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/restore [post]
+func RestoreMember(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	svc := services.NewTrashService(db.WithContext(c.Request.Context()))
+	member, err := svc.RestoreMember(uint(memberID), currentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotInTrash):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrRestoreEmailInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": User{ID: int64(member.ID), Name: member.Name, Email: member.Email}})
+}
+
+// RestoreProduct restores a soft-deleted product.
+// @Summary 還原產品
+// @Description 將回收桶中的產品還原，需要 product:write 權限
+// @Tags 回收桶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "產品 ID" example(1)
+// @Success 200 {object} map[string]ProductResponse "還原成功"
+// @Failure 400 {object} map[string]string "無效的產品 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "產品不在回收桶中"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/products/{id}/restore [post]
+func RestoreProduct(c *gin.Context) {
+	if productDB == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	svc := services.NewTrashService(productDB.WithContext(c.Request.Context()))
+	product, err := svc.RestoreProduct(uint(productID), currentUserID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrNotInTrash) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product": ProductResponse{
+		ID:                 product.ID,
+		ProductName:        product.ProductName,
+		ProductPrice:       product.ProductPrice,
+		ProductDescription: product.ProductDescription,
+		ProductImage:       product.ProductImage,
+		ProductStock:       product.ProductStock,
+	}})
+}
+
+// listPagination 解析列表的 limit 與 offset 參數，錯誤時已回應 400
+func listPagination(c *gin.Context) (int, int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit 必須介於 1 到 200"})
+		return 0, 0, false
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset 不能為負數"})
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
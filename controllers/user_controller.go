@@ -4,8 +4,11 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"member_API/auth"
 	"member_API/models"
+	"member_API/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -25,54 +28,109 @@ type User struct {
 	Email string `json:"email" example:"user@example.com"`
 }
 
-// GetUsers returns a small collection of users from the database.
+// UserSummary 會員列表中的一筆會員；status 為考慮停權期限後的實際狀態
+type UserSummary struct {
+	User
+	Status    string    `json:"status" example:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetUsers returns a page of members matching the search and filters.
 // @Summary 獲取所有會員
-// @Description 獲取會員列表，最多返回 50 條記錄，需要 JWT 認證
+// @Description 分頁查詢會員，可依姓名或 email 搜尋（不分大小寫）、依狀態、角色、群組與建立日期過濾並排序，需要 member:read 權限
 // @Tags 用戶
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} map[string][]User "獲取成功"
+// @Param q query string false "搜尋姓名或 email" example(zhang)
+// @Param status query string false "帳號狀態" Enums(pending, active, suspended, banned, closed)
+// @Param role query string false "角色名稱" example(admin)
+// @Param group query string false "群組名稱" example(vip)
+// @Param created_from query string false "建立時間起（含），YYYY-MM-DD 或 RFC 3339" example(2026-01-01)
+// @Param created_to query string false "建立時間迄，只有日期時包含當天" example(2026-01-31)
+// @Param sort query string false "排序欄位：id、name、email、status、created_at，加上 - 前綴為遞減" example(-created_at)
+// @Param limit query int false "每頁筆數（預設 50，最多 200）"
+// @Param offset query int false "略過筆數"
+// @Success 200 {object} map[string]interface{} "獲取成功"
+// @Failure 400 {object} map[string]string "請求參數錯誤"
 // @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
 // @Failure 500 {object} map[string]string "服務器錯誤"
 // @Router /users [get]
 func GetUsers(c *gin.Context) {
 	if db == nil {
 		c.JSON(http.StatusOK, gin.H{
-			"users":   []User{},
+			"users":   []UserSummary{},
 			"message": "database connection not configured",
 		})
 		return
 	}
 
-	var members []models.Member
-	if err := db.WithContext(c.Request.Context()).
-		Select("id", "name", "email").
-		Limit(50).
-		Find(&members).Error; err != nil {
+	limit, offset, ok := listPagination(c)
+	if !ok {
+		return
+	}
+
+	filter := services.MemberFilter{
+		Search: c.Query("q"),
+		Status: c.Query("status"),
+		Role:   c.Query("role"),
+		Group:  c.Query("group"),
+	}
+	var err error
+	if filter.CreatedFrom, err = services.ParseDateBound(c.Query("created_from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "created_from " + err.Error()})
+		return
+	}
+	if filter.CreatedTo, err = services.ParseDateBound(c.Query("created_to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "created_to " + err.Error()})
+		return
+	}
+
+	svc := services.NewMemberService(db.WithContext(c.Request.Context()))
+	members, total, err := svc.ListMembers(filter, c.Query("sort"), limit, offset)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMemberStatus) || errors.Is(err, services.ErrInvalidMemberSort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	users := make([]User, len(members))
-	for i, member := range members {
-		users[i] = User{ID: int64(member.ID), Name: member.Name, Email: member.Email}
+	now := time.Now()
+	users := make([]UserSummary, len(members))
+	for i := range members {
+		member := &members[i]
+		users[i] = UserSummary{
+			User:      User{ID: int64(member.ID), Name: member.Name, Email: member.Email},
+			Status:    services.EffectiveMemberStatus(member, now),
+			CreatedAt: member.CreationTime,
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"users": users})
+	c.JSON(http.StatusOK, gin.H{
+		"users":  users,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
 }
 
 // GetUserByID returns a single user by ID from the database.
 // @Summary 根據 ID 獲取會員
-// @Description 根據會員 ID 獲取單個會員的詳細信息，需要 JWT 認證
+// @Description 根據會員 ID 獲取單個會員的詳細信息；會員可查詢自己，查詢其他會員需要 member:read 權限。
+// @Description 第三方應用程式查詢會員本人需要 profile scope，未取得 email scope 時不返回 email
 // @Tags 用戶
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "會員 ID" example(1)
 // @Success 200 {object} map[string]User "獲取成功"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
 // @Failure 401 {object} map[string]string "未認證"
-// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 403 {object} map[string]string "權限不足或尚未完成電子郵件驗證"
+// @Failure 404 {object} map[string]interface{} "會員不存在；會員已合併至其他會員時 merged_into 為合併後的會員 ID"
 // @Failure 500 {object} map[string]string "服務器錯誤"
 // @Router /user/{id} [get]
 func GetUserByID(c *gin.Context) {
@@ -92,9 +150,15 @@ func GetUserByID(c *gin.Context) {
 
 	var member models.Member
 	if err := db.WithContext(c.Request.Context()).
+		Scopes(models.NotDeleted).
 		Select("id", "name", "email").
 		First(&member, memberID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			svc := services.NewMemberMergeService(db.WithContext(c.Request.Context()))
+			if targetID, err := svc.ResolveMemberID(uint(memberID)); err == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "user not found", "merged_into": targetID})
+				return
+			}
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 			return
 		}
@@ -102,12 +166,23 @@ func GetUserByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": User{ID: int64(member.ID), Name: member.Name, Email: member.Email}})
+	// 第三方應用程式查詢會員本人時受 scope 限制；查詢其他會員已由 member:read 控管
+	user := User{ID: int64(member.ID), Name: member.Name, Email: member.Email}
+	if principal := auth.PrincipalFromContext(c.Request.Context()); principal != nil && principal.UserID == member.ID {
+		if !principal.HasScope(auth.ScopeProfile) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "應用程式未取得 " + auth.ScopeProfile + " 授權"})
+			return
+		}
+		if !principal.HasScope(auth.ScopeEmail) {
+			user.Email = ""
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"user": user})
 }
 
 // DeleteUserByID deletes a user by ID from the database.
 // @Summary 刪除會員
-// @Description 根據會員 ID 刪除會員，需要 JWT 認證
+// @Description 根據會員 ID 軟刪除會員並撤銷其所有登入，會員移至回收桶，保留期限內可還原，需要 member:delete 權限
 // @Tags 用戶
 // @Accept json
 // @Produce json
@@ -116,6 +191,8 @@ func GetUserByID(c *gin.Context) {
 // @Success 200 {object} map[string]string "刪除成功"
 // @Failure 400 {object} map[string]string "無效的會員 ID"
 // @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
 // @Failure 500 {object} map[string]string "服務器錯誤"
 // @Router /user/{id} [delete]
 func DeleteUserByID(c *gin.Context) {
@@ -130,10 +207,68 @@ func DeleteUserByID(c *gin.Context) {
 		return
 	}
 
-	if err := db.WithContext(c.Request.Context()).Delete(&models.Member{}, memberID).Error; err != nil {
+	svc := services.NewMemberService(db.WithContext(c.Request.Context()))
+	if err := svc.DeleteMember(uint(memberID), currentUserID(c)); err != nil {
+		if err.Error() == "會員不存在或已被刪除" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
 }
+
+// currentUserID returns the authenticated member ID stored by auth.AuthMiddleware, or 0.
+func currentUserID(c *gin.Context) uint {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return 0
+	}
+	id, ok := userID.(int64)
+	if !ok || id <= 0 {
+		return 0
+	}
+	return uint(id)
+}
+
+// UnlockMemberLogin clears the failed-login lockout of a member.
+// @Summary 解除會員登入鎖定
+// @Description 清除會員因多次登入失敗造成的退避與鎖定，需要 member:write 權限；操作會寫入審計紀錄
+// @Tags 用戶
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "會員 ID" example(1)
+// @Success 200 {object} map[string]string "解除成功"
+// @Failure 400 {object} map[string]string "無效的會員 ID"
+// @Failure 401 {object} map[string]string "未認證"
+// @Failure 403 {object} map[string]string "權限不足"
+// @Failure 404 {object} map[string]string "會員不存在"
+// @Failure 500 {object} map[string]string "服務器錯誤"
+// @Router /admin/members/{id}/unlock [post]
+func UnlockMemberLogin(c *gin.Context) {
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not configured"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	svc := services.NewMemberService(db.WithContext(c.Request.Context()))
+	if err := svc.UnlockLogin(c.Request.Context(), uint(memberID), currentUserID(c)); err != nil {
+		if err.Error() == "會員不存在" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已解除登入鎖定"})
+}
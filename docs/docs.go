@@ -25,35 +25,34 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/.well-known/jwks.json": {
             "get": {
-                "description": "檢查服務器狀態和數據庫連接狀態",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "返回目前所有有效的 JWT 簽章公鑰（RFC 7517 JWKS），token header 的 kid 對應其中一把金鑰",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "系統"
+                    "認證"
                 ],
-                "summary": "健康檢查",
+                "summary": "JWT 公鑰集合",
                 "responses": {
                     "200": {
-                        "description": "服務正常",
+                        "description": "公鑰集合",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/auth.JWKS"
                         }
                     }
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "用戶登入，驗證郵件和密碼後返回 JWT token 和用戶信息",
+        "/admin/audit-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "依事件、會員或操作者查詢審計紀錄，新的在前，需要 audit:read 權限",
                 "consumes": [
                     "application/json"
                 ],
@@ -61,25 +60,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "認證"
+                    "審計"
                 ],
-                "summary": "用戶登入",
+                "summary": "查詢審計紀錄",
                 "parameters": [
                     {
-                        "description": "登入信息",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.LoginRequest"
-                        }
+                        "type": "string",
+                        "example": "login.failed",
+                        "description": "事件名稱",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "相關會員 ID",
+                        "name": "member_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "操作者 ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數（預設 50，最多 200）",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "略過筆數",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登入成功",
+                        "description": "獲取成功",
                         "schema": {
-                            "$ref": "#/definitions/controllers.AuthResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -92,7 +114,16 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "電子郵件或密碼錯誤",
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -112,14 +143,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/product": {
-            "post": {
+        "/admin/data-exports/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "創建新產品，需要 JWT 認證",
+                "description": "查詢任一會員的資料匯出工作狀態，需要 privacy:manage 權限",
                 "consumes": [
                     "application/json"
                 ],
@@ -127,32 +158,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "產品"
+                    "個人資料"
                 ],
-                "summary": "創建產品",
+                "summary": "查詢會員資料匯出",
                 "parameters": [
                     {
-                        "description": "產品信息",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.CreateProductRequest"
-                        }
+                        "type": "string",
+                        "description": "匯出 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "創建成功",
+                    "200": {
+                        "description": "獲取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "$ref": "#/definitions/controllers.ProductResponse"
+                                "$ref": "#/definitions/controllers.DataExportResponse"
                             }
                         }
                     },
-                    "400": {
-                        "description": "請求參數錯誤",
+                    "401": {
+                        "description": "未認證",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -160,8 +189,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "未認證",
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "匯出不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -181,46 +219,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/product/{id}": {
+        "/admin/data-exports/{id}/download": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根據產品 ID 獲取單個產品的詳細信息，需要 JWT 認證",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "下載任一會員已完成的資料匯出檔，需要 privacy:manage 權限；format 同個人資料匯出",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/zip"
                 ],
                 "tags": [
-                    "產品"
+                    "個人資料"
                 ],
-                "summary": "根據 ID 獲取產品",
+                "summary": "下載會員資料匯出檔",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "example": 1,
-                        "description": "產品 ID",
+                        "type": "string",
+                        "description": "匯出 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "zip"
+                        ],
+                        "type": "string",
+                        "description": "json 或 zip",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "獲取成功",
+                        "description": "匯出檔",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/controllers.ProductResponse"
-                            }
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "無效的產品 ID",
+                        "description": "不支援的格式",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -237,8 +279,35 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
-                        "description": "產品不存在",
+                        "description": "匯出不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "匯出尚未完成",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "410": {
+                        "description": "匯出已過期",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -256,14 +325,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/groups": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根據產品 ID 更新產品信息，需要 JWT 認證",
+                "description": "獲取所有會員群組（如 vip、wholesale、staff）與其未刪除的會員人數，依名稱排序，需要 member:read 權限",
                 "consumes": [
                     "application/json"
                 ],
@@ -271,44 +342,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "產品"
-                ],
-                "summary": "更新產品",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "example": 1,
-                        "description": "產品 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "要更新的產品信息",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.UpdateProductRequest"
-                        }
-                    }
+                    "群組"
                 ],
+                "summary": "獲取會員群組列表",
                 "responses": {
                     "200": {
-                        "description": "更新成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/controllers.ProductResponse"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "請求參數錯誤",
+                        "description": "獲取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.GroupResponse"
+                                }
                             }
                         }
                     },
@@ -321,8 +367,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "產品不存在",
+                    "403": {
+                        "description": "權限不足",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -341,13 +387,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根據產品 ID 軟刪除產品，需要 JWT 認證",
+                "description": "建立會員群組，名稱為 1 到 50 個小寫英文字母、數字、- 或 _，不分大小寫且不可重複，需要 group:manage 權限",
                 "consumes": [
                     "application/json"
                 ],
@@ -355,31 +401,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "產品"
+                    "群組"
                 ],
-                "summary": "刪除產品",
+                "summary": "建立會員群組",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "example": 1,
-                        "description": "產品 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "群組名稱與說明",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GroupRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "刪除成功",
+                    "201": {
+                        "description": "建立成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "$ref": "#/definitions/controllers.GroupResponse"
                             }
                         }
                     },
                     "400": {
-                        "description": "無效的產品 ID",
+                        "description": "請求參數錯誤",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -396,8 +443,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "產品不存在",
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "群組名稱已被使用",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -417,14 +473,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
-            "get": {
+        "/admin/groups/{id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "獲取產品列表，最多返回 100 條記錄，需要 JWT 認證",
+                "description": "修改群組名稱與說明，需要 group:manage 權限；token 中的群組名稱在會員下次換發 token 時更新",
                 "consumes": [
                     "application/json"
                 ],
@@ -432,34 +488,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "產品"
+                    "群組"
                 ],
-                "summary": "獲取所有產品",
+                "summary": "修改會員群組",
                 "parameters": [
                     {
-                        "maximum": 100,
-                        "minimum": 1,
                         "type": "integer",
-                        "default": 50,
-                        "description": "限制返回數量",
-                        "name": "limit",
-                        "in": "query"
+                        "example": 1,
+                        "description": "群組 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "minimum": 0,
-                        "type": "integer",
-                        "default": 0,
-                        "description": "偏移量",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "群組名稱與說明",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GroupRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "獲取成功",
+                        "description": "修改成功",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.GroupResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "401": {
@@ -471,26 +538,51 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "服務器錯誤",
+                    "403": {
+                        "description": "權限不足",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/profile": {
-            "get": {
+                    },
+                    "404": {
+                        "description": "群組不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "群組名稱已被使用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "獲取當前登入用戶的詳細信息，需要 JWT 認證",
+                "description": "刪除群組並將所有會員移出該群組，需要 group:manage 權限",
                 "consumes": [
                     "application/json"
                 ],
@@ -498,16 +590,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用戶"
+                    "群組"
+                ],
+                "summary": "刪除會員群組",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "群組 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "獲取當前用戶信息",
                 "responses": {
                     "200": {
-                        "description": "獲取成功",
+                        "description": "刪除成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "$ref": "#/definitions/controllers.User"
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的群組 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -520,8 +631,17 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
-                        "description": "用戶不存在",
+                        "description": "群組不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -541,9 +661,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
+        "/admin/groups/{id}/members": {
             "post": {
-                "description": "註冊新用戶，返回 JWT token 和用戶信息",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "將多位會員加入群組，已在群組中的會員略過；任一會員不存在時不加入任何會員。一次最多 1000 位，需要 group:manage 權限",
                 "consumes": [
                     "application/json"
                 ],
@@ -551,29 +676,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "認證"
+                    "群組"
                 ],
-                "summary": "用戶註冊",
+                "summary": "將會員加入群組",
                 "parameters": [
                     {
-                        "description": "註冊信息",
-                        "name": "register",
+                        "type": "integer",
+                        "example": 1,
+                        "description": "群組 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "會員 ID",
+                        "name": "members",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.RegisterRequest"
+                            "$ref": "#/definitions/controllers.AddGroupMembersRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "註冊成功",
+                    "200": {
+                        "description": "加入成功，added 為新加入的人數",
                         "schema": {
-                            "$ref": "#/definitions/controllers.AuthResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
+                            }
                         }
                     },
                     "400": {
-                        "description": "請求參數錯誤",
+                        "description": "請求參數錯誤或會員不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -581,8 +718,26 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "409": {
-                        "description": "該電子郵件已被註冊",
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "群組不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -602,14 +757,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/{id}": {
-            "get": {
+        "/admin/groups/{id}/members/{member_id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根據會員 ID 獲取單個會員的詳細信息，需要 JWT 認證",
+                "description": "將會員移出群組，需要 group:manage 權限",
                 "consumes": [
                     "application/json"
                 ],
@@ -617,26 +772,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用戶"
+                    "群組"
                 ],
-                "summary": "根據 ID 獲取會員",
+                "summary": "將會員移出群組",
                 "parameters": [
                     {
                         "type": "integer",
                         "example": 1,
-                        "description": "會員 ID",
+                        "description": "群組 ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2,
+                        "description": "會員 ID",
+                        "name": "member_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "獲取成功",
+                        "description": "移出成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "$ref": "#/definitions/controllers.User"
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -649,8 +821,17 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
-                        "description": "會員不存在",
+                        "description": "群組不存在或會員不在群組中",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -668,14 +849,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/members/duplicates": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根據會員 ID 刪除會員，需要 JWT 認證",
+                "description": "比對所有未刪除的會員，列出可能為同一人的會員組：email 正規化後相同（不分大小寫、忽略 + 別名與 Gmail 帳號中的點），\n或姓名相近（不分大小寫、標點與姓名順序）且手機號碼或 email @ 之前的部分相同。需要 member:read 權限",
                 "consumes": [
                     "application/json"
                 ],
@@ -685,29 +868,31 @@ const docTemplate = `{
                 "tags": [
                     "用戶"
                 ],
-                "summary": "刪除會員",
+                "summary": "列出可能重複的會員",
                 "parameters": [
                     {
                         "type": "integer",
-                        "example": 1,
-                        "description": "會員 ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "每頁組數（預設 50，最多 200）",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "略過組數",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "刪除成功",
+                        "description": "獲取成功",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "無效的會員 ID",
+                        "description": "請求參數錯誤",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -724,6 +909,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "服務器錯誤",
                         "schema": {
@@ -736,34 +930,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/admin/members/export": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "獲取會員列表，最多返回 50 條記錄，需要 JWT 認證",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "以串流方式匯出所有未刪除的會員（不含服務帳號），欄位為 id、name、email、email_verified、status、phone、birthday、avatar_url、locale、timezone、created_at，\n格式與匯入相同；不包含密碼雜湊。需要 member:export 權限（只限管理員），操作會寫入審計紀錄",
                 "produces": [
-                    "application/json"
+                    "text/plain"
                 ],
                 "tags": [
                     "用戶"
                 ],
-                "summary": "獲取所有會員",
+                "summary": "批次匯出會員",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "檔案格式（預設 csv）",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "獲取成功",
+                        "description": "匯出檔",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "不支援的格式",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "array",
-                                "items": {
-                                    "$ref": "#/definitions/controllers.User"
-                                }
+                                "type": "string"
                             }
                         }
                     },
@@ -776,6 +982,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "服務器錯誤",
                         "schema": {
@@ -787,67 +1002,7726 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.AuthResponse": {
-            "type": "object",
-            "properties": {
-                "token": {
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
-                },
-                "user": {
-                    "$ref": "#/definitions/controllers.User"
-                }
-            }
-        },
-        "controllers.CreateProductRequest": {
-            "type": "object",
-            "required": [
-                "product_name",
-                "product_price",
-                "product_stock"
-            ],
-            "properties": {
-                "product_description": {
-                    "type": "string",
-                    "example": "最新款 iPhone"
-                },
-                "product_image": {
-                    "type": "string",
-                    "example": "https://example.com/image.jpg"
-                },
-                "product_name": {
-                    "type": "string",
-                    "example": "iPhone 15 Pro"
-                },
-                "product_price": {
-                    "type": "number",
-                    "example": 35900
-                },
-                "product_stock": {
-                    "type": "integer",
-                    "minimum": 0,
-                    "example": 100
-                }
-            }
         },
-        "controllers.LoginRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "user@example.com"
+        "/admin/members/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "從請求內容匯入會員，format 未指定時依 Content-Type 判斷（text/csv 或 application/x-ndjson）。\nCSV 第一列為標題列，必須包含 name 與 email，可加上 password、password_hash（argon2id 或 bcrypt 雜湊）、email_verified、phone、birthday、avatar_url、locale、timezone；\nNDJSON 每行一個相同欄位的 JSON 物件。匯出檔的 id、status、created_at 欄位會被忽略，可直接匯入。\n格式錯誤的資料列會在 errors 中列出並略過；on_duplicate 決定 email 已存在時略過、以檔案內容更新或中止整個匯入。\ndry_run=true 時只驗證不寫入。新會員不會收到驗證郵件，最多 10000 筆，需要 member:write 權限",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "批次匯入會員",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "檔案格式",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "update",
+                            "fail"
+                        ],
+                        "type": "string",
+                        "description": "email 已存在時的處理方式（預設 fail）",
+                        "name": "on_duplicate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "只驗證不寫入",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV 或 NDJSON 內容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "匯入完成",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/services.ImportResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數或標題列錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "email 已存在，匯入中止，所有變更已復原",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/services.ImportResult"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "檔案過大或筆數超過上限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出指定會員或服務帳號尚未撤銷的 API key，需要 apikey:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API key"
+                ],
+                "summary": "列出會員 API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.APIKeyResponse"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "為指定會員或服務帳號建立 API key，權限範圍只能是該會員擁有的權限，需要 apikey:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API key"
+                ],
+                "summary": "為會員建立 API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "名稱、權限範圍與到期時間",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "建立成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreatedAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或權限範圍無效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "用戶不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/api-keys/{key_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "撤銷指定會員或服務帳號的某把 API key，需要 apikey:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API key"
+                ],
+                "summary": "撤銷會員 API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "key_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已撤銷",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "API key 不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/data-exports": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "在背景產生指定會員的個人資料匯出檔，用於處理會員的資料查閱請求，需要 privacy:manage 權限；操作會寫入審計紀錄",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "個人資料"
+                ],
+                "summary": "代會員匯出個人資料",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "已開始匯出",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.DataExportResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "已有進行中的匯出或個人資料已清除",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "返回與指定會員可能為同一人的會員組（包含該會員本身），沒有重複時 group 為 null，比對規則同列出可能重複的會員。需要 member:read 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "查詢會員可能的重複帳號",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/services.DuplicateGroup"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/erase": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "將會員的姓名與 email 匿名化，清空密碼與兩步驟驗證，帳號關閉並刪除，無法還原。登入、token、API key、外部帳號連結、OAuth 授權與匯出檔一併刪除；\n會員列保留，其他資料的 creator_id、last_modifier_id 等參照不受影響，審計紀錄保留但清除 IP 與 User-Agent，合併紀錄中被合併會員的姓名與 email 一併匿名化。不可清除自己，需要 privacy:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "個人資料"
+                ],
+                "summary": "清除會員個人資料",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "清除原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.EraseMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "清除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足或不可清除此會員",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "個人資料已清除",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/groups": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以指定群組完全取代會員目前的群組，空陣列會移出所有群組，需要 group:manage 權限；token 中的群組在會員下次換發 token 時更新",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "群組"
+                ],
+                "summary": "設定會員群組",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "群組名稱",
+                        "name": "groups",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SetMemberGroupsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "設定成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員或群組不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/impersonate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "簽發短效的代理登入 token，token 的 act claim 記錄實際操作的管理員。代理登入期間不可變更密碼、email、兩步驟驗證等帳號設定，\n每個請求都會寫入審計紀錄。不可代理自己、服務帳號或擁有管理員沒有之權限的會員，需要 member:impersonate 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "代理會員登入",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "代理登入原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ImpersonateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "代理登入成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ImpersonationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足或不可代理此會員",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "在同一個 transaction 中將路徑中的來源會員合併至 target_id：外部帳號連結、API key、地址、登入與 OAuth 授權紀錄及審計紀錄移轉至目標會員，來源會員建立或最後修改的資料（會員、產品、群組等）改記在目標會員名下，角色與群組取聯集，點數餘額轉入目標會員，\n目標會員未填的個人檔案欄位以來源補上。來源會員的登入與授權全部撤銷，帳號關閉並刪除，保留合併紀錄；之後以來源會員 ID 查詢會返回 merged_into。\n無法還原，不可合併自己或服務帳號，需要 member:merge 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "合併重複的會員",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 5,
+                        "description": "來源會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "目標會員與原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MergeMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "合併成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.MemberMergeResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足或不可合併此會員",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/points": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "返回會員的點數餘額、即將到期的點數與點數異動紀錄（新的在前），需要 member:read 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "點數"
+                ],
+                "summary": "獲取會員點數",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數（預設 50，最多 200）",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "略過筆數",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "在會員的點數帳本新增一筆異動：earn 累積、redeem 兌換（points 皆為正數，只限 active 的會員），\nadjust 由管理員補發（正數）或扣除（負數）並需提供原因，會寫入審計紀錄。扣點不可超過餘額；\nreference（如訂單編號）在同一會員、同一類型內不可重複，可安全重試。帳本只新增不修改，更正請再新增一筆 adjust。需要 points:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "點數"
+                ],
+                "summary": "會員點數入帳",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "點數異動",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RecordPointsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "入帳成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.PointsEntryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "餘額不足、會員未啟用或參考編號已入帳",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "將回收桶中的會員還原；刪除時撤銷的登入不會恢復，會員需重新登入，需要 member:delete 權限；操作會寫入審計紀錄",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "回收桶"
+                ],
+                "summary": "還原會員",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "還原成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不在回收桶中",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "email 已被其他會員使用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/roles": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以指定角色完全取代會員目前的角色，需要 role:manage 權限；變更會在會員下次換發 token 時生效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色"
+                ],
+                "summary": "設定會員角色",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "角色名稱",
+                        "name": "roles",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SetMemberRolesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "設定成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或角色不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出指定會員所有有效的登入，需要 member:write 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登入管理"
+                ],
+                "summary": "列出會員登入裝置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.SessionResponse"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "撤銷指定會員的所有登入，需要 member:write 權限；操作會寫入審計紀錄",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登入管理"
+                ],
+                "summary": "登出會員所有裝置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已登出",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出會員每次帳號狀態變更的時間、操作者與原因，新的在前，需要 member:read 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "會員帳號狀態歷史",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "將會員設為 pending、active、suspended（至指定時間）、banned 或 closed，需要 member:write 權限。\n變更為 active 以外的狀態時會撤銷該會員所有登入與 OAuth 授權；API key 在恢復前無法使用。每次變更都會寫入狀態歷史與審計紀錄",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "變更會員帳號狀態",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "新狀態",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ChangeMemberStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "變更成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.MemberStatusResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "不允許的狀態變更",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/members/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "清除會員因多次登入失敗造成的退避與鎖定，需要 member:write 權限；操作會寫入審計紀錄",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "解除會員登入鎖定",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "解除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/oauth/clients": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出所有已登記的 OAuth 用戶端，需要 oauth:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "列出 OAuth 用戶端",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.OAuthClientResponse"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "登記第一方或第三方應用程式。機密用戶端會取得只顯示一次的 client_secret；公開用戶端（SPA、行動 App）沒有 secret，必須使用 PKCE。\ngrant_types 預設為 authorization_code；scope 可為 profile、email、offline_access 或權限名稱，需要 oauth:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "登記 OAuth 用戶端",
+                "parameters": [
+                    {
+                        "description": "用戶端設定",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateOAuthClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "登記成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreatedOAuthClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或設定無效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/oauth/clients/{client_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "取得指定 OAuth 用戶端的設定，需要 oauth:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "取得 OAuth 用戶端",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用戶端 ID",
+                        "name": "client_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.OAuthClientResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "OAuth 用戶端不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "刪除 OAuth 用戶端，並撤銷所有會員對它的授權與已簽發的 token，需要 oauth:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "刪除 OAuth 用戶端",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用戶端 ID",
+                        "name": "client_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已刪除",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "OAuth 用戶端不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/oauth/clients/{client_id}/secret": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "為機密用戶端產生新的 client_secret，舊 secret 立即失效；新 secret 只會顯示一次，需要 oauth:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "重新產生 client secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用戶端 ID",
+                        "name": "client_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "新的 client_secret",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "公開用戶端沒有 client secret",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "OAuth 用戶端不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/products/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "將回收桶中的產品還原，需要 product:write 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "回收桶"
+                ],
+                "summary": "還原產品",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "產品 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "還原成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.ProductResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的產品 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "產品不在回收桶中",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "建立沒有密碼、無法登入的服務帳號，之後以 /admin/members/{id}/api-keys 為其建立 API key，需要 apikey:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API key"
+                ],
+                "summary": "建立服務帳號",
+                "parameters": [
+                    {
+                        "description": "服務帳號資料",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateServiceAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "建立成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "該電子郵件已被使用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/trash/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出已刪除但尚未永久清除的會員，最近刪除的在前；超過保留期限（SOFT_DELETE_RETENTION）後會被永久刪除，需要 member:delete 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "回收桶"
+                ],
+                "summary": "回收桶中的會員",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數（預設 50，最多 200）",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "略過筆數",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "分頁參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/trash/products": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出已刪除但尚未永久清除的產品，最近刪除的在前，需要 product:write 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "回收桶"
+                ],
+                "summary": "回收桶中的產品",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數（預設 50，最多 200）",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "略過筆數",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "分頁參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出當前用戶尚未撤銷的 API key，不含 key 本身",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API key"
+                ],
+                "summary": "列出 API key",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.APIKeyResponse"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "為當前用戶建立具名的 API key，權限範圍只能是自己擁有的權限；完整 key 只會顯示一次。\n機器客戶端以 X-API-Key header 傳送，API key 本身不能用來建立新的 API key",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API key"
+                ],
+                "summary": "建立 API key",
+                "parameters": [
+                    {
+                        "description": "名稱、權限範圍與到期時間",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "建立成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreatedAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或權限範圍無效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "不可以 API key 建立 API key",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "撤銷當前用戶的某把 API key，使用該 key 的請求立即被拒絕",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API key"
+                ],
+                "summary": "撤銷 API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已撤銷",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "API key 不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/identities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出當前用戶已連結的外部登入帳號",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "外部登入"
+                ],
+                "summary": "列出已連結的外部帳號",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.ExternalIdentityResponse"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/identities/{provider}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "移除當前用戶與外部登入提供者的連結；沒有設定密碼且這是唯一的外部帳號時拒絕",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "外部登入"
+                ],
+                "summary": "解除外部帳號連結",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "提供者代號",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已解除連結",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "這是唯一的登入方式",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "外部帳號連結不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/providers": {
+            "get": {
+                "description": "列出已設定、可用於登入或連結帳號的 OpenID Connect 提供者代號",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "外部登入"
+                ],
+                "summary": "列出外部登入提供者",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/authorize": {
+            "get": {
+                "description": "建立授權請求（authorization code + PKCE），返回導向提供者登入頁的網址；帶 redirect=true 時直接以 302 導向。\n同時設定 HttpOnly 的 oidc_state cookie，callback 必須由同一個瀏覽器完成",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "外部登入"
+                ],
+                "summary": "開始外部登入",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "提供者代號，例如 google、line",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否直接導向提供者",
+                        "name": "redirect",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "授權網址",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OIDCAuthorizeResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "導向提供者登入頁"
+                    },
+                    "404": {
+                        "description": "不支援的登入提供者",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/callback": {
+            "get": {
+                "description": "驗證 state 與開始登入時設定的 oidc_state cookie 相符，並以授權碼換取 ID token；已連結的外部帳號直接登入，\n未連結時若提供者確認過 email 且同 email 的會員已驗證郵箱則連結該會員（未驗證則需先以密碼登入後自行連結），否則建立新會員。\n會員已啟用兩步驟驗證時返回 MFAChallengeResponse；連結流程則只返回連結結果，不簽發 token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "外部登入"
+                ],
+                "summary": "完成外部登入",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "提供者代號",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "授權碼",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "授權請求的 state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "裝置名稱，顯示在登入裝置列表",
+                        "name": "X-Device-Name",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登入成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "state 無效或與 cookie 不符、提供者拒絕授權或 ID token 驗證失敗",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "尚未完成電子郵件驗證或帳號已停權、封鎖、關閉",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "不支援的登入提供者",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "電子郵件已被其他會員使用或外部帳號已連結其他會員",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/link": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "為當前用戶建立連結外部帳號的授權請求，提供者導回 callback 後完成連結；\n與開始外部登入相同會設定 oidc_state cookie，callback 必須由同一個瀏覽器完成",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "外部登入"
+                ],
+                "summary": "連結外部帳號",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "提供者代號，例如 google、line",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "授權網址",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OIDCAuthorizeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "不支援的登入提供者",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data-exports": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "在背景產生目前使用者的個人資料匯出檔，包含會員資料、角色、登入裝置、API key、外部帳號連結、OAuth 授權、狀態歷史、合併紀錄與審計紀錄；\n密碼、token 等機密不會匯出。同時只能有一個進行中的匯出，完成後可在 DATA_EXPORT_TTL 內下載",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "個人資料"
+                ],
+                "summary": "申請匯出個人資料",
+                "responses": {
+                    "202": {
+                        "description": "已開始匯出",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.DataExportResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "已有進行中的匯出",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data-exports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "查詢目前使用者的資料匯出工作狀態",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "個人資料"
+                ],
+                "summary": "查詢個人資料匯出",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "匯出 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.DataExportResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "匯出不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data-exports/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "下載已完成的資料匯出檔；format=json（預設）為單一 JSON 文件，format=zip 為每個資料類別一個 JSON 檔的 ZIP",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "個人資料"
+                ],
+                "summary": "下載個人資料匯出檔",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "匯出 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "zip"
+                        ],
+                        "type": "string",
+                        "description": "json 或 zip",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "匯出檔",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "不支援的格式",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "匯出不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "匯出尚未完成",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "410": {
+                        "description": "匯出已過期",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/email/verify": {
+            "post": {
+                "description": "以驗證郵件中的 token 完成電子郵件驗證；已登入的客戶端需換發 token 後才會帶有已驗證狀態",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "確認電子郵件",
+                "parameters": [
+                    {
+                        "description": "驗證 token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "驗證成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或 token 無效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/email/verify/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "為目前登入的會員重新寄送電子郵件驗證連結，先前的驗證連結將失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "重新寄送驗證郵件",
+                "responses": {
+                    "200": {
+                        "description": "已寄出",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "電子郵件已完成驗證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "檢查服務器狀態和數據庫連接狀態",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系統"
+                ],
+                "summary": "健康檢查",
+                "responses": {
+                    "200": {
+                        "description": "服務正常",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/impersonation": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以代理登入 token 呼叫，結束該次代理登入並使 token 立即失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "結束代理登入",
+                "responses": {
+                    "200": {
+                        "description": "已結束代理登入",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "目前不是代理登入",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "代理登入不存在或已結束",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "用戶登入，驗證郵件和密碼後返回 access token、refresh token 和用戶信息；\n若會員已啟用兩步驟驗證，改為返回 MFAChallengeResponse，需以 /login/mfa 提交驗證碼換取 token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "用戶登入",
+                "parameters": [
+                    {
+                        "description": "登入信息",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "裝置名稱，顯示在登入裝置列表",
+                        "name": "X-Device-Name",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登入成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "電子郵件或密碼錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "尚未完成電子郵件驗證或帳號已停權、封鎖、關閉",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "登入失敗次數過多，已暫時限制或鎖定",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa": {
+            "post": {
+                "description": "以登入時取得的 mfa_token 與 TOTP 驗證碼（或備用碼）換取 access token 與 refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "兩步驟登入第二步",
+                "parameters": [
+                    {
+                        "description": "challenge 與驗證碼",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MFALoginRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "裝置名稱，顯示在登入裝置列表",
+                        "name": "X-Device-Name",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登入成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "challenge 無效或驗證碼錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "帳號已停權、封鎖或關閉",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "登入失敗次數過多，已暫時限制或鎖定",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "撤銷 refresh token 所屬的登入，之後該登入的 refresh token 均無法再換發",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "用戶登出",
+                "parameters": [
+                    {
+                        "description": "refresh token",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登出成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "refresh token 無效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以有效的驗證碼換發一組新的備用碼，舊的備用碼全部失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "兩步驟驗證"
+                ],
+                "summary": "換發備用碼",
+                "parameters": [
+                    {
+                        "description": "驗證碼",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已換發",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或驗證碼錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "尚未啟用兩步驟驗證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/totp/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "需提供目前密碼與有效的驗證碼或備用碼，停用後所有備用碼失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "兩步驟驗證"
+                ],
+                "summary": "停用兩步驟驗證",
+                "parameters": [
+                    {
+                        "description": "密碼與驗證碼",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DisableTOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已停用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤、密碼或驗證碼錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "尚未啟用兩步驟驗證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/totp/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以驗證器 App 產生的驗證碼確認密鑰並啟用兩步驟驗證，返回只顯示一次的備用碼",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "兩步驟驗證"
+                ],
+                "summary": "啟用兩步驟驗證",
+                "parameters": [
+                    {
+                        "description": "驗證碼",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已啟用",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或驗證碼錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "已啟用兩步驟驗證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/totp/setup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "為目前登入的會員產生新的 TOTP 密鑰與 otpauth provisioning URI（可轉為 QR code），需再呼叫啟用端點確認",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "兩步驟驗證"
+                ],
+                "summary": "產生 TOTP 密鑰",
+                "responses": {
+                    "200": {
+                        "description": "已產生",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TOTPSetupResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "已啟用兩步驟驗證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "前端授權頁以已登入會員的 token 轉送用戶端的授權請求（authorization code，公開用戶端必須使用 PKCE S256）。\n第一方應用程式或會員先前已同意所有 scope 時直接返回帶授權碼的 redirect_to；否則返回 consent_required，\n前端顯示同意畫面後以 POST 送出決定。client_id 或 redirect_uri 無效時返回 400，不可導回用戶端",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "授權第三方應用程式",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用戶端 ID",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "固定為 code",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "登記過的導回網址，只登記一個時可省略",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "以空白分隔的 scope，省略時為用戶端登記的全部 scope",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用戶端的 state，原樣附在導回網址",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge",
+                        "name": "code_challenge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "固定為 S256",
+                        "name": "code_challenge_method",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "導回網址或需要會員同意",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthAuthorizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "用戶端不存在或 redirect_uri 無效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "不接受 API key 或第三方應用程式的 token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "會員在同意畫面上同意（approve=true）或拒絕授權；同意的 scope 會被記錄，之後相同的請求不再詢問。\n返回帶授權碼或 access_denied 錯誤的 redirect_to",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "同意或拒絕授權",
+                "parameters": [
+                    {
+                        "description": "原授權請求參數與會員的決定",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthConsentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "導回網址",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthAuthorizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "用戶端不存在或 redirect_uri 無效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "不接受 API key 或第三方應用程式的 token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/consents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出當前用戶同意過的第三方應用程式與其 scope",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "列出已授權的應用程式",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.OAuthAuthorizedAppResponse"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/consents/{client_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "取消當前用戶對應用程式的授權，已簽發給該應用程式的 access token 與 refresh token 立即失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "取消應用程式授權",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用戶端 ID",
+                        "name": "client_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已取消授權",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "尚未授權此應用程式",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/introspect": {
+            "post": {
+                "description": "RFC 7662 token introspection，只接受機密用戶端。第一方用戶端可查詢任何 OAuth token，\n其他用戶端只能查詢簽發給自己的 token；無效、過期、已撤銷或無權查詢的 token 一律返回 active=false",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "查詢 token 狀態",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "access token 或 refresh token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token 或 refresh_token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用戶端 ID（未使用 HTTP Basic 時）",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "client secret（未使用 HTTP Basic 時）",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token 狀態",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthIntrospectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "缺少 token",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "用戶端驗證失敗",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/revoke": {
+            "post": {
+                "description": "RFC 7009 token revocation。撤銷 access token 或 refresh token 時，同一授權下的所有 token 一併失效；\ntoken 無效或不屬於呼叫的用戶端時同樣返回 200",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "撤銷 token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "access token 或 refresh token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token 或 refresh_token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用戶端 ID（未使用 HTTP Basic 時）",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "client secret（未使用 HTTP Basic 時）",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已處理"
+                    },
+                    "400": {
+                        "description": "缺少 token",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "用戶端驗證失敗",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "OAuth 2.0 token 端點，支援 authorization_code（含 PKCE）、refresh_token（每次輪換）與 client_credentials。\n機密用戶端以 HTTP Basic 或 client_id/client_secret 表單欄位驗證；公開用戶端只提供 client_id",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "取得 token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "authorization_code、refresh_token 或 client_credentials",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "授權碼",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "授權請求帶了 redirect_uri 時必填，且須與其相同",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "refresh token",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "以空白分隔的 scope，不可超出原授權",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用戶端 ID（未使用 HTTP Basic 時）",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "client secret（未使用 HTTP Basic 時）",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "簽發成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求錯誤或授權無效",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "用戶端驗證失敗",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/userinfo": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以 OAuth access token 取得會員資料：sub 一律返回，name、picture、birthdate、locale、zoneinfo 需要 profile scope，email 與 email_verified 需要 email scope",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "取得授權會員資料",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OAuthUserInfoResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "token 沒有代表的會員",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "用戶不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "寄送重設密碼連結到會員郵箱；無論郵箱是否已註冊都返回相同結果",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "申請重設密碼",
+                "parameters": [
+                    {
+                        "description": "會員郵箱",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已受理",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "以郵件中的一次性 token 設定新密碼，成功後該會員所有登入都會被登出",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "重設密碼",
+                "parameters": [
+                    {
+                        "description": "重設 token 與新密碼",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "重設成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤、token 無效或密碼不符合規則",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/points": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "返回點數餘額、最近一批即將到期的點數與到期時間，以及點數異動紀錄（新的在前）。點數在入帳一年後到期（依設定），使用時先扣最早到期的點數",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "點數"
+                ],
+                "summary": "獲取我的點數",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數（預設 50，最多 200）",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "略過筆數",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "用戶不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/product": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "創建新產品，需要 product:write 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "產品"
+                ],
+                "summary": "創建產品",
+                "parameters": [
+                    {
+                        "description": "產品信息",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "創建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.ProductResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根據產品 ID 獲取單個產品的詳細信息，需要 JWT 認證",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "產品"
+                ],
+                "summary": "根據 ID 獲取產品",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "產品 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.ProductResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的產品 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "產品不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根據產品 ID 更新產品信息，需要 product:write 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "產品"
+                ],
+                "summary": "更新產品",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "產品 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "要更新的產品信息",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.ProductResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "產品不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根據產品 ID 軟刪除產品，需要 product:write 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "產品"
+                ],
+                "summary": "刪除產品",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "產品 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "刪除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的產品 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "產品不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "獲取產品列表，最多返回 100 條記錄，需要 JWT 認證",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "產品"
+                ],
+                "summary": "獲取所有產品",
+                "parameters": [
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "限制返回數量",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "偏移量",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "獲取當前登入用戶的詳細信息與個人檔案（profile），需要 JWT 認證；代理登入時會附上 impersonation 欄位。\n第三方應用程式需要 profile scope，與 userinfo 相同只在取得 email scope 時返回 email，且不返回手機號碼",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "獲取當前用戶信息",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "用戶不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "修改當前登入用戶的姓名與電子郵件；變更電子郵件需提供目前密碼，新郵箱需重新驗證",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "修改當前用戶資料",
+                "parameters": [
+                    {
+                        "description": "用戶資料",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或目前密碼錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "用戶不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "該電子郵件已被使用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/addresses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出當前用戶的收貨與帳單地址，預設地址在前",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "列出地址",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.AddressResponse"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "新增收貨（shipping）或帳單（billing）地址，每位會員最多 20 個；該類型的第一個地址自動設為預設，is_default 為 true 時取代原本的預設地址",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "新增地址",
+                "parameters": [
+                    {
+                        "description": "地址",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "新增成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.AddressResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "欄位格式錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "地址數量已達上限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/addresses/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以新內容取代地址；預設地址維持預設，改變類型時原類型的預設地址由最早建立的地址遞補",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "修改地址",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "地址 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "地址",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.AddressResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "欄位格式錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "地址不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "刪除地址；刪除預設地址時由同類型最早建立的地址遞補為預設",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "刪除地址",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "地址 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "刪除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的地址 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "地址不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/addresses/{id}/default": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "將地址設為同類型（收貨或帳單）的預設地址",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "設為預設地址",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "地址 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "設定成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.AddressResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的地址 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "地址不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/details": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "修改手機號碼、生日（YYYY-MM-DD）、頭像網址、語言（BCP 47）與時區（IANA）；格式錯誤時 fields 列出每個欄位的錯誤",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "修改個人檔案",
+                "parameters": [
+                    {
+                        "description": "個人檔案",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateProfileDetailsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.MemberProfileResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "欄位格式錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "用戶不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "驗證目前密碼後設定新密碼，成功後除目前登入外的其他登入都會被登出",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "修改密碼",
+                "parameters": [
+                    {
+                        "description": "目前密碼與新密碼",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤、目前密碼錯誤或新密碼不符合規則",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "用戶不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "獲取行銷訊息訂閱與偏好的聯絡管道；尚未設定時所有行銷訊息皆為不訂閱",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "獲取溝通偏好",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.PreferencesResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "修改行銷 email、行銷簡訊、電子報訂閱與偏好的聯絡管道（email、sms 或 none）；選擇 sms 前需先設定手機號碼",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "修改溝通偏好",
+                "parameters": [
+                    {
+                        "description": "溝通偏好",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.PreferencesResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "欄位格式錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "用戶不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "註冊新用戶並寄出驗證郵件，返回 access token、refresh token 和用戶信息；若驗證政策為 block_login 則不返回 token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "用戶註冊",
+                "parameters": [
+                    {
+                        "description": "註冊信息",
+                        "name": "register",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RegisterRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "裝置名稱，顯示在登入裝置列表",
+                        "name": "X-Device-Name",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "註冊成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤或密碼不符合規則",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "該電子郵件已被註冊",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "獲取所有角色及其權限，需要 role:manage 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色"
+                ],
+                "summary": "獲取角色列表",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.RoleResponse"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "列出當前用戶所有有效的登入，current 標示發出此請求的登入",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登入管理"
+                ],
+                "summary": "列出登入裝置",
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/controllers.SessionResponse"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "撤銷當前用戶的所有登入；keep_current=true 時保留發出此請求的登入",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登入管理"
+                ],
+                "summary": "登出所有裝置",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "是否保留目前的登入",
+                        "name": "keep_current",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已登出",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "撤銷當前用戶的某個登入，該裝置的 access token 與 refresh token 立即失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登入管理"
+                ],
+                "summary": "登出指定裝置",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "登入 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已登出",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "登入不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "以 refresh token 換發新的 access token 與 refresh token，舊的 refresh token 立即失效；重複使用已輪換的 refresh token 會撤銷整個登入",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "認證"
+                ],
+                "summary": "換發 token",
+                "parameters": [
+                    {
+                        "description": "refresh token",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "換發成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "refresh token 無效或已被重複使用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "帳號已停權、封鎖或關閉",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根據會員 ID 獲取單個會員的詳細信息；會員可查詢自己，查詢其他會員需要 member:read 權限。\n第三方應用程式查詢會員本人需要 profile scope，未取得 email scope 時不返回 email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "根據 ID 獲取會員",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/controllers.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足或尚未完成電子郵件驗證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在；會員已合併至其他會員時 merged_into 為合併後的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根據會員 ID 軟刪除會員並撤銷其所有登入，會員移至回收桶，保留期限內可還原，需要 member:delete 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "刪除會員",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "會員 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "刪除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "無效的會員 ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "會員不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分頁查詢會員，可依姓名或 email 搜尋（不分大小寫）、依狀態、角色、群組與建立日期過濾並排序，需要 member:read 權限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用戶"
+                ],
+                "summary": "獲取所有會員",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "zhang",
+                        "description": "搜尋姓名或 email",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "active",
+                            "suspended",
+                            "banned",
+                            "closed"
+                        ],
+                        "type": "string",
+                        "description": "帳號狀態",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "admin",
+                        "description": "角色名稱",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "vip",
+                        "description": "群組名稱",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2026-01-01",
+                        "description": "建立時間起（含），YYYY-MM-DD 或 RFC 3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2026-01-31",
+                        "description": "建立時間迄，只有日期時包含當天",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at",
+                        "description": "排序欄位：id、name、email、status、created_at，加上 - 前綴為遞減",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數（預設 50，最多 200）",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "略過筆數",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "獲取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未認證",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "權限不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "服務器錯誤",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.JWK"
+                    }
+                }
+            }
+        },
+        "controllers.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "nightly-sync"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "3f9a1c0b"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "product:read"
+                    ]
+                }
+            }
+        },
+        "controllers.AddGroupMembersRequest": {
+            "type": "object",
+            "required": [
+                "member_ids"
+            ],
+            "properties": {
+                "member_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "controllers.AddressRequest": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "信義區"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "TW"
+                },
+                "is_default": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "label": {
+                    "type": "string",
+                    "example": "公司"
+                },
+                "line1": {
+                    "type": "string",
+                    "example": "信義路五段 7 號"
+                },
+                "line2": {
+                    "type": "string",
+                    "example": "89 樓"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "0912345678"
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "110"
+                },
+                "recipient_name": {
+                    "type": "string",
+                    "example": "張三"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "臺北市"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "shipping"
+                }
+            }
+        },
+        "controllers.AddressResponse": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "信義區"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "TW"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "is_default": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "label": {
+                    "type": "string",
+                    "example": "公司"
+                },
+                "line1": {
+                    "type": "string",
+                    "example": "信義路五段 7 號"
+                },
+                "line2": {
+                    "type": "string",
+                    "example": "89 樓"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "0912345678"
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "110"
+                },
+                "recipient_name": {
+                    "type": "string",
+                    "example": "張三"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "臺北市"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "shipping"
+                }
+            }
+        },
+        "controllers.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "3q2-7wX9..."
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "user": {
+                    "$ref": "#/definitions/controllers.User"
+                }
+            }
+        },
+        "controllers.ChangeMemberStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "違反社群規範"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "active",
+                        "suspended",
+                        "banned",
+                        "closed"
+                    ],
+                    "example": "suspended"
+                },
+                "suspended_until": {
+                    "type": "string",
+                    "example": "2026-12-31T00:00:00Z"
+                }
+            }
+        },
+        "controllers.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "new_password": {
+                    "type": "string",
+                    "example": "Correct-Horse-42"
+                }
+            }
+        },
+        "controllers.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2027-01-01T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "nightly-sync"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "product:read"
+                    ]
+                }
+            }
+        },
+        "controllers.CreateOAuthClientRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "confidential": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "first_party": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "grant_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "authorization_code",
+                        "refresh_token"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Example App"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "profile",
+                        "email",
+                        "offline_access"
+                    ]
+                }
+            }
+        },
+        "controllers.CreateProductRequest": {
+            "type": "object",
+            "required": [
+                "product_name",
+                "product_price",
+                "product_stock"
+            ],
+            "properties": {
+                "product_description": {
+                    "type": "string",
+                    "example": "最新款 iPhone"
+                },
+                "product_image": {
+                    "type": "string",
+                    "example": "https://example.com/image.jpg"
+                },
+                "product_name": {
+                    "type": "string",
+                    "example": "iPhone 15 Pro"
+                },
+                "product_price": {
+                    "type": "number",
+                    "example": 35900
+                },
+                "product_stock": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 100
+                }
+            }
+        },
+        "controllers.CreateServiceAccountRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "erp-sync@service.local"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ERP 同步"
+                }
+            }
+        },
+        "controllers.CreatedAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "$ref": "#/definitions/controllers.APIKeyResponse"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "mk_3f9a1c0b_Xq2..."
+                }
+            }
+        },
+        "controllers.CreatedOAuthClientResponse": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/controllers.OAuthClientResponse"
+                },
+                "client_secret": {
+                    "type": "string",
+                    "example": "Jx8c2L..."
+                }
+            }
+        },
+        "controllers.DataExportResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "export_id": {
+                    "type": "string",
+                    "example": "q0Jx4b3Vt1..."
+                },
+                "member_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                }
+            }
+        },
+        "controllers.DisableTOTPRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "controllers.EraseMemberRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "會員依 GDPR 第 17 條申請刪除"
+                }
+            }
+        },
+        "controllers.ExternalIdentityResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@gmail.com"
+                },
+                "last_login_at": {
+                    "type": "string"
+                },
+                "linked_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "google"
+                }
+            }
+        },
+        "controllers.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "controllers.GroupRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "VIP 會員"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "vip"
+                }
+            }
+        },
+        "controllers.GroupResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "VIP 會員"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "member_count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "name": {
+                    "type": "string",
+                    "example": "vip"
+                }
+            }
+        },
+        "controllers.ImpersonateRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "協助處理工單 #1234"
+                }
+            }
+        },
+        "controllers.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJFZERTQSIs..."
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "Zr7uG1Xq..."
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "user": {
+                    "$ref": "#/definitions/controllers.User"
+                }
+            }
+        },
+        "controllers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "controllers.MFACodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "controllers.MFALoginRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJFZERTQSIsImtpZCI6..."
+                }
+            }
+        },
+        "controllers.MemberMergeResponse": {
+            "type": "object",
+            "properties": {
+                "merged_at": {
+                    "type": "string"
+                },
+                "merged_by": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "moved": {
+                    "type": "string",
+                    "example": "sessions=2 addresses=1"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "同一位會員以 Gmail 別名重複註冊"
+                },
+                "source_email": {
+                    "type": "string",
+                    "example": "zhang.san+shop@gmail.com"
+                },
+                "source_member_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "source_name": {
+                    "type": "string",
+                    "example": "張三"
+                },
+                "target_member_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "controllers.MemberProfileResponse": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/avatars/1.png"
+                },
+                "birthday": {
+                    "type": "string",
+                    "example": "1990-05-20"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "zh-TW"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+886912345678"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Asia/Taipei"
+                }
+            }
+        },
+        "controllers.MemberStatusResponse": {
+            "type": "object",
+            "properties": {
+                "member_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "違反社群規範"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "suspended"
+                },
+                "suspended_until": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.MergeMemberRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "target_id"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "同一位會員以 Gmail 別名重複註冊"
+                },
+                "target_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "controllers.OAuthAuthorizeResponse": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/controllers.OAuthClientSummary"
+                },
+                "consent_required": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "redirect_to": {
+                    "type": "string",
+                    "example": "https://app.example.com/callback?code=...\u0026state=xyz"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "profile",
+                        "email"
+                    ]
+                }
+            }
+        },
+        "controllers.OAuthAuthorizedAppResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string",
+                    "example": "q3ZkP0y9..."
+                },
+                "granted_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Example App"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "profile",
+                        "email"
+                    ]
+                }
+            }
+        },
+        "controllers.OAuthClientResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string",
+                    "example": "q3ZkP0y9..."
+                },
+                "confidential": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "first_party": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "grant_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "authorization_code",
+                        "refresh_token"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Example App"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "profile",
+                        "email",
+                        "offline_access"
+                    ]
+                }
+            }
+        },
+        "controllers.OAuthClientSummary": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string",
+                    "example": "q3ZkP0y9..."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Example App"
+                }
+            }
+        },
+        "controllers.OAuthConsentRequest": {
+            "type": "object",
+            "required": [
+                "approve",
+                "client_id"
+            ],
+            "properties": {
+                "approve": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "client_id": {
+                    "type": "string",
+                    "example": "q3ZkP0y9..."
+                },
+                "code_challenge": {
+                    "type": "string",
+                    "example": "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+                },
+                "code_challenge_method": {
+                    "type": "string",
+                    "example": "S256"
+                },
+                "redirect_uri": {
+                    "type": "string",
+                    "example": "https://app.example.com/callback"
+                },
+                "response_type": {
+                    "type": "string",
+                    "example": "code"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "profile email offline_access"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "xyz"
+                }
+            }
+        },
+        "controllers.OAuthErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid_grant"
+                },
+                "error_description": {
+                    "type": "string",
+                    "example": "授權碼已過期"
+                }
+            }
+        },
+        "controllers.OAuthIntrospectionResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "aud": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "client_id": {
+                    "type": "string",
+                    "example": "q3ZkP0y9..."
+                },
+                "exp": {
+                    "type": "integer",
+                    "example": 1767225600
+                },
+                "iat": {
+                    "type": "integer",
+                    "example": 1767222000
+                },
+                "iss": {
+                    "type": "string",
+                    "example": "member-api"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "profile email"
+                },
+                "sub": {
+                    "type": "string",
+                    "example": "42"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "access_token"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "controllers.OAuthTokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJFZERTQSIs..."
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "Zr7uG1Xq..."
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "profile email"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "controllers.OAuthUserInfoResponse": {
+            "type": "object",
+            "properties": {
+                "birthdate": {
+                    "type": "string",
+                    "example": "1990-05-20"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
                 },
-                "password": {
+                "email_verified": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "locale": {
                     "type": "string",
-                    "minLength": 6,
-                    "example": "password123"
+                    "example": "zh-TW"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "張三"
+                },
+                "picture": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/avatars/1.png"
+                },
+                "sub": {
+                    "type": "string",
+                    "example": "42"
+                },
+                "zoneinfo": {
+                    "type": "string",
+                    "example": "Asia/Taipei"
+                }
+            }
+        },
+        "controllers.OIDCAuthorizeResponse": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "type": "string",
+                    "example": "https://accounts.google.com/o/oauth2/v2/auth?client_id=..."
+                },
+                "state": {
+                    "type": "string",
+                    "example": "mF3k9Qz..."
+                }
+            }
+        },
+        "controllers.PointsEntryResponse": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "balance": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "points": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "訂單 #A1001 消費回饋"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "order-A1001"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "earn"
+                }
+            }
+        },
+        "controllers.PreferencesRequest": {
+            "type": "object",
+            "properties": {
+                "marketing_email": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "marketing_sms": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "newsletter": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "preferred_channel": {
+                    "type": "string",
+                    "example": "email"
+                }
+            }
+        },
+        "controllers.PreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "marketing_email": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "marketing_sms": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "newsletter": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "preferred_channel": {
+                    "type": "string",
+                    "example": "email"
                 }
             }
         },
@@ -880,6 +8754,57 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.RecordPointsRequest": {
+            "type": "object",
+            "required": [
+                "points",
+                "type"
+            ],
+            "properties": {
+                "points": {
+                    "type": "integer",
+                    "example": -50
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "客服補償重複扣點"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "ticket-8812"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "adjust"
+                }
+            }
+        },
+        "controllers.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ABCD-EFGH-IJKL-MNOP"
+                    ]
+                }
+            }
+        },
+        "controllers.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "3q2-7wX9..."
+                }
+            }
+        },
         "controllers.RegisterRequest": {
             "type": "object",
             "required": [
@@ -898,8 +8823,134 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string",
-                    "minLength": 6,
-                    "example": "password123"
+                    "example": "Correct-Horse-42"
+                }
+            }
+        },
+        "controllers.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "example": "Correct-Horse-42"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "q1w2e3..."
+                }
+            }
+        },
+        "controllers.RoleResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "系統管理員"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "member:read",
+                        "member:delete"
+                    ]
+                }
+            }
+        },
+        "controllers.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "device_name": {
+                    "type": "string",
+                    "example": "iPhone 15"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "Vb3o0bX5m2JtT0g3kq7QmA"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0"
+                }
+            }
+        },
+        "controllers.SetMemberGroupsRequest": {
+            "type": "object",
+            "required": [
+                "groups"
+            ],
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vip",
+                        "wholesale"
+                    ]
+                }
+            }
+        },
+        "controllers.SetMemberRolesRequest": {
+            "type": "object",
+            "required": [
+                "roles"
+            ],
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "admin",
+                        "member"
+                    ]
+                }
+            }
+        },
+        "controllers.TOTPSetupResponse": {
+            "type": "object",
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Member%20API:user@example.com?secret=..."
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
                 }
             }
         },
@@ -928,6 +8979,53 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.UpdateProfileDetailsRequest": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/avatars/1.png"
+                },
+                "birthday": {
+                    "type": "string",
+                    "example": "1990-05-20"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "zh-TW"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+886912345678"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Asia/Taipei"
+                }
+            }
+        },
+        "controllers.UpdateProfileRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "current_password": {
+                    "description": "CurrentPassword 變更 email 時必填",
+                    "type": "string",
+                    "example": "password123"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "張三"
+                }
+            }
+        },
         "controllers.User": {
             "type": "object",
             "properties": {
@@ -944,9 +9042,126 @@ const docTemplate = `{
                     "example": "張三"
                 }
             }
+        },
+        "controllers.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "q1w2e3..."
+                }
+            }
+        },
+        "services.DuplicateGroup": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.DuplicateMember"
+                    }
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "email"
+                    ]
+                }
+            }
+        },
+        "services.DuplicateMember": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "zhang.san+shop@gmail.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "張三"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+886912345678"
+                }
+            }
+        },
+        "services.ImportResult": {
+            "type": "object",
+            "properties": {
+                "aborted": {
+                    "type": "boolean"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ImportRowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "資料格式錯誤：phone：手機號碼格式錯誤"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
         }
     },
     "securityDefinitions": {
+        "APIKeyAuth": {
+            "description": "機器客戶端使用的 API key，與 Bearer token 擇一提供",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "JWT 認證，格式：Bearer {token}",
             "type": "apiKey",
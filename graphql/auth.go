@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+
+	"member_API/auth"
+	"member_API/graphql/model"
+
+	gql "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// errUnauthenticated and errForbidden are returned to GraphQL clients with an
+// extensions.code so they can be told apart from validation errors.
+var (
+	errUnauthenticated = &gqlerror.Error{
+		Message:    "未認證",
+		Extensions: map[string]interface{}{"code": "UNAUTHENTICATED"},
+	}
+	errForbidden = &gqlerror.Error{
+		Message:    "權限不足",
+		Extensions: map[string]interface{}{"code": "FORBIDDEN"},
+	}
+	errFirstPartyOnly = &gqlerror.Error{
+		Message:    auth.ErrFirstPartyOnly.Error(),
+		Extensions: map[string]interface{}{"code": "FORBIDDEN"},
+	}
+	errImpersonating = &gqlerror.Error{
+		Message:    auth.ErrImpersonating.Error(),
+		Extensions: map[string]interface{}{"code": "FORBIDDEN"},
+	}
+	errEmailNotVerified = &gqlerror.Error{
+		Message:    "請先完成電子郵件驗證",
+		Extensions: map[string]interface{}{"code": "EMAIL_NOT_VERIFIED"},
+	}
+)
+
+// authMiddleware validates an optional Bearer token or API key and injects the principal
+// into the request context. Requests without a token continue anonymously so
+// public fields keep working; an invalid token is rejected up front.
+func authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		apiKey := r.Header.Get(auth.APIKeyHeader)
+		if header == "" && apiKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := auth.AuthenticateRequest(r.Context(), header, apiKey, clientIP(r))
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+
+		ctx := auth.ContextWithPrincipal(r.Context(), principal)
+		if !principal.IsImpersonated() {
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		auth.RecordImpersonatedRequest(ctx, r, rec.status, clientIP(r))
+	})
+}
+
+// statusRecorder captures the response status so impersonated requests can be audited.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// clientIP returns the caller address without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// writeAuthError writes a GraphQL-shaped 401 response.
+func writeAuthError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []*gqlerror.Error{{
+			Message:    err.Error(),
+			Extensions: map[string]interface{}{"code": "UNAUTHENTICATED"},
+		}},
+		"data": nil,
+	})
+}
+
+// authDirective implements @auth: the field requires an authenticated caller.
+func authDirective(ctx context.Context, obj interface{}, next gql.Resolver) (interface{}, error) {
+	if auth.PrincipalFromContext(ctx) == nil {
+		return nil, errUnauthenticated
+	}
+	return next(ctx)
+}
+
+// hasRoleDirective implements @hasRole(role): the caller must hold the role.
+func hasRoleDirective(ctx context.Context, obj interface{}, next gql.Resolver, role string) (interface{}, error) {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil, errUnauthenticated
+	}
+	if !principal.HasRole(role) {
+		return nil, errForbidden
+	}
+	return next(ctx)
+}
+
+// requirePermission ensures the authenticated caller holds the given permission.
+// Privileged operations also require a verified email when the policy is enabled.
+func requirePermission(ctx context.Context, permission string) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return errUnauthenticated
+	}
+	if auth.EmailVerificationRequired() && !principal.EmailVerified {
+		return errEmailNotVerified
+	}
+	if !principal.HasPermission(permission) {
+		return errForbidden
+	}
+	return nil
+}
+
+// requireSelfOrPermission lets members read their own data; reading another
+// member's data requires the permission. Like the REST account routes, the
+// self path only accepts first-party tokens: API keys, third-party OAuth
+// tokens and admin impersonation need the permission even for their own member.
+func requireSelfOrPermission(ctx context.Context, memberID uint, permission string) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return errUnauthenticated
+	}
+	if principal.UserID != 0 && principal.UserID == memberID && principal.IsFirstParty() {
+		return nil
+	}
+	return requirePermission(ctx, permission)
+}
+
+// privateMemberField returns value when the caller may read the member's
+// private fields (self or member:read) and nil otherwise, so member lists
+// stay readable while phone numbers, birthdays and status reasons are hidden.
+func privateMemberField(ctx context.Context, obj *model.Member, value *string) *string {
+	memberID, err := strconv.ParseUint(obj.ID, 10, 32)
+	if err != nil || requireSelfOrPermission(ctx, uint(memberID), auth.PermMemberRead) != nil {
+		return nil
+	}
+	return value
+}
+
+// requireScope ensures a third-party OAuth token was granted the scope;
+// first-party tokens and API keys are not limited by scopes.
+func requireScope(ctx context.Context, scope string) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return errUnauthenticated
+	}
+	if !principal.HasScope(scope) {
+		return &gqlerror.Error{
+			Message:    "應用程式未取得 " + scope + " 授權",
+			Extensions: map[string]interface{}{"code": "FORBIDDEN"},
+		}
+	}
+	return nil
+}
+
+// requireFirstParty rejects API keys, third-party OAuth tokens and admin
+// impersonation on account management fields such as passwords, sessions and API keys.
+func requireFirstParty(ctx context.Context) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return errUnauthenticated
+	}
+	if principal.IsImpersonated() {
+		return errImpersonating
+	}
+	if !principal.IsFirstParty() {
+		return errFirstPartyOnly
+	}
+	return nil
+}
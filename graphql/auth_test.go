@@ -0,0 +1,209 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"member_API/auth"
+	"member_API/graphql/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	var got *auth.Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = auth.PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := authMiddleware(next)
+
+	t.Run("匿名請求繼續處理", func(t *testing.T) {
+		got = nil
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, got)
+	})
+
+	t.Run("無效 token 返回 GraphQL 格式錯誤", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.Header.Set("Authorization", "Bearer invalid")
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"errors"`)
+		assert.Contains(t, w.Body.String(), "UNAUTHENTICATED")
+	})
+
+	t.Run("有效 token 注入 principal", func(t *testing.T) {
+		token, err := auth.GenerateToken(11, "gql@example.com")
+		assert.NoError(t, err)
+
+		got = nil
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		if assert.NotNil(t, got) {
+			assert.Equal(t, uint(11), got.UserID)
+		}
+	})
+
+	t.Run("API key 注入 principal", func(t *testing.T) {
+		rawKey, _, err := auth.GenerateAPIKey()
+		assert.NoError(t, err)
+		auth.SetAPIKeyAuthenticator(func(_ context.Context, key, ip string) (*auth.Principal, error) {
+			if key != rawKey {
+				return nil, auth.ErrInvalidAPIKey
+			}
+			assert.Equal(t, "192.0.2.1", ip)
+			return &auth.Principal{UserID: 12, APIKeyID: 5}, nil
+		})
+		defer auth.SetAPIKeyAuthenticator(nil)
+
+		got = nil
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.Header.Set(auth.APIKeyHeader, rawKey)
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		if assert.NotNil(t, got) {
+			assert.Equal(t, uint(12), got.UserID)
+			assert.Equal(t, uint(5), got.APIKeyID)
+		}
+	})
+}
+
+func TestDirectives(t *testing.T) {
+	next := func(ctx context.Context) (interface{}, error) { return "ok", nil }
+	member := auth.ContextWithPrincipal(context.Background(), &auth.Principal{UserID: 1, Roles: []string{auth.RoleMember}})
+	admin := auth.ContextWithPrincipal(context.Background(), &auth.Principal{UserID: 2, Roles: []string{auth.RoleAdmin}})
+
+	t.Run("@auth", func(t *testing.T) {
+		_, err := authDirective(context.Background(), nil, next)
+		assert.ErrorIs(t, err, errUnauthenticated)
+
+		res, err := authDirective(member, nil, next)
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", res)
+	})
+
+	t.Run("@hasRole", func(t *testing.T) {
+		_, err := hasRoleDirective(context.Background(), nil, next, auth.RoleAdmin)
+		assert.ErrorIs(t, err, errUnauthenticated)
+
+		_, err = hasRoleDirective(member, nil, next, auth.RoleAdmin)
+		assert.ErrorIs(t, err, errForbidden)
+
+		res, err := hasRoleDirective(admin, nil, next, auth.RoleAdmin)
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", res)
+	})
+}
+
+func TestGetUserIDFromContext(t *testing.T) {
+	assert.Equal(t, uint(0), getUserIDFromContext(context.Background()))
+
+	ctx := auth.ContextWithPrincipal(context.Background(), &auth.Principal{UserID: 42})
+	assert.Equal(t, uint(42), getUserIDFromContext(ctx))
+}
+
+func TestRequireSelfOrPermission(t *testing.T) {
+	member := auth.ContextWithPrincipal(context.Background(), &auth.Principal{UserID: 1, EmailVerified: true})
+	admin := auth.ContextWithPrincipal(context.Background(), &auth.Principal{UserID: 2, EmailVerified: true, Permissions: []string{auth.PermMemberRead}})
+
+	assert.ErrorIs(t, requireSelfOrPermission(context.Background(), 1, auth.PermMemberRead), errUnauthenticated)
+	assert.NoError(t, requireSelfOrPermission(member, 1, auth.PermMemberRead))
+	assert.ErrorIs(t, requireSelfOrPermission(member, 3, auth.PermMemberRead), errForbidden)
+	assert.NoError(t, requireSelfOrPermission(admin, 3, auth.PermMemberRead))
+}
+
+func TestPrivateMemberField(t *testing.T) {
+	member := auth.ContextWithPrincipal(context.Background(), &auth.Principal{UserID: 1, EmailVerified: true})
+	admin := auth.ContextWithPrincipal(context.Background(), &auth.Principal{UserID: 2, EmailVerified: true, Permissions: []string{auth.PermMemberRead}})
+	phone := "0912345678"
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		id      string
+		visible bool
+	}{
+		{name: "匿名看不到", ctx: context.Background(), id: "1"},
+		{name: "本人看得到", ctx: member, id: "1", visible: true},
+		{name: "其他會員看不到", ctx: member, id: "3"},
+		{name: "有 member:read 看得到", ctx: admin, id: "3", visible: true},
+		{name: "無效的 ID", ctx: admin, id: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := privateMemberField(tt.ctx, &model.Member{ID: tt.id}, &phone)
+			if tt.visible {
+				assert.Equal(t, &phone, got)
+			} else {
+				assert.Nil(t, got)
+			}
+		})
+	}
+}
+
+func TestRequireSelfOrPermissionFirstPartyOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+	}{
+		{name: "API key", principal: &auth.Principal{UserID: 1, EmailVerified: true, APIKeyID: 5}},
+		{name: "第三方應用程式", principal: &auth.Principal{UserID: 1, EmailVerified: true, ClientID: "client-1", Scopes: []string{auth.ScopeProfile}}},
+		{name: "代理登入", principal: &auth.Principal{UserID: 1, EmailVerified: true, ActorID: 2}},
+	}
+
+	phone := "0912345678"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.ContextWithPrincipal(context.Background(), tt.principal)
+			assert.ErrorIs(t, requireSelfOrPermission(ctx, 1, auth.PermMemberRead), errForbidden)
+			assert.Nil(t, privateMemberField(ctx, &model.Member{ID: "1"}, &phone))
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	assert.ErrorIs(t, requireScope(context.Background(), auth.ScopeEmail), errUnauthenticated)
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		allowed   bool
+	}{
+		{name: "第一方 token", principal: &auth.Principal{UserID: 1}, allowed: true},
+		{name: "API key", principal: &auth.Principal{UserID: 1, APIKeyID: 5}, allowed: true},
+		{name: "第三方應用程式有 email", principal: &auth.Principal{UserID: 1, ClientID: "client-1", Scopes: []string{auth.ScopeProfile, auth.ScopeEmail}}, allowed: true},
+		{name: "第三方應用程式只有 profile", principal: &auth.Principal{UserID: 1, ClientID: "client-1", Scopes: []string{auth.ScopeProfile}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.ContextWithPrincipal(context.Background(), tt.principal)
+			err := requireScope(ctx, auth.ScopeEmail)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			var gqlErr *gqlerror.Error
+			require.ErrorAs(t, err, &gqlErr)
+			assert.Equal(t, "應用程式未取得 email 授權", gqlErr.Message)
+			assert.Equal(t, "FORBIDDEN", gqlErr.Extensions["code"])
+		})
+	}
+}
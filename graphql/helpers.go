@@ -2,10 +2,17 @@ package graphql
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"member_API/auth"
 	"member_API/graphql/model"
 	"member_API/models"
+	"member_API/services"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // dbToModel converts DB Member to GraphQL model
@@ -19,13 +26,188 @@ func dbToModel(m models.Member) *model.Member {
 		s := formatTime(*m.LastModificationTime)
 		updated = &s
 	}
-	return &model.Member{
-		ID:        formatID(m.ID),
-		Name:      m.Name,
-		Email:     m.Email,
-		CreatedAt: created,
-		UpdatedAt: updated,
+	out := &model.Member{
+		ID:            formatID(m.ID),
+		Name:          m.Name,
+		Email:         m.Email,
+		EmailVerified: m.EmailVerifiedAt != nil,
+		Status:        services.EffectiveMemberStatus(&m, time.Now()),
+		StatusReason:  stringPtr(m.StatusReason),
+		Phone:         stringPtr(m.Phone),
+		AvatarURL:     stringPtr(m.AvatarURL),
+		Locale:        stringPtr(m.Locale),
+		Timezone:      stringPtr(m.Timezone),
+		CreatedAt:     created,
+		UpdatedAt:     updated,
+	}
+	if m.Birthday != nil {
+		birthday := m.Birthday.Format("2006-01-02")
+		out.Birthday = &birthday
+	}
+	if out.Status == models.MemberStatusSuspended && m.SuspendedUntil != nil {
+		until := formatTime(*m.SuspendedUntil)
+		out.SuspendedUntil = &until
+	}
+	return out
+}
+
+// memberStatusChangeDBToModel converts a status history entry to GraphQL model
+func memberStatusChangeDBToModel(c models.MemberStatusChange) *model.MemberStatusChange {
+	created := formatTime(c.CreationTime)
+	out := &model.MemberStatusChange{
+		ID:         formatID(c.ID),
+		FromStatus: c.FromStatus,
+		ToStatus:   c.ToStatus,
+		Reason:     stringPtr(c.Reason),
+		CreatedAt:  &created,
+	}
+	if c.SuspendedUntil != nil {
+		until := formatTime(*c.SuspendedUntil)
+		out.SuspendedUntil = &until
 	}
+	if c.ActorID != 0 {
+		actor := formatID(c.ActorID)
+		out.ActorID = &actor
+	}
+	return out
+}
+
+// pointsEntryDBToModel converts a points ledger entry to GraphQL model
+func pointsEntryDBToModel(e models.PointsLedgerEntry) *model.PointsEntry {
+	out := &model.PointsEntry{
+		ID:        formatID(e.ID),
+		Type:      e.Type,
+		Points:    int(e.Points),
+		Balance:   int(e.Balance),
+		Reason:    stringPtr(e.Reason),
+		Reference: stringPtr(e.Reference),
+		CreatedAt: formatTime(e.CreationTime),
+	}
+	if e.ExpiresAt != nil {
+		expires := formatTime(*e.ExpiresAt)
+		out.ExpiresAt = &expires
+	}
+	return out
+}
+
+// pointsSummaryToModel converts a points summary to GraphQL model
+func pointsSummaryToModel(s services.PointsSummary) *model.PointsSummary {
+	out := &model.PointsSummary{
+		Balance:        int(s.Balance),
+		ExpiringPoints: int(s.ExpiringPoints),
+	}
+	if s.NextExpiry != nil {
+		next := formatTime(*s.NextExpiry)
+		out.NextExpiry = &next
+	}
+	return out
+}
+
+// addressDBToModel converts a member address to GraphQL model
+func addressDBToModel(a models.MemberAddress) *model.Address {
+	return &model.Address{
+		ID:            formatID(a.ID),
+		Type:          a.Type,
+		Label:         stringPtr(a.Label),
+		RecipientName: a.RecipientName,
+		Phone:         stringPtr(a.Phone),
+		Country:       a.Country,
+		PostalCode:    stringPtr(a.PostalCode),
+		Region:        stringPtr(a.Region),
+		City:          a.City,
+		Line1:         a.Line1,
+		Line2:         stringPtr(a.Line2),
+		IsDefault:     a.IsDefault,
+	}
+}
+
+// addressInputFromModel converts the GraphQL address input to the service input
+func addressInputFromModel(input model.AddressInput) services.AddressInput {
+	return services.AddressInput{
+		Type:          input.Type,
+		Label:         ptrToString(input.Label),
+		RecipientName: input.RecipientName,
+		Phone:         ptrToString(input.Phone),
+		Country:       input.Country,
+		PostalCode:    ptrToString(input.PostalCode),
+		Region:        ptrToString(input.Region),
+		City:          input.City,
+		Line1:         input.Line1,
+		Line2:         ptrToString(input.Line2),
+		IsDefault:     input.IsDefault != nil && *input.IsDefault,
+	}
+}
+
+// preferencesDBToModel converts member preferences to GraphQL model
+func preferencesDBToModel(p models.MemberPreference) *model.MemberPreferences {
+	return &model.MemberPreferences{
+		MarketingEmail:   p.MarketingEmail,
+		MarketingSms:     p.MarketingSMS,
+		Newsletter:       p.Newsletter,
+		PreferredChannel: p.PreferredChannel,
+	}
+}
+
+// groupDBToModel converts DB MemberGroup to GraphQL model
+func groupDBToModel(g models.MemberGroup) *model.MemberGroup {
+	return &model.MemberGroup{
+		ID:          formatID(g.ID),
+		Name:        g.Name,
+		Description: stringPtr(g.Description),
+	}
+}
+
+// profileError returns field validation errors with extensions.fields so
+// clients can show them next to the inputs; other errors pass through.
+func profileError(err error) error {
+	var validationErr *services.ProfileValidationError
+	if errors.As(err, &validationErr) {
+		return &gqlerror.Error{
+			Message: validationErr.Error(),
+			Extensions: map[string]interface{}{
+				"code":   "BAD_USER_INPUT",
+				"fields": validationErr.Fields,
+			},
+		}
+	}
+	return err
+}
+
+// memberPage applies the member list defaults: 50 per page, at most 200
+func memberPage(limit, offset *int) (int, int) {
+	lim, off := 50, 0
+	if limit != nil && *limit > 0 {
+		lim = *limit
+		if lim > 200 {
+			lim = 200
+		}
+	}
+	if offset != nil && *offset > 0 {
+		off = *offset
+	}
+	return lim, off
+}
+
+// memberFilterFromModel converts the GraphQL member filter, parsing the date bounds
+func memberFilterFromModel(filter *model.MemberFilter) (services.MemberFilter, error) {
+	if filter == nil {
+		return services.MemberFilter{}, nil
+	}
+
+	out := services.MemberFilter{
+		Search: ptrToString(filter.Search),
+		Status: ptrToString(filter.Status),
+		Role:   ptrToString(filter.Role),
+		Group:  ptrToString(filter.Group),
+	}
+	var err error
+	if out.CreatedFrom, err = services.ParseDateBound(ptrToString(filter.CreatedFrom), false); err != nil {
+		return out, fmt.Errorf("created_from %w", err)
+	}
+	if out.CreatedTo, err = services.ParseDateBound(ptrToString(filter.CreatedTo), true); err != nil {
+		return out, fmt.Errorf("created_to %w", err)
+	}
+	return out, nil
 }
 
 // productDBToModel converts DB Product to GraphQL model
@@ -51,6 +233,53 @@ func productDBToModel(p models.Product) *model.Product {
 	}
 }
 
+// sessionDBToModel converts DB Session to GraphQL model
+func sessionDBToModel(s models.Session, currentID string) *model.Session {
+	created := formatTime(s.CreationTime)
+	lastSeen := formatTime(s.LastSeenAt)
+	expires := formatTime(s.ExpiresAt)
+	return &model.Session{
+		ID:         s.SessionID,
+		DeviceName: stringPtr(s.DeviceName),
+		IP:         stringPtr(s.IP),
+		UserAgent:  stringPtr(s.UserAgent),
+		CreatedAt:  &created,
+		LastSeenAt: &lastSeen,
+		ExpiresAt:  &expires,
+		Current:    s.SessionID == currentID,
+	}
+}
+
+// apiKeyDBToModel converts an API key record, never including the key itself
+func apiKeyDBToModel(k models.APIKey) *model.APIKey {
+	created := formatTime(k.CreationTime)
+	out := &model.APIKey{
+		ID:         formatID(k.ID),
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     strings.Fields(k.Scopes),
+		CreatedAt:  &created,
+		LastUsedIP: stringPtr(k.LastUsedIP),
+	}
+	if k.ExpiresAt != nil {
+		expires := formatTime(*k.ExpiresAt)
+		out.ExpiresAt = &expires
+	}
+	if k.LastUsedAt != nil {
+		lastUsed := formatTime(*k.LastUsedAt)
+		out.LastUsedAt = &lastUsed
+	}
+	return out
+}
+
+// currentSessionID returns the login the request belongs to
+func currentSessionID(ctx context.Context) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		return principal.SessionID
+	}
+	return ""
+}
+
 // formatTime formats time to RFC3339 string
 func formatTime(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
@@ -61,13 +290,13 @@ func formatID(id uint) string {
 	return strconv.FormatUint(uint64(id), 10)
 }
 
-// getUserIDFromContext extracts user ID from context
+// getUserIDFromContext extracts the authenticated user ID from context, 0 when anonymous
 func getUserIDFromContext(ctx context.Context) uint {
-	userID, ok := ctx.Value("user_id").(int64)
-	if !ok || userID <= 0 {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
 		return 0
 	}
-	return uint(userID)
+	return principal.UserID
 }
 
 // stringPtr converts string to *string pointer
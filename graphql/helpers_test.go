@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"member_API/graphql/model"
+	"member_API/models"
+	"member_API/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestDBToModelStatus(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		member models.Member
+		status string
+		until  bool
+	}{
+		{name: "未設定視為 active", member: models.Member{}, status: models.MemberStatusActive},
+		{name: "停權中", member: models.Member{Status: models.MemberStatusSuspended, StatusReason: "違規", SuspendedUntil: &future}, status: models.MemberStatusSuspended, until: true},
+		{name: "停權期滿", member: models.Member{Status: models.MemberStatusSuspended, SuspendedUntil: &past}, status: models.MemberStatusActive},
+		{name: "封鎖", member: models.Member{Status: models.MemberStatusBanned, StatusReason: "詐欺"}, status: models.MemberStatusBanned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := dbToModel(tt.member)
+			assert.Equal(t, tt.status, out.Status)
+			assert.Equal(t, stringPtr(tt.member.StatusReason), out.StatusReason)
+			if tt.until {
+				require.NotNil(t, out.SuspendedUntil)
+				assert.Equal(t, formatTime(future), *out.SuspendedUntil)
+			} else {
+				assert.Nil(t, out.SuspendedUntil)
+			}
+		})
+	}
+}
+
+func TestMemberStatusChangeDBToModel(t *testing.T) {
+	until := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	out := memberStatusChangeDBToModel(models.MemberStatusChange{
+		Base:           models.Base{ID: 3, CreationTime: until.Add(-24 * time.Hour)},
+		FromStatus:     models.MemberStatusActive,
+		ToStatus:       models.MemberStatusSuspended,
+		Reason:         "違規",
+		SuspendedUntil: &until,
+		ActorID:        1,
+	})
+
+	assert.Equal(t, "3", out.ID)
+	assert.Equal(t, "2026-12-31T00:00:00Z", *out.SuspendedUntil)
+	require.NotNil(t, out.ActorID)
+	assert.Equal(t, "1", *out.ActorID)
+
+	system := memberStatusChangeDBToModel(models.MemberStatusChange{ToStatus: models.MemberStatusActive})
+	assert.Nil(t, system.ActorID)
+	assert.Nil(t, system.Reason)
+}
+
+func TestPointsDBToModel(t *testing.T) {
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	earn := pointsEntryDBToModel(models.PointsLedgerEntry{
+		Base:      models.Base{ID: 7, CreationTime: expires.AddDate(-1, 0, 0)},
+		Type:      models.PointsEntryEarn,
+		Points:    100,
+		Balance:   250,
+		ExpiresAt: &expires,
+		Reference: "order-A1001",
+	})
+	assert.Equal(t, "7", earn.ID)
+	assert.Equal(t, 100, earn.Points)
+	assert.Equal(t, 250, earn.Balance)
+	require.NotNil(t, earn.ExpiresAt)
+	assert.Equal(t, "2027-01-01T00:00:00Z", *earn.ExpiresAt)
+	assert.Equal(t, "order-A1001", *earn.Reference)
+	assert.Nil(t, earn.Reason)
+
+	redeem := pointsEntryDBToModel(models.PointsLedgerEntry{Type: models.PointsEntryRedeem, Points: -50})
+	assert.Equal(t, -50, redeem.Points)
+	assert.Nil(t, redeem.ExpiresAt)
+
+	summary := pointsSummaryToModel(services.PointsSummary{Balance: 250, ExpiringPoints: 100, NextExpiry: &expires})
+	assert.Equal(t, 250, summary.Balance)
+	require.NotNil(t, summary.NextExpiry)
+	assert.Equal(t, "2027-01-01T00:00:00Z", *summary.NextExpiry)
+	assert.Nil(t, pointsSummaryToModel(services.PointsSummary{}).NextExpiry)
+}
+
+func TestDBToModelProfile(t *testing.T) {
+	birthday := time.Date(1990, 5, 20, 0, 0, 0, 0, time.UTC)
+	out := dbToModel(models.Member{Phone: "+886912345678", Birthday: &birthday, Locale: "zh-TW", Timezone: "Asia/Taipei"})
+
+	require.NotNil(t, out.Birthday)
+	assert.Equal(t, "1990-05-20", *out.Birthday)
+	assert.Equal(t, "+886912345678", *out.Phone)
+	assert.Equal(t, "zh-TW", *out.Locale)
+	assert.Equal(t, "Asia/Taipei", *out.Timezone)
+	assert.Nil(t, out.AvatarURL)
+
+	empty := dbToModel(models.Member{})
+	assert.Nil(t, empty.Birthday)
+	assert.Nil(t, empty.Phone)
+}
+
+func TestAddressInputFromModel(t *testing.T) {
+	isDefault := true
+	line2 := "89 樓"
+	in := addressInputFromModel(model.AddressInput{
+		Type:          models.AddressTypeShipping,
+		RecipientName: "張三",
+		Country:       "tw",
+		City:          "信義區",
+		Line1:         "信義路五段 7 號",
+		Line2:         &line2,
+		IsDefault:     &isDefault,
+	})
+
+	assert.Equal(t, "89 樓", in.Line2)
+	assert.Equal(t, "", in.Label)
+	assert.True(t, in.IsDefault)
+	assert.False(t, addressInputFromModel(model.AddressInput{}).IsDefault)
+}
+
+func TestProfileError(t *testing.T) {
+	err := profileError(&services.ProfileValidationError{Fields: map[string]string{"phone": "格式錯誤"}})
+	var gqlErr *gqlerror.Error
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, "BAD_USER_INPUT", gqlErr.Extensions["code"])
+	assert.Equal(t, map[string]string{"phone": "格式錯誤"}, gqlErr.Extensions["fields"])
+
+	other := errors.New("會員不存在")
+	assert.Equal(t, other, profileError(other))
+}
+
+func TestMemberPage(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name          string
+		limit, offset *int
+		wantLimit     int
+		wantOffset    int
+	}{
+		{name: "預設值", wantLimit: 50, wantOffset: 0},
+		{name: "指定分頁", limit: intPtr(20), offset: intPtr(40), wantLimit: 20, wantOffset: 40},
+		{name: "超過上限", limit: intPtr(1000), wantLimit: 200},
+		{name: "無效值使用預設", limit: intPtr(0), offset: intPtr(-5), wantLimit: 50, wantOffset: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, offset := memberPage(tt.limit, tt.offset)
+			assert.Equal(t, tt.wantLimit, limit)
+			assert.Equal(t, tt.wantOffset, offset)
+		})
+	}
+}
+
+func TestMemberFilterFromModel(t *testing.T) {
+	empty, err := memberFilterFromModel(nil)
+	require.NoError(t, err)
+	assert.Equal(t, services.MemberFilter{}, empty)
+
+	search, group, from, to := "Zhang", "vip", "2026-01-01", "2026-01-31"
+	filter, err := memberFilterFromModel(&model.MemberFilter{Search: &search, Group: &group, CreatedFrom: &from, CreatedTo: &to})
+	require.NoError(t, err)
+	assert.Equal(t, "Zhang", filter.Search)
+	assert.Equal(t, "vip", filter.Group)
+	require.NotNil(t, filter.CreatedFrom)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedFrom)
+	require.NotNil(t, filter.CreatedTo)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedTo, "只有日期時包含當天")
+
+	precise := "2026-01-31T12:00:00+08:00"
+	filter, err = memberFilterFromModel(&model.MemberFilter{CreatedTo: &precise})
+	require.NoError(t, err)
+	assert.True(t, filter.CreatedTo.Equal(time.Date(2026, 1, 31, 4, 0, 0, 0, time.UTC)))
+
+	invalid := "31/01/2026"
+	_, err = memberFilterFromModel(&model.MemberFilter{CreatedFrom: &invalid})
+	assert.Error(t, err)
+}
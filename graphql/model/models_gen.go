@@ -2,6 +2,71 @@
 
 package model
 
+type APIKey struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  *string  `json:"created_at,omitempty"`
+	ExpiresAt  *string  `json:"expires_at,omitempty"`
+	LastUsedAt *string  `json:"last_used_at,omitempty"`
+	LastUsedIP *string  `json:"last_used_ip,omitempty"`
+}
+
+type Address struct {
+	ID string `json:"id"`
+	// shipping or billing
+	Type          string  `json:"type"`
+	Label         *string `json:"label,omitempty"`
+	RecipientName string  `json:"recipient_name"`
+	Phone         *string `json:"phone,omitempty"`
+	// ISO 3166-1 alpha-2 country code
+	Country    string  `json:"country"`
+	PostalCode *string `json:"postal_code,omitempty"`
+	Region     *string `json:"region,omitempty"`
+	City       string  `json:"city"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+	IsDefault  bool    `json:"is_default"`
+}
+
+type AddressInput struct {
+	Type          string  `json:"type"`
+	Label         *string `json:"label,omitempty"`
+	RecipientName string  `json:"recipient_name"`
+	Phone         *string `json:"phone,omitempty"`
+	Country       string  `json:"country"`
+	PostalCode    *string `json:"postal_code,omitempty"`
+	Region        *string `json:"region,omitempty"`
+	City          string  `json:"city"`
+	Line1         string  `json:"line1"`
+	Line2         *string `json:"line2,omitempty"`
+	IsDefault     *bool   `json:"is_default,omitempty"`
+}
+
+type AuthPayload struct {
+	Token        string  `json:"token"`
+	RefreshToken string  `json:"refresh_token"`
+	ExpiresIn    int     `json:"expires_in"`
+	Member       *Member `json:"member"`
+}
+
+type ChangeMemberStatusInput struct {
+	// pending, active, suspended, banned or closed
+	Status string `json:"status"`
+	// Required for every status except active
+	Reason *string `json:"reason,omitempty"`
+	// RFC 3339 timestamp; required when suspending
+	SuspendedUntil *string `json:"suspended_until,omitempty"`
+}
+
+type CreateAPIKeyInput struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// RFC 3339 timestamp; the key never expires when omitted
+	ExpiresAt *string `json:"expires_at,omitempty"`
+}
+
 type CreateMemberInput struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
@@ -16,19 +81,131 @@ type CreateProductInput struct {
 	ProductStock       int     `json:"product_stock"`
 }
 
+// A newly created API key; key is only returned once
+type CreatedAPIKey struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"api_key"`
+}
+
 // GraphQL Schema for Member API.
 // This SDL mirrors the implemented queries in the Go resolvers.
 type Member struct {
-	ID        string  `json:"id"`
-	Name      string  `json:"name"`
-	Email     string  `json:"email"`
-	CreatedAt *string `json:"created_at,omitempty"`
-	UpdatedAt *string `json:"updated_at,omitempty"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Empty for third-party OAuth tokens that were not granted the email scope
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	// pending, active, suspended, banned or closed; an expired suspension reads as active
+	Status string `json:"status"`
+	// Reason for the current status (visible to the member and holders of member:read, null otherwise)
+	StatusReason   *string `json:"status_reason,omitempty"`
+	SuspendedUntil *string `json:"suspended_until,omitempty"`
+	// Visible to the member and holders of member:read, null otherwise
+	Phone *string `json:"phone,omitempty"`
+	// YYYY-MM-DD (visible to the member and holders of member:read, null otherwise)
+	Birthday  *string `json:"birthday,omitempty"`
+	AvatarURL *string `json:"avatar_url,omitempty"`
+	// BCP 47 language tag, e.g. zh-TW
+	Locale *string `json:"locale,omitempty"`
+	// IANA time zone name, e.g. Asia/Taipei
+	Timezone *string `json:"timezone,omitempty"`
+	// Shipping and billing addresses, defaults first (visible to the member and holders of member:read)
+	Addresses []*Address `json:"addresses"`
+	// Marketing and communication preferences (visible to the member and holders of member:read)
+	Preferences *MemberPreferences `json:"preferences"`
+	// Segments such as vip, wholesale or staff, ordered by name (visible to the member and holders of member:read)
+	Groups []*MemberGroup `json:"groups"`
+	// Loyalty points balance and the next batch to expire (visible to the member and holders of member:read)
+	Points    *PointsSummary `json:"points"`
+	CreatedAt *string        `json:"created_at,omitempty"`
+	UpdatedAt *string        `json:"updated_at,omitempty"`
+}
+
+type MemberFilter struct {
+	// Matches any part of the name or email, case-insensitive
+	Search *string `json:"search,omitempty"`
+	// pending, active, suspended, banned or closed; an expired suspension counts as active
+	Status *string `json:"status,omitempty"`
+	Role   *string `json:"role,omitempty"`
+	// Group name, case-insensitive
+	Group *string `json:"group,omitempty"`
+	// Created at or after; YYYY-MM-DD or RFC 3339
+	CreatedFrom *string `json:"created_from,omitempty"`
+	// Created before; a date alone includes the whole day
+	CreatedTo *string `json:"created_to,omitempty"`
+}
+
+type MemberGroup struct {
+	ID string `json:"id"`
+	// Lowercase letters, digits, - and _
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+type MemberPreferences struct {
+	MarketingEmail bool `json:"marketing_email"`
+	MarketingSms   bool `json:"marketing_sms"`
+	Newsletter     bool `json:"newsletter"`
+	// email, sms or none
+	PreferredChannel string `json:"preferred_channel"`
+}
+
+type MemberStatusChange struct {
+	ID             string  `json:"id"`
+	FromStatus     string  `json:"from_status"`
+	ToStatus       string  `json:"to_status"`
+	Reason         *string `json:"reason,omitempty"`
+	SuspendedUntil *string `json:"suspended_until,omitempty"`
+	ActorID        *string `json:"actor_id,omitempty"`
+	CreatedAt      *string `json:"created_at,omitempty"`
+}
+
+type MembersResponse struct {
+	Members []*Member `json:"members"`
+	Total   int       `json:"total"`
+	Limit   int       `json:"limit"`
+	Offset  int       `json:"offset"`
 }
 
 type Mutation struct {
 }
 
+type PointsEntry struct {
+	ID string `json:"id"`
+	// earn, redeem, expire or adjust
+	Type string `json:"type"`
+	// Positive for credits, negative for debits
+	Points int `json:"points"`
+	// Balance right after this entry
+	Balance   int     `json:"balance"`
+	ExpiresAt *string `json:"expires_at,omitempty"`
+	Reason    *string `json:"reason,omitempty"`
+	Reference *string `json:"reference,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type PointsHistory struct {
+	Points  *PointsSummary `json:"points"`
+	Entries []*PointsEntry `json:"entries"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+type PointsSummary struct {
+	Balance int `json:"balance"`
+	// Points in the next batch to expire, 0 when none
+	ExpiringPoints int     `json:"expiring_points"`
+	NextExpiry     *string `json:"next_expiry,omitempty"`
+}
+
+type PreferencesInput struct {
+	MarketingEmail   *bool   `json:"marketing_email,omitempty"`
+	MarketingSms     *bool   `json:"marketing_sms,omitempty"`
+	Newsletter       *bool   `json:"newsletter,omitempty"`
+	PreferredChannel *string `json:"preferred_channel,omitempty"`
+}
+
 type Product struct {
 	ID                 string  `json:"id"`
 	ProductName        string  `json:"product_name"`
@@ -47,12 +224,44 @@ type ProductsResponse struct {
 	Offset   int        `json:"offset"`
 }
 
+type ProfileDetailsInput struct {
+	Phone *string `json:"phone,omitempty"`
+	// YYYY-MM-DD
+	Birthday  *string `json:"birthday,omitempty"`
+	AvatarURL *string `json:"avatar_url,omitempty"`
+	Locale    *string `json:"locale,omitempty"`
+	Timezone  *string `json:"timezone,omitempty"`
+}
+
 type Query struct {
 }
 
+type RecordPointsInput struct {
+	// earn, redeem or adjust
+	Type string `json:"type"`
+	// Positive for earn and redeem; positive or negative for adjust
+	Points int     `json:"points"`
+	Reason *string `json:"reason,omitempty"`
+	// Caller key such as an order number, unique per member and type
+	Reference *string `json:"reference,omitempty"`
+}
+
+type Session struct {
+	ID         string  `json:"id"`
+	DeviceName *string `json:"device_name,omitempty"`
+	IP         *string `json:"ip,omitempty"`
+	UserAgent  *string `json:"user_agent,omitempty"`
+	CreatedAt  *string `json:"created_at,omitempty"`
+	LastSeenAt *string `json:"last_seen_at,omitempty"`
+	ExpiresAt  *string `json:"expires_at,omitempty"`
+	Current    bool    `json:"current"`
+}
+
 type UpdateMemberInput struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	// Required when members change their own email
+	CurrentPassword *string `json:"current_password,omitempty"`
 }
 
 type UpdateProductInput struct {
@@ -62,3 +271,10 @@ type UpdateProductInput struct {
 	ProductImage       *string  `json:"product_image,omitempty"`
 	ProductStock       *int     `json:"product_stock,omitempty"`
 }
+
+type UpdateProfileInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// Required when the email changes
+	CurrentPassword *string `json:"current_password,omitempty"`
+}
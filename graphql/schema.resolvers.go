@@ -7,61 +7,701 @@ package graphql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"member_API/auth"
 	"member_API/graphql/model"
 	"member_API/models"
 	"member_API/services"
 	"strconv"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-// CreateMember is the resolver for the createMember field.
-func (r *mutationResolver) CreateMember(ctx context.Context, input model.CreateMemberInput) (*model.Member, error) {
-	svc := services.NewMemberService(r.DB)
+// Email is the resolver for the email field.
+func (r *memberResolver) Email(ctx context.Context, obj *model.Member) (string, error) {
+	// 與 userinfo 相同，第三方應用程式需要 email scope 才能取得 email
+	if principal := auth.PrincipalFromContext(ctx); principal != nil && !principal.HasScope(auth.ScopeEmail) {
+		return "", nil
+	}
+	return obj.Email, nil
+}
+
+// StatusReason is the resolver for the status_reason field.
+func (r *memberResolver) StatusReason(ctx context.Context, obj *model.Member) (*string, error) {
+	return privateMemberField(ctx, obj, obj.StatusReason), nil
+}
+
+// Phone is the resolver for the phone field.
+func (r *memberResolver) Phone(ctx context.Context, obj *model.Member) (*string, error) {
+	return privateMemberField(ctx, obj, obj.Phone), nil
+}
+
+// Birthday is the resolver for the birthday field.
+func (r *memberResolver) Birthday(ctx context.Context, obj *model.Member) (*string, error) {
+	return privateMemberField(ctx, obj, obj.Birthday), nil
+}
+
+// Addresses is the resolver for the addresses field.
+func (r *memberResolver) Addresses(ctx context.Context, obj *model.Member) ([]*model.Address, error) {
+	memberID, err := strconv.ParseUint(obj.ID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+	if err := requireSelfOrPermission(ctx, uint(memberID), auth.PermMemberRead); err != nil {
+		return nil, err
+	}
+
+	addresses, err := services.NewProfileService(r.DB.WithContext(ctx)).ListAddresses(uint(memberID))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.Address, len(addresses))
+	for i, a := range addresses {
+		out[i] = addressDBToModel(a)
+	}
+	return out, nil
+}
+
+// Preferences is the resolver for the preferences field.
+func (r *memberResolver) Preferences(ctx context.Context, obj *model.Member) (*model.MemberPreferences, error) {
+	memberID, err := strconv.ParseUint(obj.ID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+	if err := requireSelfOrPermission(ctx, uint(memberID), auth.PermMemberRead); err != nil {
+		return nil, err
+	}
+
+	preference, err := services.NewProfileService(r.DB.WithContext(ctx)).GetPreferences(uint(memberID))
+	if err != nil {
+		return nil, err
+	}
+	return preferencesDBToModel(*preference), nil
+}
+
+// Groups is the resolver for the groups field.
+func (r *memberResolver) Groups(ctx context.Context, obj *model.Member) ([]*model.MemberGroup, error) {
+	memberID, err := strconv.ParseUint(obj.ID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+	if err := requireSelfOrPermission(ctx, uint(memberID), auth.PermMemberRead); err != nil {
+		return nil, err
+	}
+
+	groups, err := services.NewGroupService(r.DB.WithContext(ctx)).MemberGroups(uint(memberID))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.MemberGroup, len(groups))
+	for i, g := range groups {
+		out[i] = groupDBToModel(g)
+	}
+	return out, nil
+}
+
+// Points is the resolver for the points field.
+func (r *memberResolver) Points(ctx context.Context, obj *model.Member) (*model.PointsSummary, error) {
+	memberID, err := strconv.ParseUint(obj.ID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+	if err := requireSelfOrPermission(ctx, uint(memberID), auth.PermMemberRead); err != nil {
+		return nil, err
+	}
+
+	summary, err := services.NewPointsService(r.DB.WithContext(ctx)).Summary(uint(memberID))
+	if err != nil {
+		return nil, err
+	}
+	return pointsSummaryToModel(*summary), nil
+}
+
+// CreateMember is the resolver for the createMember field.
+func (r *mutationResolver) CreateMember(ctx context.Context, input model.CreateMemberInput) (*model.Member, error) {
+	if err := requirePermission(ctx, auth.PermMemberWrite); err != nil {
+		return nil, err
+	}
+
+	svc := services.NewMemberService(r.DB)
+
+	// 從 context 取得使用者 ID（如果沒有則使用 0 表示系統建立）
+	creatorId := getUserIDFromContext(ctx)
+
+	member, err := svc.CreateMember(input.Name, input.Email, input.Password, creatorId)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbToModel(*member), nil
+}
+
+// UpdateMember is the resolver for the updateMember field.
+func (r *mutationResolver) UpdateMember(ctx context.Context, id string, input model.UpdateMemberInput) (*model.Member, error) {
+	svc := services.NewMemberService(r.DB)
+
+	memberID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	// 會員修改自己的資料與 updateProfile 相同：僅限第一方 token，變更 email 需要目前密碼
+	if uint64(getUserIDFromContext(ctx)) == memberID {
+		if err := requireFirstParty(ctx); err != nil {
+			return nil, err
+		}
+		var currentPassword string
+		if input.CurrentPassword != nil {
+			currentPassword = *input.CurrentPassword
+		}
+		member, err := svc.UpdateProfile(uint(memberID), input.Name, input.Email, currentPassword)
+		if err != nil {
+			return nil, err
+		}
+		return dbToModel(*member), nil
+	}
+
+	// 修改他人需要 member:write 權限
+	if err := requirePermission(ctx, auth.PermMemberWrite); err != nil {
+		return nil, err
+	}
+
+	// 從 context 取得使用者 ID
+	modifierId := getUserIDFromContext(ctx)
+
+	member, err := svc.UpdateMember(uint(memberID), input.Name, input.Email, modifierId)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbToModel(*member), nil
+}
+
+// DeleteMember is the resolver for the deleteMember field.
+func (r *mutationResolver) DeleteMember(ctx context.Context, id string) (bool, error) {
+	if err := requirePermission(ctx, auth.PermMemberDelete); err != nil {
+		return false, err
+	}
+
+	svc := services.NewMemberService(r.DB)
+
+	memberID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return false, fmt.Errorf("無效的會員 ID")
+	}
+
+	// 從 context 取得使用者 ID
+	deleterId := getUserIDFromContext(ctx)
+
+	if err := svc.DeleteMember(uint(memberID), deleterId); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RestoreMember is the resolver for the restoreMember field.
+func (r *mutationResolver) RestoreMember(ctx context.Context, id string) (*model.Member, error) {
+	if err := requirePermission(ctx, auth.PermMemberDelete); err != nil {
+		return nil, err
+	}
+
+	memberID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	svc := services.NewTrashService(r.DB.WithContext(ctx))
+	member, err := svc.RestoreMember(uint(memberID), getUserIDFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return dbToModel(*member), nil
+}
+
+// UpdateProfile is the resolver for the updateProfile field.
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input model.UpdateProfileInput) (*model.Member, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+
+	svc := services.NewMemberService(r.DB.WithContext(ctx))
+
+	var currentPassword string
+	if input.CurrentPassword != nil {
+		currentPassword = *input.CurrentPassword
+	}
+
+	member, err := svc.UpdateProfile(getUserIDFromContext(ctx), input.Name, input.Email, currentPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbToModel(*member), nil
+}
+
+// ChangePassword is the resolver for the changePassword field.
+func (r *mutationResolver) ChangePassword(ctx context.Context, currentPassword string, newPassword string) (bool, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return false, err
+	}
+
+	svc := services.NewMemberService(r.DB.WithContext(ctx))
+	if err := svc.ChangePassword(getUserIDFromContext(ctx), currentPassword, newPassword, currentSessionID(ctx)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UnlockMemberLogin is the resolver for the unlockMemberLogin field.
+func (r *mutationResolver) UnlockMemberLogin(ctx context.Context, id string) (bool, error) {
+	if err := requirePermission(ctx, auth.PermMemberWrite); err != nil {
+		return false, err
+	}
+
+	memberID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return false, fmt.Errorf("無效的會員 ID")
+	}
+
+	svc := services.NewMemberService(r.DB.WithContext(ctx))
+	if err := svc.UnlockLogin(ctx, uint(memberID), getUserIDFromContext(ctx)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ChangeMemberStatus is the resolver for the changeMemberStatus field.
+func (r *mutationResolver) ChangeMemberStatus(ctx context.Context, memberID string, input model.ChangeMemberStatusInput) (*model.Member, error) {
+	if err := requirePermission(ctx, auth.PermMemberWrite); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseUint(memberID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	var suspendedUntil *time.Time
+	if input.SuspendedUntil != nil {
+		t, err := time.Parse(time.RFC3339, *input.SuspendedUntil)
+		if err != nil {
+			return nil, fmt.Errorf("suspended_until 必須為 RFC 3339 格式")
+		}
+		suspendedUntil = &t
+	}
+
+	svc := services.NewMemberStatusService(r.DB.WithContext(ctx))
+	member, err := svc.ChangeStatus(uint(id), services.MemberStatusInput{
+		Status:         input.Status,
+		Reason:         ptrToString(input.Reason),
+		SuspendedUntil: suspendedUntil,
+	}, getUserIDFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return dbToModel(*member), nil
+}
+
+// EraseMember is the resolver for the eraseMember field.
+func (r *mutationResolver) EraseMember(ctx context.Context, id string, reason string) (*model.Member, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, auth.PermPrivacyManage); err != nil {
+		return nil, err
+	}
+
+	memberID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	svc := services.NewPrivacyService(r.DB.WithContext(ctx))
+	member, err := svc.Erase(uint(memberID), getUserIDFromContext(ctx), reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbToModel(*member), nil
+}
+
+// MergeMember is the resolver for the mergeMember field.
+func (r *mutationResolver) MergeMember(ctx context.Context, sourceID string, targetID string, reason string) (*model.Member, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, auth.PermMemberMerge); err != nil {
+		return nil, err
+	}
+
+	source, err := strconv.ParseUint(sourceID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+	target, err := strconv.ParseUint(targetID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	db := r.DB.WithContext(ctx)
+	if _, err := services.NewMemberMergeService(db).Merge(uint(source), uint(target), getUserIDFromContext(ctx), reason); err != nil {
+		return nil, err
+	}
+	member, err := services.NewMemberService(db).GetMemberByID(uint(target))
+	if err != nil {
+		return nil, err
+	}
+
+	return dbToModel(*member), nil
+}
+
+// SetMemberGroups is the resolver for the setMemberGroups field.
+func (r *mutationResolver) SetMemberGroups(ctx context.Context, id string, groups []string) (*model.Member, error) {
+	if err := requirePermission(ctx, auth.PermGroupManage); err != nil {
+		return nil, err
+	}
+
+	memberID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	member, err := services.NewGroupService(r.DB.WithContext(ctx)).SetMemberGroups(uint(memberID), groups, getUserIDFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return dbToModel(*member), nil
+}
+
+// RecordPoints is the resolver for the recordPoints field.
+func (r *mutationResolver) RecordPoints(ctx context.Context, memberID string, input model.RecordPointsInput) (*model.PointsEntry, error) {
+	if err := requirePermission(ctx, auth.PermPointsManage); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseUint(memberID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	svc := services.NewPointsService(r.DB.WithContext(ctx))
+	entry, err := svc.Record(uint(id), services.PointsEntryInput{
+		Type:      input.Type,
+		Points:    int64(input.Points),
+		Reason:    ptrToString(input.Reason),
+		Reference: ptrToString(input.Reference),
+		ActorID:   getUserIDFromContext(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pointsEntryDBToModel(*entry), nil
+}
+
+// UpdateProfileDetails is the resolver for the updateProfileDetails field.
+func (r *mutationResolver) UpdateProfileDetails(ctx context.Context, input model.ProfileDetailsInput) (*model.Member, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+
+	svc := services.NewProfileService(r.DB.WithContext(ctx))
+	member, err := svc.UpdateDetails(getUserIDFromContext(ctx), services.ProfileInput{
+		Phone:     input.Phone,
+		Birthday:  input.Birthday,
+		AvatarURL: input.AvatarURL,
+		Locale:    input.Locale,
+		Timezone:  input.Timezone,
+	})
+	if err != nil {
+		return nil, profileError(err)
+	}
+
+	return dbToModel(*member), nil
+}
+
+// AddAddress is the resolver for the addAddress field.
+func (r *mutationResolver) AddAddress(ctx context.Context, input model.AddressInput) (*model.Address, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+
+	svc := services.NewProfileService(r.DB.WithContext(ctx))
+	address, err := svc.CreateAddress(getUserIDFromContext(ctx), addressInputFromModel(input))
+	if err != nil {
+		return nil, profileError(err)
+	}
+
+	return addressDBToModel(*address), nil
+}
+
+// UpdateAddress is the resolver for the updateAddress field.
+func (r *mutationResolver) UpdateAddress(ctx context.Context, id string, input model.AddressInput) (*model.Address, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+
+	addressID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的地址 ID")
+	}
+
+	svc := services.NewProfileService(r.DB.WithContext(ctx))
+	address, err := svc.UpdateAddress(getUserIDFromContext(ctx), uint(addressID), addressInputFromModel(input))
+	if err != nil {
+		return nil, profileError(err)
+	}
+
+	return addressDBToModel(*address), nil
+}
+
+// SetDefaultAddress is the resolver for the setDefaultAddress field.
+func (r *mutationResolver) SetDefaultAddress(ctx context.Context, id string) (*model.Address, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+
+	addressID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的地址 ID")
+	}
+
+	svc := services.NewProfileService(r.DB.WithContext(ctx))
+	address, err := svc.SetDefaultAddress(getUserIDFromContext(ctx), uint(addressID))
+	if err != nil {
+		return nil, err
+	}
+
+	return addressDBToModel(*address), nil
+}
+
+// DeleteAddress is the resolver for the deleteAddress field.
+func (r *mutationResolver) DeleteAddress(ctx context.Context, id string) (bool, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return false, err
+	}
+
+	addressID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return false, fmt.Errorf("無效的地址 ID")
+	}
+
+	svc := services.NewProfileService(r.DB.WithContext(ctx))
+	if err := svc.DeleteAddress(getUserIDFromContext(ctx), uint(addressID)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdatePreferences is the resolver for the updatePreferences field.
+func (r *mutationResolver) UpdatePreferences(ctx context.Context, input model.PreferencesInput) (*model.MemberPreferences, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
 
-	// 從 context 取得使用者 ID（如果沒有則使用 0 表示系統建立）
-	creatorId := getUserIDFromContext(ctx)
+	svc := services.NewProfileService(r.DB.WithContext(ctx))
+	preference, err := svc.UpdatePreferences(getUserIDFromContext(ctx), services.PreferencesInput{
+		MarketingEmail:   input.MarketingEmail,
+		MarketingSMS:     input.MarketingSms,
+		Newsletter:       input.Newsletter,
+		PreferredChannel: input.PreferredChannel,
+	})
+	if err != nil {
+		return nil, profileError(err)
+	}
 
-	member, err := svc.CreateMember(input.Name, input.Email, input.Password, creatorId)
+	return preferencesDBToModel(*preference), nil
+}
+
+// RefreshToken is the resolver for the refreshToken field.
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*model.AuthPayload, error) {
+	svc := services.NewTokenService(r.DB.WithContext(ctx))
+
+	pair, member, err := svc.Refresh(refreshToken, services.SessionMetadata{})
 	if err != nil {
 		return nil, err
 	}
 
-	return dbToModel(*member), nil
+	return &model.AuthPayload{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    int(pair.ExpiresIn),
+		Member:       dbToModel(*member),
+	}, nil
 }
 
-// UpdateMember is the resolver for the updateMember field.
-func (r *mutationResolver) UpdateMember(ctx context.Context, id string, input model.UpdateMemberInput) (*model.Member, error) {
-	svc := services.NewMemberService(r.DB)
+// Logout is the resolver for the logout field.
+func (r *mutationResolver) Logout(ctx context.Context, refreshToken string) (bool, error) {
+	svc := services.NewTokenService(r.DB.WithContext(ctx))
 
-	memberID, err := strconv.ParseUint(id, 10, 32)
+	if err := svc.Revoke(refreshToken); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ForgotPassword is the resolver for the forgotPassword field.
+func (r *mutationResolver) ForgotPassword(ctx context.Context, email string) (bool, error) {
+	svc := services.NewPasswordResetService(r.DB.WithContext(ctx))
+
+	if err := svc.RequestReset(ctx, email); err != nil {
+		return false, fmt.Errorf("重設密碼申請失敗，請稍後再試")
+	}
+
+	return true, nil
+}
+
+// ResetPassword is the resolver for the resetPassword field.
+func (r *mutationResolver) ResetPassword(ctx context.Context, token string, newPassword string) (bool, error) {
+	svc := services.NewPasswordResetService(r.DB.WithContext(ctx))
+
+	if err := svc.ResetPassword(token, newPassword); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// VerifyEmail is the resolver for the verifyEmail field.
+func (r *mutationResolver) VerifyEmail(ctx context.Context, token string) (bool, error) {
+	svc := services.NewEmailVerificationService(r.DB.WithContext(ctx))
+
+	if _, err := svc.Verify(token); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ResendVerificationEmail is the resolver for the resendVerificationEmail field.
+func (r *mutationResolver) ResendVerificationEmail(ctx context.Context) (bool, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return false, err
+	}
+
+	svc := services.NewEmailVerificationService(r.DB.WithContext(ctx))
+
+	if err := svc.ResendVerification(ctx, getUserIDFromContext(ctx)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RevokeSession is the resolver for the revokeSession field.
+func (r *mutationResolver) RevokeSession(ctx context.Context, id string) (bool, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return false, err
+	}
+
+	svc := services.NewSessionService(r.DB.WithContext(ctx))
+
+	if err := svc.Revoke(getUserIDFromContext(ctx), id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RevokeAllSessions is the resolver for the revokeAllSessions field.
+func (r *mutationResolver) RevokeAllSessions(ctx context.Context, keepCurrent *bool) (int, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return 0, err
+	}
+
+	var keep string
+	if keepCurrent != nil && *keepCurrent {
+		keep = currentSessionID(ctx)
+	}
+
+	svc := services.NewSessionService(r.DB.WithContext(ctx))
+	count, err := svc.RevokeAll(getUserIDFromContext(ctx), keep)
 	if err != nil {
-		return nil, fmt.Errorf("無效的會員 ID")
+		return 0, err
 	}
 
-	// 從 context 取得使用者 ID
-	modifierId := getUserIDFromContext(ctx)
+	return int(count), nil
+}
 
-	member, err := svc.UpdateMember(uint(memberID), input.Name, input.Email, modifierId)
+// RevokeMemberSessions is the resolver for the revokeMemberSessions field.
+func (r *mutationResolver) RevokeMemberSessions(ctx context.Context, memberID string) (int, error) {
+	if err := requirePermission(ctx, auth.PermMemberWrite); err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.ParseUint(memberID, 10, 32)
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("無效的會員 ID")
 	}
 
-	return dbToModel(*member), nil
+	svc := services.NewSessionService(r.DB.WithContext(ctx))
+	count, err := svc.RevokeAll(uint(id), "")
+	if err != nil {
+		return 0, err
+	}
+
+	services.NewAuditService(r.DB.WithContext(ctx)).TryRecord(services.AuditEntry{
+		Action:   services.AuditSessionsRevoked,
+		MemberID: uint(id),
+		ActorID:  getUserIDFromContext(ctx),
+	})
+
+	return int(count), nil
 }
 
-// DeleteMember is the resolver for the deleteMember field.
-func (r *mutationResolver) DeleteMember(ctx context.Context, id string) (bool, error) {
-	svc := services.NewMemberService(r.DB)
+// CreateAPIKey is the resolver for the createApiKey field.
+func (r *mutationResolver) CreateAPIKey(ctx context.Context, input model.CreateAPIKeyInput) (*model.CreatedAPIKey, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
 
-	memberID, err := strconv.ParseUint(id, 10, 32)
+	var expiresAt *time.Time
+	if input.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *input.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("expires_at 必須為 RFC 3339 格式")
+		}
+		expiresAt = &t
+	}
+
+	memberID := getUserIDFromContext(ctx)
+	svc := services.NewAPIKeyService(r.DB.WithContext(ctx))
+	key, rawKey, err := svc.Create(memberID, services.APIKeyInput{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: expiresAt,
+	}, memberID)
 	if err != nil {
-		return false, fmt.Errorf("無效的會員 ID")
+		return nil, err
 	}
 
-	// 從 context 取得使用者 ID
-	deleterId := getUserIDFromContext(ctx)
+	return &model.CreatedAPIKey{Key: rawKey, APIKey: apiKeyDBToModel(*key)}, nil
+}
 
-	if err := svc.DeleteMember(uint(memberID), deleterId); err != nil {
+// RevokeAPIKey is the resolver for the revokeApiKey field.
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return false, err
+	}
+
+	keyID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return false, fmt.Errorf("無效的 API key ID")
+	}
+
+	memberID := getUserIDFromContext(ctx)
+	svc := services.NewAPIKeyService(r.DB.WithContext(ctx))
+	if err := svc.Revoke(memberID, uint(keyID), memberID); err != nil {
 		return false, err
 	}
 
@@ -70,6 +710,10 @@ func (r *mutationResolver) DeleteMember(ctx context.Context, id string) (bool, e
 
 // CreateProduct is the resolver for the createProduct field.
 func (r *mutationResolver) CreateProduct(ctx context.Context, input model.CreateProductInput) (*model.Product, error) {
+	if err := requirePermission(ctx, auth.PermProductWrite); err != nil {
+		return nil, err
+	}
+
 	if r.DB == nil {
 		return nil, fmt.Errorf("database connection not configured")
 	}
@@ -96,6 +740,10 @@ func (r *mutationResolver) CreateProduct(ctx context.Context, input model.Create
 
 // UpdateProduct is the resolver for the updateProduct field.
 func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, input model.UpdateProductInput) (*model.Product, error) {
+	if err := requirePermission(ctx, auth.PermProductWrite); err != nil {
+		return nil, err
+	}
+
 	if r.DB == nil {
 		return nil, fmt.Errorf("database connection not configured")
 	}
@@ -106,7 +754,7 @@ func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, input m
 	}
 
 	var product models.Product
-	if err := r.DB.Where("is_deleted = ?", false).First(&product, productID).Error; err != nil {
+	if err := r.DB.Scopes(models.NotDeleted).First(&product, productID).Error; err != nil {
 		return nil, fmt.Errorf("product not found")
 	}
 
@@ -145,6 +793,10 @@ func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, input m
 
 // DeleteProduct is the resolver for the deleteProduct field.
 func (r *mutationResolver) DeleteProduct(ctx context.Context, id string) (bool, error) {
+	if err := requirePermission(ctx, auth.PermProductWrite); err != nil {
+		return false, err
+	}
+
 	if r.DB == nil {
 		return false, fmt.Errorf("database connection not configured")
 	}
@@ -155,40 +807,79 @@ func (r *mutationResolver) DeleteProduct(ctx context.Context, id string) (bool,
 	}
 
 	var product models.Product
-	if err := r.DB.Where("is_deleted = ?", false).First(&product, productID).Error; err != nil {
+	if err := r.DB.Scopes(models.NotDeleted).First(&product, productID).Error; err != nil {
 		return false, fmt.Errorf("product not found")
 	}
 
-	if err := r.DB.Model(&product).Updates(map[string]interface{}{"is_deleted": true}).Error; err != nil {
+	if err := r.DB.Model(&product).Updates(models.SoftDeleteColumns(getUserIDFromContext(ctx), time.Now())).Error; err != nil {
 		return false, err
 	}
 
 	return true, nil
 }
 
+// RestoreProduct is the resolver for the restoreProduct field.
+func (r *mutationResolver) RestoreProduct(ctx context.Context, id string) (*model.Product, error) {
+	if err := requirePermission(ctx, auth.PermProductWrite); err != nil {
+		return nil, err
+	}
+
+	if r.DB == nil {
+		return nil, fmt.Errorf("database connection not configured")
+	}
+
+	productID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
+	svc := services.NewTrashService(r.DB.WithContext(ctx))
+	product, err := svc.RestoreProduct(uint(productID), getUserIDFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return productDBToModel(*product), nil
+}
+
 // Member is the resolver for the member field.
 func (r *queryResolver) Member(ctx context.Context, id string) (*model.Member, error) {
 	if r.DB == nil {
 		return nil, nil
 	}
+	memberID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+	// 會員可查詢自己（第三方應用程式需要 profile scope），查詢其他會員需要 member:read
+	if self := getUserIDFromContext(ctx); self == 0 || uint64(self) != memberID {
+		if err := requirePermission(ctx, auth.PermMemberRead); err != nil {
+			return nil, err
+		}
+	} else if err := requireScope(ctx, auth.ScopeProfile); err != nil {
+		return nil, err
+	}
 	var m models.Member
-	if err := r.DB.First(&m, id).Error; err != nil {
-		return nil, nil
+	if err := r.DB.WithContext(ctx).Scopes(models.NotDeleted).First(&m, memberID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
 	return dbToModel(m), nil
 }
 
 // Members is the resolver for the members field.
-func (r *queryResolver) Members(ctx context.Context, limit *int) ([]*model.Member, error) {
+func (r *queryResolver) Members(ctx context.Context, limit *int, offset *int) ([]*model.Member, error) {
+	if err := requirePermission(ctx, auth.PermMemberRead); err != nil {
+		return nil, err
+	}
 	if r.DB == nil {
 		return []*model.Member{}, nil
 	}
-	lim := 50
-	if limit != nil && *limit > 0 {
-		lim = *limit
-	}
-	var rows []models.Member
-	if err := r.DB.Select("id", "name", "email", "created_at", "updated_at").Limit(lim).Find(&rows).Error; err != nil {
+	lim, off := memberPage(limit, offset)
+	rows, _, err := services.NewMemberService(r.DB.WithContext(ctx)).ListMembers(services.MemberFilter{}, "", lim, off)
+	if err != nil {
 		return nil, err
 	}
 	out := make([]*model.Member, len(rows))
@@ -198,15 +889,56 @@ func (r *queryResolver) Members(ctx context.Context, limit *int) ([]*model.Membe
 	return out, nil
 }
 
+// SearchMembers is the resolver for the searchMembers field.
+func (r *queryResolver) SearchMembers(ctx context.Context, filter *model.MemberFilter, sort *string, limit *int, offset *int) (*model.MembersResponse, error) {
+	if err := requirePermission(ctx, auth.PermMemberRead); err != nil {
+		return nil, err
+	}
+	lim, off := memberPage(limit, offset)
+	if r.DB == nil {
+		return &model.MembersResponse{Members: []*model.Member{}, Limit: lim, Offset: off}, nil
+	}
+
+	memberFilter, err := memberFilterFromModel(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := services.NewMemberService(r.DB.WithContext(ctx))
+	rows, total, err := svc.ListMembers(memberFilter, ptrToString(sort), lim, off)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.Member, len(rows))
+	for i, m := range rows {
+		out[i] = dbToModel(m)
+	}
+	return &model.MembersResponse{
+		Members: out,
+		Total:   int(total),
+		Limit:   lim,
+		Offset:  off,
+	}, nil
+}
+
 // Product is the resolver for the product field.
 func (r *queryResolver) Product(ctx context.Context, id string) (*model.Product, error) {
 	if r.DB == nil {
 		return nil, nil
 	}
 
+	productID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
 	var product models.Product
-	if err := r.DB.Where("is_deleted = ?", false).First(&product, id).Error; err != nil {
-		return nil, nil
+	if err := r.DB.WithContext(ctx).Scopes(models.NotDeleted).First(&product, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
 	return productDBToModel(product), nil
@@ -241,12 +973,12 @@ func (r *queryResolver) Products(ctx context.Context, limit *int, offset *int) (
 	var total int64
 
 	// Get total count
-	if err := r.DB.Model(&models.Product{}).Where("is_deleted = ?", false).Count(&total).Error; err != nil {
+	if err := r.DB.Model(&models.Product{}).Scopes(models.NotDeleted).Count(&total).Error; err != nil {
 		return nil, err
 	}
 
 	// Get products
-	if err := r.DB.Where("is_deleted = ?", false).
+	if err := r.DB.Scopes(models.NotDeleted).
 		Order("sort ASC, id DESC").
 		Limit(lim).
 		Offset(off).
@@ -267,11 +999,161 @@ func (r *queryResolver) Products(ctx context.Context, limit *int, offset *int) (
 	}, nil
 }
 
+// Sessions is the resolver for the sessions field.
+func (r *queryResolver) Sessions(ctx context.Context) ([]*model.Session, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+
+	svc := services.NewSessionService(r.DB.WithContext(ctx))
+
+	sessions, err := svc.List(getUserIDFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	current := currentSessionID(ctx)
+	out := make([]*model.Session, len(sessions))
+	for i, s := range sessions {
+		out[i] = sessionDBToModel(s, current)
+	}
+	return out, nil
+}
+
+// MemberSessions is the resolver for the memberSessions field.
+func (r *queryResolver) MemberSessions(ctx context.Context, memberID string) ([]*model.Session, error) {
+	if err := requirePermission(ctx, auth.PermMemberWrite); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseUint(memberID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	svc := services.NewSessionService(r.DB.WithContext(ctx))
+	sessions, err := svc.List(uint(id))
+	if err != nil {
+		return nil, err
+	}
+
+	current := currentSessionID(ctx)
+	out := make([]*model.Session, len(sessions))
+	for i, s := range sessions {
+		out[i] = sessionDBToModel(s, current)
+	}
+	return out, nil
+}
+
+// MemberStatusHistory is the resolver for the memberStatusHistory field.
+func (r *queryResolver) MemberStatusHistory(ctx context.Context, memberID string) ([]*model.MemberStatusChange, error) {
+	if err := requirePermission(ctx, auth.PermMemberRead); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseUint(memberID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("無效的會員 ID")
+	}
+
+	svc := services.NewMemberStatusService(r.DB.WithContext(ctx))
+	changes, err := svc.History(uint(id))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.MemberStatusChange, len(changes))
+	for i, change := range changes {
+		out[i] = memberStatusChangeDBToModel(change)
+	}
+	return out, nil
+}
+
+// Groups is the resolver for the groups field.
+func (r *queryResolver) Groups(ctx context.Context) ([]*model.MemberGroup, error) {
+	if err := requirePermission(ctx, auth.PermMemberRead); err != nil {
+		return nil, err
+	}
+
+	groups, err := services.NewGroupService(r.DB.WithContext(ctx)).ListGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.MemberGroup, len(groups))
+	for i, g := range groups {
+		out[i] = groupDBToModel(g.Group)
+	}
+	return out, nil
+}
+
+// PointsHistory is the resolver for the pointsHistory field.
+func (r *queryResolver) PointsHistory(ctx context.Context, memberID *string, limit *int, offset *int) (*model.PointsHistory, error) {
+	id := getUserIDFromContext(ctx)
+	if memberID != nil {
+		parsed, err := strconv.ParseUint(*memberID, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("無效的會員 ID")
+		}
+		id = uint(parsed)
+	}
+	if err := requireSelfOrPermission(ctx, id, auth.PermMemberRead); err != nil {
+		return nil, err
+	}
+
+	lim, off := memberPage(limit, offset)
+	svc := services.NewPointsService(r.DB.WithContext(ctx))
+	summary, err := svc.Summary(id)
+	if err != nil {
+		return nil, err
+	}
+	entries, total, err := svc.History(id, lim, off)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.PointsEntry, len(entries))
+	for i, e := range entries {
+		out[i] = pointsEntryDBToModel(e)
+	}
+	return &model.PointsHistory{
+		Points:  pointsSummaryToModel(*summary),
+		Entries: out,
+		Total:   int(total),
+		Limit:   lim,
+		Offset:  off,
+	}, nil
+}
+
+// APIKeys is the resolver for the apiKeys field.
+func (r *queryResolver) APIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	if err := requireFirstParty(ctx); err != nil {
+		return nil, err
+	}
+
+	svc := services.NewAPIKeyService(r.DB.WithContext(ctx))
+
+	keys, err := svc.List(getUserIDFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.APIKey, len(keys))
+	for i, k := range keys {
+		out[i] = apiKeyDBToModel(k)
+	}
+	return out, nil
+}
+
+// Member returns MemberResolver implementation.
+func (r *Resolver) Member() MemberResolver { return &memberResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+type memberResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
@@ -21,16 +21,23 @@ func SetupGraphQL(db *gorm.DB) error {
 
 	log.Println("[GraphQL] Setting up schema and handler...")
 	resolver := NewResolver(db)
-	schema := NewExecutableSchema(Config{Resolvers: resolver})
+	schema := NewExecutableSchema(Config{
+		Resolvers: resolver,
+		Directives: DirectiveRoot{
+			Auth:    authDirective,
+			HasRole: hasRoleDirective,
+		},
+	})
 	server := handler.NewDefaultServer(schema)
 
-	// Single endpoint handler: GET -> Playground, others -> GraphQL server
+	// Single endpoint handler: GET -> Playground, others -> GraphQL server (with Bearer auth)
+	authenticated := authMiddleware(server)
 	gqlHTTPHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			playground.Handler("GraphQL", "/graphql").ServeHTTP(w, r)
 			return
 		}
-		server.ServeHTTP(w, r)
+		authenticated.ServeHTTP(w, r)
 	})
 	log.Println("[GraphQL] Handler initialized successfully!")
 	return nil
@@ -0,0 +1,97 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message 一封待寄出的純文字郵件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender 郵件寄送介面，正式環境可實作 SMTP 或第三方服務
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender 將郵件內容輸出到日誌，適合本地開發
+type LogSender struct {
+	From string
+}
+
+// Send 將郵件寫入日誌
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("[Mail] from=%s to=%s subject=%q\n%s\n", s.From, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender 將每封郵件寫成目錄中的 .eml 檔案，方便本地檢視或測試
+type FileSender struct {
+	From string
+	Dir  string
+}
+
+// Send 將郵件寫入檔案
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), sanitize(msg.To))
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		s.From, msg.To, msg.Subject, time.Now().UTC().Format(time.RFC1123Z), msg.Body)
+
+	return os.WriteFile(filepath.Join(s.Dir, name), []byte(content), 0o600)
+}
+
+// NewSender 依 driver 名稱建立 Sender（log 或 file）
+func NewSender(driver, from, dir string) (Sender, error) {
+	switch driver {
+	case "", "log":
+		return &LogSender{From: from}, nil
+	case "file":
+		return &FileSender{From: from, Dir: dir}, nil
+	default:
+		return nil, fmt.Errorf("不支援的郵件 driver %q", driver)
+	}
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultSender Sender = &LogSender{}
+)
+
+// SetDefault 設定全域預設的 Sender
+func SetDefault(sender Sender) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultSender = sender
+}
+
+// Default 返回全域預設的 Sender
+func Default() Sender {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultSender
+}
+
+// sanitize 將收件人地址轉為安全的檔名片段
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
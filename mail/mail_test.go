@@ -0,0 +1,74 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "預設為 log", driver: "", want: &LogSender{}},
+		{name: "log driver", driver: "log", want: &LogSender{}},
+		{name: "file driver", driver: "file", want: &FileSender{}},
+		{name: "不支援的 driver", driver: "smtp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := NewSender(tt.driver, "noreply@example.com", t.TempDir())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, sender)
+		})
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	sender := &FileSender{From: "noreply@example.com", Dir: dir}
+
+	err := sender.Send(context.Background(), Message{
+		To:      "user+tag@example.com",
+		Subject: "重設密碼",
+		Body:    "token: abc",
+	})
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Name(), "user_tag_example.com")
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "To: user+tag@example.com")
+	assert.Contains(t, string(data), "Subject: 重設密碼")
+	assert.Contains(t, string(data), "token: abc")
+}
+
+func TestLogSender(t *testing.T) {
+	sender := &LogSender{From: "noreply@example.com"}
+	assert.NoError(t, sender.Send(context.Background(), Message{To: "a@example.com"}))
+}
+
+func TestDefaultSender(t *testing.T) {
+	original := Default()
+	defer SetDefault(original)
+
+	custom := &FileSender{Dir: t.TempDir()}
+	SetDefault(custom)
+	assert.Same(t, custom, Default())
+}
@@ -2,16 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"member_API/auth"
 	"member_API/config"
 	"member_API/controllers"
 	_ "member_API/docs" // 導入 swagger 文檔
 	"member_API/graphql"
+	"member_API/mail"
 	"member_API/models"
+	"member_API/oidc"
 	"member_API/routes"
+	"member_API/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv" // 新增
@@ -43,8 +51,16 @@ import (
 // @name Authorization
 // @description JWT 認證，格式：Bearer {token}
 
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description 機器客戶端使用的 API key，與 Bearer token 擇一提供
+
 var db *gorm.DB
 
+// dbLogger GORM 的 SQL 日誌；命令列子命令改為只輸出警告至 stderr，避免混入匯出資料
+var dbLogger = logger.Default.LogMode(logger.Info)
+
 func initPostgreSQL() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -56,7 +72,8 @@ func initPostgreSQL() error {
 	}
 
 	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger:         dbLogger,
+		TranslateError: true,
 	})
 	if err != nil {
 		return err
@@ -78,11 +95,58 @@ func initPostgreSQL() error {
 	if err := gormDB.WithContext(ctx).AutoMigrate(
 		&models.Member{},
 		&models.Product{},
+		&models.RefreshToken{},
+		&models.Permission{},
+		&models.Role{},
+		&models.MemberToken{},
+		&models.MFARecoveryCode{},
+		&models.LoginAttempt{},
+		&models.AuditLog{},
+		&models.Session{},
+		&models.ExternalIdentity{},
+		&models.OIDCAuthRequest{},
+		&models.APIKey{},
+		&models.OAuthClient{},
+		&models.OAuthAuthorizationCode{},
+		&models.OAuthGrant{},
+		&models.OAuthRefreshToken{},
+		&models.OAuthConsent{},
+		&models.ImpersonationSession{},
+		&models.MemberStatusChange{},
+		&models.DataExport{},
+		&models.MemberAddress{},
+		&models.MemberPreference{},
+		&models.MemberMerge{},
+		&models.MemberGroup{},
+		&models.PointsLedgerEntry{},
+		&models.MemberPoints{},
 	); err != nil {
 		return err
 	}
 
+	collisions, err := services.MigrateMemberEmails(gormDB.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	for _, collision := range collisions {
+		log.Printf("Warning: email %s is used by members %v; merge or change them so emails can be matched case-insensitively\n", collision.Email, collision.MemberIDs)
+	}
+
+	roleSvc := services.NewRoleService(gormDB.WithContext(ctx))
+	if err := roleSvc.EnsureDefaults(); err != nil {
+		return err
+	}
+	if email := config.Load().Auth.BootstrapAdminEmail; email != "" {
+		if err := roleSvc.GrantRoleByEmail(email, auth.RoleAdmin); err != nil {
+			log.Printf("Warning: failed to grant admin role to %s: %v\n", email, err)
+		}
+	}
+
 	db = gormDB
+	auth.SetSessionValidator(services.NewSessionService(db).Validate)
+	auth.SetAPIKeyAuthenticator(services.NewAPIKeyService(db).Authenticate)
+	auth.SetOAuthGrantValidator(services.NewOAuthService(db).ValidateGrant)
+	auth.SetImpersonationRecorder(services.NewImpersonationService(db).Record)
 	controllers.SetupUserController(db)
 	controllers.SetupProductController(db)
 
@@ -90,6 +154,102 @@ func initPostgreSQL() error {
 	return nil
 }
 
+// startBackgroundJobs 啟動定期清理等背景作業，只在服務器模式執行，ctx 結束時停止；命令列子命令不會啟動
+func startBackgroundJobs(ctx context.Context, cfg *config.Config) {
+	// 金鑰重新載入不需要資料庫
+	if cfg.Auth.KeysDir != "" {
+		go auth.WatchKeys(ctx, cfg.Auth.KeysDir, cfg.Auth.ActiveKeyID, cfg.Auth.KeysReloadInterval)
+	}
+	if db == nil {
+		return
+	}
+	// 上次執行時中斷的資料匯出不會再被產生，標記為失敗讓會員可重新申請
+	if n, err := services.NewPrivacyService(db).FailStaleExports(); err != nil {
+		log.Printf("Warning: failed to mark stale data exports as failed: %v\n", err)
+	} else if n > 0 {
+		log.Printf("Marked %d stale data exports as failed\n", n)
+	}
+	go services.NewTrashService(db).RunPurge(ctx, cfg.Trash.PurgeInterval, cfg.Trash.Retention)
+	go services.NewPointsService(db).RunExpiry(ctx, cfg.Points.ExpiryInterval)
+	if cfg.Auth.LoginAttemptStore == "database" {
+		// 失敗計數在一小時後重新計算，保留一天足以涵蓋所有退避與鎖定規則
+		go services.NewDBAttemptTracker(db).RunPurge(ctx, time.Hour, 24*time.Hour)
+	}
+}
+
+// configurePasswords 套用密碼雜湊演算法與密碼規則
+func configurePasswords(cfg *config.Config) {
+	hashOpts := auth.DefaultPasswordHashOptions()
+	hashOpts.Algorithm = cfg.Password.HashAlgorithm
+	hashOpts.BcryptCost = cfg.Password.BcryptCost
+	hashOpts.Argon2.Memory = uint32(cfg.Password.Argon2Memory)
+	hashOpts.Argon2.Iterations = uint32(cfg.Password.Argon2Iterations)
+	hashOpts.Argon2.Parallelism = uint8(cfg.Password.Argon2Parallelism)
+	if err := auth.SetPasswordHashOptions(hashOpts); err != nil {
+		log.Fatalf("Invalid password hashing configuration: %v", err)
+	}
+
+	policy := auth.DefaultPasswordPolicy()
+	policy.MinLength = cfg.Password.MinLength
+	policy.RequireUpper = cfg.Password.RequireUpper
+	policy.RequireLower = cfg.Password.RequireLower
+	policy.RequireDigit = cfg.Password.RequireDigit
+	policy.RequireSymbol = cfg.Password.RequireSymbol
+	if cfg.Password.BlocklistFile != "" {
+		blocklist, err := auth.LoadPasswordBlocklist(cfg.Password.BlocklistFile)
+		if err != nil {
+			log.Fatalf("Failed to load PASSWORD_BLOCKLIST_FILE: %v", err)
+		}
+		policy.Blocklist = blocklist
+		log.Printf("Loaded %d entries from password blocklist\n", len(blocklist))
+	}
+	auth.SetPasswordPolicy(policy)
+}
+
+// configureLoginGuard 依設定建立登入失敗追蹤；資料庫不可用時退回記憶體實作
+func configureLoginGuard(cfg *config.Config) {
+	var tracker auth.LoginAttemptTracker = auth.NewMemoryAttemptTracker()
+	switch cfg.Auth.LoginAttemptStore {
+	case "memory":
+	case "database":
+		if db != nil {
+			tracker = services.NewDBAttemptTracker(db)
+		} else {
+			log.Println("Warning: LOGIN_ATTEMPT_STORE=database but PostgreSQL is unavailable, tracking login attempts in memory")
+		}
+	default:
+		log.Fatalf("Invalid LOGIN_ATTEMPT_STORE: %q", cfg.Auth.LoginAttemptStore)
+	}
+
+	guard := auth.NewLoginGuard(tracker)
+	guard.AccountPolicy.LockoutThreshold = cfg.Auth.LoginLockoutThreshold
+	guard.AccountPolicy.LockoutDuration = cfg.Auth.LoginLockoutDuration
+	auth.SetLoginGuard(guard)
+}
+
+// configureOIDC 建立外部登入提供者；discovery 文件在第一次使用時才抓取，提供者暫時無法連線不影響啟動
+func configureOIDC(cfg *config.Config) {
+	providers := make([]oidc.ProviderConfig, 0, len(cfg.OIDC.Providers))
+	for _, p := range cfg.OIDC.Providers {
+		providers = append(providers, oidc.ProviderConfig{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		})
+	}
+	registry, err := oidc.NewRegistry(providers, nil)
+	if err != nil {
+		log.Fatalf("Invalid OIDC provider configuration: %v", err)
+	}
+	oidc.SetRegistry(registry)
+	if names := registry.Names(); len(names) > 0 {
+		log.Printf("Enabled OIDC login providers: %v\n", names)
+	}
+}
+
 // HealthCheck 健康檢查端點
 // @Summary 健康檢查
 // @Description 檢查服務器狀態和數據庫連接狀態
@@ -121,6 +281,50 @@ func main() {
 		log.Println("Warning: .env file not found, using environment variables")
 	}
 
+	command := os.Args[1:]
+	if len(command) > 0 {
+		dbLogger = logger.New(log.New(os.Stderr, "", log.LstdFlags), logger.Config{
+			SlowThreshold: time.Second,
+			LogLevel:      logger.Warn,
+			Colorful:      false,
+		})
+	}
+
+	cfg := config.Load()
+	auth.SetTokenTTL(cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+	auth.SetOAuthTokenTTL(cfg.Auth.OAuthAccessTokenTTL, cfg.Auth.OAuthRefreshTokenTTL)
+	auth.SetImpersonationTTL(cfg.Auth.ImpersonationTTL)
+
+	// 載入 JWT 簽章金鑰
+	if cfg.Auth.KeysDir != "" {
+		keySet, err := auth.LoadKeySet(cfg.Auth.KeysDir, cfg.Auth.ActiveKeyID)
+		if err != nil {
+			log.Fatalf("Failed to load JWT signing keys: %v", err)
+		}
+		auth.SetKeySet(keySet)
+		log.Printf("Loaded JWT signing keys from %s (active kid: %s)\n", cfg.Auth.KeysDir, auth.CurrentKeySet().Active().ID)
+	} else {
+		log.Println("Warning: JWT_KEYS_DIR not set. Using an ephemeral signing key; tokens will not survive a restart.")
+	}
+
+	configurePasswords(cfg)
+	configureOIDC(cfg)
+
+	// 郵件寄送
+	sender, err := mail.NewSender(cfg.Mail.Driver, cfg.Mail.From, cfg.Mail.FileDir)
+	if err != nil {
+		log.Fatalf("Failed to configure mail sender: %v", err)
+	}
+	mail.SetDefault(sender)
+	services.SetPasswordResetOptions(cfg.Auth.PasswordResetTTL, cfg.Server.PublicURL+"/reset-password")
+	services.SetEmailVerificationOptions(cfg.Auth.EmailVerificationTTL, cfg.Server.PublicURL+"/verify-email")
+	services.SetMFAIssuer(cfg.Auth.MFAIssuer)
+	services.SetDataExportTTL(cfg.Privacy.DataExportTTL)
+	services.SetPointsExpiry(cfg.Points.Expiry)
+	if err := auth.SetEmailVerificationPolicy(cfg.Auth.EmailVerificationPolicy); err != nil {
+		log.Fatalf("Invalid EMAIL_VERIFICATION_POLICY: %v", err)
+	}
+
 	// 初始化 PostgreSQL 連接
 	if err := initPostgreSQL(); err != nil {
 		log.Printf("Warning: PostgreSQL connection failed: %v\n", err)
@@ -139,6 +343,16 @@ func main() {
 		}()
 	}
 
+	configureLoginGuard(cfg)
+
+	// 命令列子命令執行完即結束，不啟動服務器
+	if len(command) > 0 {
+		if err := runCommand(command); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
 	// 初始化 GraphQL（必須在路由設置之前）
 	if err := graphql.SetupGraphQL(db); err != nil {
 		log.Printf("Warning: GraphQL setup failed: %v\n", err)
@@ -158,10 +372,25 @@ func main() {
 	// 添加一個簡單的健康檢查端點（支援 GET 和 HEAD 請求）
 	Router.Any("/health", HealthCheck)
 
+	// 收到 SIGINT 或 SIGTERM 時停止背景作業並等待進行中的請求完成
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	startBackgroundJobs(ctx, cfg)
+
 	// 啟動服務器
-	cfg := config.Load()
+	server := &http.Server{Addr: ":" + cfg.Server.Port, Handler: Router}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down server: %v\n", err)
+		}
+	}()
+
 	log.Println("Server starting on :" + cfg.Server.Port)
-	if err := Router.Run(":" + cfg.Server.Port); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
+	log.Println("Server stopped")
 }
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// APIKey is a long-lived credential for machine clients acting as a member.
+// Only the SHA-256 hash of the key is stored; Prefix is the public part of
+// the key shown in listings so members can tell their keys apart. Scopes is
+// a space-separated list of permission names the key may use.
+type APIKey struct {
+	MemberID   uint       `gorm:"index;not null" json:"member_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;index;not null" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Scopes     string     `gorm:"size:1024;not null" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"size:64" json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	Base
+}
@@ -0,0 +1,13 @@
+package models
+
+// AuditLog records a security-relevant event. MemberID is the member the
+// event is about (0 when unknown) and ActorID the member who performed it.
+type AuditLog struct {
+	Action    string `gorm:"size:100;index;not null" json:"action"`
+	MemberID  uint   `gorm:"index" json:"member_id"`
+	ActorID   uint   `gorm:"index" json:"actor_id"`
+	IP        string `gorm:"size:64" json:"ip"`
+	UserAgent string `gorm:"size:512" json:"user_agent"`
+	Detail    string `gorm:"type:text" json:"detail"`
+	Base
+}
@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 // Base 基礎模型結構，包含通用的審計欄位
 type Base struct {
@@ -13,3 +18,33 @@ type Base struct {
 	IsDeleted            bool       `gorm:"default:false" json:"-"`
 	DeletedAt            *time.Time `gorm:"index" json:"-"`
 }
+
+// NotDeleted 只查詢未被軟刪除的資料；所有含 Base 的模型都以此 scope 過濾，欄位會加上目前資料表名稱，JOIN 時也不會混淆
+func NotDeleted(db *gorm.DB) *gorm.DB {
+	return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "is_deleted"}, Value: false})
+}
+
+// OnlyDeleted 只查詢已軟刪除（在回收桶中）的資料
+func OnlyDeleted(db *gorm.DB) *gorm.DB {
+	return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "is_deleted"}, Value: true})
+}
+
+// SoftDeleteColumns 軟刪除時要更新的欄位
+func SoftDeleteColumns(deleterID uint, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"is_deleted":             true,
+		"deleted_at":             &now,
+		"last_modifier_id":       deleterID,
+		"last_modification_time": &now,
+	}
+}
+
+// RestoreColumns 從回收桶還原時要更新的欄位
+func RestoreColumns(modifierID uint, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"is_deleted":             false,
+		"deleted_at":             nil,
+		"last_modifier_id":       modifierID,
+		"last_modification_time": &now,
+	}
+}
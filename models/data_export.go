@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Data export job statuses.
+const (
+	DataExportPending   = "pending"
+	DataExportRunning   = "running"
+	DataExportCompleted = "completed"
+	DataExportFailed    = "failed"
+)
+
+// DataExport is a request for a copy of the personal data of a member. The
+// bundle is built in the background and stored as one JSON document keyed
+// by section; it can be downloaded until ExpiresAt.
+type DataExport struct {
+	ExportID    string     `gorm:"size:64;uniqueIndex;not null" json:"export_id"`
+	MemberID    uint       `gorm:"index;not null" json:"member_id"`
+	RequestedBy uint       `gorm:"index" json:"requested_by"`
+	Status      string     `gorm:"size:20;not null" json:"status"`
+	Error       string     `gorm:"size:500" json:"error,omitempty"`
+	Data        []byte     `json:"-"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	Base
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ExternalIdentity links a member to an account at an external OpenID Connect
+// provider. Provider+Subject identifies the external account; Email is the
+// address the provider reported at the last login and is informational only.
+type ExternalIdentity struct {
+	MemberID    uint      `gorm:"index;not null" json:"member_id"`
+	Provider    string    `gorm:"size:50;not null;uniqueIndex:idx_external_identity_subject" json:"provider"`
+	Subject     string    `gorm:"size:255;not null;uniqueIndex:idx_external_identity_subject" json:"subject"`
+	Email       string    `gorm:"size:255" json:"email"`
+	LastLoginAt time.Time `gorm:"not null" json:"last_login_at"`
+	Base
+}
+
+// OIDCAuthRequest is a pending authorization request to an external provider,
+// looked up by the hashed state when the provider redirects back. LinkMemberID
+// is set when a logged-in member is linking a new provider to their account.
+type OIDCAuthRequest struct {
+	StateHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Provider     string     `gorm:"size:50;not null" json:"provider"`
+	Nonce        string     `gorm:"size:64;not null" json:"-"`
+	CodeVerifier string     `gorm:"size:128;not null" json:"-"`
+	LinkMemberID uint       `gorm:"index" json:"link_member_id"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`
+	Base
+}
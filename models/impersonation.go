@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ImpersonationSession records an admin acting as a member. Tokens minted
+// for it carry SessionID as their sid claim and the admin in the act claim;
+// ending the session (or letting it expire) invalidates them. There is no
+// refresh token, so the session cannot outlive ExpiresAt.
+type ImpersonationSession struct {
+	SessionID string     `gorm:"size:64;uniqueIndex;not null" json:"session_id"`
+	ActorID   uint       `gorm:"index;not null" json:"actor_id"`
+	MemberID  uint       `gorm:"index;not null" json:"member_id"`
+	Reason    string     `gorm:"size:500;not null" json:"reason"`
+	IP        string     `gorm:"size:64" json:"ip"`
+	UserAgent string     `gorm:"size:512" json:"user_agent"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Base
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LoginAttempt holds the failed-login counter for one tracking key
+// (an email or a source IP) when attempts are tracked in the database.
+type LoginAttempt struct {
+	Key           string     `gorm:"size:320;uniqueIndex;not null" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt *time.Time `json:"last_failure_at"`
+	BlockedUntil  *time.Time `json:"blocked_until"`
+	Locked        bool       `gorm:"not null;default:false" json:"locked"`
+	Base
+}
@@ -1,9 +1,35 @@
 package models
 
+import "time"
+
 // Member represents a user stored in PostgreSQL and managed by GORM.
 type Member struct {
-	Name         string `gorm:"size:255;not null" json:"name"`
-	Email        string `gorm:"size:255;uniqueIndex;not null" json:"email"`
-	PasswordHash string `gorm:"size:255" json:"-"`
+	Name string `gorm:"size:255;not null" json:"name"`
+	// Email 以標準形式（小寫）儲存；未刪除會員之間不分大小寫唯一，索引由 services.MigrateMemberEmails 建立
+	Email           string     `gorm:"size:255;not null" json:"email"`
+	PasswordHash    string     `gorm:"size:255" json:"-"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// TOTPSecret 兩步驟驗證共享密鑰，設定後須以驗證碼確認才會寫入 TOTPEnabledAt
+	TOTPSecret      string     `gorm:"size:64" json:"-"`
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at"`
+	TOTPLastCounter int64      `gorm:"not null;default:0" json:"-"`
+	// ServiceAccount 供整合程式使用的帳號，沒有密碼，只能透過 API key 存取
+	ServiceAccount bool `gorm:"not null;default:false" json:"service_account"`
+	// Status 帳號狀態，見 MemberStatus* 常數；StatusReason 為最近一次變更的原因
+	Status         string     `gorm:"size:20;not null;default:active;index" json:"status"`
+	StatusReason   string     `gorm:"size:500" json:"status_reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	// 個人檔案；Birthday 只使用日期部分，Locale 為 BCP 47 語言標籤，Timezone 為 IANA 時區名稱
+	Phone     string     `gorm:"size:32" json:"phone"`
+	Birthday  *time.Time `gorm:"type:date" json:"birthday"`
+	AvatarURL string     `gorm:"size:512" json:"avatar_url"`
+	Locale    string     `gorm:"size:16" json:"locale"`
+	Timezone  string     `gorm:"size:64" json:"timezone"`
+	// ErasedAt 個人資料被清除（匿名化）的時間；會員列保留以維持其他資料的 CreatorId 等參照
+	ErasedAt *time.Time `json:"erased_at"`
+	// MergedIntoID 會員被合併至另一位會員時指向合併後的會員；合併後的來源會員保留為已刪除的紀錄
+	MergedIntoID *uint         `gorm:"index" json:"merged_into_id,omitempty"`
+	Roles        []Role        `gorm:"many2many:member_roles;" json:"roles,omitempty"`
+	Groups       []MemberGroup `gorm:"many2many:member_group_assignments;" json:"groups,omitempty"`
 	Base
 }
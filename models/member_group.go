@@ -0,0 +1,11 @@
+package models
+
+// MemberGroup is a segment of members such as "vip", "wholesale" or
+// "staff", used for pricing and campaigns. Members join groups through
+// member_group_assignments; a member can belong to any number of groups.
+// Name is a lowercase slug that stays unique among groups not deleted.
+type MemberGroup struct {
+	Name        string `gorm:"size:50;not null;uniqueIndex:idx_member_groups_name_active,where:is_deleted = false" json:"name"`
+	Description string `gorm:"size:255" json:"description"`
+	Base
+}
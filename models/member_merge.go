@@ -0,0 +1,18 @@
+package models
+
+// MemberMerge records that a duplicate member was merged into another one.
+// The source member stays behind as a soft-deleted tombstone whose
+// MergedIntoID points at the target; this record keeps who merged it, why,
+// and the identity the source had at the time, so old references to the
+// source ID can be redirected to the surviving member.
+type MemberMerge struct {
+	SourceMemberID uint   `gorm:"uniqueIndex;not null" json:"source_member_id"`
+	TargetMemberID uint   `gorm:"index;not null" json:"target_member_id"`
+	SourceName     string `gorm:"size:255;not null" json:"source_name"`
+	SourceEmail    string `gorm:"size:255;not null" json:"source_email"`
+	MergedBy       uint   `gorm:"index" json:"merged_by"`
+	Reason         string `gorm:"size:500;not null" json:"reason"`
+	// Moved 各類資料移轉筆數的摘要，如 "sessions=2 addresses=1"
+	Moved string `gorm:"size:1000" json:"moved"`
+	Base
+}
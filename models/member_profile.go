@@ -0,0 +1,44 @@
+package models
+
+// Address types.
+const (
+	AddressTypeShipping = "shipping"
+	AddressTypeBilling  = "billing"
+)
+
+// Preferred communication channels.
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelNone  = "none"
+)
+
+// MemberAddress is a shipping or billing address of a member. Each member
+// has at most one default address per type.
+type MemberAddress struct {
+	MemberID      uint   `gorm:"index;not null" json:"member_id"`
+	Type          string `gorm:"size:20;not null" json:"type"`
+	Label         string `gorm:"size:50" json:"label"`
+	RecipientName string `gorm:"size:100;not null" json:"recipient_name"`
+	Phone         string `gorm:"size:32" json:"phone"`
+	Country       string `gorm:"size:2;not null" json:"country"`
+	PostalCode    string `gorm:"size:20" json:"postal_code"`
+	Region        string `gorm:"size:100" json:"region"`
+	City          string `gorm:"size:100;not null" json:"city"`
+	Line1         string `gorm:"size:255;not null" json:"line1"`
+	Line2         string `gorm:"size:255" json:"line2"`
+	IsDefault     bool   `gorm:"not null;default:false" json:"is_default"`
+	Base
+}
+
+// MemberPreference holds the marketing and communication choices of a
+// member. Marketing is opt-in: a member without a row has opted out of
+// everything and prefers email.
+type MemberPreference struct {
+	MemberID         uint   `gorm:"uniqueIndex;not null" json:"member_id"`
+	MarketingEmail   bool   `gorm:"not null;default:false" json:"marketing_email"`
+	MarketingSMS     bool   `gorm:"not null;default:false" json:"marketing_sms"`
+	Newsletter       bool   `gorm:"not null;default:false" json:"newsletter"`
+	PreferredChannel string `gorm:"size:20;not null;default:email" json:"preferred_channel"`
+	Base
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Member lifecycle statuses. Only active members may log in or use their
+// tokens and API keys; a suspension ends by itself once SuspendedUntil passes.
+const (
+	MemberStatusPending   = "pending"
+	MemberStatusActive    = "active"
+	MemberStatusSuspended = "suspended"
+	MemberStatusBanned    = "banned"
+	MemberStatusClosed    = "closed"
+)
+
+// MemberStatusChange is one entry in the status history of a member.
+type MemberStatusChange struct {
+	MemberID       uint       `gorm:"index;not null" json:"member_id"`
+	FromStatus     string     `gorm:"size:20;not null" json:"from_status"`
+	ToStatus       string     `gorm:"size:20;not null" json:"to_status"`
+	Reason         string     `gorm:"size:500" json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	ActorID        uint       `gorm:"index" json:"actor_id"`
+	Base
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// MemberToken represents a hashed, single-use token sent to a member for a
+// specific purpose such as password reset.
+type MemberToken struct {
+	MemberID  uint       `gorm:"index;not null" json:"member_id"`
+	Purpose   string     `gorm:"size:50;index;not null" json:"purpose"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Payload   string     `gorm:"size:255" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	Base
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// MFARecoveryCode is a hashed, single-use backup code that can replace a TOTP
+// code when the member has lost access to their authenticator.
+type MFARecoveryCode struct {
+	MemberID uint       `gorm:"index;not null" json:"member_id"`
+	CodeHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	UsedAt   *time.Time `json:"used_at"`
+	Base
+}
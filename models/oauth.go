@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// OAuthClient is a first- or third-party application allowed to obtain
+// tokens from this service. RedirectURIs, GrantTypes and Scopes are
+// space-separated lists. Confidential clients authenticate with a secret,
+// of which only the SHA-256 hash is stored; public clients (SPAs, mobile
+// apps) have no secret and must use PKCE. FirstParty clients skip the
+// consent screen and may introspect any token.
+type OAuthClient struct {
+	ClientID     string `gorm:"size:64;uniqueIndex;not null" json:"client_id"`
+	SecretHash   string `gorm:"size:64" json:"-"`
+	Name         string `gorm:"size:100;not null" json:"name"`
+	RedirectURIs string `gorm:"size:2048" json:"redirect_uris"`
+	GrantTypes   string `gorm:"size:255;not null" json:"grant_types"`
+	Scopes       string `gorm:"size:1024;not null" json:"scopes"`
+	Confidential bool   `gorm:"not null;default:false" json:"confidential"`
+	FirstParty   bool   `gorm:"not null;default:false" json:"first_party"`
+	Base
+}
+
+// OAuthAuthorizationCode is a single-use code issued at the authorization
+// endpoint and exchanged for tokens at the token endpoint. Only its hash is
+// stored. RedirectURIProvided records whether the authorization request
+// carried redirect_uri; if it did, the token request must repeat it exactly.
+type OAuthAuthorizationCode struct {
+	CodeHash            string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ClientID            string     `gorm:"size:64;index;not null" json:"client_id"`
+	MemberID            uint       `gorm:"index;not null" json:"member_id"`
+	RedirectURI         string     `gorm:"size:512;not null" json:"redirect_uri"`
+	RedirectURIProvided bool       `gorm:"not null;default:false" json:"-"`
+	Scopes              string     `gorm:"size:1024;not null" json:"scopes"`
+	CodeChallenge       string     `gorm:"size:128" json:"-"`
+	CodeChallengeMethod string     `gorm:"size:16" json:"-"`
+	ExpiresAt           time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt              *time.Time `json:"used_at"`
+	// GrantID is set once the code has been exchanged so a replayed code can
+	// revoke the tokens issued from it.
+	GrantID string `gorm:"size:64" json:"-"`
+	Base
+}
+
+// OAuthGrant ties the tokens issued to a client for a member (or for the
+// client itself under client_credentials, where MemberID is 0). Access
+// tokens carry GrantID as their sid claim; revoking the grant invalidates
+// every access and refresh token issued under it.
+type OAuthGrant struct {
+	GrantID   string     `gorm:"size:64;uniqueIndex;not null" json:"grant_id"`
+	ClientID  string     `gorm:"size:64;index;not null" json:"client_id"`
+	MemberID  uint       `gorm:"index;not null" json:"member_id"`
+	Scopes    string     `gorm:"size:1024;not null" json:"scopes"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	Base
+}
+
+// OAuthRefreshToken is a rotating refresh token belonging to a grant. A
+// token that is presented again after being used revokes the whole grant.
+type OAuthRefreshToken struct {
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	GrantID   string     `gorm:"size:64;index;not null" json:"grant_id"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	Base
+}
+
+// OAuthConsent records the scopes a member has approved for a client so the
+// consent screen is only shown again when the client asks for more.
+type OAuthConsent struct {
+	MemberID uint   `gorm:"uniqueIndex:idx_oauth_consent_member_client;not null" json:"member_id"`
+	ClientID string `gorm:"size:64;uniqueIndex:idx_oauth_consent_member_client;not null" json:"client_id"`
+	Scopes   string `gorm:"size:1024;not null" json:"scopes"`
+	Base
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Points ledger entry types. Earn and positive adjust entries are credits;
+// redeem, expire and negative adjust entries are debits.
+const (
+	PointsEntryEarn   = "earn"
+	PointsEntryRedeem = "redeem"
+	PointsEntryExpire = "expire"
+	PointsEntryAdjust = "adjust"
+)
+
+// PointsLedgerEntry is one entry in a member's points ledger. Entries are
+// append-only: a mistake is corrected with a new adjust entry, never by
+// editing or deleting an entry. Points is positive for credits and negative
+// for debits, and Balance is the member's balance right after the entry.
+// Every credit expires at ExpiresAt; debits always have a nil ExpiresAt.
+// Reference is an optional caller-supplied key (such as an order number)
+// that is unique per member and type, so retried requests are not booked twice.
+type PointsLedgerEntry struct {
+	MemberID  uint       `gorm:"index;not null;uniqueIndex:idx_points_ledger_reference,priority:1,where:reference <> ''" json:"member_id"`
+	Type      string     `gorm:"size:20;not null;uniqueIndex:idx_points_ledger_reference,priority:2" json:"type"`
+	Points    int64      `gorm:"not null" json:"points"`
+	Balance   int64      `gorm:"not null" json:"balance"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"`
+	Reason    string     `gorm:"size:500" json:"reason"`
+	Reference string     `gorm:"size:100;not null;default:'';uniqueIndex:idx_points_ledger_reference,priority:3" json:"reference"`
+	ActorID   uint       `gorm:"index" json:"actor_id"`
+	Base
+}
+
+// MemberPoints holds a member's current points balance, which always equals
+// the Balance of the member's latest ledger entry. The row is locked while an
+// entry is appended so concurrent earns and redemptions are serialized and
+// the balance can never go negative. ExpiredThrough is the cutoff of the last
+// expiry run for the member; credits expiring at or before it are settled.
+type MemberPoints struct {
+	MemberID       uint       `gorm:"uniqueIndex;not null" json:"member_id"`
+	Balance        int64      `gorm:"not null;default:0;check:chk_member_points_balance,balance >= 0" json:"balance"`
+	ExpiredThrough *time.Time `json:"expired_through"`
+	Base
+}
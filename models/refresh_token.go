@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RefreshToken represents a persisted refresh token. Only the SHA-256 hash of
+// the token is stored; tokens rotated from the same login share a FamilyID.
+type RefreshToken struct {
+	MemberID  uint       `gorm:"index;not null" json:"member_id"`
+	FamilyID  string     `gorm:"size:64;index;not null" json:"family_id"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	Base
+}
@@ -0,0 +1,16 @@
+package models
+
+// Permission represents a single named capability such as "member:delete".
+type Permission struct {
+	Name        string `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string `gorm:"size:255" json:"description"`
+	Base
+}
+
+// Role groups permissions and is assigned to members through member_roles.
+type Role struct {
+	Name        string       `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string       `gorm:"size:255" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions;" json:"permissions"`
+	Base
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session is a login of a member on one device. SessionID equals the
+// FamilyID of the refresh tokens issued for the login and is carried in
+// access tokens as the sid claim.
+type Session struct {
+	MemberID   uint       `gorm:"index;not null" json:"member_id"`
+	SessionID  string     `gorm:"size:64;uniqueIndex;not null" json:"session_id"`
+	DeviceName string     `gorm:"size:255" json:"device_name"`
+	IP         string     `gorm:"size:64" json:"ip"`
+	UserAgent  string     `gorm:"size:512" json:"user_agent"`
+	LastSeenAt time.Time  `gorm:"not null" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	Base
+}
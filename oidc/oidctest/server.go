@@ -0,0 +1,297 @@
+// Package oidctest 提供本機用的模擬 OpenID Connect 提供者，用於測試社群登入流程。
+// 授權端點不顯示登入頁面，直接以 SetUser 設定的身分核發授權碼。
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"member_API/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mock-1"
+
+// User 模擬提供者目前登入的使用者
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type authRequest struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	user          User
+	expiresAt     time.Time
+}
+
+// Server 模擬的 OpenID Connect 提供者
+type Server struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	key  *rsa.PrivateKey
+	jwks auth.JWKS
+
+	mu    sync.Mutex
+	user  User
+	codes map[string]authRequest
+}
+
+// NewServer 在隨機埠啟動模擬提供者，只接受指定的 client
+func NewServer(clientID, clientSecret string) (*Server, error) {
+	return Listen("127.0.0.1:0", clientID, clientSecret)
+}
+
+// Listen 在指定位址啟動模擬提供者，供本機手動測試使用固定的 issuer
+func Listen(addr, clientID, clientSecret string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	signing, err := auth.NewKey(keyID, key)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	ks := auth.NewKeySet()
+	ks.Add(signing)
+
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		jwks:         ks.JWKS(),
+		user:         User{Subject: "mock-user", Email: "mock-user@example.com", EmailVerified: true, Name: "Mock User"},
+		codes:        make(map[string]authRequest),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("/jwks", s.handleJWKS)
+	mux.HandleFunc("/authorize", s.handleAuthorize)
+	mux.HandleFunc("/token", s.handleToken)
+	s.Server = httptest.NewUnstartedServer(mux)
+	_ = s.Server.Listener.Close()
+	s.Server.Listener = listener
+	s.Server.Start()
+	return s, nil
+}
+
+// Issuer 返回提供者的 issuer
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// SetUser 設定之後授權時使用的身分
+func (s *Server) SetUser(u User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = u
+}
+
+// Authorize 模擬瀏覽器開啟授權網址，返回提供者導回的 callback 網址（含 code 與 state）
+func (s *Server) Authorize(authURL string) (*url.URL, error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return nil, fmt.Errorf("授權端點回應 %d", resp.StatusCode)
+	}
+	return url.Parse(resp.Header.Get("Location"))
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.jwks)
+}
+
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch {
+	case q.Get("client_id") != s.ClientID:
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	case q.Get("response_type") != "code":
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		http.Error(w, "PKCE S256 required", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.codes[code] = authRequest{
+		clientID:      s.ClientID,
+		redirectURI:   redirectURI.String(),
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		user:          s.user,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	s.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", q.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.ClientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type", "")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	req, found := s.codes[code]
+	delete(s.codes, code) // 授權碼只能使用一次
+	s.mu.Unlock()
+
+	switch {
+	case !found || time.Now().After(req.expiresAt):
+		tokenError(w, "invalid_grant", "unknown or expired code")
+		return
+	case r.PostForm.Get("redirect_uri") != req.redirectURI:
+		tokenError(w, "invalid_grant", "redirect_uri mismatch")
+		return
+	case !verifyPKCE(r.PostForm.Get("code_verifier"), req.codeChallenge):
+		tokenError(w, "invalid_grant", "PKCE verification failed")
+		return
+	}
+
+	idToken, err := s.signIDToken(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	accessToken, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// SignIDToken 以模擬提供者的金鑰簽發任意 claims，用於測試驗證失敗的情境
+func (s *Server) SignIDToken(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(s.key)
+}
+
+func (s *Server) signIDToken(req authRequest) (string, error) {
+	now := time.Now()
+	return s.SignIDToken(jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            req.user.Subject,
+		"aud":            req.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          req.nonce,
+		"email":          req.user.Email,
+		"email_verified": req.user.EmailVerified,
+		"name":           req.user.Name,
+	})
+}
+
+func verifyPKCE(verifier, challenge string) bool {
+	if verifier == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func tokenError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
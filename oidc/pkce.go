@@ -0,0 +1,27 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// GenerateRandomString 產生 URL 安全的隨機字串，用於 state、nonce 與 PKCE code verifier
+func GenerateRandomString(byteLength int) (string, error) {
+	buf := make([]byte, byteLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateCodeVerifier 產生 PKCE code verifier（RFC 7636），32 bytes 編碼後為 43 個字元
+func GenerateCodeVerifier() (string, error) {
+	return GenerateRandomString(32)
+}
+
+// CodeChallengeS256 計算 code verifier 的 S256 code challenge
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
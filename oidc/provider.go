@@ -0,0 +1,326 @@
+// Package oidc 實作 OpenID Connect 授權碼流程（含 PKCE）的用戶端，
+// 依設定連接任意符合規範的身分提供者（例如 Google、LINE）。
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"member_API/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownProvider = errors.New("不支援的登入提供者")
+	ErrInvalidIDToken  = errors.New("無效的 ID token")
+	ErrExchangeFailed  = errors.New("授權碼換取 token 失敗")
+)
+
+// jwksRefreshInterval 遇到未知 kid 時重新抓取 JWKS 的最短間隔，避免被偽造的 kid 觸發大量請求
+const jwksRefreshInterval = time.Minute
+
+// ProviderConfig 單一身分提供者的設定
+type ProviderConfig struct {
+	// Name 提供者代號，出現在網址與外部身分紀錄中，例如 google、line
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Discovery OpenID Provider Metadata 中用到的欄位
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint,omitempty"`
+}
+
+// TokenResponse token endpoint 的回應
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	IDToken      string `json:"id_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// IDTokenClaims ID token 中與會員資料相關的 claims
+type IDTokenClaims struct {
+	Email         string   `json:"email,omitempty"`
+	EmailVerified flexBool `json:"email_verified,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Picture       string   `json:"picture,omitempty"`
+	Nonce         string   `json:"nonce,omitempty"`
+	AuthorizedBy  string   `json:"azp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// flexBool 部分提供者以字串 "true" 表示布林值
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "", "null":
+		*b = false
+	default:
+		return fmt.Errorf("無效的布林值 %s", data)
+	}
+	return nil
+}
+
+// Provider 一個已設定的身分提供者；discovery 文件與簽章金鑰在第一次使用時抓取並快取
+type Provider struct {
+	cfg    ProviderConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	discovery   *Discovery
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// NewProvider 建立身分提供者，client 為 nil 時使用預設的 HTTP client
+func NewProvider(cfg ProviderConfig, client *http.Client) (*Provider, error) {
+	if cfg.Name == "" || cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("登入提供者 %q 缺少 issuer、client ID 或 redirect URL", cfg.Name)
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client}, nil
+}
+
+// Name 返回提供者代號
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// Discover 取得並快取提供者的 discovery 文件，並確認其中的 issuer 與設定一致
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc Discovery
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, &doc); err != nil {
+		return nil, fmt.Errorf("取得 %s 的 discovery 文件失敗: %w", p.cfg.Name, err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(p.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("discovery 文件的 issuer %q 與設定的 %q 不符", doc.Issuer, p.cfg.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("%s 的 discovery 文件缺少必要的端點", p.cfg.Name)
+	}
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// AuthCodeURL 組合導向提供者登入頁的網址，使用 S256 PKCE
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange 以授權碼與 code verifier 向 token endpoint 換取 token（client_secret_post）
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	doc, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &oauthErr)
+		return nil, fmt.Errorf("%w: %d %s %s", ErrExchangeFailed, resp.StatusCode, oauthErr.Error, oauthErr.ErrorDescription)
+	}
+
+	var token TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: 回應中沒有 id_token", ErrExchangeFailed)
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 驗證 ID token 的簽章、issuer、audience、有效期限與 nonce。
+// 支援提供者以 JWKS 公布的 RS256/ES256/EdDSA 金鑰，以及以 client secret 簽章的 HS256（例如 LINE）。
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*IDTokenClaims, error) {
+	doc, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}
+	if p.cfg.ClientSecret != "" {
+		methods = append(methods, "HS256")
+	}
+
+	claims := &IDTokenClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() == "HS256" {
+			return []byte(p.cfg.ClientSecret), nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.lookupKey(ctx, doc.JWKSURI, kid)
+	},
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: 缺少 sub", ErrInvalidIDToken)
+	}
+	// 多個 audience 時 azp 必須是本服務
+	if len(claims.Audience) > 1 && claims.AuthorizedBy != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp 不符", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce 不符", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+// lookupKey 依 kid 取得提供者的公鑰；找不到時（提供者輪換金鑰）重新抓取 JWKS
+func (p *Provider) lookupKey(ctx context.Context, jwksURI, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	if time.Since(p.keysFetched) < jwksRefreshInterval {
+		return nil, fmt.Errorf("找不到金鑰 %q", kid)
+	}
+
+	var set auth.JWKS
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("取得 %s 的 JWKS 失敗: %w", p.cfg.Name, err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// 略過不支援的金鑰類型，不影響其他金鑰
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("找不到金鑰 %q", kid)
+}
+
+// findKey 查詢已快取的金鑰；token 未帶 kid 時僅在提供者只有一把金鑰時使用該金鑰
+func (p *Provider) findKey(kid string) crypto.PublicKey {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 回應 %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
@@ -0,0 +1,223 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"member_API/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectURL = "http://localhost:8080/api/v1/auth/oidc/mock/callback"
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Server) {
+	t.Helper()
+	server, err := oidctest.NewServer("member-api", "s3cret")
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	p, err := NewProvider(ProviderConfig{
+		Name:         "mock",
+		Issuer:       server.Issuer(),
+		ClientID:     "member-api",
+		ClientSecret: "s3cret",
+		RedirectURL:  testRedirectURL,
+	}, nil)
+	require.NoError(t, err)
+	return p, server
+}
+
+// authorize 走完授權端點，返回授權碼
+func authorize(t *testing.T, p *Provider, server *oidctest.Server, state, nonce, verifier string) string {
+	t.Helper()
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, CodeChallengeS256(verifier))
+	require.NoError(t, err)
+
+	callback, err := server.Authorize(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/auth/oidc/mock/callback", callback.Path)
+	assert.Equal(t, state, callback.Query().Get("state"))
+	return callback.Query().Get("code")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	p, server := newTestProvider(t)
+	server.SetUser(oidctest.User{Subject: "u-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice"})
+	ctx := context.Background()
+
+	verifier, err := GenerateCodeVerifier()
+	require.NoError(t, err)
+	code := authorize(t, p, server, "state-1", "nonce-1", verifier)
+
+	token, err := p.Exchange(ctx, code, verifier)
+	require.NoError(t, err)
+
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "u-1", claims.Subject)
+	assert.Equal(t, "alice@example.com", claims.Email)
+	assert.True(t, bool(claims.EmailVerified))
+	assert.Equal(t, "Alice", claims.Name)
+
+	// 授權碼只能使用一次
+	_, err = p.Exchange(ctx, code, verifier)
+	assert.True(t, errors.Is(err, ErrExchangeFailed))
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	raw, err := p.AuthCodeURL(context.Background(), "st", "nn", "challenge")
+	require.NoError(t, err)
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+
+	q := u.Query()
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "member-api", q.Get("client_id"))
+	assert.Equal(t, testRedirectURL, q.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", q.Get("scope"))
+	assert.Equal(t, "st", q.Get("state"))
+	assert.Equal(t, "nn", q.Get("nonce"))
+	assert.Equal(t, "challenge", q.Get("code_challenge"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	p, server := newTestProvider(t)
+
+	verifier, err := GenerateCodeVerifier()
+	require.NoError(t, err)
+	code := authorize(t, p, server, "st", "nn", verifier)
+
+	other, err := GenerateCodeVerifier()
+	require.NoError(t, err)
+	_, err = p.Exchange(context.Background(), code, other)
+	assert.True(t, errors.Is(err, ErrExchangeFailed))
+}
+
+func TestVerifyIDTokenRejectsInvalidClaims(t *testing.T) {
+	p, server := newTestProvider(t)
+	now := time.Now()
+
+	base := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   server.Issuer(),
+			"sub":   "u-1",
+			"aud":   "member-api",
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+			"nonce": "nn",
+		}
+	}
+
+	valid, err := server.SignIDToken(base())
+	require.NoError(t, err)
+	_, err = p.VerifyIDToken(context.Background(), valid, "nn")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		nonce  string
+	}{
+		{"nonce 不符", func(jwt.MapClaims) {}, "other"},
+		{"issuer 不符", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, "nn"},
+		{"audience 不符", func(c jwt.MapClaims) { c["aud"] = "another-app" }, "nn"},
+		{"已過期", func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() }, "nn"},
+		{"缺少 sub", func(c jwt.MapClaims) { delete(c, "sub") }, "nn"},
+		{"多個 audience 但 azp 不符", func(c jwt.MapClaims) { c["aud"] = []string{"member-api", "another-app"} }, "nn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := base()
+			tt.mutate(claims)
+			raw, err := server.SignIDToken(claims)
+			require.NoError(t, err)
+
+			_, err = p.VerifyIDToken(context.Background(), raw, tt.nonce)
+			assert.True(t, errors.Is(err, ErrInvalidIDToken), "got %v", err)
+		})
+	}
+}
+
+func TestVerifyIDTokenHS256(t *testing.T) {
+	p, server := newTestProvider(t)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":   server.Issuer(),
+		"sub":   "line-user",
+		"aud":   "member-api",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": "nn",
+	})
+	raw, err := token.SignedString([]byte("s3cret"))
+	require.NoError(t, err)
+	claims, err := p.VerifyIDToken(context.Background(), raw, "nn")
+	require.NoError(t, err)
+	assert.Equal(t, "line-user", claims.Subject)
+
+	forged, err := token.SignedString([]byte("guessed"))
+	require.NoError(t, err)
+	_, err = p.VerifyIDToken(context.Background(), forged, "nn")
+	assert.True(t, errors.Is(err, ErrInvalidIDToken))
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	_, server := newTestProvider(t)
+
+	p, err := NewProvider(ProviderConfig{
+		Name:        "mock",
+		Issuer:      server.Issuer() + "/other",
+		ClientID:    "member-api",
+		RedirectURL: testRedirectURL,
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = p.Discover(context.Background())
+	assert.Error(t, err)
+}
+
+func TestFlexBool(t *testing.T) {
+	var claims IDTokenClaims
+	require.NoError(t, json.Unmarshal([]byte(`{"email_verified":"true"}`), &claims))
+	assert.True(t, bool(claims.EmailVerified))
+	require.NoError(t, json.Unmarshal([]byte(`{"email_verified":false}`), &claims))
+	assert.False(t, bool(claims.EmailVerified))
+	assert.Error(t, json.Unmarshal([]byte(`{"email_verified":"yes"}`), &claims))
+}
+
+func TestRegistry(t *testing.T) {
+	r, err := NewRegistry([]ProviderConfig{
+		{Name: "line", Issuer: "https://access.line.me", ClientID: "a", RedirectURL: testRedirectURL},
+		{Name: "google", Issuer: "https://accounts.google.com", ClientID: "b", RedirectURL: testRedirectURL},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"google", "line"}, r.Names())
+
+	p, err := r.Get("google")
+	require.NoError(t, err)
+	assert.Equal(t, "google", p.Name())
+
+	_, err = r.Get("facebook")
+	assert.True(t, errors.Is(err, ErrUnknownProvider))
+
+	_, err = NewRegistry([]ProviderConfig{{Name: "broken"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestCodeChallengeS256(t *testing.T) {
+	verifier, err := GenerateCodeVerifier()
+	require.NoError(t, err)
+	assert.Len(t, verifier, 43)
+	assert.Len(t, CodeChallengeS256(verifier), 43)
+	assert.NotEqual(t, verifier, CodeChallengeS256(verifier))
+}
@@ -0,0 +1,67 @@
+package oidc
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Registry 已設定的身分提供者，以代號查詢
+type Registry struct {
+	providers map[string]*Provider
+}
+
+// NewRegistry 依設定建立所有身分提供者
+func NewRegistry(configs []ProviderConfig, client *http.Client) (*Registry, error) {
+	r := &Registry{providers: make(map[string]*Provider, len(configs))}
+	for _, cfg := range configs {
+		p, err := NewProvider(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		r.providers[cfg.Name] = p
+	}
+	return r, nil
+}
+
+// Get 依代號取得身分提供者
+func (r *Registry) Get(name string) (*Provider, error) {
+	if r != nil {
+		if p, ok := r.providers[name]; ok {
+			return p, nil
+		}
+	}
+	return nil, ErrUnknownProvider
+}
+
+// Names 返回所有提供者代號（已排序）
+func (r *Registry) Names() []string {
+	names := []string{}
+	if r == nil {
+		return names
+	}
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = &Registry{providers: map[string]*Provider{}}
+)
+
+// SetRegistry 設定全域的身分提供者清單
+func SetRegistry(r *Registry) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = r
+}
+
+// CurrentRegistry 返回目前的身分提供者清單
+func CurrentRegistry() *Registry {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry
+}
@@ -16,15 +16,32 @@ func SetupRouter(Router *gin.Engine) {
 		c.JSON(200, gin.H{"message": "Hello, RESTful API!"})
 	})
 
+	// Public signing keys for token verification by other services
+	Router.GET("/.well-known/jwks.json", controllers.JWKS)
+
 	// Public - no authentication required
 	public := Router.Group("/api/v1")
 	{
 		// Authentication-related routes
 		public.POST("/register", controllers.Register)
 		public.POST("/login", controllers.Login)
+		public.POST("/login/mfa", controllers.LoginMFA)
+		public.POST("/token/refresh", controllers.RefreshToken)
+		public.POST("/logout", controllers.Logout)
+		public.POST("/password/forgot", controllers.ForgotPassword)
+		public.POST("/password/reset", controllers.ResetPassword)
+		public.POST("/email/verify", controllers.VerifyEmail)
+		public.GET("/auth/oidc/providers", controllers.GetOIDCProviders)
+		public.GET("/auth/oidc/:provider/authorize", controllers.OIDCAuthorize)
+		public.GET("/auth/oidc/:provider/callback", controllers.OIDCCallback)
+
+		// OAuth 2.0 endpoints for client applications - clients authenticate themselves
+		public.POST("/oauth/token", controllers.OAuthToken)
+		public.POST("/oauth/introspect", controllers.OAuthIntrospect)
+		public.POST("/oauth/revoke", controllers.OAuthRevoke)
 	}
 
-	// GraphQL endpoint
+	// GraphQL endpoint - Bearer token is validated by the GraphQL handler, fields opt in via @auth/@hasRole
 	Router.Any("/graphql", func(c *gin.Context) {
 		graphqlHandler := graphql.GetHandler()
 		if graphqlHandler == nil {
@@ -38,18 +55,104 @@ func SetupRouter(Router *gin.Engine) {
 	protected := Router.Group("/api/v1")
 	protected.Use(auth.AuthMiddleware()) // Add authentication middleware
 	{
-		protected.GET("/users", controllers.GetUsers)
-		protected.GET("/user/:id", func(c *gin.Context) {
-			controllers.GetUserByID(c)
-		})
-		protected.GET("/profile", controllers.GetProfile) // Get current user information
-		protected.DELETE("/user/:id", controllers.DeleteUserByID)
+		protected.GET("/users", auth.RequireVerifiedEmail(), auth.RequirePermission(auth.PermMemberRead), controllers.GetUsers)
+		protected.GET("/user/:id", auth.RequireSelfOrPermission("id", auth.PermMemberRead), controllers.GetUserByID)
+		protected.GET("/profile", auth.RequireScope(auth.ScopeProfile), controllers.GetProfile) // Get current user information
+		protected.GET("/oauth/userinfo", controllers.OAuthUserInfo)
+		protected.DELETE("/impersonation", controllers.EndImpersonation)
+
+		// Account management - not available to API keys or third-party applications
+		account := protected.Group("")
+		account.Use(auth.RequireFirstParty())
+		{
+			account.PUT("/profile", controllers.UpdateProfile)
+			account.PUT("/profile/details", controllers.UpdateProfileDetails)
+			account.GET("/profile/addresses", controllers.GetAddresses)
+			account.POST("/profile/addresses", controllers.CreateAddress)
+			account.PUT("/profile/addresses/:id", controllers.UpdateAddress)
+			account.DELETE("/profile/addresses/:id", controllers.DeleteAddress)
+			account.PUT("/profile/addresses/:id/default", controllers.SetDefaultAddress)
+			account.GET("/profile/preferences", controllers.GetPreferences)
+			account.PUT("/profile/preferences", controllers.UpdatePreferences)
+			account.GET("/points", controllers.GetMyPoints)
+			account.POST("/profile/password", controllers.ChangePassword)
+			account.GET("/sessions", controllers.GetSessions)
+			account.DELETE("/sessions", controllers.RevokeAllSessions)
+			account.DELETE("/sessions/:id", controllers.RevokeSession)
+			account.POST("/email/verify/resend", controllers.ResendVerificationEmail)
+			account.POST("/mfa/totp/setup", controllers.SetupTOTP)
+			account.POST("/mfa/totp/enable", controllers.EnableTOTP)
+			account.POST("/mfa/totp/disable", controllers.DisableTOTP)
+			account.POST("/mfa/recovery-codes", controllers.RegenerateRecoveryCodes)
+			account.GET("/api-keys", controllers.GetAPIKeys)
+			account.POST("/api-keys", controllers.CreateAPIKey)
+			account.DELETE("/api-keys/:id", controllers.RevokeAPIKey)
+			account.GET("/auth/oidc/identities", controllers.GetOIDCIdentities)
+			account.DELETE("/auth/oidc/identities/:provider", controllers.UnlinkOIDCProvider)
+			account.POST("/auth/oidc/:provider/link", controllers.LinkOIDCProvider)
+			account.GET("/oauth/authorize", controllers.GetOAuthAuthorize)
+			account.POST("/oauth/authorize", controllers.PostOAuthAuthorize)
+			account.GET("/oauth/consents", controllers.GetOAuthConsents)
+			account.DELETE("/oauth/consents/:client_id", controllers.RevokeOAuthConsent)
+			account.POST("/data-exports", controllers.RequestMyDataExport)
+			account.GET("/data-exports/:id", controllers.GetMyDataExport)
+			account.GET("/data-exports/:id/download", controllers.DownloadMyDataExport)
+		}
+		protected.DELETE("/user/:id", auth.RequireVerifiedEmail(), auth.RequirePermission(auth.PermMemberDelete), controllers.DeleteUserByID)
 
 		// Product routes
 		protected.GET("/products", controllers.GetProducts)
 		protected.GET("/product/:id", controllers.GetProductByID)
-		protected.POST("/product", controllers.CreateProduct)
-		protected.PUT("/product/:id", controllers.UpdateProduct)
-		protected.DELETE("/product/:id", controllers.DeleteProduct)
+		protected.POST("/product", auth.RequireVerifiedEmail(), auth.RequirePermission(auth.PermProductWrite), controllers.CreateProduct)
+		protected.PUT("/product/:id", auth.RequireVerifiedEmail(), auth.RequirePermission(auth.PermProductWrite), controllers.UpdateProduct)
+		protected.DELETE("/product/:id", auth.RequireVerifiedEmail(), auth.RequirePermission(auth.PermProductWrite), controllers.DeleteProduct)
+
+		// Role management
+		protected.GET("/roles", auth.RequirePermission(auth.PermRoleManage), controllers.GetRoles)
+	}
+
+	// Admin routes - require authentication and specific permissions
+	admin := Router.Group("/api/v1/admin")
+	admin.Use(auth.AuthMiddleware(), auth.RequireVerifiedEmail())
+	{
+		admin.POST("/members/import", auth.RequirePermission(auth.PermMemberWrite), controllers.ImportMembers)
+		admin.GET("/members/export", auth.RequirePermission(auth.PermMemberExport), controllers.ExportMembers)
+		admin.GET("/members/duplicates", auth.RequirePermission(auth.PermMemberRead), controllers.GetDuplicateMembers)
+		admin.GET("/members/:id/duplicates", auth.RequirePermission(auth.PermMemberRead), controllers.GetMemberDuplicates)
+		admin.POST("/members/:id/merge", auth.RequireFirstParty(), auth.RequirePermission(auth.PermMemberMerge), controllers.MergeMember)
+		admin.PUT("/members/:id/roles", auth.RequirePermission(auth.PermRoleManage), controllers.SetMemberRoles)
+		admin.PUT("/members/:id/groups", auth.RequirePermission(auth.PermGroupManage), controllers.SetMemberGroups)
+		admin.GET("/members/:id/points", auth.RequirePermission(auth.PermMemberRead), controllers.GetMemberPoints)
+		admin.POST("/members/:id/points", auth.RequirePermission(auth.PermPointsManage), controllers.RecordMemberPoints)
+		admin.GET("/groups", auth.RequirePermission(auth.PermMemberRead), controllers.GetGroups)
+		admin.POST("/groups", auth.RequirePermission(auth.PermGroupManage), controllers.CreateGroup)
+		admin.PUT("/groups/:id", auth.RequirePermission(auth.PermGroupManage), controllers.UpdateGroup)
+		admin.DELETE("/groups/:id", auth.RequirePermission(auth.PermGroupManage), controllers.DeleteGroup)
+		admin.POST("/groups/:id/members", auth.RequirePermission(auth.PermGroupManage), controllers.AddGroupMembers)
+		admin.DELETE("/groups/:id/members/:member_id", auth.RequirePermission(auth.PermGroupManage), controllers.RemoveGroupMember)
+		admin.POST("/members/:id/unlock", auth.RequirePermission(auth.PermMemberWrite), controllers.UnlockMemberLogin)
+		admin.GET("/members/:id/status", auth.RequirePermission(auth.PermMemberRead), controllers.GetMemberStatusHistory)
+		admin.PUT("/members/:id/status", auth.RequirePermission(auth.PermMemberWrite), controllers.ChangeMemberStatus)
+		admin.POST("/members/:id/restore", auth.RequirePermission(auth.PermMemberDelete), controllers.RestoreMember)
+		admin.POST("/products/:id/restore", auth.RequirePermission(auth.PermProductWrite), controllers.RestoreProduct)
+		admin.GET("/trash/members", auth.RequirePermission(auth.PermMemberDelete), controllers.GetDeletedMembers)
+		admin.GET("/trash/products", auth.RequirePermission(auth.PermProductWrite), controllers.GetDeletedProducts)
+		admin.POST("/members/:id/data-exports", auth.RequirePermission(auth.PermPrivacyManage), controllers.RequestMemberDataExport)
+		admin.GET("/data-exports/:id", auth.RequirePermission(auth.PermPrivacyManage), controllers.GetMemberDataExport)
+		admin.GET("/data-exports/:id/download", auth.RequirePermission(auth.PermPrivacyManage), controllers.DownloadMemberDataExport)
+		admin.POST("/members/:id/erase", auth.RequireFirstParty(), auth.RequirePermission(auth.PermPrivacyManage), controllers.EraseMember)
+		admin.POST("/members/:id/impersonate", auth.RequireFirstParty(), auth.RequirePermission(auth.PermMemberImpersonate), controllers.ImpersonateMember)
+		admin.GET("/members/:id/sessions", auth.RequirePermission(auth.PermMemberWrite), controllers.GetMemberSessions)
+		admin.DELETE("/members/:id/sessions", auth.RequirePermission(auth.PermMemberWrite), controllers.RevokeMemberSessions)
+		admin.GET("/audit-logs", auth.RequirePermission(auth.PermAuditRead), controllers.GetAuditLogs)
+		admin.POST("/service-accounts", auth.RequirePermission(auth.PermAPIKeyManage), controllers.CreateServiceAccount)
+		admin.GET("/members/:id/api-keys", auth.RequirePermission(auth.PermAPIKeyManage), controllers.GetMemberAPIKeys)
+		admin.POST("/members/:id/api-keys", auth.RequirePermission(auth.PermAPIKeyManage), controllers.CreateMemberAPIKey)
+		admin.DELETE("/members/:id/api-keys/:key_id", auth.RequirePermission(auth.PermAPIKeyManage), controllers.RevokeMemberAPIKey)
+		admin.GET("/oauth/clients", auth.RequirePermission(auth.PermOAuthManage), controllers.GetOAuthClients)
+		admin.POST("/oauth/clients", auth.RequirePermission(auth.PermOAuthManage), controllers.CreateOAuthClient)
+		admin.GET("/oauth/clients/:client_id", auth.RequirePermission(auth.PermOAuthManage), controllers.GetOAuthClient)
+		admin.DELETE("/oauth/clients/:client_id", auth.RequirePermission(auth.PermOAuthManage), controllers.DeleteOAuthClient)
+		admin.POST("/oauth/clients/:client_id/secret", auth.RequirePermission(auth.PermOAuthManage), controllers.RotateOAuthClientSecret)
 	}
 }
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"member_API/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportMembersRequiresExportPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRouter(router)
+
+	signed := func(roles []string, permissions ...string) string {
+		claims := auth.NewClaims(1, "export@example.com")
+		claims.EmailVerified = true
+		claims.Roles = roles
+		claims.Permissions = permissions
+		token, err := auth.SignClaims(claims)
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{
+			name:       "未認證",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "一般會員",
+			token:      signed([]string{auth.RoleMember}, auth.PermProductRead),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "只有 member:read",
+			token:      signed([]string{auth.RoleMember}, auth.PermMemberRead, auth.PermProductRead),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			// 通過權限檢查後因測試環境沒有資料庫而返回 500
+			name:       "管理員",
+			token:      signed([]string{auth.RoleAdmin}, auth.AllPermissions()...),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/members/export", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"member_API/auth"
+	"member_API/models"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyNotFound     = errors.New("API key 不存在")
+	ErrAPIKeyScopeInvalid = errors.New("API key 的權限範圍無效")
+	ErrAPIKeyExpiry       = errors.New("API key 的到期時間必須晚於現在")
+)
+
+// apiKeyTouchInterval 最後使用時間的更新間隔，避免每個請求都寫入資料庫
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyInput 建立 API key 的參數
+type APIKeyInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+type APIKeyService struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{DB: db}
+}
+
+// Create 為會員建立 API key，返回紀錄與只顯示一次的完整 key；
+// 權限範圍必須是會員目前擁有的權限，actorID 為建立者（本人或管理員）
+func (s *APIKeyService) Create(memberID uint, input APIKeyInput, actorID uint) (*models.APIKey, string, error) {
+	if _, err := NewMemberService(s.DB).GetMemberByID(memberID); err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, "", ErrAPIKeyExpiry
+	}
+
+	_, permissions, err := NewRoleService(s.DB).GetMemberAuthz(memberID)
+	if err != nil {
+		return nil, "", err
+	}
+	scopes := uniqueStrings(input.Scopes)
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: 至少需要一個權限", ErrAPIKeyScopeInvalid)
+	}
+	for _, scope := range scopes {
+		if !containsString(permissions, scope) {
+			return nil, "", fmt.Errorf("%w: 會員沒有 %s 權限", ErrAPIKeyScopeInvalid, scope)
+		}
+	}
+
+	rawKey, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    actorID,
+		},
+		MemberID:  memberID,
+		Name:      strings.TrimSpace(input.Name),
+		Prefix:    prefix,
+		KeyHash:   auth.HashToken(rawKey),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := s.DB.Create(key).Error; err != nil {
+		return nil, "", err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditAPIKeyCreated,
+		MemberID: memberID,
+		ActorID:  actorID,
+		Detail:   fmt.Sprintf("key=%s scopes=%s", prefix, key.Scopes),
+	})
+	return key, rawKey, nil
+}
+
+// List 列出會員尚未撤銷的 API key（含已過期），新的在前
+func (s *APIKeyService) List(memberID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := s.DB.Scopes(models.NotDeleted).Where("member_id = ? AND revoked_at IS NULL", memberID).
+		Order("creation_time DESC").
+		Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke 撤銷會員的某把 API key，之後使用該 key 的請求立即被拒絕
+func (s *APIKeyService) Revoke(memberID, keyID uint, actorID uint) error {
+	now := time.Now()
+	result := s.DB.Model(&models.APIKey{}).
+		Scopes(models.NotDeleted).Where("id = ? AND member_id = ? AND revoked_at IS NULL", keyID, memberID).
+		Updates(map[string]interface{}{
+			"revoked_at":             &now,
+			"last_modifier_id":       actorID,
+			"last_modification_time": &now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditAPIKeyRevoked,
+		MemberID: memberID,
+		ActorID:  actorID,
+		Detail:   fmt.Sprintf("key_id=%d", keyID),
+	})
+	return nil
+}
+
+// CreateServiceAccount 建立沒有密碼的服務帳號，只能以管理員為其建立的 API key 存取
+func (s *APIKeyService) CreateServiceAccount(name, email string, creatorID uint) (*models.Member, error) {
+	email = auth.NormalizeEmail(email)
+	var exists models.Member
+	if err := s.DB.Scopes(models.NotDeleted, ByEmail(email)).First(&exists).Error; err == nil {
+		return nil, ErrEmailInUse
+	}
+
+	now := time.Now()
+	member := &models.Member{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    creatorID,
+		},
+		Name:            name,
+		Email:           email,
+		EmailVerifiedAt: &now,
+		ServiceAccount:  true,
+		Status:          models.MemberStatusActive,
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		return NewRoleService(tx).AssignDefaultRole(member.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// Authenticate 驗證 API key 並返回呼叫者，權限為 key 的範圍與會員目前權限的交集；供 auth.SetAPIKeyAuthenticator 使用。
+// 會員停權、封鎖或關閉期間 key 無法使用，恢復後即可繼續使用
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey, clientIP string) (*auth.Principal, error) {
+	db := s.DB.WithContext(ctx)
+
+	var key models.APIKey
+	if err := db.Scopes(models.NotDeleted).Where("key_hash = ?", auth.HashToken(rawKey)).
+		First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+
+	var member models.Member
+	if err := db.Scopes(models.NotDeleted).First(&member, key.MemberID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if err := CheckMemberStatus(&member); err != nil {
+		return nil, err
+	}
+
+	roles, permissions, err := NewRoleService(db).GetMemberAuthz(member.ID)
+	if err != nil {
+		return nil, err
+	}
+	granted := make([]string, 0, len(permissions))
+	for _, scope := range strings.Fields(key.Scopes) {
+		if containsString(permissions, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	sort.Strings(granted)
+	groups, err := NewGroupService(db).MemberGroupNames(member.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval || key.LastUsedIP != clientIP {
+		if err := db.Model(&key).UpdateColumns(map[string]interface{}{
+			"last_used_at": &now,
+			"last_used_ip": truncate(clientIP, 64),
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &auth.Principal{
+		UserID:        member.ID,
+		Email:         member.Email,
+		APIKeyID:      key.ID,
+		Roles:         roles,
+		Permissions:   granted,
+		Groups:        groups,
+		EmailVerified: member.EmailVerifiedAt != nil,
+	}, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"log"
+	"member_API/models"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 審計事件名稱
+const (
+	AuditLoginFailed      = "login.failed"
+	AuditLoginLocked      = "login.locked"
+	AuditLoginUnlocked    = "login.unlocked"
+	AuditMFAFailed        = "login.mfa_failed"
+	AuditLoginThrottled   = "login.throttled"
+	AuditPasswordChanged  = "password.changed"
+	AuditEmailChanged     = "email.changed"
+	AuditSessionsRevoked  = "sessions.revoked"
+	AuditIdentityLinked   = "identity.linked"
+	AuditIdentityUnlinked = "identity.unlinked"
+	AuditAPIKeyCreated    = "api_key.created"
+	AuditAPIKeyRevoked    = "api_key.revoked"
+
+	AuditOAuthClientCreated  = "oauth_client.created"
+	AuditOAuthClientDeleted  = "oauth_client.deleted"
+	AuditOAuthSecretRotated  = "oauth_client.secret_rotated"
+	AuditOAuthConsentGranted = "oauth_consent.granted"
+	AuditOAuthConsentRevoked = "oauth_consent.revoked"
+	AuditOAuthTokenReused    = "oauth.refresh_reused"
+
+	AuditImpersonationStarted = "impersonation.started"
+	AuditImpersonationEnded   = "impersonation.ended"
+	AuditImpersonatedRequest  = "impersonation.request"
+
+	AuditMemberStatusChanged = "member.status_changed"
+	AuditMemberDeleted       = "member.deleted"
+	AuditMemberRestored      = "member.restored"
+	AuditMembersImported     = "member.imported"
+	AuditMembersExported     = "member.exported"
+	AuditMemberMerged        = "member.merged"
+	AuditMemberGroupsChanged = "member.groups_changed"
+
+	AuditGroupCreated = "group.created"
+	AuditGroupDeleted = "group.deleted"
+
+	AuditProductRestored = "product.restored"
+
+	AuditPointsAdjusted = "points.adjusted"
+
+	AuditDataExportRequested = "privacy.export_requested"
+	AuditMemberErased        = "privacy.member_erased"
+)
+
+// AuditEntry 要寫入審計紀錄的事件
+type AuditEntry struct {
+	Action    string
+	MemberID  uint
+	ActorID   uint
+	IP        string
+	UserAgent string
+	Detail    string
+}
+
+type AuditService struct {
+	DB *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{DB: db}
+}
+
+// Record 寫入一筆審計紀錄
+func (s *AuditService) Record(entry AuditEntry) error {
+	now := time.Now()
+	return s.DB.Create(&models.AuditLog{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    entry.ActorID,
+		},
+		Action:    entry.Action,
+		MemberID:  entry.MemberID,
+		ActorID:   entry.ActorID,
+		IP:        entry.IP,
+		UserAgent: truncate(entry.UserAgent, 512),
+		Detail:    entry.Detail,
+	}).Error
+}
+
+// TryRecord 寫入審計紀錄，失敗只記錄日誌，不影響主要流程
+func (s *AuditService) TryRecord(entry AuditEntry) {
+	if err := s.Record(entry); err != nil {
+		log.Printf("[Audit] failed to record %s: %v\n", entry.Action, err)
+	}
+}
+
+// AuditFilter 查詢審計紀錄的條件，零值表示不過濾
+type AuditFilter struct {
+	Action   string
+	MemberID uint
+	ActorID  uint
+	Since    *time.Time
+	Until    *time.Time
+}
+
+// List 依條件分頁查詢審計紀錄，新的在前，同時返回總數
+func (s *AuditService) List(ctx context.Context, filter AuditFilter, limit, offset int) ([]models.AuditLog, int64, error) {
+	query := s.DB.WithContext(ctx).Model(&models.AuditLog{})
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.MemberID != 0 {
+		query = query.Where("member_id = ?", filter.MemberID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Since != nil {
+		query = query.Where("creation_time >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("creation_time < ?", *filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// truncate 將字串截斷為最多 max 個位元組，不切斷多位元組字元
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
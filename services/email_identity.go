@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"member_API/auth"
+	"member_API/models"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrEmailInUse = errors.New("email 已被使用")
+
+const (
+	// memberEmailIndex 未刪除會員的 email 唯一索引，不分大小寫；回收桶中的會員不佔用 email
+	memberEmailIndex = "idx_members_email_active"
+	// legacyMemberEmailIndex 舊版包含已刪除會員、區分大小寫的唯一索引
+	legacyMemberEmailIndex = "idx_members_email"
+)
+
+// ByEmail 依標準形式的 email 查詢會員，不分大小寫；與 memberEmailIndex 使用相同的運算式
+func ByEmail(email string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("LOWER(email) = ?", auth.NormalizeEmail(email))
+	}
+}
+
+// emailConflict 將 email 唯一索引的衝突（並行建立或修改相同 email）轉換為 ErrEmailInUse
+func emailConflict(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrEmailInUse
+	}
+	return err
+}
+
+// EmailCollision 多位未刪除的會員使用標準形式相同的 email
+type EmailCollision struct {
+	Email     string
+	MemberIDs []uint
+}
+
+// FindEmailCollisions 找出標準形式相同、被多位未刪除會員使用的 email
+func FindEmailCollisions(db *gorm.DB) ([]EmailCollision, error) {
+	var rows []struct {
+		Email string
+		IDs   string
+	}
+	if err := db.Model(&models.Member{}).Scopes(models.NotDeleted).
+		Select("LOWER(TRIM(email)) AS email, string_agg(id::text, ',' ORDER BY id) AS ids").
+		Group("LOWER(TRIM(email))").
+		Having("COUNT(*) > 1").
+		Order("email").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	collisions := make([]EmailCollision, len(rows))
+	for i, row := range rows {
+		collisions[i].Email = row.Email
+		for _, id := range strings.Split(row.IDs, ",") {
+			value, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			collisions[i].MemberIDs = append(collisions[i].MemberIDs, uint(value))
+		}
+	}
+	return collisions, nil
+}
+
+// MigrateMemberEmails 將 email 唯一索引改為不分大小寫、只涵蓋未刪除會員的部分索引，並把既有 email 轉為標準形式。
+// 已有標準形式相同的 email 時不做任何變更並返回衝突清單，舊索引保留，需先合併或修改這些會員後重新執行
+func MigrateMemberEmails(db *gorm.DB) ([]EmailCollision, error) {
+	collisions, err := FindEmailCollisions(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(collisions) > 0 {
+		return collisions, nil
+	}
+
+	return nil, db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DROP INDEX IF EXISTS " + legacyMemberEmailIndex).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + memberEmailIndex +
+			" ON members (LOWER(email)) WHERE is_deleted = false").Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE members SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email))").Error
+	})
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"member_API/mail"
+	"member_API/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailAlreadyVerified = errors.New("電子郵件已完成驗證")
+)
+
+// emailVerificationTTL 驗證 token 的有效期
+var emailVerificationTTL = 24 * time.Hour
+
+// emailVerificationURL 驗證頁面網址，token 以 query string 附加
+var emailVerificationURL = "http://localhost:8080/verify-email"
+
+// SetEmailVerificationOptions 設定驗證 token 有效期與頁面網址，零值會被忽略
+func SetEmailVerificationOptions(ttl time.Duration, url string) {
+	if ttl > 0 {
+		emailVerificationTTL = ttl
+	}
+	if url != "" {
+		emailVerificationURL = url
+	}
+}
+
+type EmailVerificationService struct {
+	DB     *gorm.DB
+	Mailer mail.Sender
+}
+
+func NewEmailVerificationService(db *gorm.DB) *EmailVerificationService {
+	return &EmailVerificationService{DB: db, Mailer: mail.Default()}
+}
+
+// SendVerification 為會員目前的 email 建立驗證 token 並寄出驗證郵件
+func (s *EmailVerificationService) SendVerification(ctx context.Context, member *models.Member) error {
+	if member.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	var rawToken string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rawToken, err = issueMemberToken(tx, member.ID, TokenPurposeEmailVerification, emailVerificationTTL, member.Email)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := mail.Message{
+		To:      member.Email,
+		Subject: "請驗證您的電子郵件",
+		Body: fmt.Sprintf("%s 您好：\n\n請點擊以下連結完成電子郵件驗證，連結將於 %d 小時後失效：\n%s?token=%s\n",
+			member.Name, int(emailVerificationTTL.Hours()), emailVerificationURL, rawToken),
+	}
+	return s.Mailer.Send(ctx, msg)
+}
+
+// ResendVerification 重新寄送驗證郵件給指定會員
+func (s *EmailVerificationService) ResendVerification(ctx context.Context, memberID uint) error {
+	var member models.Member
+	if err := s.DB.Scopes(models.NotDeleted).First(&member, memberID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("會員不存在")
+		}
+		return err
+	}
+	return s.SendVerification(ctx, &member)
+}
+
+// Verify 以驗證 token 確認會員的 email；若 email 在寄出後已被修改，token 視為無效
+func (s *EmailVerificationService) Verify(rawToken string) (*models.Member, error) {
+	var member models.Member
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		record, err := consumeMemberToken(tx, rawToken, TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Scopes(models.NotDeleted).First(&member, record.MemberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidMemberToken
+			}
+			return err
+		}
+		if member.Email != record.Payload {
+			return ErrInvalidMemberToken
+		}
+
+		now := time.Now()
+		member.EmailVerifiedAt = &now
+		return tx.Model(&member).Update("email_verified_at", &now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// trySendVerification 在會員建立或變更 email 後寄送驗證郵件；寄送失敗只記錄日誌，不影響主流程
+func trySendVerification(db *gorm.DB, member *models.Member) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := NewEmailVerificationService(db).SendVerification(ctx, member); err != nil {
+		log.Printf("[EmailVerification] failed to send verification mail to member %d: %v\n", member.ID, err)
+	}
+}
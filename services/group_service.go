@@ -0,0 +1,353 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"member_API/models"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrGroupNotFound    = errors.New("群組不存在")
+	ErrGroupNameTaken   = errors.New("群組名稱已被使用")
+	ErrInvalidGroupName = errors.New("群組名稱需為 1 到 50 個小寫英文字母、數字、- 或 _，並以英文字母或數字開頭")
+	ErrMembersNotFound  = errors.New("會員不存在或已被刪除")
+	ErrNotGroupMember   = errors.New("會員不在此群組中")
+	ErrTooManyMembers   = errors.New("一次加入的會員過多")
+)
+
+// maxGroupMembersPerRequest 一次加入群組的會員數上限
+const maxGroupMembersPerRequest = 1000
+
+var groupNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// GroupSummary 群組與其未刪除的會員人數
+type GroupSummary struct {
+	Group       models.MemberGroup
+	MemberCount int64
+}
+
+// GroupService 管理會員群組（標籤）與會員的群組指派
+type GroupService struct {
+	DB *gorm.DB
+}
+
+func NewGroupService(db *gorm.DB) *GroupService {
+	return &GroupService{DB: db}
+}
+
+// ListGroups 列出所有群組與其會員人數，依名稱排序
+func (s *GroupService) ListGroups() ([]GroupSummary, error) {
+	var groups []models.MemberGroup
+	if err := s.DB.Scopes(models.NotDeleted).Order("name").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		MemberGroupID uint
+		Count         int64
+	}
+	if err := s.DB.Table("member_group_assignments").
+		Select("member_group_assignments.member_group_id, COUNT(*) AS count").
+		Joins("JOIN members ON members.id = member_group_assignments.member_id AND members.is_deleted = ?", false).
+		Group("member_group_assignments.member_group_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	byGroup := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		byGroup[c.MemberGroupID] = c.Count
+	}
+
+	out := make([]GroupSummary, len(groups))
+	for i, group := range groups {
+		out[i] = GroupSummary{Group: group, MemberCount: byGroup[group.ID]}
+	}
+	return out, nil
+}
+
+// GetGroup 取得單一群組
+func (s *GroupService) GetGroup(id uint) (*models.MemberGroup, error) {
+	var group models.MemberGroup
+	if err := s.DB.Scopes(models.NotDeleted).First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// CreateGroup 建立群組；名稱轉為小寫
+func (s *GroupService) CreateGroup(name, description string, actorID uint) (*models.MemberGroup, error) {
+	name, err := normalizeGroupName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	group := &models.MemberGroup{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    actorID,
+		},
+		Name:        name,
+		Description: truncate(strings.TrimSpace(description), 255),
+	}
+	if err := s.DB.Create(group).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrGroupNameTaken
+		}
+		return nil, err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:  AuditGroupCreated,
+		ActorID: actorID,
+		Detail:  "group=" + group.Name,
+	})
+	return group, nil
+}
+
+// UpdateGroup 修改群組名稱與說明；已簽發的 token 在刷新後才會帶有新名稱
+func (s *GroupService) UpdateGroup(id uint, name, description string, actorID uint) (*models.MemberGroup, error) {
+	name, err := normalizeGroupName(name)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.DB.Model(group).Updates(map[string]interface{}{
+		"name":                   name,
+		"description":            truncate(strings.TrimSpace(description), 255),
+		"last_modifier_id":       actorID,
+		"last_modification_time": &now,
+	}).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrGroupNameTaken
+		}
+		return nil, err
+	}
+	return s.GetGroup(id)
+}
+
+// DeleteGroup 軟刪除群組並移除所有會員的指派
+func (s *GroupService) DeleteGroup(id uint, actorID uint) error {
+	var name string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		group, err := NewGroupService(tx).GetGroup(id)
+		if err != nil {
+			return err
+		}
+		name = group.Name
+		if err := tx.Model(group).Updates(models.SoftDeleteColumns(actorID, time.Now())).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM member_group_assignments WHERE member_group_id = ?", id).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:  AuditGroupDeleted,
+		ActorID: actorID,
+		Detail:  "group=" + name,
+	})
+	return nil
+}
+
+// AddMembers 將會員加入群組，已在群組中的會員略過；任一會員不存在時不加入任何會員。返回新加入的人數
+func (s *GroupService) AddMembers(groupID uint, memberIDs []uint, actorID uint) (int64, error) {
+	memberIDs = uniqueIDs(memberIDs)
+	if len(memberIDs) == 0 {
+		return 0, nil
+	}
+	if len(memberIDs) > maxGroupMembersPerRequest {
+		return 0, fmt.Errorf("%w，最多 %d 位", ErrTooManyMembers, maxGroupMembersPerRequest)
+	}
+
+	var added int64
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		group, err := NewGroupService(tx).GetGroup(groupID)
+		if err != nil {
+			return err
+		}
+
+		var found []uint
+		if err := tx.Model(&models.Member{}).Scopes(models.NotDeleted).
+			Where("id IN ?", memberIDs).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		if len(found) != len(memberIDs) {
+			return fmt.Errorf("%w：%v", ErrMembersNotFound, missingIDs(memberIDs, found))
+		}
+
+		rows := make([]map[string]interface{}, len(memberIDs))
+		for i, id := range memberIDs {
+			rows[i] = map[string]interface{}{"member_id": id, "member_group_id": group.ID}
+		}
+		result := tx.Table("member_group_assignments").Clauses(clause.OnConflict{DoNothing: true}).Create(rows)
+		added = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if added > 0 {
+		NewAuditService(s.DB).TryRecord(AuditEntry{
+			Action:  AuditMemberGroupsChanged,
+			ActorID: actorID,
+			Detail:  fmt.Sprintf("group_id=%d added=%d", groupID, added),
+		})
+	}
+	return added, nil
+}
+
+// RemoveMember 將會員移出群組
+func (s *GroupService) RemoveMember(groupID, memberID uint, actorID uint) error {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return err
+	}
+	result := s.DB.Exec("DELETE FROM member_group_assignments WHERE member_group_id = ? AND member_id = ?", groupID, memberID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotGroupMember
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditMemberGroupsChanged,
+		MemberID: memberID,
+		ActorID:  actorID,
+		Detail:   fmt.Sprintf("group_id=%d removed", groupID),
+	})
+	return nil
+}
+
+// SetMemberGroups 以指定群組完全取代會員目前的群組
+func (s *GroupService) SetMemberGroups(memberID uint, names []string, actorID uint) (*models.Member, error) {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name, err := normalizeGroupName(name)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, name)
+	}
+	normalized = uniqueStrings(normalized)
+
+	var member models.Member
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(models.NotDeleted).First(&member, memberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("會員不存在")
+			}
+			return err
+		}
+
+		groups := make([]models.MemberGroup, 0, len(normalized))
+		if len(normalized) > 0 {
+			if err := tx.Scopes(models.NotDeleted).Where("name IN ?", normalized).Find(&groups).Error; err != nil {
+				return err
+			}
+			if len(groups) != len(normalized) {
+				return ErrGroupNotFound
+			}
+		}
+		return tx.Model(&member).Association("Groups").Replace(groups)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(normalized)
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditMemberGroupsChanged,
+		MemberID: memberID,
+		ActorID:  actorID,
+		Detail:   "groups=" + strings.Join(normalized, ","),
+	})
+
+	member.Groups, err = s.MemberGroups(memberID)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// MemberGroups 取得會員所屬的群組，依名稱排序
+func (s *GroupService) MemberGroups(memberID uint) ([]models.MemberGroup, error) {
+	var groups []models.MemberGroup
+	if err := s.DB.Joins("JOIN member_group_assignments ON member_group_assignments.member_group_id = member_groups.id").
+		Scopes(models.NotDeleted).
+		Where("member_group_assignments.member_id = ?", memberID).
+		Order("member_groups.name").
+		Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// MemberGroupNames 取得會員所屬群組的名稱（已排序），用於簽發 token
+func (s *GroupService) MemberGroupNames(memberID uint) ([]string, error) {
+	groups, err := s.MemberGroups(memberID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(groups))
+	for i, group := range groups {
+		names[i] = group.Name
+	}
+	return names, nil
+}
+
+// normalizeGroupName 去除前後空白並轉為小寫後驗證群組名稱
+func normalizeGroupName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !groupNamePattern.MatchString(name) {
+		return "", ErrInvalidGroupName
+	}
+	return name, nil
+}
+
+// uniqueIDs 去除重複與為 0 的 ID 並保留原順序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]struct{}, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok || id == 0 {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+// missingIDs 返回 want 中不在 found 的 ID
+func missingIDs(want, found []uint) []uint {
+	present := make(map[uint]struct{}, len(found))
+	for _, id := range found {
+		present[id] = struct{}{}
+	}
+	var missing []uint
+	for _, id := range want {
+		if _, ok := present[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
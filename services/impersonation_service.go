@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"member_API/auth"
+	"member_API/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrImpersonationNotAllowed = errors.New("不可代理登入此會員")
+	ErrImpersonationNotFound   = errors.New("代理登入不存在或已結束")
+	ErrImpersonationReason     = errors.New("請說明代理登入的原因")
+)
+
+// ImpersonationResult 代理登入的 token 與紀錄
+type ImpersonationResult struct {
+	AccessToken string
+	ExpiresIn   int64
+	Session     *models.ImpersonationSession
+	Member      *models.Member
+}
+
+type ImpersonationService struct {
+	DB *gorm.DB
+}
+
+func NewImpersonationService(db *gorm.DB) *ImpersonationService {
+	return &ImpersonationService{DB: db}
+}
+
+// Start 由管理員代理會員登入，簽發短效且不可刷新的 token。
+// 不可代理自己或服務帳號；目標會員的權限必須是管理員權限的子集，避免藉由代理登入取得更高權限
+func (s *ImpersonationService) Start(actorID, memberID uint, reason string, meta SessionMetadata) (*ImpersonationResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrImpersonationReason
+	}
+	if actorID == memberID {
+		return nil, fmt.Errorf("%w：不可代理自己", ErrImpersonationNotAllowed)
+	}
+
+	memberSvc := NewMemberService(s.DB)
+	actor, err := memberSvc.GetMemberByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := memberSvc.GetMemberByID(memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member.ServiceAccount {
+		return nil, fmt.Errorf("%w：不可代理服務帳號", ErrImpersonationNotAllowed)
+	}
+
+	roleSvc := NewRoleService(s.DB)
+	_, actorPermissions, err := roleSvc.GetMemberAuthz(actorID)
+	if err != nil {
+		return nil, err
+	}
+	roles, permissions, err := roleSvc.GetMemberAuthz(memberID)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range permissions {
+		if !containsString(actorPermissions, permission) {
+			return nil, fmt.Errorf("%w：會員擁有管理員沒有的 %s 權限", ErrImpersonationNotAllowed, permission)
+		}
+	}
+
+	sessionID, err := auth.GenerateOpaqueToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &models.ImpersonationSession{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    actorID,
+		},
+		SessionID: sessionID,
+		ActorID:   actorID,
+		MemberID:  memberID,
+		Reason:    truncate(reason, 500),
+		IP:        meta.IP,
+		UserAgent: truncate(meta.UserAgent, 512),
+		ExpiresAt: now.Add(auth.ImpersonationTTL()),
+	}
+	if err := s.DB.Create(session).Error; err != nil {
+		return nil, err
+	}
+	groups, err := NewGroupService(s.DB).MemberGroupNames(member.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := auth.NewClaims(int64(member.ID), member.Email)
+	claims.SessionID = sessionID
+	claims.Roles = roles
+	claims.Permissions = permissions
+	claims.Groups = groups
+	claims.EmailVerified = member.EmailVerifiedAt != nil
+	token, err := auth.SignImpersonationToken(claims, actor.ID, actor.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:    AuditImpersonationStarted,
+		MemberID:  memberID,
+		ActorID:   actorID,
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+		Detail:    fmt.Sprintf("session=%s reason=%s", sessionID, session.Reason),
+	})
+	return &ImpersonationResult{
+		AccessToken: token,
+		ExpiresIn:   int64(auth.ImpersonationTTL().Seconds()),
+		Session:     session,
+		Member:      member,
+	}, nil
+}
+
+// End 結束代理登入，以該代理登入簽發的 token 立即失效
+func (s *ImpersonationService) End(sessionID string, actorID uint) error {
+	now := time.Now()
+	var session models.ImpersonationSession
+	if err := s.DB.Where("session_id = ? AND ended_at IS NULL", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrImpersonationNotFound
+		}
+		return err
+	}
+	if err := s.DB.Model(&session).Updates(map[string]interface{}{
+		"ended_at":               &now,
+		"last_modifier_id":       actorID,
+		"last_modification_time": &now,
+	}).Error; err != nil {
+		return err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditImpersonationEnded,
+		MemberID: session.MemberID,
+		ActorID:  actorID,
+		Detail:   "session=" + sessionID,
+	})
+	return nil
+}
+
+// ListActive 列出尚未結束且未過期的代理登入，新的在前
+func (s *ImpersonationService) ListActive() ([]models.ImpersonationSession, error) {
+	var sessions []models.ImpersonationSession
+	if err := s.DB.Where("ended_at IS NULL AND expires_at > ?", time.Now()).
+		Order("creation_time DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Get 取得代理登入紀錄
+func (s *ImpersonationService) Get(sessionID string) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	if err := s.DB.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImpersonationNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Record 將代理登入期間的請求寫入審計紀錄；供 auth.SetImpersonationRecorder 使用
+func (s *ImpersonationService) Record(ctx context.Context, principal *auth.Principal, req auth.ImpersonatedRequest) {
+	NewAuditService(s.DB.WithContext(ctx)).TryRecord(AuditEntry{
+		Action:    AuditImpersonatedRequest,
+		MemberID:  principal.UserID,
+		ActorID:   principal.ActorID,
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+		Detail:    fmt.Sprintf("%s %s %d session=%s", req.Method, truncate(req.Path, 200), req.Status, principal.SessionID),
+	})
+}
+
+// validateImpersonation 檢查代理登入 token 的紀錄仍有效，且目標會員與管理員都與 token 相符
+func validateImpersonation(db *gorm.DB, claims *auth.Claims) error {
+	if claims.SessionID == "" {
+		return auth.ErrSessionRevoked
+	}
+
+	var session models.ImpersonationSession
+	if err := db.Where("session_id = ?", claims.SessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.ErrSessionRevoked
+		}
+		return err
+	}
+	if session.EndedAt != nil || time.Now().After(session.ExpiresAt) ||
+		int64(session.MemberID) != claims.UserID || session.ActorID != claims.ActorID() {
+		return auth.ErrSessionRevoked
+	}
+	return nil
+}
+
+// endMemberImpersonations 結束會員所有進行中的代理登入
+func endMemberImpersonations(tx *gorm.DB, memberID uint, now time.Time) error {
+	return tx.Model(&models.ImpersonationSession{}).
+		Where("member_id = ? AND ended_at IS NULL", memberID).
+		Updates(map[string]interface{}{
+			"ended_at":               &now,
+			"last_modification_time": &now,
+		}).Error
+}
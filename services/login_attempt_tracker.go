@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"member_API/auth"
+	"member_API/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DBAttemptTracker 以資料庫保存登入失敗狀態，多個實例可共用同一份紀錄
+type DBAttemptTracker struct {
+	DB *gorm.DB
+}
+
+func NewDBAttemptTracker(db *gorm.DB) *DBAttemptTracker {
+	return &DBAttemptTracker{DB: db}
+}
+
+var _ auth.LoginAttemptTracker = (*DBAttemptTracker)(nil)
+
+func (t *DBAttemptTracker) Get(ctx context.Context, key string) (auth.AttemptState, error) {
+	var record models.LoginAttempt
+	if err := t.DB.WithContext(ctx).Where("key = ?", key).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.AttemptState{}, nil
+		}
+		return auth.AttemptState{}, err
+	}
+	return attemptStateFromRecord(&record), nil
+}
+
+func (t *DBAttemptTracker) RecordFailure(ctx context.Context, key string, policy auth.LockoutPolicy) (auth.AttemptState, error) {
+	var state auth.AttemptState
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先確保紀錄存在，再以 FOR UPDATE 鎖定，避免並行失敗互相覆蓋計數
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginAttempt{Key: key}).Error; err != nil {
+			return err
+		}
+
+		var record models.LoginAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).
+			First(&record).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		state = policy.Fail(attemptStateFromRecord(&record), now)
+
+		var blockedUntil *time.Time
+		if !state.BlockedUntil.IsZero() {
+			blockedUntil = &state.BlockedUntil
+		}
+		return tx.Model(&record).Updates(map[string]interface{}{
+			"failures":               state.Failures,
+			"last_failure_at":        &now,
+			"blocked_until":          blockedUntil,
+			"locked":                 state.Locked,
+			"last_modification_time": &now,
+		}).Error
+	})
+	if err != nil {
+		return auth.AttemptState{}, err
+	}
+
+	return state, nil
+}
+
+func (t *DBAttemptTracker) Reset(ctx context.Context, key string) error {
+	return t.DB.WithContext(ctx).Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+// PurgeExpired 刪除已無作用的紀錄：最後一次失敗早於 olderThan 且沒有仍在生效的退避或鎖定
+func (t *DBAttemptTracker) PurgeExpired(ctx context.Context, olderThan time.Duration) (int64, error) {
+	now := time.Now()
+	result := t.DB.WithContext(ctx).
+		Where("last_failure_at < ? AND (blocked_until IS NULL OR blocked_until < ?)", now.Add(-olderThan), now).
+		Delete(&models.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
+
+// RunPurge 每隔 interval 刪除一次已無作用的登入失敗紀錄，直到 ctx 結束；
+// 隨機帳號或 IP 的失敗嘗試因此不會讓資料表無限增長
+func (t *DBAttemptTracker) RunPurge(ctx context.Context, interval, olderThan time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := t.PurgeExpired(ctx, olderThan)
+			if err != nil {
+				log.Printf("[LoginAttempt] Warning: purging expired login attempts failed: %v\n", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("[LoginAttempt] Purged %d expired login attempts\n", deleted)
+			}
+		}
+	}
+}
+
+func attemptStateFromRecord(record *models.LoginAttempt) auth.AttemptState {
+	state := auth.AttemptState{
+		Failures: record.Failures,
+		Locked:   record.Locked,
+	}
+	if record.LastFailureAt != nil {
+		state.LastFailureAt = *record.LastFailureAt
+	}
+	if record.BlockedUntil != nil {
+		state.BlockedUntil = *record.BlockedUntil
+	}
+	return state
+}
@@ -0,0 +1,659 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"member_API/auth"
+	"member_API/models"
+	netmail "net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnsupportedBulkFormat    = errors.New("不支援的格式，需為 csv 或 ndjson")
+	ErrInvalidDuplicateStrategy = errors.New("不支援的重複 email 處理方式，需為 skip、update 或 fail")
+	ErrTooManyImportRows        = errors.New("匯入筆數超過上限")
+	ErrInvalidImportHeader      = errors.New("CSV 標題列錯誤")
+)
+
+// 批次匯入與匯出支援的格式
+const (
+	BulkFormatCSV    = "csv"
+	BulkFormatNDJSON = "ndjson"
+)
+
+// 匯入時 email 已屬於其他會員的處理方式
+const (
+	DuplicateSkip   = "skip"
+	DuplicateUpdate = "update"
+	DuplicateFail   = "fail"
+)
+
+// memberExportBatchSize 匯出時每次從資料庫讀取的會員數
+const memberExportBatchSize = 500
+
+// MemberRecord 批次匯入與匯出的一筆會員資料，CSV 欄位名稱與 JSON 欄位相同。
+// id、status 與 created_at 只在匯出時填入，匯入時忽略；password 為明文密碼，password_hash 為其他系統產生的 argon2id 或 bcrypt 雜湊，兩者擇一或都不填
+type MemberRecord struct {
+	ID            uint   `json:"id,omitempty"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Password      string `json:"password,omitempty"`
+	PasswordHash  string `json:"password_hash,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+	Status        string `json:"status,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	Birthday      string `json:"birthday,omitempty"`
+	AvatarURL     string `json:"avatar_url,omitempty"`
+	Locale        string `json:"locale,omitempty"`
+	Timezone      string `json:"timezone,omitempty"`
+	CreatedAt     string `json:"created_at,omitempty"`
+}
+
+// memberRecordColumns CSV 的欄位順序；匯入時標題列可只包含部分欄位，順序不限
+var memberRecordColumns = []string{
+	"id", "name", "email", "password", "password_hash", "email_verified", "status",
+	"phone", "birthday", "avatar_url", "locale", "timezone", "created_at",
+}
+
+// ImportOptions 批次匯入的設定
+type ImportOptions struct {
+	Format string
+	// OnDuplicate email 已屬於未刪除的會員時的處理方式，預設 fail
+	OnDuplicate string
+	// DryRun 只驗證並回報結果，不寫入任何資料
+	DryRun bool
+	// MaxRows 大於 0 時限制資料列數
+	MaxRows int
+	ActorID uint
+}
+
+// ImportRowError 一列資料的錯誤；Line 為該列在檔案中的行號
+type ImportRowError struct {
+	Line   int               `json:"line" example:"3"`
+	Email  string            `json:"email,omitempty" example:"user@example.com"`
+	Error  string            `json:"error" example:"資料格式錯誤：phone：手機號碼格式錯誤"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// ImportResult 批次匯入的結果；Aborted 表示因重複 email 中止，所有變更皆已復原
+type ImportResult struct {
+	DryRun  bool             `json:"dry_run"`
+	Aborted bool             `json:"aborted"`
+	Total   int              `json:"total"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Skipped int              `json:"skipped"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+func (r *ImportResult) fail(line int, email string, err error) {
+	r.Failed++
+	rowErr := ImportRowError{Line: line, Email: email, Error: err.Error()}
+	var validation *ProfileValidationError
+	if errors.As(err, &validation) {
+		rowErr.Fields = validation.Fields
+	}
+	r.Errors = append(r.Errors, rowErr)
+}
+
+// ExportOptions 批次匯出的設定
+type ExportOptions struct {
+	Format string
+	// IncludePasswordHash 匯出密碼雜湊，僅供搬移至其他系統時由命令列使用
+	IncludePasswordHash bool
+}
+
+// errImportRollback 結束匯入 transaction 並復原所有變更（dry run 或中止）
+var errImportRollback = errors.New("import rolled back")
+
+// MemberBulkService 以 CSV 或 NDJSON 批次匯入與匯出會員
+type MemberBulkService struct {
+	DB *gorm.DB
+}
+
+func NewMemberBulkService(db *gorm.DB) *MemberBulkService {
+	return &MemberBulkService{DB: db}
+}
+
+// Import 逐列驗證並匯入會員，整個匯入在同一個 transaction 中執行。
+// 格式錯誤的資料列記錄在結果中並略過，其餘資料列照常匯入；OnDuplicate 為 fail 時遇到已存在的 email 即中止並復原所有變更。
+// 新會員為 active 並指派預設角色，不會寄送驗證郵件
+func (s *MemberBulkService) Import(r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	if opts.OnDuplicate == "" {
+		opts.OnDuplicate = DuplicateFail
+	}
+	switch opts.OnDuplicate {
+	case DuplicateSkip, DuplicateUpdate, DuplicateFail:
+	default:
+		return nil, ErrInvalidDuplicateStrategy
+	}
+	reader, err := newMemberRecordReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{DryRun: opts.DryRun, Errors: []ImportRowError{}}
+	seen := map[string]int{}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		for {
+			line, record, err := reader.next()
+			if err == io.EOF {
+				break
+			}
+			result.Total++
+			if opts.MaxRows > 0 && result.Total > opts.MaxRows {
+				return fmt.Errorf("%w（%d 筆）", ErrTooManyImportRows, opts.MaxRows)
+			}
+			if err != nil {
+				var rowErr *recordError
+				if !errors.As(err, &rowErr) {
+					return err
+				}
+				result.fail(line, "", rowErr)
+				continue
+			}
+
+			if err := s.importRecord(tx, line, record, opts, seen, result); err != nil {
+				return err
+			}
+			if result.Aborted {
+				break
+			}
+		}
+		if opts.DryRun || result.Aborted {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, err
+	}
+
+	if !opts.DryRun && !result.Aborted && result.Created+result.Updated > 0 {
+		NewAuditService(s.DB).TryRecord(AuditEntry{
+			Action:  AuditMembersImported,
+			ActorID: opts.ActorID,
+			Detail: fmt.Sprintf("format=%s on_duplicate=%s created=%d updated=%d skipped=%d failed=%d",
+				opts.Format, opts.OnDuplicate, result.Created, result.Updated, result.Skipped, result.Failed),
+		})
+	}
+	return result, nil
+}
+
+// importRecord 匯入一列資料；資料列的錯誤記錄在 result 中，只有無法繼續匯入時才返回錯誤
+func (s *MemberBulkService) importRecord(tx *gorm.DB, line int, record MemberRecord, opts ImportOptions, seen map[string]int, result *ImportResult) error {
+	record.Name = strings.TrimSpace(record.Name)
+	record.Email = auth.NormalizeEmail(record.Email)
+	profile, err := validateMemberRecord(record)
+	if err != nil {
+		result.fail(line, record.Email, err)
+		return nil
+	}
+
+	if first, ok := seen[record.Email]; ok {
+		result.fail(line, record.Email, fmt.Errorf("email 與第 %d 行重複", first))
+		return nil
+	}
+	seen[record.Email] = line
+
+	var existing models.Member
+	err = tx.Scopes(models.NotDeleted, ByEmail(record.Email)).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	found := err == nil
+
+	if found {
+		switch opts.OnDuplicate {
+		case DuplicateSkip:
+			result.Skipped++
+			return nil
+		case DuplicateFail:
+			result.fail(line, record.Email, ErrEmailInUse)
+			result.Aborted = true
+			return nil
+		}
+		if existing.ServiceAccount {
+			result.fail(line, record.Email, errors.New("不可以匯入資料覆蓋服務帳號"))
+			return nil
+		}
+	}
+
+	// 密碼雜湊很耗時，dry run 只驗證不雜湊
+	hash := record.PasswordHash
+	if record.Password != "" && !opts.DryRun {
+		if hash, err = auth.HashPassword(record.Password); err != nil {
+			return err
+		}
+	}
+
+	// 每列使用 savepoint，寫入失敗只復原該列
+	err = tx.Transaction(func(rowTx *gorm.DB) error {
+		if found {
+			return updateImportedMember(rowTx, &existing, record, hash, profile, opts.ActorID)
+		}
+		return createImportedMember(rowTx, record, hash, profile, opts.ActorID)
+	})
+	if err != nil {
+		result.fail(line, record.Email, emailConflict(err))
+		return nil
+	}
+
+	if found {
+		result.Updated++
+	} else {
+		result.Created++
+	}
+	return nil
+}
+
+// validateMemberRecord 驗證一列匯入資料，返回要寫入的個人檔案欄位
+func validateMemberRecord(record MemberRecord) (map[string]interface{}, error) {
+	errs := fieldErrors{}
+	if record.Name == "" {
+		errs["name"] = "必填"
+	} else if len(record.Name) > 255 {
+		errs["name"] = "不可超過 255 個字元"
+	}
+	if record.Email == "" {
+		errs["email"] = "必填"
+	} else if addr, err := netmail.ParseAddress(record.Email); err != nil || addr.Address != record.Email || len(record.Email) > 255 {
+		errs["email"] = "email 格式錯誤"
+	}
+
+	switch {
+	case record.Password != "" && record.PasswordHash != "":
+		errs["password"] = "password 與 password_hash 只能擇一"
+	case record.Password != "" && errs["email"] == "" && errs["name"] == "":
+		if err := auth.ValidatePassword(record.Password, record.Email, record.Name); err != nil {
+			errs["password"] = err.Error()
+		}
+	case record.PasswordHash != "" && !auth.IsPasswordHash(record.PasswordHash):
+		errs["password_hash"] = "需為 argon2id 或 bcrypt 雜湊"
+	}
+
+	// 空白欄位表示不提供，更新既有會員時保留原值
+	var input ProfileInput
+	for _, field := range []struct {
+		value  string
+		target **string
+	}{
+		{record.Phone, &input.Phone},
+		{record.Birthday, &input.Birthday},
+		{record.AvatarURL, &input.AvatarURL},
+		{record.Locale, &input.Locale},
+		{record.Timezone, &input.Timezone},
+	} {
+		if value := strings.TrimSpace(field.value); value != "" {
+			*field.target = &value
+		}
+	}
+	profile, err := profileUpdates(input)
+	if err != nil {
+		var validation *ProfileValidationError
+		if !errors.As(err, &validation) {
+			return nil, err
+		}
+		for name, message := range validation.Fields {
+			errs[name] = message
+		}
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func createImportedMember(tx *gorm.DB, record MemberRecord, hash string, profile map[string]interface{}, actorID uint) error {
+	now := time.Now()
+	member := &models.Member{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    actorID,
+		},
+		Name:         record.Name,
+		Email:        record.Email,
+		PasswordHash: hash,
+		Status:       models.MemberStatusActive,
+	}
+	if record.EmailVerified {
+		member.EmailVerifiedAt = &now
+	}
+	if err := tx.Create(member).Error; err != nil {
+		return err
+	}
+	if len(profile) > 0 {
+		if err := tx.Model(member).Updates(profile).Error; err != nil {
+			return err
+		}
+	}
+	return NewRoleService(tx).AssignDefaultRole(member.ID)
+}
+
+// updateImportedMember 以匯入資料更新既有會員；變更密碼時撤銷該會員所有登入
+func updateImportedMember(tx *gorm.DB, member *models.Member, record MemberRecord, hash string, profile map[string]interface{}, actorID uint) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"name":                   record.Name,
+		"last_modifier_id":       actorID,
+		"last_modification_time": &now,
+	}
+	for column, value := range profile {
+		updates[column] = value
+	}
+	if hash != "" {
+		updates["password_hash"] = hash
+	}
+	if record.EmailVerified && member.EmailVerifiedAt == nil {
+		updates["email_verified_at"] = &now
+	}
+	if err := tx.Model(member).Updates(updates).Error; err != nil {
+		return err
+	}
+	if record.Password != "" || record.PasswordHash != "" {
+		if _, err := revokeMemberSessions(tx, member.ID, "", now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export 依 ID 順序分批讀取未刪除的會員並寫入 w，不會一次載入整個會員表；服務帳號不匯出。返回匯出的筆數
+func (s *MemberBulkService) Export(w io.Writer, opts ExportOptions) (int, error) {
+	writer, err := newMemberRecordWriter(w, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var members []models.Member
+	now := time.Now()
+	result := s.DB.Scopes(models.NotDeleted).
+		Where("service_account = ?", false).
+		FindInBatches(&members, memberExportBatchSize, func(tx *gorm.DB, batch int) error {
+			for i := range members {
+				if err := writer.write(exportRecord(&members[i], opts.IncludePasswordHash, now)); err != nil {
+					return err
+				}
+				count++
+			}
+			return writer.flush()
+		})
+	if result.Error != nil {
+		return count, result.Error
+	}
+	return count, writer.flush()
+}
+
+// RecordExport 寫入會員匯出的審計紀錄；匯出以串流回應，由呼叫端在完成後記錄
+func (s *MemberBulkService) RecordExport(actorID uint, format string, count int) {
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:  AuditMembersExported,
+		ActorID: actorID,
+		Detail:  fmt.Sprintf("format=%s count=%d", format, count),
+	})
+}
+
+func exportRecord(member *models.Member, includePasswordHash bool, now time.Time) MemberRecord {
+	record := MemberRecord{
+		ID:            member.ID,
+		Name:          member.Name,
+		Email:         member.Email,
+		EmailVerified: member.EmailVerifiedAt != nil,
+		Status:        EffectiveMemberStatus(member, now),
+		Phone:         member.Phone,
+		AvatarURL:     member.AvatarURL,
+		Locale:        member.Locale,
+		Timezone:      member.Timezone,
+		CreatedAt:     member.CreationTime.UTC().Format(time.RFC3339),
+	}
+	if member.Birthday != nil {
+		record.Birthday = member.Birthday.Format("2006-01-02")
+	}
+	if includePasswordHash {
+		record.PasswordHash = member.PasswordHash
+	}
+	return record
+}
+
+// recordError 單一資料列無法解析，匯入時略過該列
+type recordError struct {
+	msg string
+}
+
+func (e *recordError) Error() string {
+	return e.msg
+}
+
+// memberRecordReader 逐列讀取匯入資料；讀完返回 io.EOF
+type memberRecordReader interface {
+	next() (line int, record MemberRecord, err error)
+}
+
+func newMemberRecordReader(r io.Reader, format string) (memberRecordReader, error) {
+	switch format {
+	case BulkFormatCSV:
+		return newCSVRecordReader(r)
+	case BulkFormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &ndjsonRecordReader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedBulkFormat
+	}
+}
+
+type csvRecordReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+// newCSVRecordReader 讀取並檢查標題列；必須包含 name 與 email，不可有未知或重複的欄位
+func newCSVRecordReader(r io.Reader) (*csvRecordReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w：檔案是空的", ErrInvalidImportHeader)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w：%v", ErrInvalidImportHeader, err)
+	}
+
+	known := map[string]bool{}
+	for _, column := range memberRecordColumns {
+		known[column] = true
+	}
+	seen := map[string]bool{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		if !known[column] {
+			return nil, fmt.Errorf("%w：未知的欄位 %q，可用欄位為 %s", ErrInvalidImportHeader, column, strings.Join(memberRecordColumns, ","))
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("%w：欄位 %q 重複", ErrInvalidImportHeader, column)
+		}
+		seen[column] = true
+		header[i] = column
+	}
+	if !seen["name"] || !seen["email"] {
+		return nil, fmt.Errorf("%w：必須包含 name 與 email 欄位", ErrInvalidImportHeader)
+	}
+	return &csvRecordReader{reader: reader, columns: header}, nil
+}
+
+func (r *csvRecordReader) next() (int, MemberRecord, error) {
+	var record MemberRecord
+	fields, err := r.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, record, &recordError{msg: parseErr.Err.Error()}
+		}
+		return 0, record, err
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	for i, column := range r.columns {
+		value := fields[i]
+		switch column {
+		case "name":
+			record.Name = value
+		case "email":
+			record.Email = value
+		case "password":
+			record.Password = value
+		case "password_hash":
+			record.PasswordHash = strings.TrimSpace(value)
+		case "email_verified":
+			if value = strings.TrimSpace(value); value != "" {
+				verified, err := strconv.ParseBool(value)
+				if err != nil {
+					return line, record, &recordError{msg: "email_verified 需為 true 或 false"}
+				}
+				record.EmailVerified = verified
+			}
+		case "phone":
+			record.Phone = value
+		case "birthday":
+			record.Birthday = value
+		case "avatar_url":
+			record.AvatarURL = value
+		case "locale":
+			record.Locale = value
+		case "timezone":
+			record.Timezone = value
+		}
+	}
+	return line, record, nil
+}
+
+type ndjsonRecordReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// next 讀取下一個非空白行，每行為一個 JSON 物件；不可有未知欄位
+func (r *ndjsonRecordReader) next() (int, MemberRecord, error) {
+	var record MemberRecord
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			return r.line, record, &recordError{msg: "JSON 格式錯誤：" + err.Error()}
+		}
+		if decoder.More() {
+			return r.line, record, &recordError{msg: "JSON 格式錯誤：每行只能有一個物件"}
+		}
+		return r.line, record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return r.line + 1, record, err
+	}
+	return r.line, record, io.EOF
+}
+
+// memberRecordWriter 依格式寫出匯出資料
+type memberRecordWriter interface {
+	write(record MemberRecord) error
+	flush() error
+}
+
+func newMemberRecordWriter(w io.Writer, opts ExportOptions) (memberRecordWriter, error) {
+	switch opts.Format {
+	case BulkFormatCSV:
+		columns := make([]string, 0, len(memberRecordColumns))
+		for _, column := range memberRecordColumns {
+			if column == "password" || (column == "password_hash" && !opts.IncludePasswordHash) {
+				continue
+			}
+			columns = append(columns, column)
+		}
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+		return &csvRecordWriter{writer: writer, columns: columns}, nil
+	case BulkFormatNDJSON:
+		buffered := bufio.NewWriter(w)
+		return &ndjsonRecordWriter{writer: buffered, encoder: json.NewEncoder(buffered)}, nil
+	default:
+		return nil, ErrUnsupportedBulkFormat
+	}
+}
+
+type csvRecordWriter struct {
+	writer  *csv.Writer
+	columns []string
+}
+
+func (w *csvRecordWriter) write(record MemberRecord) error {
+	fields := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		switch column {
+		case "id":
+			fields[i] = strconv.FormatUint(uint64(record.ID), 10)
+		case "name":
+			fields[i] = record.Name
+		case "email":
+			fields[i] = record.Email
+		case "password_hash":
+			fields[i] = record.PasswordHash
+		case "email_verified":
+			fields[i] = strconv.FormatBool(record.EmailVerified)
+		case "status":
+			fields[i] = record.Status
+		case "phone":
+			fields[i] = record.Phone
+		case "birthday":
+			fields[i] = record.Birthday
+		case "avatar_url":
+			fields[i] = record.AvatarURL
+		case "locale":
+			fields[i] = record.Locale
+		case "timezone":
+			fields[i] = record.Timezone
+		case "created_at":
+			fields[i] = record.CreatedAt
+		}
+	}
+	return w.writer.Write(fields)
+}
+
+func (w *csvRecordWriter) flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ndjsonRecordWriter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (w *ndjsonRecordWriter) write(record MemberRecord) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *ndjsonRecordWriter) flush() error {
+	return w.writer.Flush()
+}
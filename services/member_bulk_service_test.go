@@ -0,0 +1,206 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parsedRecord 一列讀取結果；err 為該列的錯誤訊息
+type parsedRecord struct {
+	line   int
+	record MemberRecord
+	err    string
+}
+
+func readAllRecords(t *testing.T, reader memberRecordReader) []parsedRecord {
+	t.Helper()
+	var out []parsedRecord
+	for {
+		line, record, err := reader.next()
+		if err == io.EOF {
+			return out
+		}
+		var recErr *recordError
+		if err != nil && !errors.As(err, &recErr) {
+			require.NoError(t, err)
+		}
+		parsed := parsedRecord{line: line}
+		if recErr != nil {
+			parsed.err = recErr.msg
+		} else {
+			parsed.record = record
+		}
+		out = append(out, parsed)
+	}
+}
+
+func TestNewCSVRecordReaderHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		columns []string
+		errMsg  string
+	}{
+		{name: "最少欄位", input: "name,email\n", columns: []string{"name", "email"}},
+		{name: "BOM、大小寫與空白", input: "\ufeffEmail, Name ,PHONE\n", columns: []string{"email", "name", "phone"}},
+		{name: "空檔案", input: "", errMsg: "檔案是空的"},
+		{name: "未知欄位", input: "name,email,nickname\n", errMsg: `未知的欄位 "nickname"`},
+		{name: "重複欄位", input: "name,email,Email\n", errMsg: `欄位 "email" 重複`},
+		{name: "缺少 email", input: "name,phone\n", errMsg: "必須包含 name 與 email 欄位"},
+		{name: "缺少 name", input: "email\n", errMsg: "必須包含 name 與 email 欄位"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := newCSVRecordReader(strings.NewReader(tt.input))
+			if tt.errMsg != "" {
+				assert.ErrorIs(t, err, ErrInvalidImportHeader)
+				assert.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.columns, reader.columns)
+		})
+	}
+}
+
+func TestCSVRecordReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []parsedRecord
+	}{
+		{
+			name:  "依標題列對應欄位",
+			input: "email,name,email_verified,password_hash,phone\nchen@example.com,陳小明,true, $2a$10$hash ,0912345678\n",
+			want: []parsedRecord{{line: 2, record: MemberRecord{
+				Name: "陳小明", Email: "chen@example.com", EmailVerified: true, PasswordHash: "$2a$10$hash", Phone: "0912345678",
+			}}},
+		},
+		{
+			name:  "匯出專用欄位在匯入時忽略",
+			input: "id,name,email,status,created_at\n7,林小華,lin@example.com,banned,2026-01-01T00:00:00Z\n",
+			want:  []parsedRecord{{line: 2, record: MemberRecord{Name: "林小華", Email: "lin@example.com"}}},
+		},
+		{
+			name:  "email_verified 空白視為 false",
+			input: "name,email,email_verified\n王大同,wang@example.com,\n",
+			want:  []parsedRecord{{line: 2, record: MemberRecord{Name: "王大同", Email: "wang@example.com"}}},
+		},
+		{
+			name:  "email_verified 格式錯誤只略過該列",
+			input: "name,email,email_verified\nA,a@example.com,yes\nB,b@example.com,false\n",
+			want: []parsedRecord{
+				{line: 2, err: "email_verified 需為 true 或 false"},
+				{line: 3, record: MemberRecord{Name: "B", Email: "b@example.com"}},
+			},
+		},
+		{
+			name:  "欄位數不符",
+			input: "name,email\nA,a@example.com,extra\nB,b@example.com\n",
+			want: []parsedRecord{
+				{line: 2, err: "wrong number of fields"},
+				{line: 3, record: MemberRecord{Name: "B", Email: "b@example.com"}},
+			},
+		},
+		{
+			name:  "引號內的換行",
+			input: "name,email\n\"多行\n姓名\",multi@example.com\nC,c@example.com\n",
+			want: []parsedRecord{
+				{line: 2, record: MemberRecord{Name: "多行\n姓名", Email: "multi@example.com"}},
+				{line: 4, record: MemberRecord{Name: "C", Email: "c@example.com"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := newMemberRecordReader(strings.NewReader(tt.input), BulkFormatCSV)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, readAllRecords(t, reader))
+		})
+	}
+}
+
+func TestNDJSONRecordReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []parsedRecord
+	}{
+		{
+			name:  "略過空白行並保留行號",
+			input: "{\"name\":\"陳小明\",\"email\":\"chen@example.com\",\"email_verified\":true}\n\n  \n{\"name\":\"B\",\"email\":\"b@example.com\",\"locale\":\"zh-TW\"}\n",
+			want: []parsedRecord{
+				{line: 1, record: MemberRecord{Name: "陳小明", Email: "chen@example.com", EmailVerified: true}},
+				{line: 4, record: MemberRecord{Name: "B", Email: "b@example.com", Locale: "zh-TW"}},
+			},
+		},
+		{
+			name:  "最後一行沒有換行",
+			input: `{"name":"A","email":"a@example.com"}`,
+			want:  []parsedRecord{{line: 1, record: MemberRecord{Name: "A", Email: "a@example.com"}}},
+		},
+		{
+			name:  "未知欄位",
+			input: "{\"name\":\"A\",\"email\":\"a@example.com\",\"nickname\":\"a\"}\n{\"name\":\"B\",\"email\":\"b@example.com\"}\n",
+			want: []parsedRecord{
+				{line: 1, err: `JSON 格式錯誤：json: unknown field "nickname"`},
+				{line: 2, record: MemberRecord{Name: "B", Email: "b@example.com"}},
+			},
+		},
+		{
+			name:  "一行多個物件",
+			input: "{\"name\":\"A\",\"email\":\"a@example.com\"} {\"name\":\"B\",\"email\":\"b@example.com\"}\n",
+			want:  []parsedRecord{{line: 1, err: "JSON 格式錯誤：每行只能有一個物件"}},
+		},
+		{
+			name:  "格式錯誤",
+			input: "{\"name\":\"A\",\n",
+			want:  []parsedRecord{{line: 1, err: "JSON 格式錯誤：unexpected EOF"}},
+		},
+		{
+			name:  "空檔案",
+			input: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := newMemberRecordReader(strings.NewReader(tt.input), BulkFormatNDJSON)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, readAllRecords(t, reader))
+		})
+	}
+}
+
+func TestNewMemberRecordReaderUnsupportedFormat(t *testing.T) {
+	_, err := newMemberRecordReader(strings.NewReader(""), "xlsx")
+	assert.ErrorIs(t, err, ErrUnsupportedBulkFormat)
+}
+
+func TestMemberRecordCSVRoundTrip(t *testing.T) {
+	record := MemberRecord{
+		ID: 7, Name: "陳, 小明", Email: "chen@example.com", PasswordHash: "$argon2id$hash", EmailVerified: true,
+		Status: "active", Phone: "0912345678", Birthday: "1990-05-01", Locale: "zh-TW", Timezone: "Asia/Taipei",
+		CreatedAt: "2026-01-01T00:00:00Z",
+	}
+
+	var buf bytes.Buffer
+	writer, err := newMemberRecordWriter(&buf, ExportOptions{Format: BulkFormatCSV, IncludePasswordHash: true})
+	require.NoError(t, err)
+	require.NoError(t, writer.write(record))
+	require.NoError(t, writer.flush())
+
+	reader, err := newMemberRecordReader(&buf, BulkFormatCSV)
+	require.NoError(t, err)
+	want := record
+	want.ID, want.Status, want.CreatedAt = 0, "", ""
+	assert.Equal(t, []parsedRecord{{line: 2, record: want}}, readAllRecords(t, reader))
+}
@@ -0,0 +1,517 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"member_API/auth"
+	"member_API/models"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMergeSameMember      = errors.New("來源與目標會員不可相同")
+	ErrMergeNotAllowed      = errors.New("不可合併此會員")
+	ErrMergeReasonRequired  = errors.New("合併會員需提供原因")
+	ErrMemberMergedNotFound = errors.New("會員沒有合併紀錄")
+)
+
+// 判斷為重複會員的原因
+const (
+	// DuplicateReasonEmail email 正規化後相同：不分大小寫、忽略 + 之後的別名，Gmail 忽略帳號中的點
+	DuplicateReasonEmail = "email"
+	// DuplicateReasonNamePhone 姓名相近且手機號碼相同
+	DuplicateReasonNamePhone = "name_phone"
+	// DuplicateReasonNameEmailLocal 姓名相近且 email @ 之前的部分相同（不同網域）
+	DuplicateReasonNameEmailLocal = "name_email_local"
+)
+
+// maxMergeChain 解析合併轉址時最多追蹤的次數
+const maxMergeChain = 10
+
+// mergeTrackedModels 含有 creator_id 與 last_modifier_id 的資料表；合併時來源會員建立或最後修改的資料改記在目標會員名下
+var mergeTrackedModels = []interface{}{
+	&models.Member{}, &models.Product{}, &models.RefreshToken{}, &models.Permission{}, &models.Role{},
+	&models.MemberToken{}, &models.MFARecoveryCode{}, &models.LoginAttempt{}, &models.AuditLog{},
+	&models.Session{}, &models.ExternalIdentity{}, &models.OIDCAuthRequest{}, &models.APIKey{},
+	&models.OAuthClient{}, &models.OAuthAuthorizationCode{}, &models.OAuthGrant{}, &models.OAuthRefreshToken{},
+	&models.OAuthConsent{}, &models.ImpersonationSession{}, &models.MemberStatusChange{}, &models.DataExport{},
+	&models.MemberAddress{}, &models.MemberPreference{}, &models.MemberMerge{}, &models.MemberGroup{},
+	&models.PointsLedgerEntry{}, &models.MemberPoints{},
+}
+
+// DuplicateMember 可能重複的會員
+type DuplicateMember struct {
+	ID        uint      `json:"id" example:"1"`
+	Name      string    `json:"name" example:"張三"`
+	Email     string    `json:"email" example:"zhang.san+shop@gmail.com"`
+	Phone     string    `json:"phone,omitempty" example:"+886912345678"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DuplicateGroup 一組可能為同一人的會員，依 ID 排序；Reasons 為判斷的依據
+type DuplicateGroup struct {
+	Reasons []string          `json:"reasons" example:"email"`
+	Members []DuplicateMember `json:"members"`
+}
+
+// MemberMergeService 找出可能重複的會員，並將重複的會員合併為一位
+type MemberMergeService struct {
+	DB *gorm.DB
+}
+
+func NewMemberMergeService(db *gorm.DB) *MemberMergeService {
+	return &MemberMergeService{DB: db}
+}
+
+// FindDuplicates 比對所有未刪除的會員（不含服務帳號），返回可能重複的會員組與總組數；依各組最小的會員 ID 排序
+func (s *MemberMergeService) FindDuplicates(limit, offset int) ([]DuplicateGroup, int, error) {
+	groups, err := s.duplicateGroups()
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(groups)
+	if offset >= total {
+		return []DuplicateGroup{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return groups[offset:end], total, nil
+}
+
+// FindDuplicatesOf 返回與指定會員可能重複的會員組；沒有重複時返回 nil
+func (s *MemberMergeService) FindDuplicatesOf(memberID uint) (*DuplicateGroup, error) {
+	if _, err := NewMemberService(s.DB).GetMemberByID(memberID); err != nil {
+		return nil, err
+	}
+	groups, err := s.duplicateGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		for _, member := range groups[i].Members {
+			if member.ID == memberID {
+				return &groups[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// duplicateGroups 分批讀取會員並依 email、姓名與手機的比對鍵分組
+func (s *MemberMergeService) duplicateGroups() ([]DuplicateGroup, error) {
+	var members []DuplicateMember
+	var batch []models.Member
+	result := s.DB.Scopes(models.NotDeleted).
+		Select("id", "name", "email", "phone", "creation_time").
+		Where("service_account = ?", false).
+		FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+			for _, m := range batch {
+				members = append(members, DuplicateMember{
+					ID:        m.ID,
+					Name:      m.Name,
+					Email:     m.Email,
+					Phone:     m.Phone,
+					CreatedAt: m.CreationTime,
+				})
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return groupDuplicates(members), nil
+}
+
+// groupDuplicates 以 union-find 依 email、姓名與手機的比對鍵將會員分組；符合任一條件的會員會被併入同一組，
+// 只返回兩位以上的組，組內依 ID 排序，各組依第一位會員的 ID 排序
+func groupDuplicates(members []DuplicateMember) []DuplicateGroup {
+	parent := make([]int, len(members))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// 每個比對鍵第一次出現的會員；之後出現相同鍵的會員與其合併
+	first := map[string]int{}
+	reasons := map[[2]int]string{}
+	for i, member := range members {
+		name := duplicateNameKey(member.Name)
+		local, domain := duplicateEmailKey(member.Email)
+		keys := map[string]string{}
+		if local != "" {
+			keys[DuplicateReasonEmail+"\x00"+local+"@"+domain] = DuplicateReasonEmail
+			if name != "" {
+				keys[DuplicateReasonNameEmailLocal+"\x00"+name+"\x00"+local] = DuplicateReasonNameEmailLocal
+			}
+		}
+		if name != "" && member.Phone != "" {
+			keys[DuplicateReasonNamePhone+"\x00"+name+"\x00"+member.Phone] = DuplicateReasonNamePhone
+		}
+		for key, reason := range keys {
+			j, ok := first[key]
+			if !ok {
+				first[key] = i
+				continue
+			}
+			reasons[[2]int{j, i}] = reason
+			if a, b := find(i), find(j); a != b {
+				parent[a] = b
+			}
+		}
+	}
+
+	byRoot := map[int]*DuplicateGroup{}
+	for i := range members {
+		root := find(i)
+		group, ok := byRoot[root]
+		if !ok {
+			group = &DuplicateGroup{}
+			byRoot[root] = group
+		}
+		group.Members = append(group.Members, members[i])
+	}
+	for pair, reason := range reasons {
+		group := byRoot[find(pair[0])]
+		if !containsString(group.Reasons, reason) {
+			group.Reasons = append(group.Reasons, reason)
+		}
+	}
+
+	groups := make([]DuplicateGroup, 0)
+	for _, group := range byRoot {
+		if len(group.Members) < 2 {
+			continue
+		}
+		sort.Slice(group.Members, func(a, b int) bool { return group.Members[a].ID < group.Members[b].ID })
+		sort.Strings(group.Reasons)
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(a, b int) bool { return groups[a].Members[0].ID < groups[b].Members[0].ID })
+	return groups
+}
+
+// duplicateEmailKey 返回比對重複會員用的 email 帳號與網域：轉為小寫並去除 + 之後的別名；
+// gmail.com 與 googlemail.com 視為同一網域並忽略帳號中的點
+func duplicateEmailKey(email string) (local, domain string) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return "", ""
+	}
+	local, domain = email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local, domain
+}
+
+// duplicateNameKey 返回比對重複會員用的姓名：轉為小寫、去除標點，並將以空白分隔的各部分排序，
+// 「Zhang San」、「san zhang」與「San, Zhang」視為相同
+func duplicateNameKey(name string) string {
+	parts := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// ResolveMemberID 依合併紀錄找出會員目前的 ID；會員沒有被合併時返回 ErrMemberMergedNotFound
+func (s *MemberMergeService) ResolveMemberID(memberID uint) (uint, error) {
+	current := memberID
+	for i := 0; i < maxMergeChain; i++ {
+		var merge models.MemberMerge
+		err := s.DB.Scopes(models.NotDeleted).Where("source_member_id = ?", current).First(&merge).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if current == memberID {
+				return 0, ErrMemberMergedNotFound
+			}
+			return current, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		current = merge.TargetMemberID
+	}
+	return current, nil
+}
+
+// Merge 在同一個 transaction 中將來源會員合併至目標會員：
+// 外部帳號連結、API key、地址、登入與 OAuth 授權紀錄、代理登入紀錄與審計紀錄改為屬於目標會員，角色與群組取聯集，點數餘額轉入目標會員，目標會員未填的個人檔案欄位以來源補上。
+// 來源會員的登入與 OAuth 授權全部撤銷，驗證 token、備用碼與資料匯出檔刪除，
+// 之後來源會員關閉並刪除，MergedIntoID 指向目標會員，另建立合併紀錄供舊的會員 ID 轉址；合併後的會員不會被回收桶還原或永久刪除
+func (s *MemberMergeService) Merge(sourceID, targetID, actorID uint, reason string) (*models.MemberMerge, error) {
+	reason = truncate(strings.TrimSpace(reason), 500)
+	if reason == "" {
+		return nil, ErrMergeReasonRequired
+	}
+	if sourceID == targetID {
+		return nil, ErrMergeSameMember
+	}
+	if sourceID == actorID {
+		return nil, fmt.Errorf("%w：不可將自己合併至其他會員", ErrMergeNotAllowed)
+	}
+
+	now := time.Now()
+	merge := &models.MemberMerge{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    actorID,
+		},
+		SourceMemberID: sourceID,
+		TargetMemberID: targetID,
+		MergedBy:       actorID,
+		Reason:         reason,
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		source, target, err := lockMergeMembers(tx, sourceID, targetID)
+		if err != nil {
+			return err
+		}
+		if source.ServiceAccount || target.ServiceAccount {
+			return fmt.Errorf("%w：服務帳號不可合併", ErrMergeNotAllowed)
+		}
+		merge.SourceName = source.Name
+		merge.SourceEmail = source.Email
+
+		// 來源會員的登入與授權一律撤銷，不會因合併而登入目標會員
+		if _, err := revokeMemberSessions(tx, sourceID, "", now); err != nil {
+			return err
+		}
+		if _, err := revokeOAuthGrants(tx.Where("member_id = ?", sourceID), now); err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&models.MemberToken{},
+			&models.MFARecoveryCode{},
+			&models.OAuthAuthorizationCode{},
+			&models.DataExport{},
+		} {
+			if err := tx.Where("member_id = ?", sourceID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		moved, err := moveMemberRecords(tx, sourceID, targetID, actorID, now)
+		if err != nil {
+			return err
+		}
+		merge.Moved = moved
+
+		if err := tx.Model(target).Updates(mergedProfile(source, target, actorID, now)).Error; err != nil {
+			return err
+		}
+
+		from := EffectiveMemberStatus(source, now)
+		statusReason := fmt.Sprintf("已合併至會員 #%d", targetID)
+		updates := models.SoftDeleteColumns(actorID, now)
+		updates["status"] = models.MemberStatusClosed
+		updates["status_reason"] = statusReason
+		updates["suspended_until"] = nil
+		updates["merged_into_id"] = targetID
+		if err := tx.Model(source).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.MemberStatusChange{
+			Base: models.Base{
+				CreationTime: now,
+				CreatorId:    actorID,
+			},
+			MemberID:   sourceID,
+			FromStatus: from,
+			ToStatus:   models.MemberStatusClosed,
+			Reason:     statusReason,
+			ActorID:    actorID,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("key = ?", auth.AccountAttemptKey(source.Email)).Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(merge).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditMemberMerged,
+		MemberID: targetID,
+		ActorID:  actorID,
+		Detail:   fmt.Sprintf("source=%d reason=%s", sourceID, reason),
+	})
+	return merge, nil
+}
+
+// lockMergeMembers 依 ID 順序鎖定來源與目標會員，避免同時合併時互相等待
+func lockMergeMembers(tx *gorm.DB, sourceID, targetID uint) (*models.Member, *models.Member, error) {
+	firstID, secondID := sourceID, targetID
+	if firstID > secondID {
+		firstID, secondID = secondID, firstID
+	}
+	first, err := lockMember(tx, firstID)
+	if err != nil {
+		return nil, nil, err
+	}
+	second, err := lockMember(tx, secondID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if first.ID == sourceID {
+		return first, second, nil
+	}
+	return second, first, nil
+}
+
+// moveMemberRecords 將來源會員的資料與其建立、最後修改的紀錄改為屬於目標會員，返回各類資料的移轉筆數摘要
+func moveMemberRecords(tx *gorm.DB, sourceID, targetID, actorID uint, now time.Time) (string, error) {
+	var summary []string
+	count := func(name string, result *gorm.DB) error {
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			summary = append(summary, fmt.Sprintf("%s=%d", name, result.RowsAffected))
+		}
+		return nil
+	}
+	move := func(model interface{}) *gorm.DB {
+		return tx.Model(model).Where("member_id = ?", sourceID).Update("member_id", targetID)
+	}
+
+	// 目標會員已同意相同用戶端時保留目標會員的同意紀錄
+	if err := tx.Where("member_id = ? AND client_id IN (?)", sourceID,
+		tx.Model(&models.OAuthConsent{}).Select("client_id").Where("member_id = ?", targetID)).
+		Delete(&models.OAuthConsent{}).Error; err != nil {
+		return "", err
+	}
+	// 目標會員已有同類型的預設地址時，來源會員的地址不再是預設
+	if err := tx.Model(&models.MemberAddress{}).
+		Where("member_id = ? AND is_default = ? AND type IN (?)", sourceID, true,
+			tx.Model(&models.MemberAddress{}).Select("type").Scopes(models.NotDeleted).
+				Where("member_id = ? AND is_default = ?", targetID, true)).
+		Updates(map[string]interface{}{"is_default": false, "last_modification_time": &now}).Error; err != nil {
+		return "", err
+	}
+	// 溝通偏好每位會員一筆，目標會員已設定時保留目標會員的設定
+	var preferences int64
+	if err := tx.Model(&models.MemberPreference{}).Where("member_id = ?", targetID).Count(&preferences).Error; err != nil {
+		return "", err
+	}
+	if preferences > 0 {
+		if err := tx.Where("member_id = ?", sourceID).Delete(&models.MemberPreference{}).Error; err != nil {
+			return "", err
+		}
+	} else if err := count("preferences", move(&models.MemberPreference{})); err != nil {
+		return "", err
+	}
+
+	for _, item := range []struct {
+		name  string
+		model interface{}
+	}{
+		{"external_identities", &models.ExternalIdentity{}},
+		{"api_keys", &models.APIKey{}},
+		{"addresses", &models.MemberAddress{}},
+		{"oauth_consents", &models.OAuthConsent{}},
+		{"oauth_grants", &models.OAuthGrant{}},
+		{"sessions", &models.Session{}},
+		{"refresh_tokens", &models.RefreshToken{}},
+		{"impersonation_sessions", &models.ImpersonationSession{}},
+	} {
+		if err := count(item.name, move(item.model)); err != nil {
+			return "", err
+		}
+	}
+	if err := count("audit_logs", tx.Model(&models.AuditLog{}).
+		Where("member_id = ?", sourceID).Update("member_id", targetID)); err != nil {
+		return "", err
+	}
+	if err := count("audit_logs_as_actor", tx.Model(&models.AuditLog{}).
+		Where("actor_id = ?", sourceID).Update("actor_id", targetID)); err != nil {
+		return "", err
+	}
+
+	if err := count("roles", tx.Exec(
+		"INSERT INTO member_roles (member_id, role_id) SELECT ?, role_id FROM member_roles WHERE member_id = ? ON CONFLICT DO NOTHING",
+		targetID, sourceID)); err != nil {
+		return "", err
+	}
+	if err := tx.Exec("DELETE FROM member_roles WHERE member_id = ?", sourceID).Error; err != nil {
+		return "", err
+	}
+	if err := count("groups", tx.Exec(
+		"INSERT INTO member_group_assignments (member_id, member_group_id) SELECT ?, member_group_id FROM member_group_assignments WHERE member_id = ? ON CONFLICT DO NOTHING",
+		targetID, sourceID)); err != nil {
+		return "", err
+	}
+	if err := tx.Exec("DELETE FROM member_group_assignments WHERE member_id = ?", sourceID).Error; err != nil {
+		return "", err
+	}
+
+	// 以 UpdateColumn 更新，不改動各資料的最後修改時間
+	for _, column := range []string{"creator_id", "last_modifier_id"} {
+		var moved int64
+		for _, model := range mergeTrackedModels {
+			result := tx.Model(model).Where(column+" = ?", sourceID).UpdateColumn(column, targetID)
+			if result.Error != nil {
+				return "", result.Error
+			}
+			moved += result.RowsAffected
+		}
+		if moved > 0 {
+			summary = append(summary, fmt.Sprintf("%s=%d", column, moved))
+		}
+	}
+
+	points, err := transferPoints(tx, sourceID, targetID, actorID, now)
+	if err != nil {
+		return "", err
+	}
+	if points > 0 {
+		summary = append(summary, fmt.Sprintf("points=%d", points))
+	}
+	return strings.Join(summary, " "), nil
+}
+
+// mergedProfile 返回目標會員合併後要更新的欄位：目標會員未填的個人檔案欄位以來源會員的資料補上
+func mergedProfile(source, target *models.Member, actorID uint, now time.Time) map[string]interface{} {
+	updates := map[string]interface{}{
+		"last_modifier_id":       actorID,
+		"last_modification_time": &now,
+	}
+	if target.Phone == "" && source.Phone != "" {
+		updates["phone"] = source.Phone
+	}
+	if target.Birthday == nil && source.Birthday != nil {
+		updates["birthday"] = source.Birthday
+	}
+	if target.AvatarURL == "" && source.AvatarURL != "" {
+		updates["avatar_url"] = source.AvatarURL
+	}
+	if target.Locale == "" && source.Locale != "" {
+		updates["locale"] = source.Locale
+	}
+	if target.Timezone == "" && source.Timezone != "" {
+		updates["timezone"] = source.Timezone
+	}
+	return updates
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicateEmailKey(t *testing.T) {
+	tests := []struct {
+		email  string
+		local  string
+		domain string
+	}{
+		{email: "Zhang.San@Example.com", local: "zhang.san", domain: "example.com"},
+		{email: "  zhang@example.com ", local: "zhang", domain: "example.com"},
+		{email: "zhang+shop@example.com", local: "zhang", domain: "example.com"},
+		{email: "Zhang.San+shop@gmail.com", local: "zhangsan", domain: "gmail.com"},
+		{email: "zhang.san@googlemail.com", local: "zhangsan", domain: "gmail.com"},
+		{email: "+shop@example.com", local: "+shop", domain: "example.com"},
+		{email: "a@b@example.com", local: "a@b", domain: "example.com"},
+		{email: "", local: "", domain: ""},
+		{email: "no-at-sign", local: "", domain: ""},
+		{email: "@example.com", local: "", domain: ""},
+		{email: "zhang@", local: "", domain: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			local, domain := duplicateEmailKey(tt.email)
+			assert.Equal(t, tt.local, local)
+			assert.Equal(t, tt.domain, domain)
+		})
+	}
+}
+
+func TestDuplicateNameKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Zhang San", want: "san zhang"},
+		{name: "san zhang", want: "san zhang"},
+		{name: "San, Zhang", want: "san zhang"},
+		{name: "  ZHANG   san. ", want: "san zhang"},
+		{name: "張三", want: "張三"},
+		{name: "O'Brien Anne-Marie", want: "anne brien marie o"},
+		{name: "Agent 007", want: "007 agent"},
+		{name: "", want: ""},
+		{name: "---", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, duplicateNameKey(tt.name))
+		})
+	}
+}
+
+func TestGroupDuplicates(t *testing.T) {
+	member := func(id uint, name, email, phone string) DuplicateMember {
+		return DuplicateMember{ID: id, Name: name, Email: email, Phone: phone}
+	}
+	ids := func(groups []DuplicateGroup) [][]uint {
+		out := make([][]uint, len(groups))
+		for i, group := range groups {
+			for _, m := range group.Members {
+				out[i] = append(out[i], m.ID)
+			}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		members []DuplicateMember
+		groups  [][]uint
+		reasons [][]string
+	}{
+		{
+			name:    "沒有會員",
+			members: nil,
+		},
+		{
+			name: "沒有重複",
+			members: []DuplicateMember{
+				member(1, "Zhang San", "zhang@example.com", "0911111111"),
+				member(2, "Li Si", "li@example.com", "0922222222"),
+			},
+		},
+		{
+			name: "email 正規化後相同",
+			members: []DuplicateMember{
+				member(1, "A", "Zhang.San@gmail.com", ""),
+				member(2, "B", "zhangsan+shop@googlemail.com", ""),
+				member(3, "C", "other@example.com", ""),
+			},
+			groups:  [][]uint{{1, 2}},
+			reasons: [][]string{{DuplicateReasonEmail}},
+		},
+		{
+			name: "姓名相近且手機相同",
+			members: []DuplicateMember{
+				member(1, "Zhang San", "a@example.com", "0912345678"),
+				member(2, "san, zhang", "b@example.org", "0912345678"),
+				member(3, "Li Si", "c@example.com", "0912345678"),
+			},
+			groups:  [][]uint{{1, 2}},
+			reasons: [][]string{{DuplicateReasonNamePhone}},
+		},
+		{
+			name: "姓名相近且 email 帳號相同",
+			members: []DuplicateMember{
+				member(1, "Zhang San", "zhang@example.com", ""),
+				member(2, "San Zhang", "zhang@example.org", ""),
+				member(3, "Li Si", "zhang@example.net", ""),
+			},
+			groups:  [][]uint{{1, 2}},
+			reasons: [][]string{{DuplicateReasonNameEmailLocal}},
+		},
+		{
+			name: "不同條件串連成同一組",
+			members: []DuplicateMember{
+				member(4, "Zhang San", "zhang@example.com", ""),
+				member(2, "Other Name", "ZHANG@example.com", "0912345678"),
+				member(9, "other name", "x@example.org", "0912345678"),
+				member(1, "Li Si", "li@example.com", ""),
+				member(7, "Li Si", "li@example.org", ""),
+			},
+			groups:  [][]uint{{1, 7}, {2, 4, 9}},
+			reasons: [][]string{{DuplicateReasonNameEmailLocal}, {DuplicateReasonEmail, DuplicateReasonNamePhone}},
+		},
+		{
+			name: "沒有姓名或手機不以空值比對",
+			members: []DuplicateMember{
+				member(1, "", "a@example.com", ""),
+				member(2, "", "b@example.com", ""),
+				member(3, "---", "a@example.org", "0912345678"),
+				member(4, "...", "c@example.net", "0912345678"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupDuplicates(tt.members)
+			assert.Equal(t, len(tt.groups), len(groups))
+			if len(tt.groups) > 0 {
+				assert.Equal(t, tt.groups, ids(groups))
+				for i, group := range groups {
+					assert.Equal(t, tt.reasons[i], group.Reasons)
+				}
+			}
+		})
+	}
+}
@@ -1,14 +1,25 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
 	"member_API/auth"
 	"member_API/models"
+	"sort"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidPassword   = errors.New("密碼錯誤")
+	ErrSamePassword      = errors.New("新密碼不可與目前密碼相同")
+	ErrInvalidMemberSort = errors.New("不支援的排序欄位")
+)
+
 type MemberService struct {
 	DB *gorm.DB
 }
@@ -17,12 +28,18 @@ func NewMemberService(db *gorm.DB) *MemberService {
 	return &MemberService{DB: db}
 }
 
-// CreateMember 建立新會員
+// CreateMember 建立新會員；email 以標準形式儲存
 func (s *MemberService) CreateMember(name, email, password string, creatorId uint) (*models.Member, error) {
+	email = auth.NormalizeEmail(email)
+
 	// 檢查 email 是否已存在
 	var exists models.Member
-	if err := s.DB.Where("email = ? AND is_deleted = ?", email, false).First(&exists).Error; err == nil {
-		return nil, errors.New("email 已被使用")
+	if err := s.DB.Scopes(models.NotDeleted, ByEmail(email)).First(&exists).Error; err == nil {
+		return nil, ErrEmailInUse
+	}
+
+	if err := auth.ValidatePassword(password, email, name); err != nil {
+		return nil, err
 	}
 
 	// 加密密碼
@@ -41,19 +58,66 @@ func (s *MemberService) CreateMember(name, email, password string, creatorId uin
 		Name:         name,
 		Email:        email,
 		PasswordHash: hash,
+		Status:       models.MemberStatusActive,
 	}
 
-	if err := s.DB.Create(member).Error; err != nil {
-		return nil, err
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		// 新會員預設擁有一般會員角色
+		return NewRoleService(tx).AssignDefaultRole(member.ID)
+	})
+	if err != nil {
+		return nil, emailConflict(err)
+	}
+
+	trySendVerification(s.DB, member)
+
+	return member, nil
+}
+
+// CreateExternalMember 為首次以外部帳號登入的使用者建立沒有密碼的會員；
+// 提供者已確認 email 時直接視為已驗證，驗證郵件由呼叫端在 transaction 完成後寄送
+func (s *MemberService) CreateExternalMember(name, email string, emailVerified bool) (*models.Member, error) {
+	email = auth.NormalizeEmail(email)
+	var exists models.Member
+	if err := s.DB.Scopes(models.NotDeleted, ByEmail(email)).First(&exists).Error; err == nil {
+		return nil, ErrEmailInUse
+	}
+
+	now := time.Now()
+	member := &models.Member{
+		Base: models.Base{
+			CreationTime: now,
+			IsDeleted:    false,
+		},
+		Name:   name,
+		Email:  email,
+		Status: models.MemberStatusActive,
+	}
+	if emailVerified {
+		member.EmailVerifiedAt = &now
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		return NewRoleService(tx).AssignDefaultRole(member.ID)
+	})
+	if err != nil {
+		return nil, emailConflict(err)
 	}
 
 	return member, nil
 }
 
-// UpdateMember 更新會員資訊
+// UpdateMember 更新會員資訊；只有大小寫不同時視為同一個 email，不需重新驗證
 func (s *MemberService) UpdateMember(id uint, name, email string, modifierId uint) (*models.Member, error) {
+	email = auth.NormalizeEmail(email)
 	var member models.Member
-	if err := s.DB.Where("is_deleted = ?", false).First(&member, id).Error; err != nil {
+	if err := s.DB.Scopes(models.NotDeleted).First(&member, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("會員不存在")
 		}
@@ -61,45 +125,144 @@ func (s *MemberService) UpdateMember(id uint, name, email string, modifierId uin
 	}
 
 	now := time.Now()
+	emailChanged := auth.NormalizeEmail(member.Email) != email
+	if emailChanged {
+		var exists models.Member
+		if err := s.DB.Scopes(models.NotDeleted, ByEmail(email)).Where("id <> ?", id).First(&exists).Error; err == nil {
+			return nil, ErrEmailInUse
+		}
+	}
 	member.Name = name
 	member.Email = email
 	member.LastModificationTime = &now
 	member.LastModifierId = modifierId
+	if emailChanged {
+		// 新的 email 需要重新驗證
+		member.EmailVerifiedAt = nil
+	}
 
 	if err := s.DB.Save(&member).Error; err != nil {
-		return nil, err
+		return nil, emailConflict(err)
+	}
+
+	if emailChanged {
+		trySendVerification(s.DB, &member)
 	}
 
 	return &member, nil
 }
 
-// DeleteMember 軟刪除會員
-func (s *MemberService) DeleteMember(id uint, deleterId uint) error {
-	now := time.Now()
-	result := s.DB.Model(&models.Member{}).
-		Where("id = ? AND is_deleted = ?", id, false).
-		Updates(map[string]interface{}{
-			"is_deleted":             true,
-			"deleted_at":             &now,
-			"last_modifier_id":       deleterId,
-			"last_modification_time": &now,
+// UpdateProfile 會員修改自己的資料；變更 email 必須提供目前密碼，新 email 需重新驗證
+func (s *MemberService) UpdateProfile(id uint, name, email, currentPassword string) (*models.Member, error) {
+	member, err := s.GetMemberByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldEmail := member.Email
+	emailChanged := auth.NormalizeEmail(email) != auth.NormalizeEmail(oldEmail)
+	if emailChanged && !auth.CheckPassword(currentPassword, member.PasswordHash) {
+		return nil, ErrInvalidPassword
+	}
+
+	updated, err := s.UpdateMember(id, name, email, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		NewAuditService(s.DB).TryRecord(AuditEntry{
+			Action:   AuditEmailChanged,
+			MemberID: id,
+			ActorID:  id,
+			Detail:   fmt.Sprintf("from=%s to=%s", oldEmail, updated.Email),
 		})
+	}
+
+	return updated, nil
+}
+
+// ChangePassword 驗證目前密碼後設定新密碼，並撤銷 keepSessionID 以外的所有登入
+func (s *MemberService) ChangePassword(id uint, currentPassword, newPassword, keepSessionID string) error {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		member, err := lockMember(tx, id)
+		if err != nil {
+			return err
+		}
+		if !auth.CheckPassword(currentPassword, member.PasswordHash) {
+			return ErrInvalidPassword
+		}
+		if auth.CheckPassword(newPassword, member.PasswordHash) {
+			return ErrSamePassword
+		}
+		if err := auth.ValidatePassword(newPassword, member.Email, member.Name); err != nil {
+			return err
+		}
 
-	if result.Error != nil {
-		return result.Error
+		hash, err := auth.HashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(member).Updates(map[string]interface{}{
+			"password_hash":          hash,
+			"last_modifier_id":       id,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return NewTokenService(tx).RevokeAllForMemberExcept(id, keepSessionID)
+	})
+	if err != nil {
+		return err
 	}
 
-	if result.RowsAffected == 0 {
-		return errors.New("會員不存在或已被刪除")
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditPasswordChanged,
+		MemberID: id,
+		ActorID:  id,
+	})
+	return nil
+}
+
+// DeleteMember 軟刪除會員並撤銷其所有登入與 OAuth 授權；會員會移至回收桶，保留期限內可還原
+func (s *MemberService) DeleteMember(id uint, deleterId uint) error {
+	now := time.Now()
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Member{}).
+			Scopes(models.NotDeleted).Where("id = ?", id).
+			Updates(models.SoftDeleteColumns(deleterId, now))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("會員不存在或已被刪除")
+		}
+
+		if _, err := revokeMemberSessions(tx, id, "", now); err != nil {
+			return err
+		}
+		_, err := revokeOAuthGrants(tx.Where("member_id = ?", id), now)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditMemberDeleted,
+		MemberID: id,
+		ActorID:  deleterId,
+	})
 	return nil
 }
 
 // GetMemberByID 取得單一會員
 func (s *MemberService) GetMemberByID(id uint) (*models.Member, error) {
 	var member models.Member
-	if err := s.DB.Where("is_deleted = ?", false).First(&member, id).Error; err != nil {
+	if err := s.DB.Scopes(models.NotDeleted).First(&member, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("會員不存在")
 		}
@@ -108,11 +271,180 @@ func (s *MemberService) GetMemberByID(id uint) (*models.Member, error) {
 	return &member, nil
 }
 
-// GetMembers 取得會員列表
-func (s *MemberService) GetMembers(limit int) ([]models.Member, error) {
+// MemberFilter 查詢會員列表的條件，零值表示不過濾
+type MemberFilter struct {
+	// Search 不分大小寫比對姓名或 email 的任一部分
+	Search string
+	// Status 依實際狀態過濾，停權期滿的會員視為 active
+	Status string
+	Role   string
+	// Group 群組名稱，不分大小寫
+	Group       string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// memberSortColumns 可排序的欄位與對應的資料表欄位
+var memberSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"email":      "email",
+	"status":     "status",
+	"created_at": "creation_time",
+}
+
+// MemberSortFields 返回可用於排序的欄位名稱
+func MemberSortFields() []string {
+	fields := make([]string, 0, len(memberSortColumns))
+	for field := range memberSortColumns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ListMembers 依條件分頁查詢未刪除的會員，同時返回總數。
+// sortBy 為 MemberSortFields 其中之一，加上 "-" 前綴表示遞減，空字串依 ID 遞增
+func (s *MemberService) ListMembers(filter MemberFilter, sortBy string, limit, offset int) ([]models.Member, int64, error) {
+	order, err := memberOrder(sortBy)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := s.DB.Model(&models.Member{}).Scopes(models.NotDeleted)
+	if search := strings.ToLower(strings.TrimSpace(filter.Search)); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where("(LOWER(name) LIKE ? OR LOWER(email) LIKE ?)", pattern, pattern)
+	}
+	if filter.Status != "" {
+		now := time.Now()
+		switch filter.Status {
+		case models.MemberStatusActive:
+			query = query.Where("(status = ? OR (status = ? AND suspended_until <= ?))",
+				models.MemberStatusActive, models.MemberStatusSuspended, now)
+		case models.MemberStatusSuspended:
+			query = query.Where("status = ? AND (suspended_until IS NULL OR suspended_until > ?)",
+				models.MemberStatusSuspended, now)
+		case models.MemberStatusPending, models.MemberStatusBanned, models.MemberStatusClosed:
+			query = query.Where("status = ?", filter.Status)
+		default:
+			return nil, 0, ErrInvalidMemberStatus
+		}
+	}
+	if filter.Role != "" {
+		query = query.Where("id IN (?)", s.DB.Table("member_roles").
+			Select("member_roles.member_id").
+			Joins("JOIN roles ON roles.id = member_roles.role_id").
+			Where("roles.name = ? AND roles.is_deleted = ?", filter.Role, false))
+	}
+	if filter.Group != "" {
+		query = query.Where("id IN (?)", s.DB.Table("member_group_assignments").
+			Select("member_group_assignments.member_id").
+			Joins("JOIN member_groups ON member_groups.id = member_group_assignments.member_group_id").
+			Where("member_groups.name = ? AND member_groups.is_deleted = ?", strings.ToLower(strings.TrimSpace(filter.Group)), false))
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("creation_time >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("creation_time < ?", *filter.CreatedTo)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	var members []models.Member
-	if err := s.DB.Where("is_deleted = ?", false).Limit(limit).Find(&members).Error; err != nil {
-		return nil, err
+	if err := query.Order(order).Limit(limit).Offset(offset).Find(&members).Error; err != nil {
+		return nil, 0, err
+	}
+	return members, total, nil
+}
+
+// ParseDateBound 解析列表過濾的日期條件，接受 RFC 3339 時間或 YYYY-MM-DD；
+// 結束條件只有日期時包含當天，返回隔天 00:00（UTC）
+func ParseDateBound(value string, end bool) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式需為 YYYY-MM-DD 或 RFC 3339：%s", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// memberOrder 將排序參數轉換為 ORDER BY 子句；同值時依 ID 排序，分頁結果穩定
+func memberOrder(sortBy string) (string, error) {
+	sortBy = strings.TrimSpace(sortBy)
+	if sortBy == "" {
+		return "id", nil
+	}
+	direction := "ASC"
+	if strings.HasPrefix(sortBy, "-") {
+		direction = "DESC"
+		sortBy = sortBy[1:]
+	}
+	column, ok := memberSortColumns[sortBy]
+	if !ok {
+		return "", fmt.Errorf("%w：可用欄位為 %s", ErrInvalidMemberSort, strings.Join(MemberSortFields(), "、"))
+	}
+	if column == "id" {
+		return "id " + direction, nil
+	}
+	return fmt.Sprintf("%s %s, id %s", column, direction, direction), nil
+}
+
+// escapeLike 跳脫 LIKE 的萬用字元
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// UnlockLogin 解除會員因登入失敗造成的鎖定與退避，並寫入審計紀錄
+func (s *MemberService) UnlockLogin(ctx context.Context, id uint, actorID uint) error {
+	member, err := s.GetMemberByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.CurrentLoginGuard().Unlock(ctx, member.Email); err != nil {
+		return err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditLoginUnlocked,
+		MemberID: member.ID,
+		ActorID:  actorID,
+	})
+	return nil
+}
+
+// UpgradePasswordHash 在密碼驗證成功後，以目前設定的演算法與成本重新雜湊舊密碼；失敗只記錄日誌
+func (s *MemberService) UpgradePasswordHash(member *models.Member, password string) {
+	if !auth.NeedsRehash(member.PasswordHash) {
+		return
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		log.Printf("[Member] failed to rehash password for member %d: %v\n", member.ID, err)
+		return
+	}
+
+	// 以舊雜湊為條件，避免覆蓋並行請求中剛修改的密碼
+	if err := s.DB.Model(&models.Member{}).
+		Where("id = ? AND password_hash = ?", member.ID, member.PasswordHash).
+		Update("password_hash", hash).Error; err != nil {
+		log.Printf("[Member] failed to store upgraded password hash for member %d: %v\n", member.ID, err)
+		return
 	}
-	return members, nil
+	member.PasswordHash = hash
 }
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"member_API/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMemberPending           = errors.New("帳號尚待啟用")
+	ErrMemberSuspended         = errors.New("帳號已停權")
+	ErrMemberBanned            = errors.New("帳號已被封鎖")
+	ErrMemberClosed            = errors.New("帳號已關閉")
+	ErrInvalidMemberStatus     = errors.New("無效的帳號狀態")
+	ErrInvalidStatusTransition = errors.New("不允許的帳號狀態變更")
+	ErrStatusReasonRequired    = errors.New("請說明變更帳號狀態的原因")
+	ErrInvalidSuspendedUntil   = errors.New("停權需指定未來的結束時間")
+)
+
+// memberStatusTransitions 各狀態可變更為的狀態；停權中可再次停權以調整結束時間
+var memberStatusTransitions = map[string][]string{
+	models.MemberStatusPending:   {models.MemberStatusActive, models.MemberStatusBanned, models.MemberStatusClosed},
+	models.MemberStatusActive:    {models.MemberStatusSuspended, models.MemberStatusBanned, models.MemberStatusClosed},
+	models.MemberStatusSuspended: {models.MemberStatusActive, models.MemberStatusSuspended, models.MemberStatusBanned, models.MemberStatusClosed},
+	models.MemberStatusBanned:    {models.MemberStatusActive, models.MemberStatusClosed},
+	models.MemberStatusClosed:    {models.MemberStatusActive},
+}
+
+// MemberStatusInput 管理員變更帳號狀態的內容
+type MemberStatusInput struct {
+	Status         string
+	Reason         string
+	SuspendedUntil *time.Time
+}
+
+type MemberStatusService struct {
+	DB *gorm.DB
+}
+
+func NewMemberStatusService(db *gorm.DB) *MemberStatusService {
+	return &MemberStatusService{DB: db}
+}
+
+// EffectiveMemberStatus 返回會員目前的狀態；停權期滿視為 active
+func EffectiveMemberStatus(member *models.Member, now time.Time) string {
+	switch {
+	case member.Status == "":
+		return models.MemberStatusActive
+	case member.Status == models.MemberStatusSuspended && member.SuspendedUntil != nil && !now.Before(*member.SuspendedUntil):
+		return models.MemberStatusActive
+	default:
+		return member.Status
+	}
+}
+
+// CheckMemberStatus 會員不是 active 時返回對應的錯誤；登入、刷新 token 與 API key 驗證前呼叫
+func CheckMemberStatus(member *models.Member) error {
+	switch EffectiveMemberStatus(member, time.Now()) {
+	case models.MemberStatusActive:
+		return nil
+	case models.MemberStatusPending:
+		return ErrMemberPending
+	case models.MemberStatusSuspended:
+		if member.SuspendedUntil != nil {
+			return fmt.Errorf("%w，至 %s", ErrMemberSuspended, member.SuspendedUntil.Format(time.RFC3339))
+		}
+		return ErrMemberSuspended
+	case models.MemberStatusBanned:
+		return ErrMemberBanned
+	default:
+		return ErrMemberClosed
+	}
+}
+
+// IsMemberStatusError 判斷錯誤是否為帳號狀態造成的拒絕
+func IsMemberStatusError(err error) bool {
+	return errors.Is(err, ErrMemberPending) || errors.Is(err, ErrMemberSuspended) ||
+		errors.Is(err, ErrMemberBanned) || errors.Is(err, ErrMemberClosed)
+}
+
+// ChangeStatus 變更會員的帳號狀態並寫入狀態歷史。
+// 變更為 active 以外的狀態時，撤銷會員所有登入與 OAuth 授權，既有的 token 立即失效；API key 保留，恢復後可繼續使用
+func (s *MemberStatusService) ChangeStatus(memberID uint, input MemberStatusInput, actorID uint) (*models.Member, error) {
+	status := strings.TrimSpace(input.Status)
+	reason := truncate(strings.TrimSpace(input.Reason), 500)
+	if _, ok := memberStatusTransitions[status]; !ok {
+		return nil, ErrInvalidMemberStatus
+	}
+	if status != models.MemberStatusActive && reason == "" {
+		return nil, ErrStatusReasonRequired
+	}
+	if memberID == actorID {
+		return nil, fmt.Errorf("%w：不可變更自己的帳號狀態", ErrInvalidStatusTransition)
+	}
+
+	now := time.Now()
+	var suspendedUntil *time.Time
+	if status == models.MemberStatusSuspended {
+		if input.SuspendedUntil == nil || !input.SuspendedUntil.After(now) {
+			return nil, ErrInvalidSuspendedUntil
+		}
+		until := input.SuspendedUntil.UTC()
+		suspendedUntil = &until
+	}
+
+	var (
+		member *models.Member
+		from   string
+	)
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		member, err = lockMember(tx, memberID)
+		if err != nil {
+			return err
+		}
+
+		from = EffectiveMemberStatus(member, now)
+		if !containsString(memberStatusTransitions[from], status) {
+			return fmt.Errorf("%w：%s → %s", ErrInvalidStatusTransition, from, status)
+		}
+
+		if err := tx.Model(member).Updates(map[string]interface{}{
+			"status":                 status,
+			"status_reason":          reason,
+			"suspended_until":        suspendedUntil,
+			"last_modifier_id":       actorID,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.MemberStatusChange{
+			Base: models.Base{
+				CreationTime: now,
+				CreatorId:    actorID,
+			},
+			MemberID:       memberID,
+			FromStatus:     from,
+			ToStatus:       status,
+			Reason:         reason,
+			SuspendedUntil: suspendedUntil,
+			ActorID:        actorID,
+		}).Error; err != nil {
+			return err
+		}
+
+		if status == models.MemberStatusActive {
+			return nil
+		}
+		if _, err := revokeMemberSessions(tx, memberID, "", now); err != nil {
+			return err
+		}
+		_, err = revokeOAuthGrants(tx.Where("member_id = ?", memberID), now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	member.Status = status
+	member.StatusReason = reason
+	member.SuspendedUntil = suspendedUntil
+
+	detail := fmt.Sprintf("%s -> %s", from, status)
+	if suspendedUntil != nil {
+		detail += " until=" + suspendedUntil.Format(time.RFC3339)
+	}
+	if reason != "" {
+		detail += " reason=" + reason
+	}
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditMemberStatusChanged,
+		MemberID: memberID,
+		ActorID:  actorID,
+		Detail:   detail,
+	})
+	return member, nil
+}
+
+// History 返回會員的狀態變更歷史，新的在前
+func (s *MemberStatusService) History(memberID uint) ([]models.MemberStatusChange, error) {
+	if _, err := NewMemberService(s.DB).GetMemberByID(memberID); err != nil {
+		return nil, err
+	}
+
+	var changes []models.MemberStatusChange
+	if err := s.DB.Where("member_id = ?", memberID).
+		Order("creation_time DESC, id DESC").
+		Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"member_API/auth"
+	"member_API/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 一次性 token 的用途
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+var (
+	ErrInvalidMemberToken = errors.New("無效或已過期的 token")
+)
+
+// issueMemberToken 作廢會員同用途尚未使用的 token，並建立新的一次性 token，返回 token 原文
+func issueMemberToken(tx *gorm.DB, memberID uint, purpose string, ttl time.Duration, payload string) (string, error) {
+	rawToken, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.MemberToken{}).
+		Where("member_id = ? AND purpose = ? AND used_at IS NULL", memberID, purpose).
+		Updates(map[string]interface{}{
+			"used_at":                &now,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+		return "", err
+	}
+
+	record := &models.MemberToken{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    memberID,
+		},
+		MemberID:  memberID,
+		Purpose:   purpose,
+		TokenHash: auth.HashToken(rawToken),
+		Payload:   payload,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// consumeMemberToken 驗證並標記一次性 token 為已使用，必須在 transaction 中呼叫
+func consumeMemberToken(tx *gorm.DB, rawToken, purpose string) (*models.MemberToken, error) {
+	var record models.MemberToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", auth.HashToken(rawToken), purpose).
+		First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMemberToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if record.UsedAt != nil || now.After(record.ExpiresAt) {
+		return nil, ErrInvalidMemberToken
+	}
+
+	if err := tx.Model(&record).Updates(map[string]interface{}{
+		"used_at":                &now,
+		"last_modification_time": &now,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"errors"
+	"member_API/auth"
+	"member_API/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("已啟用兩步驟驗證")
+	ErrMFANotEnabled     = errors.New("尚未啟用兩步驟驗證")
+	ErrMFASetupRequired  = errors.New("請先產生 TOTP 密鑰")
+	ErrInvalidMFACode    = errors.New("驗證碼錯誤")
+)
+
+// recoveryCodeCount 每次產生的備用碼數量
+const recoveryCodeCount = 10
+
+// mfaIssuer 顯示在驗證器 App 中的服務名稱
+var mfaIssuer = "Member API"
+
+// SetMFAIssuer 設定 TOTP provisioning URI 中的發行者名稱，空值會被忽略
+func SetMFAIssuer(issuer string) {
+	if issuer != "" {
+		mfaIssuer = issuer
+	}
+}
+
+type MFAService struct {
+	DB *gorm.DB
+}
+
+func NewMFAService(db *gorm.DB) *MFAService {
+	return &MFAService{DB: db}
+}
+
+// SetupTOTP 為會員產生新的 TOTP 密鑰並返回密鑰與 provisioning URI，須再以 EnableTOTP 確認後才會生效
+func (s *MFAService) SetupTOTP(memberID uint) (string, string, error) {
+	var member models.Member
+	if err := s.DB.Scopes(models.NotDeleted).First(&member, memberID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", errors.New("會員不存在")
+		}
+		return "", "", err
+	}
+	if member.TOTPEnabledAt != nil {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	if err := s.DB.Model(&member).Updates(map[string]interface{}{
+		"totp_secret":            secret,
+		"totp_last_counter":      0,
+		"last_modification_time": &now,
+	}).Error; err != nil {
+		return "", "", err
+	}
+
+	return secret, auth.TOTPProvisioningURI(mfaIssuer, member.Email, secret), nil
+}
+
+// EnableTOTP 以驗證碼確認 TOTP 密鑰並啟用兩步驟驗證，返回只顯示一次的備用碼
+func (s *MFAService) EnableTOTP(memberID uint, code string) ([]string, error) {
+	var codes []string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		member, err := lockMember(tx, memberID)
+		if err != nil {
+			return err
+		}
+		if member.TOTPEnabledAt != nil {
+			return ErrMFAAlreadyEnabled
+		}
+		if member.TOTPSecret == "" {
+			return ErrMFASetupRequired
+		}
+
+		counter, ok := auth.ValidateTOTP(member.TOTPSecret, code, time.Now())
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		now := time.Now()
+		if err := tx.Model(member).Updates(map[string]interface{}{
+			"totp_enabled_at":        &now,
+			"totp_last_counter":      counter,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, memberID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP 停用兩步驟驗證，需同時提供目前密碼與有效的驗證碼或備用碼
+func (s *MFAService) DisableTOTP(memberID uint, password, code string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		member, err := lockMember(tx, memberID)
+		if err != nil {
+			return err
+		}
+		if member.TOTPEnabledAt == nil {
+			return ErrMFANotEnabled
+		}
+		if !auth.CheckPassword(password, member.PasswordHash) {
+			return ErrInvalidPassword
+		}
+		if err := verifySecondFactor(tx, member, code); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(member).Updates(map[string]interface{}{
+			"totp_secret":            "",
+			"totp_enabled_at":        nil,
+			"totp_last_counter":      0,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("member_id = ?", memberID).Delete(&models.MFARecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes 以有效的驗證碼換發一組新的備用碼，舊的備用碼全部失效
+func (s *MFAService) RegenerateRecoveryCodes(memberID uint, code string) ([]string, error) {
+	var codes []string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		member, err := lockMember(tx, memberID)
+		if err != nil {
+			return err
+		}
+		if member.TOTPEnabledAt == nil {
+			return ErrMFANotEnabled
+		}
+		if err := verifySecondFactor(tx, member, code); err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, memberID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// VerifyLogin 兩步驟登入的第二步，接受 TOTP 驗證碼或備用碼
+func (s *MFAService) VerifyLogin(memberID uint, code string) (*models.Member, error) {
+	var member *models.Member
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		member, err = lockMember(tx, memberID)
+		if err != nil {
+			return err
+		}
+		if member.TOTPEnabledAt == nil {
+			return ErrMFANotEnabled
+		}
+		return verifySecondFactor(tx, member, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// lockMember 以 FOR UPDATE 讀取會員，確保同一驗證碼不會被並行請求重複使用
+func lockMember(tx *gorm.DB, memberID uint) (*models.Member, error) {
+	var member models.Member
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scopes(models.NotDeleted).
+		First(&member, memberID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("會員不存在")
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// verifySecondFactor 驗證 TOTP 驗證碼（拒絕已使用過的時間步長）或一次性備用碼
+func verifySecondFactor(tx *gorm.DB, member *models.Member, code string) error {
+	code = strings.TrimSpace(code)
+	now := time.Now()
+
+	if counter, ok := auth.ValidateTOTP(member.TOTPSecret, code, now); ok {
+		if counter <= member.TOTPLastCounter {
+			return ErrInvalidMFACode
+		}
+		member.TOTPLastCounter = counter
+		return tx.Model(member).Update("totp_last_counter", counter).Error
+	}
+
+	result := tx.Model(&models.MFARecoveryCode{}).
+		Where("member_id = ? AND code_hash = ? AND used_at IS NULL", member.ID, auth.HashToken(normalizeRecoveryCode(code))).
+		Updates(map[string]interface{}{
+			"used_at":                &now,
+			"last_modification_time": &now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 刪除會員既有的備用碼並產生新的一組，返回原文
+func replaceRecoveryCodes(tx *gorm.DB, memberID uint) ([]string, error) {
+	if err := tx.Where("member_id = ?", memberID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := auth.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, models.MFARecoveryCode{
+			Base: models.Base{
+				CreationTime: now,
+				CreatorId:    memberID,
+			},
+			MemberID: memberID,
+			CodeHash: auth.HashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode 忽略大小寫與分隔符號，方便使用者輸入
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
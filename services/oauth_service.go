@@ -0,0 +1,1084 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"member_API/auth"
+	"member_API/models"
+	"member_API/oidc"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOAuthClientNotFound  = errors.New("OAuth 用戶端不存在")
+	ErrOAuthClientInvalid   = errors.New("OAuth 用戶端設定無效")
+	ErrInvalidRedirectURI   = errors.New("redirect_uri 與用戶端登記的不符")
+	ErrOAuthConsentNotFound = errors.New("尚未授權此應用程式")
+)
+
+// OAuth 錯誤代碼（RFC 6749 §4.1.2.1、§5.2）
+const (
+	OAuthErrInvalidRequest          = "invalid_request"
+	OAuthErrInvalidClient           = "invalid_client"
+	OAuthErrInvalidGrant            = "invalid_grant"
+	OAuthErrUnauthorizedClient      = "unauthorized_client"
+	OAuthErrUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrInvalidScope            = "invalid_scope"
+	OAuthErrAccessDenied            = "access_denied"
+)
+
+// 支援的授權類型
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// oauthCodeTTL 授權碼的有效期
+const oauthCodeTTL = 5 * time.Minute
+
+// OAuthError 依 RFC 6749 回應給用戶端的錯誤
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// OAuthClientInput 登記 OAuth 用戶端的參數
+type OAuthClientInput struct {
+	Name         string
+	RedirectURIs []string
+	GrantTypes   []string
+	Scopes       []string
+	Confidential bool
+	FirstParty   bool
+}
+
+// AuthorizeRequest 授權端點的參數（RFC 6749 §4.1.1、RFC 7636 §4.3）
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// AuthorizeResult 授權端點的結果：ConsentRequired 為 true 時需先取得會員同意，
+// 否則 RedirectTo 為帶有授權碼或錯誤代碼、要導回用戶端的網址
+type AuthorizeResult struct {
+	RedirectTo      string
+	ConsentRequired bool
+	Client          *models.OAuthClient
+	Scopes          []string
+}
+
+// TokenRequest token 端點的參數（RFC 6749 §4.1.3、§4.4.2、§6）
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// OAuthTokens token 端點簽發的 token
+type OAuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+	Scopes       []string
+}
+
+// OAuthIntrospection token 的狀態（RFC 7662 §2.2），Active 為 false 時其餘欄位為空
+type OAuthIntrospection struct {
+	Active    bool
+	Scope     string
+	ClientID  string
+	Username  string
+	TokenType string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	TokenID   string
+}
+
+// OAuthAuthorizedApp 會員已授權的應用程式
+type OAuthAuthorizedApp struct {
+	ClientID  string
+	Name      string
+	Scopes    []string
+	GrantedAt time.Time
+}
+
+// oauthTokenInfo 依 token 原文找到的授權
+type oauthTokenInfo struct {
+	grant     models.OAuthGrant
+	tokenType string
+	claims    *auth.Claims
+	refresh   *models.OAuthRefreshToken
+}
+
+type OAuthService struct {
+	DB *gorm.DB
+}
+
+func NewOAuthService(db *gorm.DB) *OAuthService {
+	return &OAuthService{DB: db}
+}
+
+// RegisterClient 登記 OAuth 用戶端，返回紀錄與只顯示一次的 client secret（公開用戶端沒有 secret）
+func (s *OAuthService) RegisterClient(input OAuthClientInput, creatorID uint) (*models.OAuthClient, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: 名稱不可為空", ErrOAuthClientInvalid)
+	}
+
+	grantTypes := uniqueStrings(input.GrantTypes)
+	if len(grantTypes) == 0 {
+		grantTypes = []string{GrantTypeAuthorizationCode}
+	}
+	for _, grantType := range grantTypes {
+		switch grantType {
+		case GrantTypeAuthorizationCode, GrantTypeRefreshToken:
+		case GrantTypeClientCredentials:
+			if !input.Confidential {
+				return nil, "", fmt.Errorf("%w: 公開用戶端不可使用 client_credentials", ErrOAuthClientInvalid)
+			}
+		default:
+			return nil, "", fmt.Errorf("%w: 不支援的授權類型 %s", ErrOAuthClientInvalid, grantType)
+		}
+	}
+	if containsString(grantTypes, GrantTypeRefreshToken) && !containsString(grantTypes, GrantTypeAuthorizationCode) {
+		return nil, "", fmt.Errorf("%w: refresh_token 必須搭配 authorization_code", ErrOAuthClientInvalid)
+	}
+
+	redirectURIs := uniqueStrings(input.RedirectURIs)
+	if containsString(grantTypes, GrantTypeAuthorizationCode) && len(redirectURIs) == 0 {
+		return nil, "", fmt.Errorf("%w: authorization_code 至少需要一個 redirect_uri", ErrOAuthClientInvalid)
+	}
+	for _, uri := range redirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return nil, "", err
+		}
+	}
+
+	scopes := uniqueStrings(input.Scopes)
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: 至少需要一個 scope", ErrOAuthClientInvalid)
+	}
+	for _, scope := range scopes {
+		if !auth.IsValidScope(scope) {
+			return nil, "", fmt.Errorf("%w: 不支援的 scope %s", ErrOAuthClientInvalid, scope)
+		}
+	}
+	if containsString(scopes, auth.ScopeOfflineAccess) && !containsString(grantTypes, GrantTypeRefreshToken) {
+		return nil, "", fmt.Errorf("%w: offline_access 必須搭配 refresh_token 授權類型", ErrOAuthClientInvalid)
+	}
+
+	clientID, err := auth.GenerateOpaqueToken(18)
+	if err != nil {
+		return nil, "", err
+	}
+	var secret, secretHash string
+	if input.Confidential {
+		if secret, err = auth.GenerateOpaqueToken(32); err != nil {
+			return nil, "", err
+		}
+		secretHash = auth.HashToken(secret)
+	}
+
+	client := &models.OAuthClient{
+		Base: models.Base{
+			CreationTime: time.Now(),
+			CreatorId:    creatorID,
+		},
+		ClientID:     clientID,
+		SecretHash:   secretHash,
+		Name:         name,
+		RedirectURIs: strings.Join(redirectURIs, " "),
+		GrantTypes:   strings.Join(grantTypes, " "),
+		Scopes:       strings.Join(scopes, " "),
+		Confidential: input.Confidential,
+		FirstParty:   input.FirstParty,
+	}
+	if err := s.DB.Create(client).Error; err != nil {
+		return nil, "", err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditOAuthClientCreated,
+		MemberID: creatorID,
+		ActorID:  creatorID,
+		Detail:   fmt.Sprintf("client_id=%s name=%s scopes=%s", clientID, name, client.Scopes),
+	})
+	return client, secret, nil
+}
+
+// ListClients 列出所有 OAuth 用戶端，新的在前
+func (s *OAuthService) ListClients() ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	if err := s.DB.Scopes(models.NotDeleted).
+		Order("creation_time DESC").
+		Find(&clients).Error; err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// GetClient 以 client_id 取得 OAuth 用戶端
+func (s *OAuthService) GetClient(clientID string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, ErrOAuthClientNotFound
+	}
+	var client models.OAuthClient
+	if err := s.DB.Scopes(models.NotDeleted).Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+// RotateClientSecret 為機密用戶端產生新的 client secret，舊 secret 立即失效
+func (s *OAuthService) RotateClientSecret(clientID string, actorID uint) (string, error) {
+	client, err := s.GetClient(clientID)
+	if err != nil {
+		return "", err
+	}
+	if !client.Confidential {
+		return "", fmt.Errorf("%w: 公開用戶端沒有 client secret", ErrOAuthClientInvalid)
+	}
+
+	secret, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if err := s.DB.Model(client).Updates(map[string]interface{}{
+		"secret_hash":            auth.HashToken(secret),
+		"last_modifier_id":       actorID,
+		"last_modification_time": &now,
+	}).Error; err != nil {
+		return "", err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditOAuthSecretRotated,
+		MemberID: actorID,
+		ActorID:  actorID,
+		Detail:   "client_id=" + clientID,
+	})
+	return secret, nil
+}
+
+// DeleteClient 刪除 OAuth 用戶端，並撤銷其所有授權與同意紀錄
+func (s *OAuthService) DeleteClient(clientID string, actorID uint) error {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.OAuthClient{}).
+			Scopes(models.NotDeleted).Where("client_id = ?", clientID).
+			Updates(models.SoftDeleteColumns(actorID, now))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOAuthClientNotFound
+		}
+		if err := tx.Where("client_id = ?", clientID).Delete(&models.OAuthConsent{}).Error; err != nil {
+			return err
+		}
+		_, err := revokeOAuthGrants(tx.Where("client_id = ?", clientID), now)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditOAuthClientDeleted,
+		MemberID: actorID,
+		ActorID:  actorID,
+		Detail:   "client_id=" + clientID,
+	})
+	return nil
+}
+
+// Authorize 處理已登入會員的授權請求。用戶端或 redirect_uri 無效時返回錯誤，不可導回用戶端；
+// 其餘錯誤以導回網址上的 error 參數告知用戶端。approve 為 nil 時表示尚未詢問會員，
+// 第一方用戶端或先前已同意涵蓋所有 scope 時直接簽發授權碼，否則要求會員同意。
+func (s *OAuthService) Authorize(memberID uint, req AuthorizeRequest, approve *bool) (*AuthorizeResult, error) {
+	client, err := s.GetClient(req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	redirectURI, err := matchRedirectURI(client, req.RedirectURI)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := NewMemberService(s.DB).GetMemberByID(memberID); err != nil {
+		return nil, err
+	}
+
+	result := &AuthorizeResult{Client: client}
+	fail := func(code, description string) (*AuthorizeResult, error) {
+		result.RedirectTo = appendQuery(redirectURI, url.Values{
+			"error":             {code},
+			"error_description": {description},
+		}, req.State)
+		return result, nil
+	}
+
+	if req.ResponseType != "code" {
+		return fail(OAuthErrUnsupportedResponseType, "只支援 response_type=code")
+	}
+	if !hasField(client.GrantTypes, GrantTypeAuthorizationCode) {
+		return fail(OAuthErrUnauthorizedClient, "此用戶端不可使用 authorization_code")
+	}
+	scopes, oerr := resolveScopes(client.Scopes, req.Scope)
+	if oerr != nil {
+		return fail(oerr.Code, oerr.Description)
+	}
+	switch {
+	case req.CodeChallenge == "" && !client.Confidential:
+		return fail(OAuthErrInvalidRequest, "公開用戶端必須使用 PKCE")
+	case req.CodeChallenge != "" && req.CodeChallengeMethod != "S256":
+		return fail(OAuthErrInvalidRequest, "code_challenge_method 必須為 S256")
+	case req.CodeChallenge != "" && (len(req.CodeChallenge) < 43 || len(req.CodeChallenge) > 128):
+		return fail(OAuthErrInvalidRequest, "code_challenge 格式錯誤")
+	}
+	result.Scopes = scopes
+
+	if approve != nil && !*approve {
+		return fail(OAuthErrAccessDenied, "會員拒絕授權")
+	}
+	if approve == nil && !client.FirstParty {
+		covered, err := s.consentCovers(memberID, client.ClientID, scopes)
+		if err != nil {
+			return nil, err
+		}
+		if !covered {
+			result.ConsentRequired = true
+			return result, nil
+		}
+	}
+
+	code, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		return nil, err
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if approve != nil {
+			if err := saveConsent(tx, memberID, client.ClientID, scopes); err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		return tx.Create(&models.OAuthAuthorizationCode{
+			Base: models.Base{
+				CreationTime: now,
+				CreatorId:    memberID,
+			},
+			CodeHash:            auth.HashToken(code),
+			ClientID:            client.ClientID,
+			MemberID:            memberID,
+			RedirectURI:         redirectURI,
+			RedirectURIProvided: req.RedirectURI != "",
+			Scopes:              strings.Join(scopes, " "),
+			CodeChallenge:       req.CodeChallenge,
+			CodeChallengeMethod: req.CodeChallengeMethod,
+			ExpiresAt:           now.Add(oauthCodeTTL),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if approve != nil {
+		NewAuditService(s.DB).TryRecord(AuditEntry{
+			Action:   AuditOAuthConsentGranted,
+			MemberID: memberID,
+			ActorID:  memberID,
+			Detail:   fmt.Sprintf("client_id=%s scopes=%s", client.ClientID, strings.Join(scopes, " ")),
+		})
+	}
+	result.RedirectTo = appendQuery(redirectURI, url.Values{"code": {code}}, req.State)
+	return result, nil
+}
+
+// AuthenticateClient 驗證 token、introspection 與 revocation 端點的用戶端身分；
+// 機密用戶端必須提供正確的 client secret，公開用戶端不可提供 secret
+func (s *OAuthService) AuthenticateClient(clientID, secret string) (*models.OAuthClient, error) {
+	client, err := s.GetClient(clientID)
+	if err != nil {
+		if errors.Is(err, ErrOAuthClientNotFound) {
+			return nil, oauthError(OAuthErrInvalidClient, "用戶端驗證失敗")
+		}
+		return nil, err
+	}
+
+	if client.Confidential {
+		if secret == "" || subtle.ConstantTimeCompare([]byte(auth.HashToken(secret)), []byte(client.SecretHash)) != 1 {
+			return nil, oauthError(OAuthErrInvalidClient, "用戶端驗證失敗")
+		}
+	} else if secret != "" {
+		return nil, oauthError(OAuthErrInvalidClient, "公開用戶端不可提供 client secret")
+	}
+	return client, nil
+}
+
+// Token 依授權類型簽發 token，錯誤為 *OAuthError 時應依 RFC 6749 §5.2 回應
+func (s *OAuthService) Token(ctx context.Context, client *models.OAuthClient, req TokenRequest) (*OAuthTokens, error) {
+	if req.GrantType == "" {
+		return nil, oauthError(OAuthErrInvalidRequest, "缺少 grant_type")
+	}
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials:
+	default:
+		return nil, oauthError(OAuthErrUnsupportedGrantType, "不支援的授權類型")
+	}
+	if !hasField(client.GrantTypes, req.GrantType) {
+		return nil, oauthError(OAuthErrUnauthorizedClient, "此用戶端不可使用 "+req.GrantType)
+	}
+
+	db := s.DB.WithContext(ctx)
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode:
+		return exchangeAuthorizationCode(db, client, req)
+	case GrantTypeRefreshToken:
+		return refreshOAuthToken(db, client, req)
+	default:
+		return issueClientCredentials(db, client, req)
+	}
+}
+
+// Introspect 查詢 token 狀態；第一方用戶端可查詢任何 token，其他用戶端只能查詢簽發給自己的 token
+func (s *OAuthService) Introspect(ctx context.Context, caller *models.OAuthClient, rawToken, hint string) (*OAuthIntrospection, error) {
+	db := s.DB.WithContext(ctx)
+	info, err := lookupOAuthToken(db, rawToken, hint)
+	if err != nil {
+		return nil, err
+	}
+	inactive := &OAuthIntrospection{}
+	if info == nil || info.grant.RevokedAt != nil {
+		return inactive, nil
+	}
+	if !caller.FirstParty && info.grant.ClientID != caller.ClientID {
+		return inactive, nil
+	}
+
+	out := &OAuthIntrospection{
+		Active:    true,
+		ClientID:  info.grant.ClientID,
+		TokenType: info.tokenType,
+	}
+	if info.claims != nil {
+		out.Scope = info.claims.Scope
+		out.Subject = info.claims.Subject
+		out.Audience = info.claims.Audience
+		out.ExpiresAt = info.claims.ExpiresAt.Time
+		out.IssuedAt = info.claims.IssuedAt.Time
+		out.TokenID = info.claims.ID
+	} else {
+		out.Scope = info.grant.Scopes
+		out.Subject = strconv.FormatUint(uint64(info.grant.MemberID), 10)
+		out.Audience = []string{info.grant.ClientID}
+		out.ExpiresAt = info.refresh.ExpiresAt
+		out.IssuedAt = info.refresh.CreationTime
+	}
+	if info.grant.MemberID != 0 {
+		var member models.Member
+		if err := db.Scopes(models.NotDeleted).First(&member, info.grant.MemberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return inactive, nil
+			}
+			return nil, err
+		}
+		out.Username = member.Email
+	} else {
+		out.Subject = info.grant.ClientID
+	}
+	return out, nil
+}
+
+// Revoke 撤銷 token 所屬的整個授權（RFC 7009）；token 無效或不屬於呼叫的用戶端時不做任何事
+func (s *OAuthService) Revoke(ctx context.Context, caller *models.OAuthClient, rawToken, hint string) error {
+	db := s.DB.WithContext(ctx)
+	info, err := lookupOAuthToken(db, rawToken, hint)
+	if err != nil || info == nil || info.grant.ClientID != caller.ClientID {
+		return err
+	}
+	_, err = revokeOAuthGrants(db.Where("grant_id = ?", info.grant.GrantID), time.Now())
+	return err
+}
+
+// ValidateGrant 檢查 OAuth access token 所屬的授權是否仍有效；供 auth.SetOAuthGrantValidator 使用
+func (s *OAuthService) ValidateGrant(ctx context.Context, claims *auth.Claims) error {
+	if claims.SessionID == "" {
+		return auth.ErrGrantRevoked
+	}
+
+	var grant models.OAuthGrant
+	if err := s.DB.WithContext(ctx).Where("grant_id = ?", claims.SessionID).First(&grant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.ErrGrantRevoked
+		}
+		return err
+	}
+	if grant.RevokedAt != nil || grant.ClientID != claims.ClientID || int64(grant.MemberID) != claims.UserID {
+		return auth.ErrGrantRevoked
+	}
+	return nil
+}
+
+// ListConsents 列出會員已授權的應用程式
+func (s *OAuthService) ListConsents(memberID uint) ([]OAuthAuthorizedApp, error) {
+	var consents []models.OAuthConsent
+	if err := s.DB.Where("member_id = ?", memberID).
+		Order("creation_time DESC").
+		Find(&consents).Error; err != nil {
+		return nil, err
+	}
+
+	apps := make([]OAuthAuthorizedApp, 0, len(consents))
+	for _, consent := range consents {
+		client, err := s.GetClient(consent.ClientID)
+		if err != nil {
+			if errors.Is(err, ErrOAuthClientNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		apps = append(apps, OAuthAuthorizedApp{
+			ClientID:  client.ClientID,
+			Name:      client.Name,
+			Scopes:    strings.Fields(consent.Scopes),
+			GrantedAt: consent.CreationTime,
+		})
+	}
+	return apps, nil
+}
+
+// RevokeConsent 取消會員對應用程式的授權，並撤銷已簽發給該應用程式的所有 token
+func (s *OAuthService) RevokeConsent(memberID uint, clientID string) error {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		deleted := tx.Where("member_id = ? AND client_id = ?", memberID, clientID).Delete(&models.OAuthConsent{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		revoked, err := revokeOAuthGrants(tx.Where("member_id = ? AND client_id = ?", memberID, clientID), time.Now())
+		if err != nil {
+			return err
+		}
+		if deleted.RowsAffected == 0 && revoked == 0 {
+			return ErrOAuthConsentNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditOAuthConsentRevoked,
+		MemberID: memberID,
+		ActorID:  memberID,
+		Detail:   "client_id=" + clientID,
+	})
+	return nil
+}
+
+// consentCovers 判斷會員先前的同意是否已涵蓋所有要求的 scope
+func (s *OAuthService) consentCovers(memberID uint, clientID string, scopes []string) (bool, error) {
+	var consent models.OAuthConsent
+	if err := s.DB.Where("member_id = ? AND client_id = ?", memberID, clientID).First(&consent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	granted := strings.Fields(consent.Scopes)
+	for _, scope := range scopes {
+		if !containsString(granted, scope) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// saveConsent 記錄會員同意的 scope，與先前同意的合併
+func saveConsent(tx *gorm.DB, memberID uint, clientID string, scopes []string) error {
+	var consent models.OAuthConsent
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("member_id = ? AND client_id = ?", memberID, clientID).
+		First(&consent).Error
+	now := time.Now()
+	switch {
+	case err == nil:
+		merged := uniqueStrings(append(strings.Fields(consent.Scopes), scopes...))
+		return tx.Model(&consent).Updates(map[string]interface{}{
+			"scopes":                 strings.Join(merged, " "),
+			"last_modifier_id":       memberID,
+			"last_modification_time": &now,
+		}).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return tx.Create(&models.OAuthConsent{
+			Base: models.Base{
+				CreationTime: now,
+				CreatorId:    memberID,
+			},
+			MemberID: memberID,
+			ClientID: clientID,
+			Scopes:   strings.Join(scopes, " "),
+		}).Error
+	default:
+		return err
+	}
+}
+
+// exchangeAuthorizationCode 以授權碼換取 token；重複使用的授權碼視為外洩，撤銷先前以它簽發的授權
+func exchangeAuthorizationCode(db *gorm.DB, client *models.OAuthClient, req TokenRequest) (*OAuthTokens, error) {
+	if req.Code == "" {
+		return nil, oauthError(OAuthErrInvalidRequest, "缺少 code")
+	}
+
+	var (
+		tokens       *OAuthTokens
+		replayedFrom string
+	)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var code models.OAuthAuthorizationCode
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code_hash = ?", auth.HashToken(req.Code)).
+			First(&code).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return oauthError(OAuthErrInvalidGrant, "授權碼無效")
+			}
+			return err
+		}
+
+		now := time.Now()
+		switch {
+		case code.ClientID != client.ClientID:
+			return oauthError(OAuthErrInvalidGrant, "授權碼無效")
+		case code.UsedAt != nil:
+			replayedFrom = code.GrantID
+			return oauthError(OAuthErrInvalidGrant, "授權碼已使用")
+		case now.After(code.ExpiresAt):
+			return oauthError(OAuthErrInvalidGrant, "授權碼已過期")
+		case code.RedirectURIProvided && req.RedirectURI == "":
+			// RFC 6749 §4.1.3：授權請求帶了 redirect_uri，換取 token 時必須帶上相同的值
+			return oauthError(OAuthErrInvalidGrant, "缺少 redirect_uri")
+		case req.RedirectURI != "" && req.RedirectURI != code.RedirectURI:
+			return oauthError(OAuthErrInvalidGrant, "redirect_uri 與授權請求不符")
+		}
+		if code.CodeChallenge != "" {
+			if req.CodeVerifier == "" || oidc.CodeChallengeS256(req.CodeVerifier) != code.CodeChallenge {
+				return oauthError(OAuthErrInvalidGrant, "code_verifier 驗證失敗")
+			}
+		} else if req.CodeVerifier != "" {
+			return oauthError(OAuthErrInvalidGrant, "授權請求未使用 PKCE")
+		}
+
+		var member models.Member
+		if err := tx.Scopes(models.NotDeleted).First(&member, code.MemberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return oauthError(OAuthErrInvalidGrant, "會員不存在")
+			}
+			return err
+		}
+
+		scopes := strings.Fields(code.Scopes)
+		grant, err := createOAuthGrant(tx, client.ClientID, member.ID, scopes, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&code).Updates(map[string]interface{}{
+			"used_at":                &now,
+			"grant_id":               grant.GrantID,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		tokens, err = issueOAuthTokens(tx, client, &member, grant, scopes, now)
+		return err
+	})
+	if replayedFrom != "" {
+		if _, rerr := revokeOAuthGrants(db.Where("grant_id = ?", replayedFrom), time.Now()); rerr != nil {
+			return nil, rerr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// refreshOAuthToken 以 refresh token 換發 token 並輪換 refresh token；
+// 已輪換的 refresh token 再次被使用時視為外洩，撤銷整個授權
+func refreshOAuthToken(db *gorm.DB, client *models.OAuthClient, req TokenRequest) (*OAuthTokens, error) {
+	if req.RefreshToken == "" {
+		return nil, oauthError(OAuthErrInvalidRequest, "缺少 refresh_token")
+	}
+
+	var (
+		tokens *OAuthTokens
+		reused string
+	)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var token models.OAuthRefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", auth.HashToken(req.RefreshToken)).
+			First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return oauthError(OAuthErrInvalidGrant, "refresh token 無效")
+			}
+			return err
+		}
+
+		var grant models.OAuthGrant
+		if err := tx.Where("grant_id = ?", token.GrantID).First(&grant).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return oauthError(OAuthErrInvalidGrant, "refresh token 無效")
+			}
+			return err
+		}
+
+		now := time.Now()
+		switch {
+		case grant.ClientID != client.ClientID:
+			return oauthError(OAuthErrInvalidGrant, "refresh token 無效")
+		case token.UsedAt != nil:
+			reused = grant.GrantID
+			return oauthError(OAuthErrInvalidGrant, "refresh token 已被使用，授權已撤銷")
+		case grant.RevokedAt != nil:
+			return oauthError(OAuthErrInvalidGrant, "授權已撤銷")
+		case now.After(token.ExpiresAt):
+			return oauthError(OAuthErrInvalidGrant, "refresh token 已過期")
+		}
+
+		// 可以要求較小的範圍，但不可超出原本的授權
+		scopes, oerr := resolveScopes(grant.Scopes, req.Scope)
+		if oerr != nil {
+			return oerr
+		}
+
+		var member models.Member
+		if err := tx.Scopes(models.NotDeleted).First(&member, grant.MemberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return oauthError(OAuthErrInvalidGrant, "會員不存在")
+			}
+			return err
+		}
+
+		if err := tx.Model(&token).Updates(map[string]interface{}{
+			"used_at":                &now,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = issueOAuthTokens(tx, client, &member, &grant, scopes, now)
+		return err
+	})
+	if reused != "" {
+		if _, rerr := revokeOAuthGrants(db.Where("grant_id = ?", reused), time.Now()); rerr != nil {
+			return nil, rerr
+		}
+		NewAuditService(db).TryRecord(AuditEntry{
+			Action: AuditOAuthTokenReused,
+			Detail: fmt.Sprintf("client_id=%s grant_id=%s", client.ClientID, reused),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// issueClientCredentials 為機密用戶端本身簽發 token，只能申請權限類的 scope，不簽發 refresh token
+func issueClientCredentials(db *gorm.DB, client *models.OAuthClient, req TokenRequest) (*OAuthTokens, error) {
+	if !client.Confidential {
+		return nil, oauthError(OAuthErrUnauthorizedClient, "公開用戶端不可使用 client_credentials")
+	}
+
+	allowed := make([]string, 0)
+	for _, scope := range strings.Fields(client.Scopes) {
+		if containsString(auth.AllPermissions(), scope) {
+			allowed = append(allowed, scope)
+		}
+	}
+	scopes, oerr := resolveScopes(strings.Join(allowed, " "), req.Scope)
+	if oerr != nil {
+		return nil, oerr
+	}
+	if len(scopes) == 0 {
+		return nil, oauthError(OAuthErrInvalidScope, "此用戶端沒有可用於 client_credentials 的 scope")
+	}
+
+	var tokens *OAuthTokens
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		grant, err := createOAuthGrant(tx, client.ClientID, 0, scopes, now)
+		if err != nil {
+			return err
+		}
+		tokens, err = issueOAuthTokens(tx, client, nil, grant, scopes, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func createOAuthGrant(tx *gorm.DB, clientID string, memberID uint, scopes []string, now time.Time) (*models.OAuthGrant, error) {
+	grantID, err := auth.GenerateOpaqueToken(16)
+	if err != nil {
+		return nil, err
+	}
+	grant := &models.OAuthGrant{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    memberID,
+		},
+		GrantID:  grantID,
+		ClientID: clientID,
+		MemberID: memberID,
+		Scopes:   strings.Join(scopes, " "),
+	}
+	if err := tx.Create(grant).Error; err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// issueOAuthTokens 簽發 access token，授權含 offline_access 時一併簽發 refresh token。
+// access token 的權限為權限類 scope 與會員目前權限的交集；只有 email scope 才帶 email。會員不是 active 時拒絕簽發
+func issueOAuthTokens(tx *gorm.DB, client *models.OAuthClient, member *models.Member, grant *models.OAuthGrant, scopes []string, now time.Time) (*OAuthTokens, error) {
+	claims := &auth.Claims{
+		SessionID: grant.GrantID,
+		ClientID:  client.ClientID,
+		Scope:     strings.Join(scopes, " "),
+	}
+
+	var permissions []string
+	if member != nil {
+		if err := CheckMemberStatus(member); err != nil {
+			return nil, oauthError(OAuthErrInvalidGrant, err.Error())
+		}
+		_, memberPermissions, err := NewRoleService(tx).GetMemberAuthz(member.ID)
+		if err != nil {
+			return nil, err
+		}
+		permissions = memberPermissions
+		claims.UserID = int64(member.ID)
+		claims.EmailVerified = member.EmailVerifiedAt != nil
+		if containsString(scopes, auth.ScopeEmail) {
+			claims.Email = member.Email
+		}
+	} else {
+		permissions = auth.AllPermissions()
+		claims.EmailVerified = true
+	}
+	for _, scope := range scopes {
+		if containsString(permissions, scope) {
+			claims.Permissions = append(claims.Permissions, scope)
+		}
+	}
+
+	accessToken, err := auth.SignOAuthAccessToken(claims)
+	if err != nil {
+		return nil, err
+	}
+	tokens := &OAuthTokens{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(auth.OAuthAccessTokenTTL().Seconds()),
+		Scopes:      scopes,
+	}
+
+	if member != nil && hasField(grant.Scopes, auth.ScopeOfflineAccess) && hasField(client.GrantTypes, GrantTypeRefreshToken) {
+		refreshToken, err := auth.GenerateRefreshToken()
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Create(&models.OAuthRefreshToken{
+			Base: models.Base{
+				CreationTime: now,
+				CreatorId:    member.ID,
+			},
+			TokenHash: auth.HashToken(refreshToken),
+			GrantID:   grant.GrantID,
+			ExpiresAt: now.Add(auth.OAuthRefreshTokenTTL()),
+		}).Error; err != nil {
+			return nil, err
+		}
+		tokens.RefreshToken = refreshToken
+	}
+	return tokens, nil
+}
+
+// lookupOAuthToken 依 token 原文找出 access token 或 refresh token 所屬的授權，找不到時返回 nil
+func lookupOAuthToken(db *gorm.DB, rawToken, hint string) (*oauthTokenInfo, error) {
+	if rawToken == "" {
+		return nil, nil
+	}
+
+	findAccess := func() (*oauthTokenInfo, error) {
+		claims, err := auth.ValidateOAuthAccessToken(rawToken)
+		if err != nil {
+			return nil, nil
+		}
+		var grant models.OAuthGrant
+		if err := db.Where("grant_id = ? AND client_id = ?", claims.SessionID, claims.ClientID).First(&grant).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &oauthTokenInfo{grant: grant, tokenType: "access_token", claims: claims}, nil
+	}
+	findRefresh := func() (*oauthTokenInfo, error) {
+		var token models.OAuthRefreshToken
+		if err := db.Where("token_hash = ?", auth.HashToken(rawToken)).First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+			return nil, nil
+		}
+		var grant models.OAuthGrant
+		if err := db.Where("grant_id = ?", token.GrantID).First(&grant).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &oauthTokenInfo{grant: grant, tokenType: "refresh_token", refresh: &token}, nil
+	}
+
+	// token_type_hint 只決定查詢順序（RFC 7009 §2.1）
+	lookups := []func() (*oauthTokenInfo, error){findAccess, findRefresh}
+	if hint == "refresh_token" {
+		lookups = []func() (*oauthTokenInfo, error){findRefresh, findAccess}
+	}
+	for _, lookup := range lookups {
+		info, err := lookup()
+		if err != nil || info != nil {
+			return info, err
+		}
+	}
+	return nil, nil
+}
+
+// revokeOAuthGrants 撤銷符合條件且尚未撤銷的授權，返回撤銷的數量
+func revokeOAuthGrants(query *gorm.DB, now time.Time) (int64, error) {
+	result := query.Model(&models.OAuthGrant{}).
+		Where("revoked_at IS NULL").
+		Updates(map[string]interface{}{
+			"revoked_at":             &now,
+			"last_modification_time": &now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// resolveScopes 解析要求的 scope，必須是 allowed 的子集；未指定時為 allowed 全部
+func resolveScopes(allowed, requested string) ([]string, *OAuthError) {
+	allowedScopes := strings.Fields(allowed)
+	scopes := auth.ParseScope(requested)
+	if len(scopes) == 0 {
+		return allowedScopes, nil
+	}
+	for _, scope := range scopes {
+		if !containsString(allowedScopes, scope) {
+			return nil, oauthError(OAuthErrInvalidScope, "不允許的 scope "+scope)
+		}
+	}
+	return scopes, nil
+}
+
+// matchRedirectURI 返回與用戶端登記完全相符的 redirect_uri；未提供時只在登記了唯一一個時採用
+func matchRedirectURI(client *models.OAuthClient, requested string) (string, error) {
+	registered := strings.Fields(client.RedirectURIs)
+	if requested == "" {
+		if len(registered) == 1 {
+			return registered[0], nil
+		}
+		return "", ErrInvalidRedirectURI
+	}
+	if !containsString(registered, requested) {
+		return "", ErrInvalidRedirectURI
+	}
+	return requested, nil
+}
+
+// validateRedirectURI 檢查登記的 redirect_uri：必須是不含 fragment 的絕對網址，
+// http 只允許本機（RFC 8252 §7.3），原生 App 可使用自訂 scheme
+func validateRedirectURI(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Fragment != "" {
+		return fmt.Errorf("%w: redirect_uri %q 必須是不含 fragment 的絕對網址", ErrOAuthClientInvalid, raw)
+	}
+	switch u.Scheme {
+	case "https":
+		if u.Host == "" {
+			return fmt.Errorf("%w: redirect_uri %q 缺少主機名稱", ErrOAuthClientInvalid, raw)
+		}
+	case "http":
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("%w: redirect_uri %q 必須使用 https（本機除外）", ErrOAuthClientInvalid, raw)
+		}
+	}
+	return nil
+}
+
+// appendQuery 在 redirect_uri 原有的 query 之後加上參數與 state
+func appendQuery(redirectURI string, params url.Values, state string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	q := u.Query()
+	for key, values := range params {
+		q[key] = values
+	}
+	if state != "" {
+		q.Set("state", state)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// hasField 判斷以空白分隔的清單是否包含 target
+func hasField(list, target string) bool {
+	return containsString(strings.Fields(list), target)
+}
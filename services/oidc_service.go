@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"member_API/auth"
+	"member_API/models"
+	"member_API/oidc"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidOIDCState      = errors.New("無效或已過期的登入請求")
+	ErrOIDCEmailRequired     = errors.New("登入提供者未提供電子郵件")
+	ErrOIDCEmailInUse        = errors.New("該電子郵件已註冊，請先以密碼登入後再連結外部帳號")
+	ErrIdentityLinked        = errors.New("此外部帳號已連結其他會員")
+	ErrProviderAlreadyLinked = errors.New("已連結此登入提供者的其他帳號")
+	ErrIdentityNotFound      = errors.New("外部帳號連結不存在")
+	ErrLastLoginMethod       = errors.New("無法移除唯一的登入方式，請先透過忘記密碼設定密碼")
+)
+
+// OIDCAuthRequestTTL 從導向提供者到返回 callback 的最長時間
+const OIDCAuthRequestTTL = 10 * time.Minute
+
+// OIDCLoginResult 完成外部登入的結果
+type OIDCLoginResult struct {
+	Member   *models.Member
+	Identity *models.ExternalIdentity
+	// Created 本次登入建立了新會員
+	Created bool
+	// Linked 本次為已登入會員連結外部帳號，不應簽發新的 token
+	Linked bool
+
+	identityCreated bool
+}
+
+type OIDCService struct {
+	DB        *gorm.DB
+	Providers *oidc.Registry
+}
+
+func NewOIDCService(db *gorm.DB) *OIDCService {
+	return &OIDCService{DB: db, Providers: oidc.CurrentRegistry()}
+}
+
+// BeginLogin 建立授權請求並返回導向提供者的網址與 state；linkMemberID 非 0 時為已登入會員連結外部帳號
+func (s *OIDCService) BeginLogin(ctx context.Context, providerName string, linkMemberID uint) (string, string, error) {
+	provider, err := s.Providers.Get(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := oidc.GenerateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.GenerateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	// 順便清除早已過期的請求
+	if err := s.DB.Where("expires_at < ?", now.Add(-time.Hour)).Delete(&models.OIDCAuthRequest{}).Error; err != nil {
+		return "", "", err
+	}
+	if err := s.DB.Create(&models.OIDCAuthRequest{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    linkMemberID,
+		},
+		StateHash:    auth.HashToken(state),
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkMemberID: linkMemberID,
+		ExpiresAt:    now.Add(OIDCAuthRequestTTL),
+	}).Error; err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// CompleteLogin 處理提供者導回的 callback：驗證 state、以授權碼換取並驗證 ID token，
+// 再找出已連結的會員、以已驗證的 email 連結既有會員，或建立新會員。
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName, state, code string) (*OIDCLoginResult, error) {
+	provider, err := s.Providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	if state == "" || code == "" {
+		return nil, ErrInvalidOIDCState
+	}
+
+	// 先將授權請求標記為已使用，確保同一個 state 只能完成一次
+	var request models.OIDCAuthRequest
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ? AND provider = ?", auth.HashToken(state), provider.Name()).
+			First(&request).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidOIDCState
+			}
+			return err
+		}
+
+		now := time.Now()
+		if request.UsedAt != nil || now.After(request.ExpiresAt) {
+			return ErrInvalidOIDCState
+		}
+		return tx.Model(&request).Updates(map[string]interface{}{
+			"used_at":                &now,
+			"last_modification_time": &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := provider.Exchange(ctx, code, request.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, request.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *OIDCLoginResult
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if request.LinkMemberID != 0 {
+			result, err = linkIdentity(tx, request.LinkMemberID, provider.Name(), claims)
+		} else {
+			result, err = loginWithIdentity(tx, provider.Name(), claims)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Created && result.Member.EmailVerifiedAt == nil {
+		trySendVerification(s.DB, result.Member)
+	}
+	if result.identityCreated {
+		NewAuditService(s.DB).TryRecord(AuditEntry{
+			Action:   AuditIdentityLinked,
+			MemberID: result.Member.ID,
+			ActorID:  result.Member.ID,
+			Detail:   fmt.Sprintf("provider=%s subject=%s", provider.Name(), claims.Subject),
+		})
+	}
+	return result, nil
+}
+
+// ListIdentities 列出會員已連結的外部帳號
+func (s *OIDCService) ListIdentities(memberID uint) ([]models.ExternalIdentity, error) {
+	var identities []models.ExternalIdentity
+	if err := s.DB.Scopes(models.NotDeleted).Where("member_id = ?", memberID).
+		Order("provider").
+		Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// Unlink 移除會員與外部帳號的連結；會員沒有密碼且這是唯一的外部帳號時拒絕，避免無法再登入
+func (s *OIDCService) Unlink(memberID uint, providerName string) error {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		member, err := lockMember(tx, memberID)
+		if err != nil {
+			return err
+		}
+
+		var identities []models.ExternalIdentity
+		if err := tx.Scopes(models.NotDeleted).Where("member_id = ?", memberID).Find(&identities).Error; err != nil {
+			return err
+		}
+
+		var target *models.ExternalIdentity
+		for i := range identities {
+			if identities[i].Provider == providerName {
+				target = &identities[i]
+			}
+		}
+		if target == nil {
+			return ErrIdentityNotFound
+		}
+		if member.PasswordHash == "" && len(identities) == 1 {
+			return ErrLastLoginMethod
+		}
+
+		// 外部帳號需可再連結到其他會員，因此直接刪除而非軟刪除
+		return tx.Delete(target).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditIdentityUnlinked,
+		MemberID: memberID,
+		ActorID:  memberID,
+		Detail:   "provider=" + providerName,
+	})
+	return nil
+}
+
+// loginWithIdentity 以外部帳號登入：已連結則返回該會員，否則以 email 連結已驗證郵箱的會員或建立會員
+func loginWithIdentity(tx *gorm.DB, provider string, claims *oidc.IDTokenClaims) (*OIDCLoginResult, error) {
+	identity, err := findIdentity(tx, provider, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		var member models.Member
+		if err := tx.Scopes(models.NotDeleted).First(&member, identity.MemberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("會員不存在")
+			}
+			return nil, err
+		}
+		if err := touchIdentity(tx, identity, claims.Email); err != nil {
+			return nil, err
+		}
+		return &OIDCLoginResult{Member: &member, Identity: identity}, nil
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	var member models.Member
+	err = tx.Scopes(models.NotDeleted, ByEmail(email)).First(&member).Error
+	switch {
+	case err == nil:
+		// 只有提供者確認過 email 屬於該使用者時才自動連結，否則任何人都能以他人 email 註冊外部帳號接管會員。
+		// 本地會員的 email 尚未驗證時也不自動連結：可能是他人搶先以此 email 註冊，連結後其密碼與登入仍然有效。
+		// 使用者需先以密碼登入（或透過忘記密碼重設密碼，同時登出所有登入）後再自行連結
+		if !claims.EmailVerified || member.EmailVerifiedAt == nil {
+			return nil, ErrOIDCEmailInUse
+		}
+		identity, err := createIdentity(tx, member.ID, provider, claims)
+		if err != nil {
+			return nil, err
+		}
+		return &OIDCLoginResult{Member: &member, Identity: identity, identityCreated: true}, nil
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		name := strings.TrimSpace(claims.Name)
+		if name == "" {
+			// 提供者未給名稱時以 email 的帳號部分代替
+			name = email
+			if at := strings.Index(email, "@"); at > 0 {
+				name = email[:at]
+			}
+		}
+		created, err := NewMemberService(tx).CreateExternalMember(name, email, bool(claims.EmailVerified))
+		if err != nil {
+			return nil, err
+		}
+		identity, err := createIdentity(tx, created.ID, provider, claims)
+		if err != nil {
+			return nil, err
+		}
+		return &OIDCLoginResult{Member: created, Identity: identity, Created: true, identityCreated: true}, nil
+
+	default:
+		return nil, err
+	}
+}
+
+// linkIdentity 將外部帳號連結到已登入的會員
+func linkIdentity(tx *gorm.DB, memberID uint, provider string, claims *oidc.IDTokenClaims) (*OIDCLoginResult, error) {
+	member, err := lockMember(tx, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := findIdentity(tx, provider, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		if identity.MemberID != memberID {
+			return nil, ErrIdentityLinked
+		}
+		if err := touchIdentity(tx, identity, claims.Email); err != nil {
+			return nil, err
+		}
+		return &OIDCLoginResult{Member: member, Identity: identity, Linked: true}, nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.ExternalIdentity{}).
+		Scopes(models.NotDeleted).Where("member_id = ? AND provider = ?", memberID, provider).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrProviderAlreadyLinked
+	}
+
+	identity, err = createIdentity(tx, memberID, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCLoginResult{Member: member, Identity: identity, Linked: true, identityCreated: true}, nil
+}
+
+func findIdentity(tx *gorm.DB, provider, subject string) (*models.ExternalIdentity, error) {
+	var identity models.ExternalIdentity
+	if err := tx.Scopes(models.NotDeleted).Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func createIdentity(tx *gorm.DB, memberID uint, provider string, claims *oidc.IDTokenClaims) (*models.ExternalIdentity, error) {
+	now := time.Now()
+	identity := &models.ExternalIdentity{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    memberID,
+		},
+		MemberID:    memberID,
+		Provider:    provider,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: now,
+	}
+	if err := tx.Create(identity).Error; err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func touchIdentity(tx *gorm.DB, identity *models.ExternalIdentity, email string) error {
+	now := time.Now()
+	identity.LastLoginAt = now
+	identity.Email = email
+	return tx.Model(identity).Updates(map[string]interface{}{
+		"last_login_at":          now,
+		"email":                  email,
+		"last_modification_time": &now,
+	}).Error
+}
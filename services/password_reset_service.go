@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"member_API/auth"
+	"member_API/mail"
+	"member_API/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// passwordResetTTL 重設密碼 token 的有效期
+var passwordResetTTL = 30 * time.Minute
+
+// passwordResetURL 重設密碼頁面網址，token 以 query string 附加
+var passwordResetURL = "http://localhost:8080/reset-password"
+
+// SetPasswordResetOptions 設定重設密碼 token 有效期與頁面網址，零值會被忽略
+func SetPasswordResetOptions(ttl time.Duration, url string) {
+	if ttl > 0 {
+		passwordResetTTL = ttl
+	}
+	if url != "" {
+		passwordResetURL = url
+	}
+}
+
+type PasswordResetService struct {
+	DB     *gorm.DB
+	Mailer mail.Sender
+}
+
+func NewPasswordResetService(db *gorm.DB) *PasswordResetService {
+	return &PasswordResetService{DB: db, Mailer: mail.Default()}
+}
+
+// RequestReset 為會員建立重設密碼 token 並寄出郵件。
+// email 不存在時同樣返回 nil；找到會員之後建立 token 或寄信失敗只記錄日誌，不返回錯誤，
+// 回應因此不會因 email 是否已註冊而不同，避免洩漏帳號是否存在。
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	var member models.Member
+	if err := s.DB.Scopes(models.NotDeleted, ByEmail(email)).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	var rawToken string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rawToken, err = issueMemberToken(tx, member.ID, TokenPurposePasswordReset, passwordResetTTL, "")
+		return err
+	})
+	if err != nil {
+		log.Printf("[PasswordReset] failed to issue reset token for member %d: %v\n", member.ID, err)
+		return nil
+	}
+
+	msg := mail.Message{
+		To:      member.Email,
+		Subject: "重設您的密碼",
+		Body: fmt.Sprintf("%s 您好：\n\n請點擊以下連結重設密碼，連結將於 %d 分鐘後失效且只能使用一次：\n%s?token=%s\n\n若您沒有申請重設密碼，請忽略此郵件。",
+			member.Name, int(passwordResetTTL.Minutes()), passwordResetURL, rawToken),
+	}
+	if err := s.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("[PasswordReset] failed to send reset mail to member %d: %v\n", member.ID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword 以一次性 token 設定新密碼，並撤銷該會員所有登入
+// 密碼不符合規則時 transaction 會回滾，token 仍可再次使用。
+func (s *PasswordResetService) ResetPassword(rawToken, newPassword string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		record, err := consumeMemberToken(tx, rawToken, TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+
+		var member models.Member
+		if err := tx.Scopes(models.NotDeleted).First(&member, record.MemberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidMemberToken
+			}
+			return err
+		}
+
+		if err := auth.ValidatePassword(newPassword, member.Email, member.Name); err != nil {
+			return err
+		}
+		hash, err := auth.HashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&member).Updates(map[string]interface{}{
+			"password_hash":          hash,
+			"last_modifier_id":       record.MemberID,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return NewTokenService(tx).RevokeAllForMember(record.MemberID)
+	})
+}
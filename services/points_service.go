@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"member_API/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidPoints            = errors.New("點數必須為正整數且不可超過上限")
+	ErrInvalidPointsEntryType   = errors.New("點數異動類型必須為 earn、redeem 或 adjust")
+	ErrInsufficientPoints       = errors.New("點數餘額不足")
+	ErrPointsReasonRequired     = errors.New("調整點數需提供原因")
+	ErrPointsMemberInactive     = errors.New("會員帳號未啟用，無法累積或兌換點數")
+	ErrDuplicatePointsReference = errors.New("相同參考編號的點數異動已入帳")
+)
+
+// maxPointsPerEntry 單筆點數異動的上限，避免誤植或溢位
+const maxPointsPerEntry = 1_000_000_000
+
+var pointsExpiry = 365 * 24 * time.Hour
+
+// SetPointsExpiry 設定點數入帳後的有效期限，非正值會被忽略
+func SetPointsExpiry(period time.Duration) {
+	if period > 0 {
+		pointsExpiry = period
+	}
+}
+
+// PointsEntryInput 一筆點數異動。earn 與 redeem 的 Points 為正數；adjust 可為正（補發）或負（扣除），需提供原因
+type PointsEntryInput struct {
+	Type      string
+	Points    int64
+	Reason    string
+	Reference string
+	ActorID   uint
+}
+
+// PointsSummary 會員的點數餘額與最近一批即將到期的點數
+type PointsSummary struct {
+	Balance        int64      `json:"balance" example:"1200"`
+	ExpiringPoints int64      `json:"expiring_points" example:"300"`
+	NextExpiry     *time.Time `json:"next_expiry,omitempty"`
+}
+
+// PointsExpiryResult 一次點數到期作業的結果
+type PointsExpiryResult struct {
+	Members int
+	Points  int64
+}
+
+// PointsService 管理會員的點數帳本。帳本只新增不修改，餘額保存在 MemberPoints 並在鎖定該筆資料後與帳本一起更新。
+// 扣點（兌換、扣除與到期）一律先扣最早到期的點數；點數的到期時間依入帳先後遞增，因此只需依帳本總額即可算出到期的點數
+type PointsService struct {
+	DB *gorm.DB
+}
+
+func NewPointsService(db *gorm.DB) *PointsService {
+	return &PointsService{DB: db}
+}
+
+// Summary 取得會員的點數餘額與下一批到期的點數
+func (s *PointsService) Summary(memberID uint) (*PointsSummary, error) {
+	if _, err := NewMemberService(s.DB).GetMemberByID(memberID); err != nil {
+		return nil, err
+	}
+
+	var points models.MemberPoints
+	err := s.DB.Where("member_id = ?", memberID).First(&points).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &PointsSummary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &PointsSummary{Balance: points.Balance}
+	if points.Balance == 0 {
+		return summary, nil
+	}
+
+	// 尚未結算的最早到期時間；該時間點到期的點數 = 當時已到期的入帳總額 - 所有扣點總額
+	next := s.DB.Model(&models.PointsLedgerEntry{}).
+		Where("member_id = ? AND points > 0 AND expires_at IS NOT NULL", memberID)
+	if points.ExpiredThrough != nil {
+		next = next.Where("expires_at > ?", *points.ExpiredThrough)
+	}
+	var nextExpiry sql.NullTime
+	if err := next.Select("MIN(expires_at)").Row().Scan(&nextExpiry); err != nil {
+		return nil, err
+	}
+	if !nextExpiry.Valid {
+		return summary, nil
+	}
+
+	credits, debits, err := pointsTotals(s.DB, memberID, nextExpiry.Time)
+	if err != nil {
+		return nil, err
+	}
+	if expiring := fifoExpiringPoints(credits, debits, points.Balance); expiring > 0 {
+		summary.ExpiringPoints = expiring
+		summary.NextExpiry = &nextExpiry.Time
+	}
+	return summary, nil
+}
+
+// History 列出會員的點數異動，新的在前，並返回總筆數
+func (s *PointsService) History(memberID uint, limit, offset int) ([]models.PointsLedgerEntry, int64, error) {
+	if _, err := NewMemberService(s.DB).GetMemberByID(memberID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.DB.Model(&models.PointsLedgerEntry{}).Where("member_id = ?", memberID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.PointsLedgerEntry
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// Record 在會員的帳本新增一筆累積、兌換或調整，返回新增的異動。
+// 累積與兌換只限 active 的會員；兌換或扣除超過餘額時返回 ErrInsufficientPoints。
+// 有 Reference 且相同類型的異動已入帳時返回 ErrDuplicatePointsReference，不會重複入帳
+func (s *PointsService) Record(memberID uint, input PointsEntryInput) (*models.PointsLedgerEntry, error) {
+	input.Reason = truncate(strings.TrimSpace(input.Reason), 500)
+	input.Reference = truncate(strings.TrimSpace(input.Reference), 100)
+
+	delta := input.Points
+	switch input.Type {
+	case models.PointsEntryEarn:
+		if input.Points <= 0 || input.Points > maxPointsPerEntry {
+			return nil, ErrInvalidPoints
+		}
+	case models.PointsEntryRedeem:
+		if input.Points <= 0 || input.Points > maxPointsPerEntry {
+			return nil, ErrInvalidPoints
+		}
+		delta = -input.Points
+	case models.PointsEntryAdjust:
+		if input.Points == 0 || input.Points > maxPointsPerEntry || input.Points < -maxPointsPerEntry {
+			return nil, ErrInvalidPoints
+		}
+		if input.Reason == "" {
+			return nil, ErrPointsReasonRequired
+		}
+	default:
+		return nil, ErrInvalidPointsEntryType
+	}
+
+	now := time.Now()
+	entry := &models.PointsLedgerEntry{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    input.ActorID,
+		},
+		MemberID:  memberID,
+		Type:      input.Type,
+		Points:    delta,
+		Reason:    input.Reason,
+		Reference: input.Reference,
+		ActorID:   input.ActorID,
+	}
+	if delta > 0 {
+		expiresAt := now.Add(pointsExpiry)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var member models.Member
+		if err := tx.Scopes(models.NotDeleted).First(&member, memberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("會員不存在")
+			}
+			return err
+		}
+		if input.Type != models.PointsEntryAdjust {
+			if err := CheckMemberStatus(&member); err != nil {
+				return fmt.Errorf("%w：%v", ErrPointsMemberInactive, err)
+			}
+		}
+
+		points, err := lockMemberPoints(tx, memberID)
+		if err != nil {
+			return err
+		}
+		if input.Reference != "" {
+			var exists int64
+			if err := tx.Model(&models.PointsLedgerEntry{}).
+				Where("member_id = ? AND type = ? AND reference = ?", memberID, input.Type, input.Reference).
+				Count(&exists).Error; err != nil {
+				return err
+			}
+			if exists > 0 {
+				return ErrDuplicatePointsReference
+			}
+		}
+		return appendPointsEntry(tx, points, entry)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrDuplicatePointsReference
+		}
+		return nil, err
+	}
+
+	if input.Type == models.PointsEntryAdjust {
+		NewAuditService(s.DB).TryRecord(AuditEntry{
+			Action:   AuditPointsAdjusted,
+			MemberID: memberID,
+			ActorID:  input.ActorID,
+			Detail:   fmt.Sprintf("points=%+d balance=%d reason=%s", entry.Points, entry.Balance, entry.Reason),
+		})
+	}
+	return entry, nil
+}
+
+// Expire 結算所有在 now 之前到期的點數，每位會員各自在一個 transaction 中寫入 expire 異動
+func (s *PointsService) Expire(now time.Time) (*PointsExpiryResult, error) {
+	var memberIDs []uint
+	if err := s.DB.Model(&models.PointsLedgerEntry{}).
+		Joins("JOIN member_points ON member_points.member_id = points_ledger_entries.member_id").
+		Where("points_ledger_entries.points > 0 AND points_ledger_entries.expires_at <= ?", now).
+		Where("member_points.expired_through IS NULL OR points_ledger_entries.expires_at > member_points.expired_through").
+		Distinct().
+		Order("points_ledger_entries.member_id").
+		Pluck("points_ledger_entries.member_id", &memberIDs).Error; err != nil {
+		return nil, err
+	}
+
+	result := &PointsExpiryResult{}
+	for _, memberID := range memberIDs {
+		var expired int64
+		if err := s.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			expired, err = expireMemberPoints(tx, memberID, now)
+			return err
+		}); err != nil {
+			return result, err
+		}
+		if expired > 0 {
+			result.Members++
+			result.Points += expired
+		}
+	}
+	return result, nil
+}
+
+// RunExpiry 每隔 interval 結算一次到期的點數，直到 ctx 結束
+func (s *PointsService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := NewPointsService(s.DB.WithContext(ctx)).Expire(time.Now())
+			if err != nil {
+				log.Printf("[Points] Warning: expiring points failed: %v\n", err)
+				continue
+			}
+			if result.Members > 0 {
+				log.Printf("[Points] Expired %d points of %d members\n", result.Points, result.Members)
+			}
+		}
+	}
+}
+
+// expireMemberPoints 鎖定會員的點數餘額後寫入在 now 之前到期、尚未使用的點數，返回到期的點數
+func expireMemberPoints(tx *gorm.DB, memberID uint, now time.Time) (int64, error) {
+	points, err := lockMemberPoints(tx, memberID)
+	if err != nil {
+		return 0, err
+	}
+	if points.ExpiredThrough != nil && !now.After(*points.ExpiredThrough) {
+		return 0, nil
+	}
+
+	credits, debits, err := pointsTotals(tx, memberID, now)
+	if err != nil {
+		return 0, err
+	}
+	expired := fifoExpiringPoints(credits, debits, points.Balance)
+	if expired > 0 {
+		if err := appendPointsEntry(tx, points, &models.PointsLedgerEntry{
+			Base: models.Base{
+				CreationTime: now,
+			},
+			MemberID: memberID,
+			Type:     models.PointsEntryExpire,
+			Points:   -expired,
+			Reason:   "點數到期",
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Model(points).Update("expired_through", &now).Error; err != nil {
+		return 0, err
+	}
+	return expired, nil
+}
+
+// pointsTotals 返回會員在 cutoff 之前到期的入帳總額，以及所有扣點（含先前的到期）總額
+func pointsTotals(db *gorm.DB, memberID uint, cutoff time.Time) (credits, debits int64, err error) {
+	var totals struct {
+		Credits int64
+		Debits  int64
+	}
+	if err := db.Model(&models.PointsLedgerEntry{}).
+		Select("COALESCE(SUM(CASE WHEN points > 0 AND expires_at <= ? THEN points ELSE 0 END), 0) AS credits, "+
+			"COALESCE(SUM(CASE WHEN points < 0 THEN -points ELSE 0 END), 0) AS debits", cutoff).
+		Where("member_id = ?", memberID).
+		Scan(&totals).Error; err != nil {
+		return 0, 0, err
+	}
+	return totals.Credits, totals.Debits, nil
+}
+
+// fifoExpiringPoints 返回到期、尚未被扣除的點數。扣點一律先扣最早到期的點數，
+// 因此到期的入帳總額減去所有扣點總額即為剩下會到期的點數；不會是負數，也不超過目前餘額
+func fifoExpiringPoints(credits, debits, balance int64) int64 {
+	expiring := credits - debits
+	if expiring > balance {
+		expiring = balance
+	}
+	if expiring < 0 {
+		return 0
+	}
+	return expiring
+}
+
+// lockMemberPoints 鎖定會員的點數餘額（沒有時先建立），同一會員的點數異動因此依序進行
+func lockMemberPoints(tx *gorm.DB, memberID uint) (*models.MemberPoints, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.MemberPoints{MemberID: memberID}).Error; err != nil {
+		return nil, err
+	}
+	var points models.MemberPoints
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("member_id = ?", memberID).
+		First(&points).Error; err != nil {
+		return nil, err
+	}
+	return &points, nil
+}
+
+// appendPointsEntry 以已鎖定的餘額計算異動後的餘額，新增帳本並更新餘額；扣點超過餘額時返回 ErrInsufficientPoints
+func appendPointsEntry(tx *gorm.DB, points *models.MemberPoints, entry *models.PointsLedgerEntry) error {
+	balance := points.Balance + entry.Points
+	if balance < 0 {
+		return fmt.Errorf("%w，目前餘額 %d", ErrInsufficientPoints, points.Balance)
+	}
+	entry.Balance = balance
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+	points.Balance = balance
+	return tx.Model(points).Updates(map[string]interface{}{
+		"balance":                balance,
+		"last_modification_time": entry.CreationTime,
+	}).Error
+}
+
+// transferPoints 合併會員時將來源會員的點數餘額以一對調整異動轉給目標會員，返回轉移的點數；
+// 目標會員收到的點數視為新入帳，有效期限重新計算
+func transferPoints(tx *gorm.DB, sourceID, targetID, actorID uint, now time.Time) (int64, error) {
+	source, err := lockMemberPoints(tx, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	amount := source.Balance
+	if amount == 0 {
+		return 0, nil
+	}
+	target, err := lockMemberPoints(tx, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	base := models.Base{CreationTime: now, CreatorId: actorID}
+	if err := appendPointsEntry(tx, source, &models.PointsLedgerEntry{
+		Base:     base,
+		MemberID: sourceID,
+		Type:     models.PointsEntryAdjust,
+		Points:   -amount,
+		Reason:   fmt.Sprintf("合併至會員 #%d", targetID),
+		ActorID:  actorID,
+	}); err != nil {
+		return 0, err
+	}
+	expiresAt := now.Add(pointsExpiry)
+	credit := &models.PointsLedgerEntry{
+		Base:      base,
+		MemberID:  targetID,
+		Type:      models.PointsEntryAdjust,
+		Points:    amount,
+		ExpiresAt: &expiresAt,
+		Reason:    fmt.Sprintf("由會員 #%d 合併轉入", sourceID),
+		ActorID:   actorID,
+	}
+	if err := appendPointsEntry(tx, target, credit); err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
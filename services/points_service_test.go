@@ -0,0 +1,158 @@
+package services
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ledgerStep 帳本中的一筆異動；expiresIn 為入帳的到期天數，扣點為 0
+type ledgerStep struct {
+	points    int64
+	expiresIn int
+}
+
+// ledgerTotals 與 pointsTotals 的 SQL 相同：cutoff 之前到期的入帳總額、所有扣點總額與餘額
+func ledgerTotals(steps []ledgerStep, start, cutoff time.Time) (credits, debits, balance int64) {
+	for _, step := range steps {
+		balance += step.points
+		if step.points < 0 {
+			debits -= step.points
+		} else if !start.AddDate(0, 0, step.expiresIn).After(cutoff) {
+			credits += step.points
+		}
+	}
+	return credits, debits, balance
+}
+
+// simulateFIFO 逐筆模擬先扣最早到期的點數，返回 cutoff 之前到期、尚未被扣除的點數
+func simulateFIFO(steps []ledgerStep, start, cutoff time.Time) int64 {
+	type batch struct {
+		expiresAt time.Time
+		remaining int64
+	}
+	var batches []*batch
+	for _, step := range steps {
+		if step.points > 0 {
+			batches = append(batches, &batch{expiresAt: start.AddDate(0, 0, step.expiresIn), remaining: step.points})
+			sort.SliceStable(batches, func(a, b int) bool { return batches[a].expiresAt.Before(batches[b].expiresAt) })
+			continue
+		}
+		debit := -step.points
+		for _, b := range batches {
+			used := min(b.remaining, debit)
+			b.remaining -= used
+			debit -= used
+		}
+	}
+	var expiring int64
+	for _, b := range batches {
+		if !b.expiresAt.After(cutoff) {
+			expiring += b.remaining
+		}
+	}
+	return expiring
+}
+
+func TestFIFOExpiringPoints(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		steps  []ledgerStep
+		cutoff int
+		want   int64
+	}{
+		{
+			name:   "沒有異動",
+			cutoff: 10,
+			want:   0,
+		},
+		{
+			name:   "尚未到期",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}},
+			cutoff: 9,
+			want:   0,
+		},
+		{
+			name:   "到期當下整批到期",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}},
+			cutoff: 10,
+			want:   100,
+		},
+		{
+			name:   "兌換先扣最早到期的一批",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}, {points: 50, expiresIn: 20}, {points: -30}},
+			cutoff: 10,
+			want:   70,
+		},
+		{
+			name:   "兌換超過第一批時扣到下一批",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}, {points: 50, expiresIn: 20}, {points: -120}},
+			cutoff: 10,
+			want:   0,
+		},
+		{
+			name:   "第二批到期時扣除已用掉的部分",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}, {points: 50, expiresIn: 20}, {points: -120}},
+			cutoff: 20,
+			want:   30,
+		},
+		{
+			name:   "先前的到期也算扣點",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}, {points: 50, expiresIn: 20}, {points: -100}, {points: -10}},
+			cutoff: 20,
+			want:   40,
+		},
+		{
+			name:   "兌換後再入帳",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}, {points: -60}, {points: 80, expiresIn: 30}, {points: -50}},
+			cutoff: 10,
+			want:   0,
+		},
+		{
+			name:   "管理員補發與扣除",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}, {points: 20, expiresIn: 15}, {points: -25}, {points: 40, expiresIn: 40}},
+			cutoff: 15,
+			want:   95,
+		},
+		{
+			name:   "全部扣完",
+			steps:  []ledgerStep{{points: 100, expiresIn: 10}, {points: 50, expiresIn: 20}, {points: -150}},
+			cutoff: 30,
+			want:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cutoff := start.AddDate(0, 0, tt.cutoff)
+			credits, debits, balance := ledgerTotals(tt.steps, start, cutoff)
+			got := fifoExpiringPoints(credits, debits, balance)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, simulateFIFO(tt.steps, start, cutoff), got)
+		})
+	}
+}
+
+func TestFIFOExpiringPointsBounds(t *testing.T) {
+	tests := []struct {
+		name                     string
+		credits, debits, balance int64
+		want                     int64
+	}{
+		{name: "扣點多於到期入帳", credits: 100, debits: 150, balance: 50, want: 0},
+		{name: "不超過餘額", credits: 100, debits: 0, balance: 60, want: 60},
+		{name: "餘額為零", credits: 100, debits: 20, balance: 0, want: 0},
+		{name: "一般情況", credits: 100, debits: 20, balance: 300, want: 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fifoExpiringPoints(tt.credits, tt.debits, tt.balance))
+		})
+	}
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"member_API/auth"
+	"member_API/models"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrExportInProgress      = errors.New("已有進行中的資料匯出，請稍後再試")
+	ErrExportNotFound        = errors.New("資料匯出不存在")
+	ErrExportNotReady        = errors.New("資料匯出尚未完成")
+	ErrExportExpired         = errors.New("資料匯出已過期，請重新申請")
+	ErrAlreadyErased         = errors.New("會員個人資料已清除")
+	ErrErasureNotAllowed     = errors.New("不可清除此會員的個人資料")
+	ErrErasureReasonRequired = errors.New("請說明清除個人資料的原因")
+)
+
+// ErasedMemberName 個人資料清除後會員的顯示名稱
+const ErasedMemberName = "已清除會員"
+
+var dataExportTTL = 7 * 24 * time.Hour
+
+// dataExportStaleAfter 匯出工作停在 pending/running 超過此時間即視為中斷（例如服務在產生期間重啟）
+const dataExportStaleAfter = 30 * time.Minute
+
+// errDataExportStale 寫入中斷的匯出工作的錯誤訊息
+const errDataExportStale = "匯出逾時未完成，請重新申請"
+
+// SetDataExportTTL 設定匯出檔可下載的期限
+func SetDataExportTTL(ttl time.Duration) {
+	if ttl > 0 {
+		dataExportTTL = ttl
+	}
+}
+
+// DataExportDocument 匯出檔的內容，Sections 以資料類別為鍵
+type DataExportDocument struct {
+	ExportID    string                     `json:"export_id"`
+	MemberID    uint                       `json:"member_id"`
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Sections    map[string]json.RawMessage `json:"sections"`
+}
+
+// PrivacyService 處理會員個人資料的匯出與清除（資料主體請求）
+type PrivacyService struct {
+	DB *gorm.DB
+}
+
+func NewPrivacyService(db *gorm.DB) *PrivacyService {
+	return &PrivacyService{DB: db}
+}
+
+// RequestExport 建立資料匯出工作並在背景產生匯出檔；同一會員同時只能有一個進行中的匯出，過期的匯出檔在此時刪除，
+// 逾時未完成的匯出工作標記為失敗
+func (s *PrivacyService) RequestExport(memberID, requestedBy uint) (*models.DataExport, error) {
+	var member models.Member
+	if err := s.DB.First(&member, memberID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("會員不存在")
+		}
+		return nil, err
+	}
+	if member.ErasedAt != nil {
+		return nil, ErrAlreadyErased
+	}
+
+	exportID, err := auth.GenerateOpaqueToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	export := &models.DataExport{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    requestedBy,
+		},
+		ExportID:    exportID,
+		MemberID:    memberID,
+		RequestedBy: requestedBy,
+		Status:      models.DataExportPending,
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("member_id = ? AND expires_at < ?", memberID, now).
+			Delete(&models.DataExport{}).Error; err != nil {
+			return err
+		}
+		// 中斷的匯出工作不再阻擋新的申請
+		if _, err := failStaleExports(tx.Where("member_id = ?", memberID), now); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.DataExport{}).
+			Where("member_id = ? AND status IN ?", memberID, []string{models.DataExportPending, models.DataExportRunning}).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrExportInProgress
+		}
+		return tx.Create(export).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditDataExportRequested,
+		MemberID: memberID,
+		ActorID:  requestedBy,
+		Detail:   "export=" + exportID,
+	})
+
+	// 請求結束後 context 會被取消，背景工作改用獨立的 context
+	go NewPrivacyService(s.DB.WithContext(context.Background())).runExport(export.ID, memberID, exportID)
+	return export, nil
+}
+
+// GetExport 依匯出 ID 取得匯出工作；逾時未完成的匯出工作標記為失敗後返回
+func (s *PrivacyService) GetExport(exportID string) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := s.DB.Where("export_id = ?", exportID).First(&export).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+	if now := time.Now(); dataExportStale(&export, now) {
+		if _, err := failStaleExports(s.DB.Where("id = ?", export.ID), now); err != nil {
+			return nil, err
+		}
+		if err := s.DB.First(&export, export.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &export, nil
+}
+
+// ExportDocument 返回已完成且未過期的匯出檔內容
+func ExportDocument(export *models.DataExport) (*DataExportDocument, error) {
+	if export.Status != models.DataExportCompleted {
+		return nil, ErrExportNotReady
+	}
+	if export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt) {
+		return nil, ErrExportExpired
+	}
+
+	var doc DataExportDocument
+	if err := json.Unmarshal(export.Data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// ExportZip 將匯出檔打包為 ZIP：manifest.json 與每個資料類別一個 JSON 檔
+func ExportZip(doc *DataExportDocument) ([]byte, error) {
+	names := make([]string, 0, len(doc.Sections))
+	for name := range doc.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, data []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: doc.GeneratedAt})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"export_id":    doc.ExportID,
+		"member_id":    doc.MemberID,
+		"generated_at": doc.GeneratedAt,
+		"sections":     names,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := write("manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		var section bytes.Buffer
+		if err := json.Indent(&section, doc.Sections[name], "", "  "); err != nil {
+			return nil, err
+		}
+		if err := write(name+".json", section.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FailStaleExports 將所有逾時未完成的匯出工作標記為失敗，在服務啟動時呼叫；
+// 匯出在背景 goroutine 中產生，服務重啟時進行中的工作會中斷且不會再被執行
+func (s *PrivacyService) FailStaleExports() (int64, error) {
+	return failStaleExports(s.DB, time.Now())
+}
+
+// dataExportStale 判斷匯出工作是否停在 pending/running 超過 dataExportStaleAfter；
+// 以最後更新時間（開始產生時會更新）為準，未曾更新時以建立時間為準
+func dataExportStale(export *models.DataExport, now time.Time) bool {
+	if export.Status != models.DataExportPending && export.Status != models.DataExportRunning {
+		return false
+	}
+	last := export.CreationTime
+	if export.LastModificationTime != nil && export.LastModificationTime.After(last) {
+		last = *export.LastModificationTime
+	}
+	return now.Sub(last) > dataExportStaleAfter
+}
+
+// failStaleExports 將 query 範圍內逾時未完成的匯出工作標記為失敗，條件與 dataExportStale 相同
+func failStaleExports(query *gorm.DB, now time.Time) (int64, error) {
+	cutoff := now.Add(-dataExportStaleAfter)
+	result := query.Model(&models.DataExport{}).
+		Where("status IN ?", []string{models.DataExportPending, models.DataExportRunning}).
+		Where("COALESCE(last_modification_time, creation_time) < ? AND creation_time < ?", cutoff, cutoff).
+		Updates(map[string]interface{}{
+			"status":                 models.DataExportFailed,
+			"error":                  errDataExportStale,
+			"completed_at":           &now,
+			"last_modification_time": &now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// runExport 產生匯出檔並更新匯出工作的狀態
+func (s *PrivacyService) runExport(id, memberID uint, exportID string) {
+	now := time.Now()
+	if err := s.DB.Model(&models.DataExport{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":                 models.DataExportRunning,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+		log.Printf("[Privacy] Warning: failed to start data export %s: %v\n", exportID, err)
+		return
+	}
+
+	data, err := s.collect(memberID, exportID)
+	now = time.Now()
+	updates := map[string]interface{}{
+		"completed_at":           &now,
+		"last_modification_time": &now,
+	}
+	if err != nil {
+		log.Printf("[Privacy] Warning: data export %s failed: %v\n", exportID, err)
+		updates["status"] = models.DataExportFailed
+		updates["error"] = truncate(err.Error(), 500)
+	} else {
+		expiresAt := now.Add(dataExportTTL)
+		updates["status"] = models.DataExportCompleted
+		updates["data"] = data
+		updates["expires_at"] = &expiresAt
+	}
+	if err := s.DB.Model(&models.DataExport{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		log.Printf("[Privacy] Warning: failed to save data export %s: %v\n", exportID, err)
+	}
+}
+
+// collect 收集會員本人的資料與所有相關資料；密碼雜湊、token 雜湊等機密欄位不會輸出
+func (s *PrivacyService) collect(memberID uint, exportID string) ([]byte, error) {
+	var member models.Member
+	if err := s.DB.First(&member, memberID).Error; err != nil {
+		return nil, err
+	}
+	roles, permissions, err := NewRoleService(s.DB).GetMemberAuthz(memberID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := NewGroupService(s.DB).MemberGroupNames(memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sessions       []models.Session
+		apiKeys        []models.APIKey
+		identities     []models.ExternalIdentity
+		consents       []models.OAuthConsent
+		grants         []models.OAuthGrant
+		statusHistory  []models.MemberStatusChange
+		impersonations []models.ImpersonationSession
+		tokens         []models.MemberToken
+		auditLogs      []models.AuditLog
+		addresses      []models.MemberAddress
+		preferences    []models.MemberPreference
+		points         []models.PointsLedgerEntry
+		merges         []models.MemberMerge
+	)
+	for _, dest := range []interface{}{
+		&sessions, &apiKeys, &identities, &consents, &grants,
+		&statusHistory, &impersonations, &tokens, &addresses, &preferences, &points,
+	} {
+		if err := s.DB.Where("member_id = ?", memberID).Order("id").Find(dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := s.DB.Where("member_id = ? OR actor_id = ?", memberID, memberID).
+		Order("id").
+		Find(&auditLogs).Error; err != nil {
+		return nil, err
+	}
+	// 合併紀錄保留了被合併會員的姓名與 email，合併至此會員或此會員被合併的紀錄都要匯出
+	if err := s.DB.Where("source_member_id = ? OR target_member_id = ?", memberID, memberID).
+		Order("id").
+		Find(&merges).Error; err != nil {
+		return nil, err
+	}
+
+	sections := map[string]interface{}{
+		"member":                 member,
+		"roles":                  map[string][]string{"roles": roles, "permissions": permissions},
+		"groups":                 groups,
+		"sessions":               sessions,
+		"api_keys":               apiKeys,
+		"external_identities":    identities,
+		"oauth_consents":         consents,
+		"oauth_grants":           grants,
+		"status_history":         statusHistory,
+		"impersonation_sessions": impersonations,
+		"member_tokens":          tokens,
+		"audit_logs":             auditLogs,
+		"addresses":              addresses,
+		"preferences":            preferences,
+		"points":                 points,
+		"merges":                 merges,
+	}
+	doc := DataExportDocument{
+		ExportID:    exportID,
+		MemberID:    memberID,
+		GeneratedAt: time.Now().UTC(),
+		Sections:    make(map[string]json.RawMessage, len(sections)),
+	}
+	for name, value := range sections {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		doc.Sections[name] = raw
+	}
+	return json.Marshal(doc)
+}
+
+// Erase 清除會員的個人資料：姓名與 email 匿名化、清空個人檔案、密碼與兩步驟驗證，帳號關閉並移入刪除狀態。
+// 登入、token、API key、外部帳號連結、OAuth 授權、地址、溝通偏好與匯出檔一併刪除；會員列保留，其他資料的 CreatorId、LastModifierId 等參照仍然有效。
+// 審計紀錄保留但清除其中的 IP、User-Agent 與含有 email 的說明；合併紀錄中保留的被合併會員姓名與 email 同樣匿名化。
+// 已清除的會員不會被回收桶還原或永久刪除
+func (s *PrivacyService) Erase(memberID, actorID uint, reason string) (*models.Member, error) {
+	reason = truncate(strings.TrimSpace(reason), 500)
+	if reason == "" {
+		return nil, ErrErasureReasonRequired
+	}
+	if memberID == actorID {
+		return nil, fmt.Errorf("%w：不可清除自己的個人資料", ErrErasureNotAllowed)
+	}
+
+	now := time.Now()
+	var member models.Member
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// 已刪除（在回收桶中）的會員也可以清除
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&member, memberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("會員不存在")
+			}
+			return err
+		}
+		if member.ErasedAt != nil {
+			return ErrAlreadyErased
+		}
+		email := member.Email
+		from := EffectiveMemberStatus(&member, now)
+
+		updates := models.SoftDeleteColumns(actorID, now)
+		if member.IsDeleted && member.DeletedAt != nil {
+			updates["deleted_at"] = member.DeletedAt
+		}
+		updates["name"] = ErasedMemberName
+		updates["email"] = fmt.Sprintf("erased-%d@invalid", member.ID)
+		updates["password_hash"] = ""
+		updates["email_verified_at"] = nil
+		updates["totp_secret"] = ""
+		updates["totp_enabled_at"] = nil
+		updates["totp_last_counter"] = 0
+		updates["status"] = models.MemberStatusClosed
+		updates["status_reason"] = ""
+		updates["suspended_until"] = nil
+		updates["phone"] = ""
+		updates["birthday"] = nil
+		updates["avatar_url"] = ""
+		updates["locale"] = ""
+		updates["timezone"] = ""
+		updates["erased_at"] = &now
+		if err := tx.Model(&member).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.MemberStatusChange{
+			Base: models.Base{
+				CreationTime: now,
+				CreatorId:    actorID,
+			},
+			MemberID:   memberID,
+			FromStatus: from,
+			ToStatus:   models.MemberStatusClosed,
+			Reason:     "個人資料已清除",
+			ActorID:    actorID,
+		}).Error; err != nil {
+			return err
+		}
+		if err := endMemberImpersonations(tx, memberID, now); err != nil {
+			return err
+		}
+		if err := deleteMemberData(tx, memberID); err != nil {
+			return err
+		}
+		if err := tx.Where("key = ?", auth.AccountAttemptKey(email)).Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.AuditLog{}).
+			Where("member_id = ? OR actor_id = ?", memberID, memberID).
+			Updates(map[string]interface{}{"ip": "", "user_agent": ""}).Error; err != nil {
+			return err
+		}
+		// 合併至此會員的來源會員是同一人，其姓名與 email 也要清除；合併紀錄本身保留供轉址
+		emails := []string{email}
+		var mergedEmails []string
+		if err := tx.Model(&models.MemberMerge{}).Where("target_member_id = ?", memberID).
+			Pluck("source_email", &mergedEmails).Error; err != nil {
+			return err
+		}
+		emails = append(emails, mergedEmails...)
+		if err := tx.Model(&models.MemberMerge{}).
+			Where("source_member_id = ? OR target_member_id = ?", memberID, memberID).
+			Updates(map[string]interface{}{
+				"source_name":  ErasedMemberName,
+				"source_email": gorm.Expr("'erased-' || source_member_id || '@invalid'"),
+			}).Error; err != nil {
+			return err
+		}
+		for _, e := range uniqueStrings(emails) {
+			if e == "" {
+				continue
+			}
+			if err := tx.Model(&models.AuditLog{}).
+				Where("position(? in detail) > 0", e).
+				Update("detail", "").Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.First(&member, memberID).Error; err != nil {
+		return nil, err
+	}
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditMemberErased,
+		MemberID: memberID,
+		ActorID:  actorID,
+		Detail:   "reason=" + reason,
+	})
+	return &member, nil
+}
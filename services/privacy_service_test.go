@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"member_API/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataExportStale(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-dataExportStaleAfter - time.Minute)
+	recent := now.Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		status   string
+		created  time.Time
+		modified *time.Time
+		stale    bool
+	}{
+		{name: "剛建立的 pending", status: models.DataExportPending, created: recent},
+		{name: "逾時的 pending", status: models.DataExportPending, created: old, stale: true},
+		{name: "剛開始的 running", status: models.DataExportRunning, created: old, modified: &recent},
+		{name: "逾時的 running", status: models.DataExportRunning, created: old, modified: &old, stale: true},
+		{name: "剛好在期限內", status: models.DataExportRunning, created: now.Add(-dataExportStaleAfter)},
+		{name: "已完成不受影響", status: models.DataExportCompleted, created: old, modified: &old},
+		{name: "已失敗不受影響", status: models.DataExportFailed, created: old},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := &models.DataExport{
+				Status: tt.status,
+				Base:   models.Base{CreationTime: tt.created, LastModificationTime: tt.modified},
+			}
+			assert.Equal(t, tt.stale, dataExportStale(export, now))
+		})
+	}
+}
@@ -42,7 +42,7 @@ func (s *ProductService) CreateProduct(name string, price float64, description,
 // UpdateProduct 更新產品資訊
 func (s *ProductService) UpdateProduct(id uint, updates map[string]interface{}, modifierId uint) (*models.Product, error) {
 	var product models.Product
-	if err := s.DB.Where("is_deleted = ?", false).First(&product, id).Error; err != nil {
+	if err := s.DB.Scopes(models.NotDeleted).First(&product, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("產品不存在")
 		}
@@ -69,13 +69,8 @@ func (s *ProductService) UpdateProduct(id uint, updates map[string]interface{},
 func (s *ProductService) DeleteProduct(id uint, deleterId uint) error {
 	now := time.Now()
 	result := s.DB.Model(&models.Product{}).
-		Where("id = ? AND is_deleted = ?", id, false).
-		Updates(map[string]interface{}{
-			"is_deleted":             true,
-			"deleted_at":             &now,
-			"last_modifier_id":       deleterId,
-			"last_modification_time": &now,
-		})
+		Scopes(models.NotDeleted).Where("id = ?", id).
+		Updates(models.SoftDeleteColumns(deleterId, now))
 
 	if result.Error != nil {
 		return result.Error
@@ -91,7 +86,7 @@ func (s *ProductService) DeleteProduct(id uint, deleterId uint) error {
 // GetProductByID 取得單一產品
 func (s *ProductService) GetProductByID(id uint) (*models.Product, error) {
 	var product models.Product
-	if err := s.DB.Where("is_deleted = ?", false).First(&product, id).Error; err != nil {
+	if err := s.DB.Scopes(models.NotDeleted).First(&product, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("產品不存在")
 		}
@@ -106,12 +101,12 @@ func (s *ProductService) GetProducts(limit, offset int) ([]models.Product, int64
 	var total int64
 
 	// 取得總數
-	if err := s.DB.Model(&models.Product{}).Where("is_deleted = ?", false).Count(&total).Error; err != nil {
+	if err := s.DB.Model(&models.Product{}).Scopes(models.NotDeleted).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 取得產品列表
-	if err := s.DB.Where("is_deleted = ?", false).
+	if err := s.DB.Scopes(models.NotDeleted).
 		Order("sort ASC, id DESC").
 		Limit(limit).
 		Offset(offset).
@@ -0,0 +1,525 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"member_API/models"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	// 內嵌 IANA 時區資料，容器內沒有 tzdata 時也能驗證時區
+	_ "time/tzdata"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAddressNotFound  = errors.New("地址不存在")
+	ErrTooManyAddresses = errors.New("地址數量已達上限")
+)
+
+// maxAddressesPerMember 每位會員最多可儲存的地址數
+const maxAddressesPerMember = 20
+
+var (
+	phonePattern      = regexp.MustCompile(`^\+?[0-9]{6,15}$`)
+	phoneSeparators   = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+	localePattern     = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	countryPattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	postalCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]{1,19}$`)
+)
+
+// ProfileValidationError 個人資料欄位不符合格式，Fields 以欄位名稱對應錯誤說明
+type ProfileValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ProfileValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = name + "：" + e.Fields[name]
+	}
+	return "資料格式錯誤：" + strings.Join(messages, "；")
+}
+
+// fieldErrors 收集欄位驗證錯誤
+type fieldErrors map[string]string
+
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &ProfileValidationError{Fields: f}
+}
+
+// ProfileInput 個人檔案的修改內容；nil 表示不修改，空字串表示清除
+type ProfileInput struct {
+	Phone     *string
+	Birthday  *string // YYYY-MM-DD
+	AvatarURL *string
+	Locale    *string
+	Timezone  *string
+}
+
+// AddressInput 新增或修改地址的內容
+type AddressInput struct {
+	Type          string
+	Label         string
+	RecipientName string
+	Phone         string
+	Country       string
+	PostalCode    string
+	Region        string
+	City          string
+	Line1         string
+	Line2         string
+	IsDefault     bool
+}
+
+// PreferencesInput 溝通偏好的修改內容；nil 表示不修改
+type PreferencesInput struct {
+	MarketingEmail   *bool
+	MarketingSMS     *bool
+	Newsletter       *bool
+	PreferredChannel *string
+}
+
+// ProfileService 管理會員的個人檔案、地址與溝通偏好
+type ProfileService struct {
+	DB *gorm.DB
+}
+
+func NewProfileService(db *gorm.DB) *ProfileService {
+	return &ProfileService{DB: db}
+}
+
+// UpdateDetails 驗證並修改會員的個人檔案欄位
+func (s *ProfileService) UpdateDetails(memberID uint, input ProfileInput) (*models.Member, error) {
+	updates, err := profileUpdates(input)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := NewMemberService(s.DB).GetMemberByID(memberID)
+	if err != nil {
+		return nil, err
+	}
+	if len(updates) == 0 {
+		return member, nil
+	}
+
+	now := time.Now()
+	updates["last_modifier_id"] = memberID
+	updates["last_modification_time"] = &now
+	if err := s.DB.Model(member).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return NewMemberService(s.DB).GetMemberByID(memberID)
+}
+
+// ListAddresses 列出會員的地址，預設地址在前
+func (s *ProfileService) ListAddresses(memberID uint) ([]models.MemberAddress, error) {
+	var addresses []models.MemberAddress
+	if err := s.DB.Scopes(models.NotDeleted).
+		Where("member_id = ?", memberID).
+		Order("is_default DESC, id").
+		Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// CreateAddress 新增地址；會員該類型的第一個地址自動設為預設
+func (s *ProfileService) CreateAddress(memberID uint, input AddressInput) (*models.MemberAddress, error) {
+	address, err := normalizeAddress(input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	address.MemberID = memberID
+	address.CreationTime = now
+	address.CreatorId = memberID
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockMember(tx, memberID); err != nil {
+			return err
+		}
+
+		var count, sameType int64
+		if err := tx.Model(&models.MemberAddress{}).Scopes(models.NotDeleted).
+			Where("member_id = ?", memberID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxAddressesPerMember {
+			return fmt.Errorf("%w（%d 個）", ErrTooManyAddresses, maxAddressesPerMember)
+		}
+		if err := tx.Model(&models.MemberAddress{}).Scopes(models.NotDeleted).
+			Where("member_id = ? AND type = ?", memberID, address.Type).
+			Count(&sameType).Error; err != nil {
+			return err
+		}
+		if sameType == 0 {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, memberID, address.Type, now); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// UpdateAddress 修改會員的地址；改變類型時預設標記會取消，原類型的預設地址另行遞補
+func (s *ProfileService) UpdateAddress(memberID, addressID uint, input AddressInput) (*models.MemberAddress, error) {
+	normalized, err := normalizeAddress(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var address *models.MemberAddress
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		address, err = lockAddress(tx, memberID, addressID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		oldType, wasDefault := address.Type, address.IsDefault
+		isDefault := normalized.IsDefault || (wasDefault && oldType == normalized.Type)
+		if isDefault {
+			if err := clearDefaultAddress(tx, memberID, normalized.Type, now); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(address).Updates(map[string]interface{}{
+			"type":                   normalized.Type,
+			"label":                  normalized.Label,
+			"recipient_name":         normalized.RecipientName,
+			"phone":                  normalized.Phone,
+			"country":                normalized.Country,
+			"postal_code":            normalized.PostalCode,
+			"region":                 normalized.Region,
+			"city":                   normalized.City,
+			"line1":                  normalized.Line1,
+			"line2":                  normalized.Line2,
+			"is_default":             isDefault,
+			"last_modifier_id":       memberID,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+			return err
+		}
+		if wasDefault && oldType != normalized.Type {
+			if err := promoteDefaultAddress(tx, memberID, oldType, now); err != nil {
+				return err
+			}
+		}
+
+		address, err = lockAddress(tx, memberID, addressID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// SetDefaultAddress 將地址設為該類型的預設地址
+func (s *ProfileService) SetDefaultAddress(memberID, addressID uint) (*models.MemberAddress, error) {
+	var address *models.MemberAddress
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		address, err = lockAddress(tx, memberID, addressID)
+		if err != nil {
+			return err
+		}
+		if address.IsDefault {
+			return nil
+		}
+
+		now := time.Now()
+		if err := clearDefaultAddress(tx, memberID, address.Type, now); err != nil {
+			return err
+		}
+		address.IsDefault = true
+		return tx.Model(address).Updates(map[string]interface{}{
+			"is_default":             true,
+			"last_modifier_id":       memberID,
+			"last_modification_time": &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// DeleteAddress 刪除地址；刪除預設地址時由同類型最早建立的地址遞補
+func (s *ProfileService) DeleteAddress(memberID, addressID uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		address, err := lockAddress(tx, memberID, addressID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		updates := models.SoftDeleteColumns(memberID, now)
+		updates["is_default"] = false
+		if err := tx.Model(address).Updates(updates).Error; err != nil {
+			return err
+		}
+		if address.IsDefault {
+			return promoteDefaultAddress(tx, memberID, address.Type, now)
+		}
+		return nil
+	})
+}
+
+// GetPreferences 返回會員的溝通偏好；尚未設定時返回預設值（不接收行銷訊息、偏好 email）
+func (s *ProfileService) GetPreferences(memberID uint) (*models.MemberPreference, error) {
+	var preference models.MemberPreference
+	err := s.DB.Where("member_id = ?", memberID).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.MemberPreference{MemberID: memberID, PreferredChannel: models.ChannelEmail}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// UpdatePreferences 修改會員的溝通偏好
+func (s *ProfileService) UpdatePreferences(memberID uint, input PreferencesInput) (*models.MemberPreference, error) {
+	if input.PreferredChannel != nil {
+		channel := strings.TrimSpace(*input.PreferredChannel)
+		if channel != models.ChannelEmail && channel != models.ChannelSMS && channel != models.ChannelNone {
+			return nil, fieldErrors{"preferred_channel": "必須為 email、sms 或 none"}.err()
+		}
+		input.PreferredChannel = &channel
+	}
+
+	var preference *models.MemberPreference
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		member, err := lockMember(tx, memberID)
+		if err != nil {
+			return err
+		}
+		if input.PreferredChannel != nil && *input.PreferredChannel == models.ChannelSMS && member.Phone == "" {
+			return fieldErrors{"preferred_channel": "請先設定手機號碼"}.err()
+		}
+
+		preference, err = NewProfileService(tx).GetPreferences(memberID)
+		if err != nil {
+			return err
+		}
+		if input.MarketingEmail != nil {
+			preference.MarketingEmail = *input.MarketingEmail
+		}
+		if input.MarketingSMS != nil {
+			preference.MarketingSMS = *input.MarketingSMS
+		}
+		if input.Newsletter != nil {
+			preference.Newsletter = *input.Newsletter
+		}
+		if input.PreferredChannel != nil {
+			preference.PreferredChannel = *input.PreferredChannel
+		}
+
+		now := time.Now()
+		preference.LastModifierId = memberID
+		preference.LastModificationTime = &now
+		if preference.ID == 0 {
+			preference.CreationTime = now
+			preference.CreatorId = memberID
+		}
+		return tx.Save(preference).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// profileUpdates 驗證個人檔案欄位並轉換為要更新的欄位
+func profileUpdates(input ProfileInput) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+	errs := fieldErrors{}
+
+	if input.Phone != nil {
+		phone, ok := normalizePhone(*input.Phone)
+		if !ok {
+			errs["phone"] = "手機號碼格式錯誤，需為 6 到 15 位數字，可加國碼（如 +886912345678）"
+		}
+		updates["phone"] = phone
+	}
+	if input.Birthday != nil {
+		value := strings.TrimSpace(*input.Birthday)
+		if value == "" {
+			updates["birthday"] = nil
+		} else if birthday, err := time.Parse("2006-01-02", value); err != nil {
+			errs["birthday"] = "日期格式需為 YYYY-MM-DD"
+		} else if birthday.Year() < 1900 || !birthday.Before(time.Now()) {
+			errs["birthday"] = "生日需介於 1900-01-01 與今天之間"
+		} else {
+			updates["birthday"] = &birthday
+		}
+	}
+	if input.AvatarURL != nil {
+		value := strings.TrimSpace(*input.AvatarURL)
+		if value != "" {
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs["avatar_url"] = "需為 http 或 https 網址"
+			} else if len(value) > 512 {
+				errs["avatar_url"] = "網址不可超過 512 個字元"
+			}
+		}
+		updates["avatar_url"] = value
+	}
+	if input.Locale != nil {
+		value := strings.TrimSpace(*input.Locale)
+		if value != "" && (len(value) > 16 || !localePattern.MatchString(value)) {
+			errs["locale"] = "需為 BCP 47 語言標籤（如 zh-TW、en）"
+		}
+		updates["locale"] = value
+	}
+	if input.Timezone != nil {
+		value := strings.TrimSpace(*input.Timezone)
+		if value != "" {
+			if _, err := time.LoadLocation(value); err != nil || value == "Local" || len(value) > 64 {
+				errs["timezone"] = "需為 IANA 時區名稱（如 Asia/Taipei）"
+			}
+		}
+		updates["timezone"] = value
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// normalizeAddress 驗證地址欄位並去除前後空白；國家代碼轉為大寫
+func normalizeAddress(input AddressInput) (*models.MemberAddress, error) {
+	address := &models.MemberAddress{
+		Type:          strings.TrimSpace(input.Type),
+		Label:         strings.TrimSpace(input.Label),
+		RecipientName: strings.TrimSpace(input.RecipientName),
+		Country:       strings.ToUpper(strings.TrimSpace(input.Country)),
+		PostalCode:    strings.TrimSpace(input.PostalCode),
+		Region:        strings.TrimSpace(input.Region),
+		City:          strings.TrimSpace(input.City),
+		Line1:         strings.TrimSpace(input.Line1),
+		Line2:         strings.TrimSpace(input.Line2),
+		IsDefault:     input.IsDefault,
+	}
+	errs := fieldErrors{}
+
+	if address.Type != models.AddressTypeShipping && address.Type != models.AddressTypeBilling {
+		errs["type"] = "必須為 shipping 或 billing"
+	}
+	for _, field := range []struct {
+		name     string
+		value    string
+		max      int
+		required bool
+	}{
+		{"label", address.Label, 50, false},
+		{"recipient_name", address.RecipientName, 100, true},
+		{"region", address.Region, 100, false},
+		{"city", address.City, 100, true},
+		{"line1", address.Line1, 255, true},
+		{"line2", address.Line2, 255, false},
+	} {
+		switch {
+		case field.required && field.value == "":
+			errs[field.name] = "必填"
+		case len([]rune(field.value)) > field.max:
+			errs[field.name] = fmt.Sprintf("不可超過 %d 個字", field.max)
+		}
+	}
+	if !countryPattern.MatchString(address.Country) {
+		errs["country"] = "需為 ISO 3166-1 二碼國家代碼（如 TW）"
+	}
+	if address.PostalCode != "" && !postalCodePattern.MatchString(address.PostalCode) {
+		errs["postal_code"] = "郵遞區號格式錯誤"
+	}
+	if phone, ok := normalizePhone(input.Phone); ok {
+		address.Phone = phone
+	} else {
+		errs["phone"] = "電話號碼格式錯誤，需為 6 到 15 位數字，可加國碼"
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// normalizePhone 去除空白、括號與分隔符號；空字串視為未填
+func normalizePhone(value string) (string, bool) {
+	phone := phoneSeparators.Replace(strings.TrimSpace(value))
+	if phone == "" {
+		return "", true
+	}
+	return phone, phonePattern.MatchString(phone)
+}
+
+func lockAddress(tx *gorm.DB, memberID, addressID uint) (*models.MemberAddress, error) {
+	var address models.MemberAddress
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scopes(models.NotDeleted).
+		Where("member_id = ?", memberID).
+		First(&address, addressID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// clearDefaultAddress 取消會員該類型地址的預設標記
+func clearDefaultAddress(tx *gorm.DB, memberID uint, addressType string, now time.Time) error {
+	return tx.Model(&models.MemberAddress{}).Scopes(models.NotDeleted).
+		Where("member_id = ? AND type = ? AND is_default = ?", memberID, addressType, true).
+		Updates(map[string]interface{}{
+			"is_default":             false,
+			"last_modification_time": &now,
+		}).Error
+}
+
+// promoteDefaultAddress 會員該類型沒有預設地址時，將最早建立的地址設為預設
+func promoteDefaultAddress(tx *gorm.DB, memberID uint, addressType string, now time.Time) error {
+	var next models.MemberAddress
+	err := tx.Scopes(models.NotDeleted).
+		Where("member_id = ? AND type = ?", memberID, addressType).
+		Order("is_default DESC, id").
+		First(&next).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil || next.IsDefault {
+		return err
+	}
+	return tx.Model(&next).Updates(map[string]interface{}{
+		"is_default":             true,
+		"last_modification_time": &now,
+	}).Error
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"member_API/auth"
+	"member_API/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoleNotFound = errors.New("角色不存在")
+)
+
+// defaultPermissions 內建權限及說明
+var defaultPermissions = map[string]string{
+	auth.PermMemberRead:        "查看會員資料",
+	auth.PermMemberWrite:       "建立與修改會員",
+	auth.PermMemberDelete:      "刪除會員",
+	auth.PermProductRead:       "查看產品",
+	auth.PermProductWrite:      "建立、修改與刪除產品",
+	auth.PermRoleManage:        "管理角色與會員角色指派",
+	auth.PermAuditRead:         "查看審計紀錄",
+	auth.PermAPIKeyManage:      "管理服務帳號與其他會員的 API key",
+	auth.PermOAuthManage:       "管理 OAuth 用戶端（第三方應用程式）",
+	auth.PermMemberImpersonate: "代理會員登入",
+	auth.PermPrivacyManage:     "匯出與清除會員個人資料",
+	auth.PermMemberExport:      "匯出所有會員資料",
+	auth.PermMemberMerge:       "合併重複的會員",
+	auth.PermGroupManage:       "管理會員群組與群組成員",
+	auth.PermPointsManage:      "為會員累積、兌換或調整點數",
+}
+
+// defaultRoles 內建角色及其權限
+var defaultRoles = map[string]struct {
+	Description string
+	Permissions []string
+}{
+	auth.RoleAdmin: {
+		Description: "系統管理員",
+		Permissions: []string{
+			auth.PermMemberRead, auth.PermMemberWrite, auth.PermMemberDelete,
+			auth.PermProductRead, auth.PermProductWrite, auth.PermRoleManage,
+			auth.PermAuditRead, auth.PermAPIKeyManage, auth.PermOAuthManage,
+			auth.PermMemberImpersonate, auth.PermPrivacyManage, auth.PermMemberExport,
+			auth.PermMemberMerge, auth.PermGroupManage, auth.PermPointsManage,
+		},
+	},
+	auth.RoleMember: {
+		Description: "一般會員",
+		Permissions: []string{auth.PermProductRead},
+	},
+}
+
+// revokedRolePermissions 舊版曾授予內建角色、現已收回的權限；啟動時從既有角色移除。
+// member:read 可查看其他會員的地址、偏好、點數與狀態歷史，只限管理員
+var revokedRolePermissions = map[string][]string{
+	auth.RoleMember: {auth.PermMemberRead},
+}
+
+type RoleService struct {
+	DB *gorm.DB
+}
+
+func NewRoleService(db *gorm.DB) *RoleService {
+	return &RoleService{DB: db}
+}
+
+// EnsureDefaults 建立內建權限與角色、收回內建角色已不再擁有的權限，並在第一次建立角色時為既有會員指派一般會員角色
+func (s *RoleService) EnsureDefaults() error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		permissions := make(map[string]models.Permission, len(defaultPermissions))
+		for name, description := range defaultPermissions {
+			permission := models.Permission{Name: name}
+			if err := tx.Where(models.Permission{Name: name}).
+				Attrs(models.Permission{Description: description}).
+				FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			permissions[name] = permission
+		}
+
+		for name, def := range defaultRoles {
+			role := models.Role{Name: name}
+			if err := tx.Where(models.Role{Name: name}).
+				Attrs(models.Role{Description: def.Description}).
+				FirstOrCreate(&role).Error; err != nil {
+				return err
+			}
+
+			rolePermissions := make([]models.Permission, 0, len(def.Permissions))
+			for _, p := range def.Permissions {
+				rolePermissions = append(rolePermissions, permissions[p])
+			}
+			if err := tx.Model(&role).Association("Permissions").Append(rolePermissions); err != nil {
+				return err
+			}
+
+			if revoked := revokedRolePermissions[name]; len(revoked) > 0 {
+				if err := tx.Exec(`DELETE FROM role_permissions
+					WHERE role_id = ? AND permission_id IN (SELECT id FROM permissions WHERE name IN ?)`,
+					role.ID, revoked).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// 只在第一次建立角色時為既有會員補上一般會員角色；之後的新會員由 AssignDefaultRole 指派，
+		// 被刻意移除所有角色的會員不會在重新啟動時拿回角色
+		var assigned int64
+		if err := tx.Table("member_roles").Count(&assigned).Error; err != nil {
+			return err
+		}
+		if assigned > 0 {
+			return nil
+		}
+
+		var memberRole models.Role
+		if err := tx.Where("name = ?", auth.RoleMember).First(&memberRole).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO member_roles (member_id, role_id)
+			SELECT id, ? FROM members WHERE is_deleted = ?`, memberRole.ID, false).Error
+	})
+}
+
+// AssignDefaultRole 為新會員指派一般會員角色
+func (s *RoleService) AssignDefaultRole(memberID uint) error {
+	return s.addRole(memberID, auth.RoleMember)
+}
+
+// GrantRoleByEmail 依 email 為會員加上角色，用於啟動時指定初始管理員
+func (s *RoleService) GrantRoleByEmail(email, roleName string) error {
+	var member models.Member
+	if err := s.DB.Scopes(models.NotDeleted, ByEmail(email)).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("會員不存在")
+		}
+		return err
+	}
+	return s.addRole(member.ID, roleName)
+}
+
+// GetRoles 取得所有角色及其權限
+func (s *RoleService) GetRoles() ([]models.Role, error) {
+	var roles []models.Role
+	if err := s.DB.Scopes(models.NotDeleted).
+		Preload("Permissions").
+		Order("id ASC").
+		Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetMemberAuthz 取得會員的角色名稱與權限名稱（皆已去重並排序），用於簽發 token
+func (s *RoleService) GetMemberAuthz(memberID uint) ([]string, []string, error) {
+	var roles []models.Role
+	if err := s.DB.Preload("Permissions").
+		Joins("JOIN member_roles ON member_roles.role_id = roles.id").
+		Scopes(models.NotDeleted).Where("member_roles.member_id = ?", memberID).
+		Find(&roles).Error; err != nil {
+		return nil, nil, err
+	}
+
+	roleNames := make([]string, 0, len(roles))
+	permissionSet := make(map[string]struct{})
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+		for _, p := range role.Permissions {
+			permissionSet[p.Name] = struct{}{}
+		}
+	}
+
+	permissions := make([]string, 0, len(permissionSet))
+	for p := range permissionSet {
+		permissions = append(permissions, p)
+	}
+	sort.Strings(roleNames)
+	sort.Strings(permissions)
+
+	return roleNames, permissions, nil
+}
+
+// SetMemberRoles 以指定角色完全取代會員目前的角色
+func (s *RoleService) SetMemberRoles(memberID uint, roleNames []string, modifierId uint) (*models.Member, error) {
+	var member models.Member
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(models.NotDeleted).First(&member, memberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("會員不存在")
+			}
+			return err
+		}
+
+		roles := make([]models.Role, 0, len(roleNames))
+		if len(roleNames) > 0 {
+			if err := tx.Scopes(models.NotDeleted).Where("name IN ?", roleNames).Find(&roles).Error; err != nil {
+				return err
+			}
+			if len(roles) != len(uniqueStrings(roleNames)) {
+				return ErrRoleNotFound
+			}
+		}
+
+		if err := tx.Model(&member).Association("Roles").Replace(roles); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&member).Updates(map[string]interface{}{
+			"last_modifier_id":       modifierId,
+			"last_modification_time": &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Preload("Roles").First(&member, memberID).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// addRole 為會員追加單一角色（已擁有時不重複建立）
+func (s *RoleService) addRole(memberID uint, roleName string) error {
+	var role models.Role
+	if err := s.DB.Scopes(models.NotDeleted).Where("name = ?", roleName).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	member := models.Member{Base: models.Base{ID: memberID}}
+	return s.DB.Model(&member).Association("Roles").Append(&role)
+}
+
+// uniqueStrings 去除重複字串並保留原順序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"member_API/auth"
+	"member_API/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionNotFound = errors.New("登入不存在")
+)
+
+// sessionTouchInterval 最後活動時間的更新間隔，避免每個請求都寫入資料庫
+const sessionTouchInterval = time.Minute
+
+// SessionMetadata 建立或刷新登入時記錄的裝置資訊
+type SessionMetadata struct {
+	DeviceName string
+	IP         string
+	UserAgent  string
+}
+
+type SessionService struct {
+	DB *gorm.DB
+}
+
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{DB: db}
+}
+
+// List 返回會員目前有效的登入，最近活動的在前
+func (s *SessionService) List(memberID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	if err := s.DB.
+		Where("member_id = ? AND revoked_at IS NULL AND expires_at > ?", memberID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Revoke 撤銷會員的某個登入，該登入的 access token 與 refresh token 立即失效
+func (s *SessionService) Revoke(memberID uint, sessionID string) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Session{}).
+			Where("member_id = ? AND session_id = ? AND revoked_at IS NULL", memberID, sessionID).
+			Updates(map[string]interface{}{
+				"revoked_at":             &now,
+				"last_modification_time": &now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSessionNotFound
+		}
+		return revokeFamily(tx, sessionID, now)
+	})
+}
+
+// RevokeAll 撤銷會員除 keepSessionID 以外的所有登入，返回撤銷的數量
+func (s *SessionService) RevokeAll(memberID uint, keepSessionID string) (int64, error) {
+	var count int64
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = revokeMemberSessions(tx, memberID, keepSessionID, time.Now())
+		return err
+	})
+	return count, err
+}
+
+// Validate 檢查 access token 所屬的登入是否仍有效，並更新最後活動時間；供 auth.SetSessionValidator 使用。
+// 代理登入 token 改為檢查代理登入紀錄
+func (s *SessionService) Validate(ctx context.Context, claims *auth.Claims) error {
+	if claims.Act != nil {
+		return validateImpersonation(s.DB.WithContext(ctx), claims)
+	}
+	if claims.SessionID == "" || claims.ID == "" {
+		return auth.ErrSessionRevoked
+	}
+
+	var session models.Session
+	if err := s.DB.WithContext(ctx).
+		Where("session_id = ?", claims.SessionID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.ErrSessionRevoked
+		}
+		return err
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) || session.MemberID != uint(claims.UserID) {
+		return auth.ErrSessionRevoked
+	}
+
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.DB.WithContext(ctx).Model(&session).
+			UpdateColumn("last_seen_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createSession 為新的登入鏈建立登入紀錄
+func createSession(tx *gorm.DB, memberID uint, sessionID string, meta SessionMetadata, now time.Time) error {
+	return tx.Create(&models.Session{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    memberID,
+		},
+		MemberID:   memberID,
+		SessionID:  sessionID,
+		DeviceName: truncate(meta.DeviceName, 255),
+		IP:         meta.IP,
+		UserAgent:  truncate(meta.UserAgent, 512),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(auth.RefreshTokenTTL()),
+	}).Error
+}
+
+// touchSession 刷新 token 時延長登入有效期並更新裝置資訊；
+// 登入紀錄功能上線前建立的登入鏈沒有紀錄，此時補建一筆
+func touchSession(tx *gorm.DB, memberID uint, sessionID string, meta SessionMetadata, now time.Time) error {
+	updates := map[string]interface{}{
+		"last_seen_at": now,
+		"expires_at":   now.Add(auth.RefreshTokenTTL()),
+	}
+	if meta.IP != "" {
+		updates["ip"] = meta.IP
+	}
+	if meta.UserAgent != "" {
+		updates["user_agent"] = truncate(meta.UserAgent, 512)
+	}
+	result := tx.Model(&models.Session{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Updates(updates)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+
+	var count int64
+	if err := tx.Model(&models.Session{}).Where("session_id = ?", sessionID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrInvalidRefreshToken
+	}
+	return createSession(tx, memberID, sessionID, meta, now)
+}
+
+// revokeMemberSessions 撤銷會員除 keepSessionID 以外的所有登入與 refresh token，並結束進行中的代理登入
+func revokeMemberSessions(tx *gorm.DB, memberID uint, keepSessionID string, now time.Time) (int64, error) {
+	sessions := tx.Model(&models.Session{}).Where("member_id = ? AND revoked_at IS NULL", memberID)
+	tokens := tx.Model(&models.RefreshToken{}).Where("member_id = ? AND revoked_at IS NULL", memberID)
+	if keepSessionID != "" {
+		sessions = sessions.Where("session_id <> ?", keepSessionID)
+		tokens = tokens.Where("family_id <> ?", keepSessionID)
+	}
+
+	result := sessions.Updates(map[string]interface{}{
+		"revoked_at":             &now,
+		"last_modification_time": &now,
+	})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if err := tokens.Updates(map[string]interface{}{
+		"revoked_at":             &now,
+		"last_modification_time": &now,
+	}).Error; err != nil {
+		return 0, err
+	}
+	if err := endMemberImpersonations(tx, memberID, now); err != nil {
+		return 0, err
+	}
+	return result.RowsAffected, nil
+}
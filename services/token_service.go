@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"member_API/auth"
+	"member_API/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("無效的 refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token 已被重複使用，該登入已全部撤銷")
+)
+
+// TokenPair 登入或刷新後返回給客戶端的 token 組合
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+type TokenService struct {
+	DB *gorm.DB
+}
+
+func NewTokenService(db *gorm.DB) *TokenService {
+	return &TokenService{DB: db}
+}
+
+// IssueTokenPair 為會員開啟新的登入（登入鏈）並簽發 access / refresh token
+func (s *TokenService) IssueTokenPair(member *models.Member, meta SessionMetadata) (*TokenPair, error) {
+	familyID, err := auth.GenerateOpaqueToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	var pair *TokenPair
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := createSession(tx, member.ID, familyID, meta, time.Now()); err != nil {
+			return err
+		}
+		pair, err = issueInFamily(tx, member, familyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Refresh 以 refresh token 換發新的 token 組合。
+// 舊 token 會被標記為已輪換；若已輪換的 token 再次被使用，視為外洩並撤銷整個登入鏈。
+// 會員不是 active 時返回帳號狀態的錯誤。
+func (s *TokenService) Refresh(rawToken string, meta SessionMetadata) (*TokenPair, *models.Member, error) {
+	var (
+		pair   *TokenPair
+		member models.Member
+		reused bool
+	)
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", auth.HashToken(rawToken)).
+			First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		if current.RevokedAt != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		now := time.Now()
+		if current.RotatedAt != nil {
+			reused = true
+			return revokeFamily(tx, current.FamilyID, now)
+		}
+		if now.After(current.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		if err := tx.Scopes(models.NotDeleted).First(&member, current.MemberID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if err := CheckMemberStatus(&member); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&current).Update("rotated_at", &now).Error; err != nil {
+			return err
+		}
+		if err := touchSession(tx, current.MemberID, current.FamilyID, meta, now); err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = issueInFamily(tx, &member, current.FamilyID)
+		return err
+	})
+
+	// 重複使用時撤銷已提交，再回報錯誤
+	if err == nil && reused {
+		return nil, nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, &member, nil
+}
+
+// Revoke 撤銷 refresh token 所屬的整個登入鏈（登出）
+func (s *TokenService) Revoke(rawToken string) error {
+	var current models.RefreshToken
+	if err := s.DB.Where("token_hash = ?", auth.HashToken(rawToken)).First(&current).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	return revokeFamily(s.DB, current.FamilyID, time.Now())
+}
+
+// RevokeAllForMember 撤銷會員所有登入與尚未失效的 refresh token
+func (s *TokenService) RevokeAllForMember(memberID uint) error {
+	_, err := revokeMemberSessions(s.DB, memberID, "", time.Now())
+	return err
+}
+
+// RevokeAllForMemberExcept 撤銷會員除 keepFamilyID 以外的所有登入，keepFamilyID 為空時全部撤銷
+func (s *TokenService) RevokeAllForMemberExcept(memberID uint, keepFamilyID string) error {
+	_, err := revokeMemberSessions(s.DB, memberID, keepFamilyID, time.Now())
+	return err
+}
+
+// issueInFamily 在指定登入鏈中建立新的 refresh token 並簽發 access token
+func issueInFamily(tx *gorm.DB, member *models.Member, familyID string) (*TokenPair, error) {
+	rawToken, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &models.RefreshToken{
+		Base: models.Base{
+			CreationTime: now,
+			CreatorId:    member.ID,
+		},
+		MemberID:  member.ID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashToken(rawToken),
+		ExpiresAt: now.Add(auth.RefreshTokenTTL()),
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return nil, err
+	}
+
+	roles, permissions, err := NewRoleService(tx).GetMemberAuthz(member.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := NewGroupService(tx).MemberGroupNames(member.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := auth.NewClaims(int64(member.ID), member.Email)
+	claims.SessionID = familyID
+	claims.Roles = roles
+	claims.Permissions = permissions
+	claims.Groups = groups
+	claims.EmailVerified = member.EmailVerifiedAt != nil
+	accessToken, err := auth.SignClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rawToken,
+		ExpiresIn:    int64(auth.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// revokeFamily 撤銷登入及登入鏈內所有尚未撤銷的 refresh token
+func revokeFamily(tx *gorm.DB, familyID string, now time.Time) error {
+	if err := tx.Model(&models.Session{}).
+		Where("session_id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]interface{}{
+			"revoked_at":             &now,
+			"last_modification_time": &now,
+		}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]interface{}{
+			"revoked_at":             &now,
+			"last_modification_time": &now,
+		}).Error
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"member_API/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotInTrash        = errors.New("資料不存在或未被刪除")
+	ErrRestoreEmailInUse = errors.New("email 已被其他會員使用，無法還原")
+)
+
+// PurgeResult 一次清除永久刪除的數量
+type PurgeResult struct {
+	Members  int64
+	Products int64
+}
+
+// TrashService 管理被軟刪除的資料：列出、還原，以及超過保留期限後永久刪除
+type TrashService struct {
+	DB *gorm.DB
+}
+
+func NewTrashService(db *gorm.DB) *TrashService {
+	return &TrashService{DB: db}
+}
+
+// ListMembers 列出回收桶中的會員，最近刪除的在前；個人資料已清除或已合併至其他會員的會員不在回收桶中
+func (s *TrashService) ListMembers(limit, offset int) ([]models.Member, int64, error) {
+	var total int64
+	if err := s.DB.Model(&models.Member{}).Scopes(models.OnlyDeleted).Where("erased_at IS NULL AND merged_into_id IS NULL").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var members []models.Member
+	if err := s.DB.Scopes(models.OnlyDeleted).
+		Where("erased_at IS NULL AND merged_into_id IS NULL").
+		Order("deleted_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&members).Error; err != nil {
+		return nil, 0, err
+	}
+	return members, total, nil
+}
+
+// ListProducts 列出回收桶中的產品，最近刪除的在前
+func (s *TrashService) ListProducts(limit, offset int) ([]models.Product, int64, error) {
+	var total int64
+	if err := s.DB.Model(&models.Product{}).Scopes(models.OnlyDeleted).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []models.Product
+	if err := s.DB.Scopes(models.OnlyDeleted).
+		Order("deleted_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// RestoreMember 從回收桶還原會員；刪除時撤銷的登入不會恢復，會員需重新登入
+func (s *TrashService) RestoreMember(id uint, actorID uint) (*models.Member, error) {
+	var member models.Member
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(models.OnlyDeleted).Where("erased_at IS NULL AND merged_into_id IS NULL").First(&member, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotInTrash
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Member{}).Scopes(models.NotDeleted, ByEmail(member.Email)).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrRestoreEmailInUse
+		}
+
+		return tx.Model(&member).Updates(models.RestoreColumns(actorID, time.Now())).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrRestoreEmailInUse
+		}
+		return nil, err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:   AuditMemberRestored,
+		MemberID: id,
+		ActorID:  actorID,
+	})
+	return &member, nil
+}
+
+// RestoreProduct 從回收桶還原產品並寫入審計紀錄
+func (s *TrashService) RestoreProduct(id uint, actorID uint) (*models.Product, error) {
+	var product models.Product
+	if err := s.DB.Scopes(models.OnlyDeleted).First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotInTrash
+		}
+		return nil, err
+	}
+	if err := s.DB.Model(&product).Updates(models.RestoreColumns(actorID, time.Now())).Error; err != nil {
+		return nil, err
+	}
+
+	NewAuditService(s.DB).TryRecord(AuditEntry{
+		Action:  AuditProductRestored,
+		ActorID: actorID,
+		Detail:  fmt.Sprintf("product=%d", product.ID),
+	})
+	return &product, nil
+}
+
+// Purge 永久刪除在回收桶中超過 retention 的會員與產品；會員的登入、token、API key、外部帳號連結等資料一併刪除，審計紀錄保留。
+// 個人資料已清除的會員保留匿名化後的資料列，已合併的會員保留供轉址，都不會被永久刪除
+func (s *TrashService) Purge(retention time.Duration) (*PurgeResult, error) {
+	cutoff := time.Now().Add(-retention)
+	result := &PurgeResult{}
+
+	var memberIDs []uint
+	if err := s.DB.Model(&models.Member{}).Scopes(models.OnlyDeleted).
+		Where("deleted_at < ? AND erased_at IS NULL AND merged_into_id IS NULL", cutoff).
+		Pluck("id", &memberIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range memberIDs {
+		if err := s.DB.Transaction(func(tx *gorm.DB) error {
+			return purgeMember(tx, id)
+		}); err != nil {
+			return result, err
+		}
+		result.Members++
+	}
+
+	deleted := s.DB.Scopes(models.OnlyDeleted).
+		Where("deleted_at < ?", cutoff).
+		Delete(&models.Product{})
+	if deleted.Error != nil {
+		return result, deleted.Error
+	}
+	result.Products = deleted.RowsAffected
+	return result, nil
+}
+
+// RunPurge 每隔 interval 清除一次超過保留期限的資料，直到 ctx 結束
+func (s *TrashService) RunPurge(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := NewTrashService(s.DB.WithContext(ctx)).Purge(retention)
+			if err != nil {
+				log.Printf("[Trash] Warning: purging deleted records failed: %v\n", err)
+				continue
+			}
+			if result.Members > 0 || result.Products > 0 {
+				log.Printf("[Trash] Purged %d members and %d products deleted more than %s ago\n", result.Members, result.Products, retention)
+			}
+		}
+	}
+}
+
+// purgeMember 永久刪除會員與其所屬資料（含點數帳本）；審計紀錄只保留會員 ID
+func purgeMember(tx *gorm.DB, memberID uint) error {
+	if err := deleteMemberData(tx, memberID); err != nil {
+		return err
+	}
+	if err := tx.Where("member_id = ?", memberID).Delete(&models.MemberStatusChange{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("member_id = ?", memberID).Delete(&models.ImpersonationSession{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("member_id = ?", memberID).Delete(&models.PointsLedgerEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("member_id = ?", memberID).Delete(&models.MemberPoints{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.Member{}, memberID).Error
+}
+
+// deleteMemberData 刪除會員的登入、token、API key、外部帳號連結、OAuth 授權、資料匯出、地址、溝通偏好、角色與群組指派
+func deleteMemberData(tx *gorm.DB, memberID uint) error {
+	var grantIDs []string
+	if err := tx.Model(&models.OAuthGrant{}).Where("member_id = ?", memberID).Pluck("grant_id", &grantIDs).Error; err != nil {
+		return err
+	}
+	if len(grantIDs) > 0 {
+		if err := tx.Where("grant_id IN ?", grantIDs).Delete(&models.OAuthRefreshToken{}).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{
+		&models.OAuthGrant{},
+		&models.OAuthAuthorizationCode{},
+		&models.OAuthConsent{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.MemberToken{},
+		&models.MFARecoveryCode{},
+		&models.ExternalIdentity{},
+		&models.APIKey{},
+		&models.DataExport{},
+		&models.MemberAddress{},
+		&models.MemberPreference{},
+	} {
+		if err := tx.Where("member_id = ?", memberID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Exec("DELETE FROM member_group_assignments WHERE member_id = ?", memberID).Error; err != nil {
+		return err
+	}
+	return tx.Exec("DELETE FROM member_roles WHERE member_id = ?", memberID).Error
+}